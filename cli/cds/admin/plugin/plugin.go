@@ -17,6 +17,7 @@ func Cmd() *cobra.Command {
 
 	cmd.AddCommand(addPluginCmd)
 	cmd.AddCommand(updatePluginCmd)
+	cmd.AddCommand(addPluginBinaryCmd)
 	cmd.AddCommand(deletePluginCmd)
 	cmd.AddCommand(downloadPluginCmd)
 	return cmd
@@ -78,6 +79,28 @@ var updatePluginCmd = &cobra.Command{
 	},
 }
 
+var addPluginBinaryCmd = &cobra.Command{
+	Use:   "binary",
+	Short: "cds plugin binary <name> <version> <os> <arch> <file>",
+	Long:  "Add the binary of a plugin version for another OS and architecture, the job can pin the version with the requirement <name>@<version>",
+	Run: func(cmd *cobra.Command, args []string) {
+		if ok, err := sdk.IsAdmin(); !ok {
+			if err != nil {
+				fmt.Printf("Error : %v\n", err)
+			}
+			sdk.Exit("You are not allowed to run this command")
+		}
+
+		if len(args) != 5 {
+			sdk.Exit("Wrong usage: %s\n", cmd.Short)
+		}
+		if err := sdk.UploadPluginBinary(args[4], args[0], args[1], args[2], args[3]); err != nil {
+			sdk.Exit("Error: cannot add binary of plugin %s (%s)\n", args[0], err)
+		}
+		fmt.Printf("OK\n")
+	},
+}
+
 var deletePluginCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "cds plugin delete <name>",
@@ -3,12 +3,14 @@ package main
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 
 	"github.com/spf13/cobra"
 
 	"github.com/ovh/cds/cli"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
 )
 
 var (
@@ -23,6 +25,8 @@ var (
 			cli.NewGetCommand(actionShowCmd, actionShowRun, nil),
 			cli.NewCommand(actionDeleteCmd, actionDeleteRun, nil),
 			cli.NewCommand(actionDocCmd, actionDocRun, nil),
+			cli.NewCommand(actionImportCmd, actionImportRun, nil),
+			cli.NewCommand(actionExportCmd, actionExportRun, nil),
 		})
 )
 
@@ -89,3 +93,71 @@ func actionDocRun(v cli.Values) error {
 	fmt.Println(sdk.ActionInfoMarkdown(action, path.Base(v["path"])))
 	return nil
 }
+
+var actionImportCmd = cli.Command{
+	Name:  "import",
+	Short: "Import a CDS action from a yaml file",
+	Long: `Import an action made of steps, with its inputs and outputs:
+
+	name: go-build
+	version: 1.0.0
+	description: Build a go program
+	inputs:
+	  package:
+	    default: ./...
+	outputs:
+	  binary:
+	    value: "{{.cds.build.gobin}}"
+	requirements:
+	- binary: go
+	steps:
+	- script:
+	  - go build -o bin {{.package}}
+	  - worker export gobin bin
+
+The action is usable as a step of a job with go-build@1.0.0, or go-build for the latest imported version.
+The outputs are available in the next steps as {{.cds.build.<output>}}.`,
+	Args: []cli.Arg{
+		{Name: "path"},
+	},
+}
+
+func actionImportRun(v cli.Values) error {
+	f, err := os.Open(v["path"])
+	if err != nil {
+		return fmt.Errorf("Error while opening file: %s", err)
+	}
+	defer f.Close()
+
+	a, err := client.ActionImport(f)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Action %s imported\n", a.Name)
+	return nil
+}
+
+var actionExportCmd = cli.Command{
+	Name:  "export",
+	Short: "Export a CDS action as yaml",
+	Args: []cli.Arg{
+		{Name: "action-name"},
+	},
+}
+
+func actionExportRun(v cli.Values) error {
+	a, err := client.ActionGet(v["action-name"])
+	if err != nil {
+		return err
+	}
+	if a.Type != sdk.DefaultAction {
+		return fmt.Errorf("Action %s is a %s action, it cannot be exported", a.Name, a.Type)
+	}
+
+	btes, err := exportentities.Marshal(exportentities.NewAction(*a), exportentities.FormatYAML)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(btes))
+	return nil
+}
@@ -29,6 +29,7 @@ var (
 			environmentKey,
 			environmentVariable,
 			environmentGroup,
+			cli.NewListCommand(environmentDeploymentsCmd, environmentDeploymentsRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(environmentExportCmd, environmentExportRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(environmentImportCmd, environmentImportRun, nil, withAllCommandModifiers()...),
 		})
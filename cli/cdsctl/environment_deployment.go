@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/ovh/cds/cli"
+)
+
+var environmentDeploymentsCmd = cli.Command{
+	Name:  "deployments",
+	Short: "List the deployments on a CDS environment",
+	Long:  "List the application versions currently deployed on a CDS environment, or the deployments history with flag --history",
+	Example: `
+		cdsctl environment deployments MYPROJECT production # Versions currently deployed on production
+		cdsctl environment deployments MYPROJECT production --history --application myapp --limit 50
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "environment-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "history",
+			Usage: "Display the deployments history",
+			Kind:  reflect.Bool,
+		},
+		{
+			Name:  "application",
+			Usage: "Filter the deployments history on an application",
+			Kind:  reflect.String,
+		},
+		{
+			Name:  "offset",
+			Usage: "Offset of the deployments history",
+			IsValid: func(s string) bool {
+				_, err := strconv.ParseInt(s, 10, 64)
+				return s == "" || err == nil
+			},
+			Kind: reflect.String,
+		},
+		{
+			Name:  "limit",
+			Usage: "Number of deployments of the history to display",
+			IsValid: func(s string) bool {
+				_, err := strconv.ParseInt(s, 10, 64)
+				return s == "" || err == nil
+			},
+			Kind: reflect.String,
+		},
+	},
+}
+
+func environmentDeploymentsRun(v cli.Values) (cli.ListResult, error) {
+	if !v.GetBool("history") {
+		deployments, err := client.EnvironmentDeployments(v[_ProjectKey], v["environment-name"])
+		if err != nil {
+			return nil, err
+		}
+		return cli.AsListResult(deployments), nil
+	}
+
+	var offset, limit int64
+	if v.GetString("offset") != "" {
+		var err error
+		if offset, err = v.GetInt64("offset"); err != nil {
+			return nil, err
+		}
+	}
+	if v.GetString("limit") != "" {
+		var err error
+		if limit, err = v.GetInt64("limit"); err != nil {
+			return nil, err
+		}
+	}
+
+	deployments, err := client.EnvironmentDeploymentsHistory(v[_ProjectKey], v["environment-name"], v.GetString("application"), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(deployments), nil
+}
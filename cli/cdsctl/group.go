@@ -22,6 +22,7 @@ var (
 			cli.NewCommand(groupCreateCmd, groupCreateRun, nil),
 			cli.NewDeleteCommand(groupDeleteCmd, groupDeleteRun, nil),
 			groupUser,
+			groupQuota,
 		})
 )
 
@@ -25,6 +25,7 @@ func main() {
 	update := cli.NewCommand(updateCmd, updateRun, nil, cli.CommandWithoutExtraFlags)
 	version := cli.NewCommand(versionCmd, versionRun, nil, cli.CommandWithoutExtraFlags)
 	doc := cli.NewCommand(docCmd, docRun, nil, cli.CommandWithoutExtraFlags)
+	validate := cli.NewCommand(validateCmd, validateRun, nil, cli.CommandWithoutExtraFlags)
 	monitoring := cli.NewGetCommand(monitoringCmd, monitoringRun, nil, cli.CommandWithoutExtraFlags)
 
 	root = cli.NewCommand(mainCmd, mainRun,
@@ -41,6 +42,7 @@ func main() {
 			project,
 			worker,
 			workflow,
+			validate,
 			update,
 			usr,
 			monitoring,
@@ -55,8 +57,8 @@ func main() {
 	root.PersistentFlags().BoolVarP(&noWarnings, "no-warnings", "w", false, "do not display warnings")
 	root.PersistentFlags().BoolVarP(&insecureSkipVerifyTLS, "insecure", "k", false, `(SSL) This option explicitly allows curl to perform "insecure" SSL connections and transfers.`)
 	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		//Do not load config on login
-		if cmd == login || cmd == signup || cmd == doc || (cmd.Run == nil && cmd.RunE == nil) {
+		//Do not load config on login, validate works offline
+		if cmd == login || cmd == signup || cmd == doc || cmd == validate || (cmd.Run == nil && cmd.RunE == nil) {
 			return
 		}
 
@@ -27,6 +27,9 @@ var (
 			projectKey,
 			projectGroup,
 			projectVariable,
+			projectQuota,
+			projectVault,
+			cli.NewListCommand(projectMigrateCmd, projectMigrateRun, nil, withAllCommandModifiers()...),
 		})
 )
 
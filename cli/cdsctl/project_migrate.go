@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/ovh/cds/cli"
+)
+
+var projectMigrateCmd = cli.Command{
+	Name:  "migrate",
+	Short: "Migrate the applications and pipelines of a project to workflows (admin only)",
+	Long: `Migrate the applications and pipelines of a project to workflows (admin only).
+
+The pipelines of each application which is not migrated yet are converted to workflows, with their triggers.
+The schedulers of the root pipelines are converted to scheduler hooks, their pollers and repository hooks to repository webhooks.
+The report lists the converted items and the reason why the other ones cannot be converted. Use --dry-run to get the report without migrating.`,
+	Example: `
+		cdsctl project migrate MYPROJECT --dry-run
+		cdsctl project migrate MYPROJECT
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Flags: []cli.Flag{
+		{
+			Kind:    reflect.Bool,
+			Name:    "dry-run",
+			Usage:   "Only report what would be converted",
+			Default: "false",
+		},
+		{
+			Kind:    reflect.Bool,
+			Name:    "force",
+			Usage:   "Replace the existing workflows with the names of the migrated ones",
+			Default: "false",
+		},
+	},
+}
+
+func projectMigrateRun(v cli.Values) (cli.ListResult, error) {
+	report, err := client.ProjectMigrate(v[_ProjectKey], v.GetBool("dry-run"), v.GetBool("force"))
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(report.Items), nil
+}
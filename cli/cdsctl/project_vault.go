@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var (
+	projectVaultCmd = cli.Command{
+		Name:  "vault",
+		Short: "Manage CDS project vault, used to resolve the vault variables",
+	}
+
+	projectVault = cli.NewCommand(projectVaultCmd, nil,
+		[]*cobra.Command{
+			cli.NewGetCommand(projectVaultShowCmd, projectVaultShowRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(projectVaultSetCmd, projectVaultSetRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(projectVaultDeleteCmd, projectVaultDeleteRun, nil, withAllCommandModifiers()...),
+		})
+)
+
+var projectVaultShowCmd = cli.Command{
+	Name:  "show",
+	Short: "Show the vault configuration of a project",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectVaultShowRun(v cli.Values) (interface{}, error) {
+	return client.ProjectVaultGet(v[_ProjectKey])
+}
+
+var projectVaultSetCmd = cli.Command{
+	Name:  "set",
+	Short: "Set the vault configuration of a project. auth-method can be token or approle",
+	Long: `Set the vault configuration of a project. auth-method can be token or approle.
+
+Variables of type vault are formatted as path#field, for instance secret/data/myapp#password.
+They are resolved with this configuration when a job is taken by a worker.`,
+	Example: `
+		cdsctl project vault set MYPROJECT https://vault.local:8200 token --token s.xxxxx
+		cdsctl project vault set MYPROJECT https://vault.local:8200 approle --role-id my-role --secret-id xxxxx
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "address"},
+		{Name: "auth-method"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "token",
+			Usage: "Vault token, for the token authentication method",
+			Kind:  reflect.String,
+		},
+		{
+			Name:  "role-id",
+			Usage: "AppRole role ID, for the approle authentication method",
+			Kind:  reflect.String,
+		},
+		{
+			Name:  "secret-id",
+			Usage: "AppRole secret ID, for the approle authentication method",
+			Kind:  reflect.String,
+		},
+	},
+}
+
+func projectVaultSetRun(v cli.Values) error {
+	pv := sdk.ProjectVault{
+		Address:    v.GetString("address"),
+		AuthMethod: v.GetString("auth-method"),
+		Token:      v.GetString("token"),
+		RoleID:     v.GetString("role-id"),
+		SecretID:   v.GetString("secret-id"),
+	}
+	if err := pv.IsValid(); err != nil {
+		return err
+	}
+	return client.ProjectVaultSet(v[_ProjectKey], pv)
+}
+
+var projectVaultDeleteCmd = cli.Command{
+	Name:  "delete",
+	Short: "Delete the vault configuration of a project",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectVaultDeleteRun(v cli.Values) error {
+	return client.ProjectVaultDelete(v[_ProjectKey])
+}
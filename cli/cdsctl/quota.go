@@ -0,0 +1,182 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var quotaFlags = []cli.Flag{
+	{
+		Name:  "max-concurrent-jobs",
+		Usage: "Maximum number of jobs running at once, 0 means unlimited",
+		Kind:  reflect.String,
+	},
+	{
+		Name:  "max-daily-worker-minutes",
+		Usage: "Maximum number of worker minutes consumed over the last 24 hours, 0 means unlimited",
+		Kind:  reflect.String,
+	},
+	{
+		Name:  "priority",
+		Usage: "Priority of the jobs in the queue, jobs with the highest priority are served first",
+		Kind:  reflect.String,
+	},
+}
+
+func quotaFromValues(v cli.Values) (sdk.Quota, error) {
+	var q sdk.Quota
+	maxJobs, err := v.GetInt64("max-concurrent-jobs")
+	if err != nil {
+		return q, err
+	}
+	maxMinutes, err := v.GetInt64("max-daily-worker-minutes")
+	if err != nil {
+		return q, err
+	}
+	priority, err := v.GetInt64("priority")
+	if err != nil {
+		return q, err
+	}
+	q.MaxConcurrentJobs = int(maxJobs)
+	q.MaxDailyWorkerMinutes = maxMinutes
+	q.Priority = int(priority)
+	return q, q.IsValid()
+}
+
+var (
+	projectQuotaCmd = cli.Command{
+		Name:  "quota",
+		Short: "Manage CDS project quota",
+	}
+
+	projectQuota = cli.NewCommand(projectQuotaCmd, nil,
+		[]*cobra.Command{
+			cli.NewListCommand(projectQuotaShowCmd, projectQuotaShowRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(projectQuotaSetCmd, projectQuotaSetRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(projectQuotaDeleteCmd, projectQuotaDeleteRun, nil, withAllCommandModifiers()...),
+		})
+)
+
+var projectQuotaShowCmd = cli.Command{
+	Name:  "show",
+	Short: "Show the usage of the quotas applied on a project: the project quota and the quotas of its groups",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectQuotaShowRun(v cli.Values) (cli.ListResult, error) {
+	usages, err := client.ProjectQuota(v[_ProjectKey])
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(usages), nil
+}
+
+var projectQuotaSetCmd = cli.Command{
+	Name:  "set",
+	Short: "Set the quota of a project (admin only)",
+	Example: `
+		cdsctl project quota set MYPROJECT --max-concurrent-jobs 10 --max-daily-worker-minutes 6000 --priority 5
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Flags: quotaFlags,
+}
+
+func projectQuotaSetRun(v cli.Values) error {
+	q, err := quotaFromValues(v)
+	if err != nil {
+		return err
+	}
+	return client.ProjectQuotaSet(v[_ProjectKey], q)
+}
+
+var projectQuotaDeleteCmd = cli.Command{
+	Name:  "delete",
+	Short: "Delete the quota of a project (admin only)",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectQuotaDeleteRun(v cli.Values) error {
+	return client.ProjectQuotaDelete(v[_ProjectKey])
+}
+
+var (
+	groupQuotaCmd = cli.Command{
+		Name:  "quota",
+		Short: "Manage CDS group quota",
+	}
+
+	groupQuota = cli.NewCommand(groupQuotaCmd, nil,
+		[]*cobra.Command{
+			cli.NewListCommand(groupQuotaListCmd, groupQuotaListRun, nil),
+			cli.NewGetCommand(groupQuotaShowCmd, groupQuotaShowRun, nil),
+			cli.NewCommand(groupQuotaSetCmd, groupQuotaSetRun, nil),
+			cli.NewCommand(groupQuotaDeleteCmd, groupQuotaDeleteRun, nil),
+		})
+)
+
+var groupQuotaListCmd = cli.Command{
+	Name:  "list",
+	Short: "List the usage of all the projects and groups quotas (admin only)",
+}
+
+func groupQuotaListRun(v cli.Values) (cli.ListResult, error) {
+	usages, err := client.QuotaList()
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(usages), nil
+}
+
+var groupQuotaShowCmd = cli.Command{
+	Name:  "show",
+	Short: "Show the usage of the quota of a group. It applies to all the projects on which the group has the write permission",
+	Args: []cli.Arg{
+		{Name: "group-name"},
+	},
+}
+
+func groupQuotaShowRun(v cli.Values) (interface{}, error) {
+	return client.GroupQuota(v["group-name"])
+}
+
+var groupQuotaSetCmd = cli.Command{
+	Name:  "set",
+	Short: "Set the quota of a group (admin only)",
+	Example: `
+		cdsctl group quota set mygroup --max-concurrent-jobs 50
+	`,
+	Args: []cli.Arg{
+		{Name: "group-name"},
+	},
+	Flags: quotaFlags,
+}
+
+func groupQuotaSetRun(v cli.Values) error {
+	q, err := quotaFromValues(v)
+	if err != nil {
+		return err
+	}
+	return client.GroupQuotaSet(v["group-name"], q)
+}
+
+var groupQuotaDeleteCmd = cli.Command{
+	Name:  "delete",
+	Short: "Delete the quota of a group (admin only)",
+	Args: []cli.Arg{
+		{Name: "group-name"},
+	},
+}
+
+func groupQuotaDeleteRun(v cli.Values) error {
+	return client.GroupQuotaDelete(v["group-name"])
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+var validateCmd = cli.Command{
+	Name:  "validate",
+	Short: "Check the structure of workflow, pipeline, application and environment files",
+	Long: `
+		Check the structure of yaml files without calling the CDS API, so it can be used as a git pre-commit hook.
+
+		The kind of each file is given by its name: *.pip.yml for pipelines, *.app.yml for applications,
+		*.env.yml for environments, *.action.yml for actions and *.yml for workflows.
+
+		It reports the unknown and duplicated keys, the unsupported when conditions and condition operators,
+		the cycles in the nodes dependencies, the unknown hook types, the invalid steps and variable types.
+		Each error is displayed as file:line:column: message.
+	`,
+	Example: `
+		cdsctl validate my-workflow.yml build.pip.yml my-app.app.yml
+
+		# .git/hooks/pre-commit
+		git diff --cached --name-only --diff-filter=ACM | grep -E '\.ya?ml$' | xargs -r cdsctl validate
+	`,
+	VariadicArgs: cli.Arg{
+		Name: "yaml-file",
+	},
+}
+
+func validateRun(c cli.Values) error {
+	var nbErrors int
+	for _, file := range c.GetStringSlice("yaml-file") {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		for _, e := range exportentities.Validate(file, content) {
+			fmt.Println(e.Error())
+			nbErrors++
+		}
+	}
+	if nbErrors > 0 {
+		return fmt.Errorf("%d error(s) found", nbErrors)
+	}
+	return nil
+}
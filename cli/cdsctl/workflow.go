@@ -24,8 +24,13 @@ var (
 			cli.NewGetCommand(workflowStatusCmd, workflowStatusRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowRunManualCmd, workflowRunManualRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowStopCmd, workflowStopRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(workflowLogsCmd, workflowLogsRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(workflowApproveCmd, workflowApproveRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(workflowRejectCmd, workflowRejectRun, nil, withAllCommandModifiers()...),
+			cli.NewListCommand(workflowConcurrencyCmd, workflowConcurrencyRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowExportCmd, workflowExportRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowImportCmd, workflowImportRun, nil, withAllCommandModifiers()...),
+			cli.NewCommand(workflowLintCmd, workflowLintRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowPullCmd, workflowPullRun, nil, withAllCommandModifiers()...),
 			cli.NewCommand(workflowPushCmd, workflowPushRun, nil, withAllCommandModifiers()...),
 			workflowArtifact,
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var workflowApproveCmd = cli.Command{
+	Name:  "approve",
+	Short: "Approve a CDS workflow node waiting for approval",
+	Long:  "Approve a CDS workflow node waiting for approval. The node run starts as soon as the node gets enough approvals",
+	Example: `
+		cdsctl workflow approve MYPROJECT myworkflow 5 deploy-prod # To approve the node deploy-prod on workflow run 5
+		cdsctl workflow approve MYPROJECT myworkflow 5 deploy-prod --comment "Release validated by QA"
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "run-number"},
+		{Name: "node-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "comment",
+			Usage: "Comment of the approval",
+			Kind:  reflect.String,
+		},
+	},
+}
+
+func workflowApproveRun(v cli.Values) error {
+	return workflowDecideRun(v, true)
+}
+
+var workflowRejectCmd = cli.Command{
+	Name:  "reject",
+	Short: "Reject a CDS workflow node waiting for approval",
+	Long:  "Reject a CDS workflow node waiting for approval. The node will not be run",
+	Example: `
+		cdsctl workflow reject MYPROJECT myworkflow 5 deploy-prod --comment "Wrong version"
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "run-number"},
+		{Name: "node-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "comment",
+			Usage: "Comment of the rejection",
+			Kind:  reflect.String,
+		},
+	},
+}
+
+func workflowRejectRun(v cli.Values) error {
+	return workflowDecideRun(v, false)
+}
+
+func workflowDecideRun(v cli.Values, approved bool) error {
+	runNumber, err := v.GetInt64("run-number")
+	if err != nil {
+		return err
+	}
+
+	wr, err := client.WorkflowRunGet(v[_ProjectKey], v[_WorkflowName], runNumber)
+	if err != nil {
+		return err
+	}
+
+	node := wr.Workflow.GetNodeByName(v.GetString("node-name"))
+	if node == nil {
+		return fmt.Errorf("Node %s not found", v.GetString("node-name"))
+	}
+
+	var a *sdk.WorkflowNodeApproval
+	if approved {
+		a, err = client.WorkflowNodeApprove(v[_ProjectKey], v[_WorkflowName], runNumber, node.ID, v.GetString("comment"))
+	} else {
+		a, err = client.WorkflowNodeReject(v[_ProjectKey], v[_WorkflowName], runNumber, node.ID, v.GetString("comment"))
+	}
+	if err != nil {
+		return err
+	}
+
+	switch a.Status {
+	case sdk.WorkflowNodeApprovalWaiting:
+		fmt.Printf("Workflow node %s on workflow %s #%d is waiting for %d more approval(s)\n", node.Name, v[_WorkflowName], runNumber, a.Rule.Required()-a.Approvals())
+	default:
+		fmt.Printf("Workflow node %s on workflow %s #%d: %s\n", node.Name, v[_WorkflowName], runNumber, a.Status)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/ovh/cds/cli"
+)
+
+var workflowConcurrencyCmd = cli.Command{
+	Name:  "concurrency",
+	Short: "List workflow node runs holding or waiting for a concurrency group",
+	Example: `
+		cdsctl workflow concurrency deploy-prod
+	`,
+	Args: []cli.Arg{
+		{Name: "group"},
+	},
+}
+
+func workflowConcurrencyRun(v cli.Values) (cli.ListResult, error) {
+	runs, err := client.WorkflowConcurrencyGroup(v.GetString("group"))
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(runs), nil
+}
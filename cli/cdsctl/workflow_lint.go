@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ovh/cds/cli"
+)
+
+var workflowLintCmd = cli.Command{
+	Name:  "lint",
+	Short: "Check a workflow file for configuration errors",
+	Long: `
+		Check a workflow file without importing it. The pipelines, applications and environments used by the workflow must exist in the project.
+
+		It reports the variables used but never defined, the nodes which will never be triggered, the conditions on unknown variables,
+		the repository hooks on applications without repository and the jobs requirements that no worker model can satisfy.
+	`,
+	Example: `
+		cdsctl workflow lint MYPROJECT my-workflow.yml
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "filename"},
+	},
+}
+
+func workflowLintRun(c cli.Values) error {
+	path := c.GetString("filename")
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var format = "yaml"
+	if strings.HasSuffix(path, ".json") {
+		format = "json"
+	}
+
+	warnings, err := client.WorkflowLintFile(c.GetString(_ProjectKey), f, format)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Println(w.Message)
+	}
+	if len(warnings) > 0 {
+		return fmt.Errorf("%d warning(s) found in %s", len(warnings), path)
+	}
+	return nil
+}
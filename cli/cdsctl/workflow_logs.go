@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+var workflowLogsCmd = cli.Command{
+	Name:  "logs",
+	Short: "Show the logs of the steps of a Workflow Run",
+	Long: `Show the logs of all the steps of a workflow run, or only the last lines of each step with --tail:
+
+	$ cdsctl workflow logs MYPROJ my-workflow 42 --tail 100`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "number"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "tail",
+			Usage: "Show only the last lines of the logs of each step",
+			IsValid: func(s string) bool {
+				if s == "" {
+					return true
+				}
+				n, err := strconv.Atoi(s)
+				return err == nil && n >= 0
+			},
+			Kind: reflect.String,
+		},
+	},
+}
+
+func workflowLogsRun(v cli.Values) error {
+	number, err := strconv.ParseInt(v["number"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("number parameter have to be an integer")
+	}
+
+	var mods []cdsclient.RequestModifier
+	if tail := v.GetString("tail"); tail != "" {
+		mods = append(mods, cdsclient.WithQueryParameter("tail", tail))
+	}
+
+	wr, err := client.WorkflowRunGet(v[_ProjectKey], v[_WorkflowName], number)
+	if err != nil {
+		return err
+	}
+
+	var nodeRuns []sdk.WorkflowNodeRun
+	for _, wnrs := range wr.WorkflowNodeRuns {
+		nodeRuns = append(nodeRuns, wnrs...)
+	}
+	sort.Slice(nodeRuns, func(i, j int) bool { return nodeRuns[i].ID < nodeRuns[j].ID })
+
+	for _, wnr := range nodeRuns {
+		var nodeName string
+		if wn := wr.Workflow.GetNode(wnr.WorkflowNodeID); wn != nil {
+			nodeName = wn.Name
+		}
+		for _, stage := range wnr.Stages {
+			for _, job := range stage.RunJobs {
+				for _, step := range job.Job.StepStatus {
+					buildState, err := client.WorkflowNodeRunJobStep(v[_ProjectKey], v[_WorkflowName], number, wnr.ID, job.ID, step.StepOrder, mods...)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("==> %s/%s/%s/%s step %d (%s)\n", v[_WorkflowName], nodeName, stage.Name, job.Job.Action.Name, step.StepOrder, step.Status)
+					fmt.Println(buildState.StepLogs.Val)
+				}
+			}
+		}
+	}
+	return nil
+}
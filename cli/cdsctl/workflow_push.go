@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
-	"strings"
 
 	"github.com/ovh/cds/cli"
 )
@@ -29,7 +28,7 @@ var workflowPushCmd = cli.Command{
 
 func workflowPushRun(c cli.Values) error {
 	// Get the file
-	files := strings.Split(c.GetString("yaml-file"), ",")
+	files := c.GetStringSlice("yaml-file")
 
 	// Create a buffer to write our archive to.
 	buf := new(bytes.Buffer)
@@ -67,10 +67,15 @@ var workflowRunManualCmd = cli.Command{
 			Usage: "Node Name to relaunch; Flag run-number is mandatory",
 			Kind:  reflect.String,
 		},
+		{
+			Name:  "resume-from",
+			Usage: "Resume the pipeline of node-name from its first failed stage with 'failed', or from a job with its name, reusing the successful jobs; Flags run-number and node-name are mandatory",
+			Kind:  reflect.String,
+		},
 		{
 			Name:      "interactive",
 			ShortHand: "i",
-			Usage:     "Follow the workflow run in an interactive terminal user interface",
+			Usage:     "Prompt for the workflow run parameters and follow the workflow run in an interactive terminal user interface",
 			Kind:      reflect.Bool,
 		},
 		{
@@ -105,6 +110,12 @@ func workflowRunManualRun(v cli.Values) error {
 
 	var runNumber, fromNodeID int64
 
+	if v.GetBool("interactive") && v.GetString("run-number") == "" {
+		if err := workflowRunInteractiveParameters(v, &manual); err != nil {
+			return err
+		}
+	}
+
 	if v.GetString("run-number") != "" {
 		var errp error
 		runNumber, errp = strconv.ParseInt(v.GetString("run-number"), 10, 64)
@@ -132,6 +143,16 @@ func workflowRunManualRun(v cli.Values) error {
 		}
 	}
 
+	if resumeFrom := v.GetString("resume-from"); resumeFrom != "" {
+		if fromNodeID == 0 {
+			return fmt.Errorf("You can use flag resume-from only with flags run-number and node-name")
+		}
+		manual.ResumeFrom = &sdk.WorkflowNodeRunResume{}
+		if resumeFrom != "failed" {
+			manual.ResumeFrom.Job = resumeFrom
+		}
+	}
+
 	w, err := client.WorkflowRunFromManual(v[_ProjectKey], v[_WorkflowName], manual, runNumber, fromNodeID)
 	if err != nil {
 		return err
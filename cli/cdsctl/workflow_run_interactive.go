@@ -114,3 +114,79 @@ func workflowRunInteractive(v cli.Values, w *sdk.WorkflowRun, baseURL string) er
 	tm.Flush()
 	return nil
 }
+
+func workflowRunInteractiveParameters(v cli.Values, manual *sdk.WorkflowNodeRunManual) error {
+	wf, err := client.WorkflowGet(v[_ProjectKey], v[_WorkflowName])
+	if err != nil {
+		return err
+	}
+	if len(wf.RunParameters) == 0 {
+		return nil
+	}
+
+	payload, ok := manual.Payload.(map[string]interface{})
+	if !ok || payload == nil {
+		payload = map[string]interface{}{}
+	}
+	params := sdk.ParametersToMap(manual.PipelineParameters)
+
+	for _, s := range wf.RunParameters {
+		if _, has := payload[s.Name]; has {
+			continue
+		}
+		if _, has := params[s.Name]; has {
+			continue
+		}
+
+		value := workflowRunAskParameter(s)
+		if value != "" {
+			payload[s.Name] = value
+		}
+	}
+
+	if len(payload) > 0 {
+		manual.Payload = payload
+	}
+	return nil
+}
+
+func workflowRunAskParameter(s sdk.WorkflowParameterSchema) string {
+	question := s.Name
+	if s.Description != "" {
+		question = fmt.Sprintf("%s (%s)", s.Name, s.Description)
+	}
+
+	if len(s.Enum) > 0 {
+		return cli.MultiChoice(question, s.Enum...)
+	}
+
+	if s.Type == sdk.WorkflowRunParameterBoolean && s.Default == "" {
+		return fmt.Sprintf("%t", cli.AskForConfirmation(question))
+	}
+
+	for {
+		if s.Default != "" {
+			fmt.Printf("%s [%s]: ", question, s.Default)
+		} else {
+			fmt.Printf("%s: ", question)
+		}
+
+		value := strings.TrimSpace(cli.ReadLine())
+		if value == "" {
+			value = s.Default
+		}
+		if value == "" {
+			if !s.Required {
+				return ""
+			}
+			fmt.Println("this parameter is required")
+			continue
+		}
+
+		if err := s.Check(value); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return value
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/ovh/cds/cli"
 )
@@ -14,6 +15,7 @@ var workflowStopCmd = cli.Command{
 		cdsctl workflow stop # Stop the workflow run for the current repo and the current hash
 		cdsctl workflow stop MYPROJECT myworkflow 5 # To stop a workflow run on number 5
 		cdsctl workflow stop MYPROJECT myworkflow 5 compile # To stop a workflow node run on workflow run 5
+		cdsctl workflow stop MYPROJECT myworkflow 5 --reason "wrong branch" # To stop a workflow run with a reason
 	`,
 	Ctx: []cli.Arg{
 		{Name: _ProjectKey},
@@ -29,6 +31,13 @@ var workflowStopCmd = cli.Command{
 			Weight: 2,
 		},
 	},
+	Flags: []cli.Flag{
+		{
+			Name:  "reason",
+			Usage: "Reason of the stop, recorded on the stopped jobs",
+			Kind:  reflect.String,
+		},
+	},
 }
 
 func workflowStopRun(v cli.Values) error {
@@ -62,13 +71,13 @@ func workflowStopRun(v cli.Values) error {
 	}
 
 	if fromNodeID != 0 {
-		wNodeRun, err := client.WorkflowNodeStop(v[_ProjectKey], v[_WorkflowName], runNumber, fromNodeID)
+		wNodeRun, err := client.WorkflowNodeStop(v[_ProjectKey], v[_WorkflowName], runNumber, fromNodeID, v.GetString("reason"))
 		if err != nil {
 			return err
 		}
 		fmt.Printf("Workflow node %s from workflow %s #%d has been stopped\n", v.GetString("node-name"), v[_WorkflowName], wNodeRun.Number)
 	} else {
-		w, err := client.WorkflowStop(v[_ProjectKey], v[_WorkflowName], runNumber)
+		w, err := client.WorkflowStop(v[_ProjectKey], v[_WorkflowName], runNumber, v.GetString("reason"))
 		if err != nil {
 			return err
 		}
@@ -142,7 +142,8 @@ func newCommand(c Command, run interface{}, subCommands []*cobra.Command, mods .
 				}
 				vals[s] = args[i]
 			} else {
-				vals[c.VariadicArgs.Name] = strings.Join(args[i:], ",")
+				// each variadic arg is kept as is, they are read with GetStringSlice
+				vals[c.VariadicArgs.Name] = strings.Join(args[i:], "||")
 				break
 			}
 		}
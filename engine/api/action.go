@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/go-gorp/gorp"
 	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v2"
 
 	"github.com/ovh/cds/engine/api/action"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
 	"github.com/ovh/cds/sdk/log"
 )
 
@@ -258,6 +261,7 @@ func (api *API) importActionHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		var a *sdk.Action
 		url := r.Form.Get("url")
+		contentType := r.Header.Get("Content-Type")
 		//Load action from url
 		if url != "" {
 			var errnew error
@@ -282,6 +286,20 @@ func (api *API) importActionHandler() Handler {
 			if errnew != nil {
 				return errnew
 			}
+		} else if contentType == "application/x-yaml" || contentType == "text/x-yaml" { // an action is posted as code
+			body, errRead := ioutil.ReadAll(r.Body)
+			if errRead != nil {
+				return sdk.NewError(sdk.ErrWrongRequest, errRead)
+			}
+			var ea exportentities.Action
+			if err := yaml.Unmarshal(body, &ea); err != nil {
+				return sdk.NewError(sdk.ErrWrongRequest, err)
+			}
+			var errnew error
+			a, errnew = ea.Action()
+			if errnew != nil {
+				return sdk.WrapError(errnew, "importActionHandler> Invalid action")
+			}
 		} else { // a jsonified action is posted in body
 			if err := UnmarshalBody(r, &a); err != nil {
 				return err
@@ -299,30 +317,19 @@ func (api *API) importActionHandler() Handler {
 
 		defer tx.Rollback()
 
-		//Check if action exists
-		exist := false
-		existingAction, errload := action.LoadPublicAction(tx, a.Name)
-		if errload == nil {
-			exist = true
-			a.ID = existingAction.ID
+		code, err := importAction(tx, a, getUser(ctx).ID)
+		if err != nil {
+			return sdk.WrapError(err, "importActionHandler> Unable to import action %s", a.Name)
 		}
 
-		//http code status
-		var code int
-
-		//Update or Insert the action
-		if exist {
-			if err := action.UpdateActionDB(tx, a, getUser(ctx).ID); err != nil {
-				return err
+		// The action without version is the latest imported version
+		if name, version := sdk.ParseActionName(a.Name); version != "" {
+			latest := *a
+			latest.ID = 0
+			latest.Name = name
+			if _, err := importAction(tx, &latest, getUser(ctx).ID); err != nil {
+				return sdk.WrapError(err, "importActionHandler> Unable to import action %s", name)
 			}
-			code = 200
-		} else {
-			a.Enabled = true
-			a.Type = sdk.DefaultAction
-			if err := action.InsertAction(tx, a, true); err != nil {
-				return err
-			}
-			code = 201
 		}
 
 		if err := tx.Commit(); err != nil {
@@ -332,3 +339,27 @@ func (api *API) importActionHandler() Handler {
 		return WriteJSON(w, r, a, code)
 	}
 }
+
+// importAction inserts or updates a public action, it returns the http status of the import.
+// The builtin and plugin actions cannot be replaced by an import
+func importAction(tx gorp.SqlExecutor, a *sdk.Action, userID int64) (int, error) {
+	existingAction, errload := action.LoadPublicAction(tx, a.Name)
+	if errload == nil {
+		if existingAction.Type != sdk.DefaultAction {
+			return 0, sdk.WrapError(sdk.ErrForbidden, "importAction> Action %s is a %s action, it cannot be imported", a.Name, existingAction.Type)
+		}
+		a.ID = existingAction.ID
+		a.Type = sdk.DefaultAction
+		if err := action.UpdateActionDB(tx, a, userID); err != nil {
+			return 0, err
+		}
+		return http.StatusOK, nil
+	}
+
+	a.Enabled = true
+	a.Type = sdk.DefaultAction
+	if err := action.InsertAction(tx, a, true); err != nil {
+		return 0, err
+	}
+	return http.StatusCreated, nil
+}
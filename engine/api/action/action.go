@@ -84,7 +84,7 @@ func InsertAction(tx gorp.SqlExecutor, a *sdk.Action, public bool) error {
 		for _, cr := range c.Requirements {
 			found := false
 			for _, pr := range a.Requirements {
-				if pr.Type == cr.Type && pr.Value == cr.Value {
+				if isSameRequirement(pr, cr) {
 					found = true
 					break
 				}
@@ -100,6 +100,12 @@ func InsertAction(tx gorp.SqlExecutor, a *sdk.Action, public bool) error {
 		}
 	}
 
+	for i := range a.Outputs {
+		if err := InsertActionOutput(tx, a.ID, a.Outputs[i]); err != nil {
+			return err
+		}
+	}
+
 	for i := range a.Parameters {
 		if err := InsertActionParameter(tx, a.ID, a.Parameters[i]); err != nil {
 			return sdk.WrapError(err, "InsertAction> Cannot InsertActionParameter %s", a.Parameters[i].Name)
@@ -215,6 +221,12 @@ func loadActionDependencies(db gorp.SqlExecutor, a *sdk.Action) error {
 		return fmt.Errorf("cannot LoadActionParameters> %s", err)
 	}
 
+	// Load outputs
+	a.Outputs, err = LoadActionOutputs(db, a.ID)
+	if err != nil {
+		return fmt.Errorf("cannot LoadActionOutputs> %s", err)
+	}
+
 	// Don't try to load children is action is builtin
 	if a.Type == sdk.BuiltinAction {
 		return nil
@@ -232,7 +244,7 @@ func loadActionDependencies(db gorp.SqlExecutor, a *sdk.Action) error {
 		for _, cr := range c.Requirements {
 			found := false
 			for _, pr := range a.Requirements {
-				if pr.Type == cr.Type && pr.Value == cr.Value {
+				if isSameRequirement(pr, cr) {
 					found = true
 					break
 				}
@@ -287,6 +299,15 @@ func UpdateActionDB(db gorp.SqlExecutor, a *sdk.Action, userID int64) error {
 		}
 	}
 
+	if err := DeleteActionOutputs(db, a.ID); err != nil {
+		return err
+	}
+	for i := range a.Outputs {
+		if err := InsertActionOutput(db, a.ID, a.Outputs[i]); err != nil {
+			return err
+		}
+	}
+
 	if err := DeleteActionRequirements(db, a.ID); err != nil {
 		return err
 	}
@@ -298,7 +319,7 @@ func UpdateActionDB(db gorp.SqlExecutor, a *sdk.Action, userID int64) error {
 		for _, cr := range c.Requirements {
 			found := false
 			for _, pr := range a.Requirements {
-				if pr.Type == cr.Type && pr.Value == cr.Value {
+				if isSameRequirement(pr, cr) {
 					found = true
 					break
 				}
@@ -355,6 +376,10 @@ func DeleteAction(db gorp.SqlExecutor, actionID, userID int64) error {
 		return err
 	}
 
+	if err := DeleteActionOutputs(db, actionID); err != nil {
+		return err
+	}
+
 	query = `DELETE FROM action WHERE action.id = $1`
 	if _, err := db.Exec(query, actionID); err != nil {
 		return err
@@ -440,3 +465,17 @@ func insertAudit(db gorp.SqlExecutor, actionID, userID int64, change string) err
 
 	return nil
 }
+
+// isSameRequirement returns true if the child requirement is already satisfied by the parent one.
+// A plugin pinned in a version by the parent overrides the plugin of the child
+func isSameRequirement(parent, child sdk.Requirement) bool {
+	if parent.Type != child.Type {
+		return false
+	}
+	if parent.Type == sdk.PluginRequirement {
+		pName, _ := sdk.ParsePluginRequirement(parent.Value)
+		cName, _ := sdk.ParsePluginRequirement(child.Value)
+		return pName == cName
+	}
+	return parent.Value == child.Value
+}
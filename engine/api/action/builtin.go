@@ -175,6 +175,73 @@ Tag the current branch and push it.`
 		return err
 	}
 
+	// ----------------------------------- Docker Build -----------------------
+	dockerBuild := sdk.NewAction(sdk.DockerBuildAction)
+	dockerBuild.Type = sdk.BuiltinAction
+	dockerBuild.Description = `CDS Builtin Action.
+Build a docker image, tag it and push it to a docker registry.
+The digest of the image is exported as the output image.digest, and the pushed image is recorded as an artifact of the workflow.`
+
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "dockerfile",
+		Description: "Path of the Dockerfile, relative to the build context.",
+		Value:       "Dockerfile",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "context",
+		Description: "Directory of the build context.",
+		Value:       ".",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "buildArgs",
+		Description: "Build args of the image, one KEY=VALUE per line.",
+		Type:        sdk.TextParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "target",
+		Description: "Stage of a multi-stage Dockerfile to build.",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "image",
+		Description: "Name of the image, without registry and tag.",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "tags",
+		Description: "Tags of the image, separated by commas. E.g., {{.cds.version}},latest",
+		Value:       "{{.cds.version}}",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "push",
+		Description: "Push the image to the registry.",
+		Value:       "true",
+		Type:        sdk.BooleanParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "registry",
+		Description: "The docker registry to push the image to.",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "registryUsername",
+		Description: "Username to login to the registry, generally a project variable as {{.cds.proj.docker_registry_username}}.",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Parameter(sdk.Parameter{
+		Name:        "registryPassword",
+		Description: "Password to login to the registry, generally a project variable of type password as {{.cds.proj.docker_registry_password}}, or a project key.",
+		Type:        sdk.StringParameter,
+	})
+	dockerBuild.Requirement("docker", sdk.BinaryRequirement, "docker")
+
+	if err := checkBuiltinAction(db, dockerBuild); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -254,6 +321,10 @@ func createBuiltinArtifactUploadAction(db *gorp.DbMap) error {
 		Type:        sdk.StringParameter,
 		Description: "Artifact will be uploaded with a tag, generally {{.cds.version}}",
 		Value:       "{{.cds.version}}"})
+	upload.Parameter(sdk.Parameter{
+		Name:        "metadata",
+		Type:        sdk.TextParameter,
+		Description: "Metadata of the artifact, one KEY=VALUE per line"})
 	upload.Parameter(sdk.Parameter{
 		Name:        "enabled",
 		Type:        sdk.BooleanParameter,
@@ -1,6 +1,8 @@
 package action
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -10,11 +12,22 @@ import (
 	"github.com/ovh/cds/sdk/log"
 )
 
-func insertEdge(db gorp.SqlExecutor, parentID, childID int64, execOrder int, optional, alwaysExecuted, enabled bool) (int64, error) {
-	query := `INSERT INTO action_edge (parent_id, child_id, exec_order, optional, always_executed, enabled) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+func insertEdge(db gorp.SqlExecutor, parentID int64, child sdk.Action, execOrder int) (int64, error) {
+	query := `INSERT INTO action_edge (parent_id, child_id, exec_order, step_name, optional, always_executed, enabled, on_failure, continue_on_error, conditions)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	var conditions sql.NullString
+	if len(child.Conditions) > 0 {
+		btes, err := json.Marshal(child.Conditions)
+		if err != nil {
+			return 0, err
+		}
+		conditions.Valid = true
+		conditions.String = string(btes)
+	}
 
 	var id int64
-	err := db.QueryRow(query, parentID, childID, execOrder, optional, alwaysExecuted, enabled).Scan(&id)
+	err := db.QueryRow(query, parentID, child.ID, execOrder, child.StepName, child.Optional, child.AlwaysExecuted, child.Enabled, child.OnFailure, child.ContinueOnError, conditions).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
@@ -27,7 +40,7 @@ func insertActionChild(db gorp.SqlExecutor, actionID int64, child sdk.Action, ex
 		return fmt.Errorf("insertActionChild: child action has no id")
 	}
 
-	id, err := insertEdge(db, actionID, child.ID, execOrder, child.Optional, child.AlwaysExecuted, child.Enabled)
+	id, err := insertEdge(db, actionID, child, execOrder)
 	if err != nil {
 		return err
 	}
@@ -67,7 +80,8 @@ func loadActionChildren(db gorp.SqlExecutor, actionID int64) ([]sdk.Action, erro
 	var children []sdk.Action
 	var edgeIDs []int64
 	var childrenIDs []int64
-	query := `SELECT id, child_id, exec_order, optional, always_executed, enabled FROM action_edge WHERE parent_id = $1 ORDER BY exec_order ASC`
+	query := `SELECT id, child_id, exec_order, step_name, optional, always_executed, enabled, on_failure, continue_on_error, conditions
+	FROM action_edge WHERE parent_id = $1 ORDER BY exec_order ASC`
 
 	rows, err := db.Query(query, actionID)
 	if err != nil {
@@ -77,21 +91,37 @@ func loadActionChildren(db gorp.SqlExecutor, actionID int64) ([]sdk.Action, erro
 
 	var edgeID, childID int64
 	var execOrder int
-	var optional, alwaysExecuted, enabled bool
+	var stepName string
+	var optional, alwaysExecuted, enabled, onFailure, continueOnError bool
+	var conditions sql.NullString
 	var mapOptional = make(map[int64]bool)
 	var mapAlwaysExecuted = make(map[int64]bool)
 	var mapEnabled = make(map[int64]bool)
+	var mapStepName = make(map[int64]string)
+	var mapOnFailure = make(map[int64]bool)
+	var mapContinueOnError = make(map[int64]bool)
+	var mapConditions = make(map[int64][]sdk.WorkflowNodeCondition)
 
 	for rows.Next() {
-		err = rows.Scan(&edgeID, &childID, &execOrder, &optional, &alwaysExecuted, &enabled)
+		err = rows.Scan(&edgeID, &childID, &execOrder, &stepName, &optional, &alwaysExecuted, &enabled, &onFailure, &continueOnError, &conditions)
 		if err != nil {
 			return nil, err
 		}
+		if conditions.Valid {
+			var conds []sdk.WorkflowNodeCondition
+			if err := json.Unmarshal([]byte(conditions.String), &conds); err != nil {
+				return nil, sdk.WrapError(err, "loadActionChildren> cannot unmarshal conditions of edge %d", edgeID)
+			}
+			mapConditions[edgeID] = conds
+		}
 		edgeIDs = append(edgeIDs, edgeID)
 		childrenIDs = append(childrenIDs, childID)
 		mapOptional[edgeID] = optional
 		mapAlwaysExecuted[edgeID] = alwaysExecuted
 		mapEnabled[edgeID] = enabled
+		mapStepName[edgeID] = stepName
+		mapOnFailure[edgeID] = onFailure
+		mapContinueOnError[edgeID] = continueOnError
 	}
 	rows.Close()
 
@@ -118,6 +148,11 @@ func loadActionChildren(db gorp.SqlExecutor, actionID int64) ([]sdk.Action, erro
 		children[i].AlwaysExecuted = mapAlwaysExecuted[edgeIDs[i]]
 		// Get enable flag
 		children[i].Enabled = mapEnabled[edgeIDs[i]]
+		children[i].StepName = mapStepName[edgeIDs[i]]
+		// Get conditions & failure handling of the step
+		children[i].OnFailure = mapOnFailure[edgeIDs[i]]
+		children[i].ContinueOnError = mapContinueOnError[edgeIDs[i]]
+		children[i].Conditions = mapConditions[edgeIDs[i]]
 	}
 
 	return children, nil
@@ -0,0 +1,43 @@
+package action
+
+import (
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadActionOutputs retrieves the outputs of given action in database
+func LoadActionOutputs(db gorp.SqlExecutor, actionID int64) ([]sdk.ActionOutput, error) {
+	query := `SELECT name, value, description FROM action_output WHERE action_id = $1 ORDER BY name`
+	rows, err := db.Query(query, actionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var outputs []sdk.ActionOutput
+	for rows.Next() {
+		var o sdk.ActionOutput
+		if err := rows.Scan(&o.Name, &o.Value, &o.Description); err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, o)
+	}
+	return outputs, nil
+}
+
+// InsertActionOutput inserts given output in database
+func InsertActionOutput(db gorp.SqlExecutor, actionID int64, o sdk.ActionOutput) error {
+	query := `INSERT INTO action_output (action_id, name, value, description) VALUES ($1, $2, $3, $4)`
+	if _, err := db.Exec(query, actionID, o.Name, o.Value, o.Description); err != nil {
+		return sdk.WrapError(err, "InsertActionOutput> Unable to insert output %s of action %d", o.Name, actionID)
+	}
+	return nil
+}
+
+// DeleteActionOutputs deletes all outputs of given action
+func DeleteActionOutputs(db gorp.SqlExecutor, actionID int64) error {
+	query := `DELETE FROM action_output WHERE action_id = $1`
+	_, err := db.Exec(query, actionID)
+	return err
+}
@@ -0,0 +1,149 @@
+package actionplugin
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+const binaryColumns = "id, plugin_name, version, os, arch, size, perm, sha256sum, object_path, object_name, created"
+
+//NewBinary returns the binary of a plugin stored in a local file, with its size, permissions and SHA-256 checksum.
+//Its name in the objectstore ends with its checksum, so that a new binary does not replace the stored one
+func NewBinary(name, version, goos, goarch, path string) (*sdk.PluginBinary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	return &sdk.PluginBinary{
+		PluginName: name,
+		Version:    version,
+		OS:         goos,
+		Arch:       goarch,
+		Size:       stat.Size(),
+		Perm:       uint32(stat.Mode().Perm()),
+		SHA256Sum:  sum,
+		ObjectName: fmt.Sprintf("%s-%s-%s-%s-%s", name, version, goos, goarch, sum),
+	}, nil
+}
+
+//InsertBinary inserts a plugin binary, replacing the binary of the same version, OS and architecture.
+//It returns the replaced binary, which has to be removed from the objectstore
+func InsertBinary(db gorp.SqlExecutor, b *sdk.PluginBinary) (*sdk.PluginBinary, error) {
+	old, err := LoadBinary(db, b.PluginName, b.Version, b.OS, b.Arch)
+	if err != nil && err != sdk.ErrPluginNotFound {
+		return nil, err
+	}
+	if old != nil {
+		if _, err := db.Exec("DELETE FROM plugin_binary WHERE id = $1", old.ID); err != nil {
+			return nil, sdk.WrapError(err, "InsertBinary> Unable to delete binary %d", old.ID)
+		}
+	}
+
+	b.Created = time.Now()
+	query := `INSERT INTO plugin_binary (plugin_name, version, os, arch, size, perm, sha256sum, object_path, object_name, created)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	if err := db.QueryRow(query, b.PluginName, b.Version, b.OS, b.Arch, b.Size, b.Perm, b.SHA256Sum, b.ObjectPath, b.ObjectName, b.Created).Scan(&b.ID); err != nil {
+		return nil, sdk.WrapError(err, "InsertBinary> Unable to insert binary of %s", b.PluginName)
+	}
+	return old, nil
+}
+
+//LoadBinaries returns all the binaries of a plugin, the latest first
+func LoadBinaries(db gorp.SqlExecutor, name string) ([]sdk.PluginBinary, error) {
+	rows, err := db.Query("SELECT "+binaryColumns+" FROM plugin_binary WHERE plugin_name = $1 ORDER BY created DESC", name)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadBinaries> Unable to load binaries of %s", name)
+	}
+	defer rows.Close()
+
+	bs := []sdk.PluginBinary{}
+	for rows.Next() {
+		b, err := scanBinary(rows)
+		if err != nil {
+			return nil, sdk.WrapError(err, "LoadBinaries> Unable to scan binary of %s", name)
+		}
+		bs = append(bs, *b)
+	}
+	return bs, nil
+}
+
+//LoadBinary returns the binary of a plugin for a version, an OS and an architecture. The latest version is returned
+//if version is empty
+func LoadBinary(db gorp.SqlExecutor, name, version, goos, goarch string) (*sdk.PluginBinary, error) {
+	query := "SELECT " + binaryColumns + " FROM plugin_binary WHERE plugin_name = $1 AND os = $2 AND arch = $3"
+	args := []interface{}{name, goos, goarch}
+	if version != "" {
+		query += " AND version = $4"
+		args = append(args, version)
+	}
+	query += " ORDER BY created DESC LIMIT 1"
+
+	b, err := scanBinary(db.QueryRow(query, args...))
+	if err == sql.ErrNoRows {
+		return nil, sdk.ErrPluginNotFound
+	}
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadBinary> Unable to load binary of %s", name)
+	}
+	return b, nil
+}
+
+//DeleteBinaries deletes the binaries of a plugin for a version, or all its binaries if version is empty.
+//It returns the deleted binaries, which have to be removed from the objectstore
+func DeleteBinaries(db gorp.SqlExecutor, name, version string) ([]sdk.PluginBinary, error) {
+	bs, err := LoadBinaries(db, name)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []sdk.PluginBinary{}
+	for _, b := range bs {
+		if version != "" && b.Version != version {
+			continue
+		}
+		if _, err := db.Exec("DELETE FROM plugin_binary WHERE id = $1", b.ID); err != nil {
+			return nil, sdk.WrapError(err, "DeleteBinaries> Unable to delete binary %d", b.ID)
+		}
+		deleted = append(deleted, b)
+	}
+	return deleted, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBinary(s scanner) (*sdk.PluginBinary, error) {
+	var b sdk.PluginBinary
+	var size sql.NullInt64
+	var perm sql.NullInt64
+	var objectPath sql.NullString
+	if err := s.Scan(&b.ID, &b.PluginName, &b.Version, &b.OS, &b.Arch, &size, &perm, &b.SHA256Sum, &objectPath, &b.ObjectName, &b.Created); err != nil {
+		return nil, err
+	}
+	b.Size = size.Int64
+	b.Perm = uint32(perm.Int64)
+	b.ObjectPath = objectPath.String
+	return &b, nil
+}
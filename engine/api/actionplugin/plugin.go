@@ -12,6 +12,7 @@ import (
 
 	"github.com/ovh/cds/engine/api/action"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/grpcplugin"
 	"github.com/ovh/cds/sdk/log"
 	"github.com/ovh/cds/sdk/plugin"
 )
@@ -20,13 +21,16 @@ import (
 func Get(name, path string) (*sdk.ActionPlugin, *plugin.Parameters, error) {
 	//FIXME: run this in a jail with apparmor
 	log.Debug("actionplugin.Get> Getting info from '%s' (%s)", name, path)
-	client := plugin.NewClient(context.Background(), name, path, "ID", "http://127.0.0.1:8081", true)
+	client, err := grpcplugin.Start(context.Background(), name, path, "ID", "http://127.0.0.1:8081", true)
+	if err != nil {
+		return nil, nil, sdk.WrapError(err, "actionplugin.Get> ")
+	}
 	defer func() {
-		log.Debug("actionplugin.Get> kill rpc-server")
+		log.Debug("actionplugin.Get> kill plugin")
 		client.Kill()
 	}()
-	log.Debug("actionplugin.Get> Client '%s'", name)
-	_plugin, err := client.Instance()
+	log.Debug("actionplugin.Get> Client '%s' protocol %d", name, client.ProtocolVersion)
+	manifest, err := client.Manifest(context.Background())
 	if err != nil {
 		return nil, nil, sdk.WrapError(err, "actionplugin.Get> ")
 	}
@@ -51,16 +55,20 @@ func Get(name, path string) (*sdk.ActionPlugin, *plugin.Parameters, error) {
 
 	ap := sdk.ActionPlugin{
 		Filename:    name,
-		Name:        _plugin.Name(),
-		Author:      _plugin.Author(),
-		Description: _plugin.Description(),
+		Name:        manifest.Name,
+		Author:      manifest.Author,
+		Version:     manifest.Version,
+		Description: manifest.Description,
 		Path:        path,
 		Size:        stat.Size(),
 		Perm:        uint32(stat.Mode().Perm()),
 		MD5sum:      md5sumStr,
 	}
 
-	params := _plugin.Parameters()
+	params := plugin.NewParameters()
+	for _, p := range manifest.Parameters {
+		params.Add(p.Name, plugin.ParameterType(p.Type), p.Description, p.Value)
+	}
 
 	return &ap, &params, nil
 }
@@ -117,7 +125,7 @@ func actionPluginToAction(ap *sdk.ActionPlugin, params *plugin.Parameters) (*sdk
 	return a, nil
 }
 
-//Insert create action in database
+//Insert create action in database, the plugin binaries are inserted with InsertBinary
 func Insert(db gorp.SqlExecutor, ap *sdk.ActionPlugin, params *plugin.Parameters) (*sdk.Action, error) {
 	a, err := actionPluginToAction(ap, params)
 	if err != nil {
@@ -128,15 +136,10 @@ func Insert(db gorp.SqlExecutor, ap *sdk.ActionPlugin, params *plugin.Parameters
 		log.Warning("plugin.Insert> Action: Cannot insert action: %s\n", err)
 		return nil, err
 	}
-
-	query := `INSERT INTO plugin (name, size, perm, md5sum, object_path) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	if err = db.QueryRow(query, ap.Name, ap.Size, ap.Perm, ap.MD5sum, ap.ObjectPath).Scan(&ap.ID); err != nil {
-		return nil, err
-	}
 	return a, nil
 }
 
-//Update action in database
+//Update action in database, the plugin binaries are inserted with InsertBinary
 func Update(db gorp.SqlExecutor, ap *sdk.ActionPlugin, params *plugin.Parameters, userID int64) (*sdk.Action, error) {
 	a, err := actionPluginToAction(ap, params)
 	if err != nil {
@@ -153,41 +156,36 @@ func Update(db gorp.SqlExecutor, ap *sdk.ActionPlugin, params *plugin.Parameters
 	if err := action.UpdateActionDB(db, a, userID); err != nil {
 		return nil, err
 	}
-
-	query := "DELETE FROM plugin WHERE name = $1"
-	if _, err := db.Exec(query, a.Name); err != nil {
-		return nil, err
-	}
-
-	query = `INSERT INTO plugin (name, size, perm, md5sum, object_path) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	if err = db.QueryRow(query, ap.Name, ap.Size, ap.Perm, ap.MD5sum, ap.ObjectPath).Scan(&ap.ID); err != nil {
-		return nil, err
-	}
 	return a, nil
 }
 
-//Delete action in database
-func Delete(db *gorp.DbMap, name string, userID int64) error {
+//Delete action in database, it returns the deleted plugin binaries which have to be removed from the objectstore
+func Delete(db *gorp.DbMap, name string, userID int64) ([]sdk.PluginBinary, error) {
 	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback()
 
 	a, err := action.LoadPublicAction(tx, name)
 	if err != nil {
-		return sdk.WrapError(err, "plugin.Delete> Action: Cannot get action %s", name)
+		return nil, sdk.WrapError(err, "plugin.Delete> Action: Cannot get action %s", name)
 	}
 
+	// plugins uploaded before the binaries registry
 	query := "DELETE FROM plugin WHERE name = $1"
 	if _, err := tx.Exec(query, a.Name); err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := action.DeleteAction(tx, a.ID, userID); err != nil {
-		return err
+	binaries, err := DeleteBinaries(tx, a.Name, "")
+	if err != nil {
+		return nil, err
 	}
 
-	return tx.Commit()
+	if err := action.DeleteAction(tx, a.ID, userID); err != nil {
+		return nil, err
+	}
 
+	return binaries, tx.Commit()
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/ovh/cds/engine/api/pipeline"
 	"github.com/ovh/cds/engine/api/poller"
 	"github.com/ovh/cds/engine/api/queue"
+	"github.com/ovh/cds/engine/api/quota"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/engine/api/scheduler"
 	"github.com/ovh/cds/engine/api/secret"
@@ -53,7 +54,9 @@ type Configuration struct {
 		Port int `toml:"port" default:"8082"`
 	} `toml:"grpc"`
 	Secrets struct {
-		Key string `toml:"key"`
+		Key          string `toml:"key"`
+		KeyID        string `toml:"keyID" default:"default" comment:"Identifier of the key, stored with the encrypted data. Change it with the key when rotating the key"`
+		PreviousKeys string `toml:"previousKeys" comment:"Keys used before a rotation, only used to decrypt data. Format: keyID1:key1,keyID2:key2"`
 	} `toml:"secrets"`
 	Database struct {
 		User           string `toml:"user" default:"cds"`
@@ -70,7 +73,7 @@ type Configuration struct {
 	Cache struct {
 		TTL   int `toml:"ttl" default:"60"`
 		Redis struct {
-			Host     string `toml:"host" default:"localhost:6379" comment:"If your want to use a redis-sentinel based cluster, follow this syntax ! <clustername>@sentinel1:26379,sentinel2:26379sentinel3:26379"`
+			Host     string `toml:"host" default:"localhost:6379" comment:"If your want to use a redis-sentinel based cluster, follow this syntax ! <clustername>@sentinel1:26379,sentinel2:26379sentinel3:26379. Leave it empty to use a local in memory cache"`
 			Password string `toml:"password"`
 		} `toml:"redis" comment:"Connect CDS to a redis cache If you more than one CDS instance and to avoid losing data at startup"`
 	} `toml:"cache" comment:"######################\n CDS Cache Settings \n#####################\nIf your CDS is made of a unique instance, a local cache if enough, but rememeber that all cached data will be lost on startup."`
@@ -117,6 +120,10 @@ type Configuration struct {
 			ContainerPrefix string `toml:"containerPrefix" comment:"Use if your want to prefix containers for CDS Artifacts"`
 		} `toml:"openstack"`
 	} `toml:"artifact" comment:"Either filesystem local storage or Openstack Swift Storage are supported"`
+	Log struct {
+		Store       string `toml:"store" default:"database" comment:"database or objectstore. With objectstore, the steps logs are stored in chunks in the artifact storage, and compressed when the steps are done"`
+		StepMaxSize int64  `toml:"stepMaxSize" default:"15728640" comment:"Maximum size in bytes of the logs of a step, 0 for no limit. Over this size, the first lines are removed. It can be set per project"`
+	} `toml:"log" comment:"The steps logs are stored in the database or in the artifact storage"`
 	Events struct {
 		Kafka struct {
 			Enabled  bool   `toml:"enabled"`
@@ -134,6 +141,38 @@ type Configuration struct {
 	} `toml:"vault"`
 }
 
+// ObjectstoreConfig returns the configuration of the artifact storage
+func (c Configuration) ObjectstoreConfig() (objectstore.Config, error) {
+	var objectstoreKind objectstore.Kind
+	switch c.Artifact.Mode {
+	case "openstack":
+		objectstoreKind = objectstore.Openstack
+	case "swift":
+		objectstoreKind = objectstore.Swift
+	case "filesystem", "local":
+		objectstoreKind = objectstore.Filesystem
+	default:
+		return objectstore.Config{}, fmt.Errorf("unsupported objecstore mode : %s", c.Artifact.Mode)
+	}
+
+	return objectstore.Config{
+		Kind: objectstoreKind,
+		Options: objectstore.ConfigOptions{
+			Openstack: objectstore.ConfigOptionsOpenstack{
+				Address:         c.Artifact.Openstack.URL,
+				Username:        c.Artifact.Openstack.Username,
+				Password:        c.Artifact.Openstack.Password,
+				Tenant:          c.Artifact.Openstack.Tenant,
+				Region:          c.Artifact.Openstack.Region,
+				ContainerPrefix: c.Artifact.Openstack.ContainerPrefix,
+			},
+			Filesystem: objectstore.ConfigOptionsFilesystem{
+				Basedir: c.Artifact.Local.BaseDirectory,
+			},
+		},
+	}, nil
+}
+
 // DefaultValues is the struc for API Default configuration default values
 type DefaultValues struct {
 	ServerSecretsKey     string
@@ -229,6 +268,12 @@ func (a *API) CheckConfiguration(config interface{}) error {
 		return fmt.Errorf("Invalid artifact mode")
 	}
 
+	switch aConfig.Log.Store {
+	case "", workflow.LogStoreDatabase, workflow.LogStoreObjectstore:
+	default:
+		return fmt.Errorf("Invalid log store")
+	}
+
 	if aConfig.Artifact.Mode == "local" {
 		if aConfig.Artifact.Local.BaseDirectory == "" {
 			return fmt.Errorf("Invalid artifact local base directory")
@@ -247,6 +292,10 @@ func (a *API) CheckConfiguration(config interface{}) error {
 		return fmt.Errorf("Invalid secret key. It should be 32 bits (%d)", len(aConfig.Secrets.Key))
 	}
 
+	if _, err := secret.ParseKeys(aConfig.Secrets.PreviousKeys); err != nil {
+		return fmt.Errorf("Invalid previous secret keys: %v", err)
+	}
+
 	return nil
 }
 
@@ -329,7 +378,13 @@ func (a *API) Serve(ctx context.Context) error {
 	a.StartupTime = time.Now()
 
 	//Initialize secret driver
-	secret.Init(a.Config.Secrets.Key)
+	previousKeys, errK := secret.ParseKeys(a.Config.Secrets.PreviousKeys)
+	if errK != nil {
+		return fmt.Errorf("invalid previous secret keys: %v", errK)
+	}
+	if err := secret.Init(a.Config.Secrets.Key, a.Config.Secrets.KeyID, previousKeys); err != nil {
+		return fmt.Errorf("cannot initialize secrets: %v", err)
+	}
 
 	//Initialize mail package
 	log.Info("Initializing mail driver...")
@@ -343,39 +398,19 @@ func (a *API) Serve(ctx context.Context) error {
 
 	//Initialize artifacts storage
 	log.Info("Initializing %s objectstore...", a.Config.Artifact.Mode)
-	var objectstoreKind objectstore.Kind
-	switch a.Config.Artifact.Mode {
-	case "openstack":
-		objectstoreKind = objectstore.Openstack
-	case "swift":
-		objectstoreKind = objectstore.Swift
-	case "filesystem", "local":
-		objectstoreKind = objectstore.Filesystem
-	default:
-		return fmt.Errorf("unsupported objecstore mode : %s", a.Config.Artifact.Mode)
-	}
-
-	cfg := objectstore.Config{
-		Kind: objectstoreKind,
-		Options: objectstore.ConfigOptions{
-			Openstack: objectstore.ConfigOptionsOpenstack{
-				Address:         a.Config.Artifact.Openstack.URL,
-				Username:        a.Config.Artifact.Openstack.Username,
-				Password:        a.Config.Artifact.Openstack.Password,
-				Tenant:          a.Config.Artifact.Openstack.Tenant,
-				Region:          a.Config.Artifact.Openstack.Region,
-				ContainerPrefix: a.Config.Artifact.Openstack.ContainerPrefix,
-			},
-			Filesystem: objectstore.ConfigOptionsFilesystem{
-				Basedir: a.Config.Artifact.Local.BaseDirectory,
-			},
-		},
+	cfg, err := a.Config.ObjectstoreConfig()
+	if err != nil {
+		return err
 	}
 
 	if err := objectstore.Initialize(ctx, cfg); err != nil {
 		return fmt.Errorf("cannot initialize storage: %v", err)
 	}
 
+	if err := workflow.InitLogStore(a.Config.Log.Store, a.Config.Log.StepMaxSize); err != nil {
+		return err
+	}
+
 	log.Info("Initializing database connection...")
 	//Intialize database
 	var errDB error
@@ -406,7 +441,9 @@ func (a *API) Serve(ctx context.Context) error {
 		return fmt.Errorf("cannot setup builtin workflow hook models: %v", err)
 	}
 
-	log.Info("Initializing redis cache on %s...", a.Config.Cache.Redis.Host)
+	if a.Config.Cache.Redis.Host != "" {
+		log.Info("Initializing redis cache on %s...", a.Config.Cache.Redis.Host)
+	}
 	//Init the cache
 	var errCache error
 	a.Cache, errCache = cache.New(
@@ -491,6 +528,8 @@ func (a *API) Serve(ctx context.Context) error {
 	go repositoriesmanager.ReceiveEvents(ctx, a.DBConnectionFactory.GetDBMap, a.Cache)
 	go action.RequirementsCacheLoader(ctx, 5*time.Second, a.DBConnectionFactory.GetDBMap, a.Cache)
 	go hookRecoverer(ctx, a.DBConnectionFactory.GetDBMap, a.Cache)
+	go concurrencyGroupsRoutine(ctx, a.DBConnectionFactory.GetDBMap, a.Cache)
+	go quota.CleanWorkerUsages(ctx, a.DBConnectionFactory.GetDBMap)
 	go services.KillDeadServices(ctx, services.NewRepository(a.mustDB, a.Cache))
 	go poller.Initialize(ctx, a.Cache, 10, a.DBConnectionFactory.GetDBMap)
 	go migrate.CleanOldWorkflow(ctx, a.Cache, a.DBConnectionFactory.GetDBMap, a.Config.URL.API)
@@ -524,7 +563,7 @@ func (a *API) Serve(ctx context.Context) error {
 
 	go func() {
 		//TLS is disabled for the moment. We need to serve TLS on HTTP too
-		if err := grpcInit(a.DBConnectionFactory, a.Config.GRPC.Port, false, "", ""); err != nil {
+		if err := grpcInit(a.DBConnectionFactory, a.Cache, a.Config.GRPC.Port, false, "", ""); err != nil {
 			log.Error("Cannot start GRPC server: %v", err)
 		}
 	}()
@@ -33,6 +33,7 @@ func (api *API) InitRouter() {
 	// Admin
 	r.Handle("/admin/warning", r.DELETE(api.adminTruncateWarningsHandler, NeedAdmin(true)))
 	r.Handle("/admin/maintenance", r.POST(api.postAdminMaintenanceHandler, NeedAdmin(true)), r.GET(api.getAdminMaintenanceHandler, NeedAdmin(true)), r.DELETE(api.deleteAdminMaintenanceHandler, NeedAdmin(true)))
+	r.Handle("/admin/quota", r.GET(api.getQuotasHandler, NeedAdmin(true)))
 	r.Handle("/admin/debug", r.GET(api.getProfileIndexHandler, Auth(false)))
 	r.Handle("/admin/debug/trace", r.POST(api.getTraceHandler, NeedAdmin(true)))
 	r.Handle("/admin/debug/cpu", r.POST(api.getCPUProfileHandler, NeedAdmin(true)))
@@ -41,6 +42,9 @@ func (api *API) InitRouter() {
 	// Action plugin
 	r.Handle("/plugin", r.POST(api.addPluginHandler, NeedAdmin(true)), r.PUT(api.updatePluginHandler, NeedAdmin(true)))
 	r.Handle("/plugin/{name}", r.DELETE(api.deletePluginHandler, NeedAdmin(true)))
+	r.Handle("/plugin/{name}/binary", r.GET(api.getPluginBinariesHandler), r.POST(api.addPluginBinaryHandler, NeedAdmin(true)))
+	r.Handle("/plugin/{name}/binary/{version}", r.DELETE(api.deletePluginBinariesHandler, NeedAdmin(true)))
+	r.Handle("/plugin/{name}/binary/{os}/{arch}", r.GET(api.getPluginBinaryHandler))
 	r.Handle("/plugin/download/{name}", r.GET(api.downloadPluginHandler))
 
 	// Download file
@@ -54,6 +58,7 @@ func (api *API) InitRouter() {
 	r.Handle("/group/{permGroupName}/user", r.POST(api.addUserInGroupHandler))
 	r.Handle("/group/{permGroupName}/user/{user}", r.DELETE(api.removeUserFromGroupHandler))
 	r.Handle("/group/{permGroupName}/user/{user}/admin", r.POST(api.setUserGroupAdminHandler), r.DELETE(api.removeUserGroupAdminHandler))
+	r.Handle("/group/{permGroupName}/quota", r.GET(api.getGroupQuotaHandler), r.PUT(api.putGroupQuotaHandler, NeedAdmin(true)), r.DELETE(api.deleteGroupQuotaHandler, NeedAdmin(true)))
 	r.Handle("/group/{permGroupName}/token", r.GET(api.getGroupTokenListHandler), r.POST(api.generateTokenHandler))
 	r.Handle("/group/{permGroupName}/token/{tokenid}", r.DELETE(api.deleteTokenHandler))
 
@@ -86,6 +91,11 @@ func (api *API) InitRouter() {
 	// Project
 	r.Handle("/project", r.GET(api.getProjectsHandler), r.POST(api.addProjectHandler))
 	r.Handle("/project/{permProjectKey}", r.GET(api.getProjectHandler), r.PUT(api.updateProjectHandler), r.DELETE(api.deleteProjectHandler))
+	r.Handle("/project/{permProjectKey}/quota", r.GET(api.getProjectQuotaHandler), r.PUT(api.putProjectQuotaHandler, NeedAdmin(true)), r.DELETE(api.deleteProjectQuotaHandler, NeedAdmin(true)))
+	r.Handle("/project/{permProjectKey}/log/settings", r.GET(api.getProjectLogSettingsHandler), r.PUT(api.putProjectLogSettingsHandler, NeedAdmin(true)), r.DELETE(api.deleteProjectLogSettingsHandler, NeedAdmin(true)))
+	r.Handle("/project/{permProjectKey}/artifact/settings", r.GET(api.getProjectArtifactSettingsHandler), r.PUT(api.putProjectArtifactSettingsHandler, NeedAdmin(true)), r.DELETE(api.deleteProjectArtifactSettingsHandler, NeedAdmin(true)))
+	r.Handle("/project/{permProjectKey}/migrate", r.POST(api.postProjectMigrationHandler, NeedAdmin(true)))
+	r.Handle("/project/{permProjectKey}/vault", r.GET(api.getProjectVaultHandler), r.PUT(api.putProjectVaultHandler), r.DELETE(api.deleteProjectVaultHandler))
 	r.Handle("/project/{permProjectKey}/group", r.POST(api.addGroupInProjectHandler), r.PUT(api.updateGroupsInProjectHandler, DEPRECATED))
 	r.Handle("/project/{permProjectKey}/group/import", r.POST(api.importGroupsInProjectHandler))
 	r.Handle("/project/{permProjectKey}/group/{group}", r.PUT(api.updateGroupRoleOnProjectHandler), r.DELETE(api.deleteGroupFromProjectHandler))
@@ -182,12 +192,15 @@ func (api *API) InitRouter() {
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/groups", r.POST(api.postWorkflowGroupHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/groups/{groupName}", r.PUT(api.putWorkflowGroupHandler), r.DELETE(api.deleteWorkflowGroupHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/hooks/{uuid}", r.GET(api.getWorkflowHookHandler))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/hooks/{uuid}/next", r.GET(api.getWorkflowHookNextExecutionsHandler))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/lint", r.GET(api.getWorkflowLintHandler))
 	r.Handle("/project/{key}/workflow/{permWorkflowName}/node/{nodeID}/hook/model", r.GET(api.getWorkflowHookModelsHandler))
 
 	// Preview workflows
 	r.Handle("/project/{permProjectKey}/preview/workflows", r.POST(api.postWorkflowPreviewHandler))
 	// Import workflows
 	r.Handle("/project/{permProjectKey}/import/workflows", r.POST(api.postWorkflowImportHandler))
+	r.Handle("/project/{permProjectKey}/lint/workflows", r.POST(api.postWorkflowLintHandler, NeedReadPermission()))
 	// Export workflows
 	r.Handle("/project/{key}/export/workflows/{permWorkflowName}", r.GET(api.getWorkflowExportHandler))
 	// Pull workflows
@@ -197,7 +210,7 @@ func (api *API) InitRouter() {
 
 	// Workflows run
 	r.Handle("/project/{permProjectKey}/runs", r.GET(api.getWorkflowAllRunsHandler))
-	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs", r.GET(api.getWorkflowRunsHandler), r.POSTEXECUTE(api.postWorkflowRunHandler, AllowServices(true)))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs", r.GET(api.getWorkflowRunsHandler, AllowServices(true)), r.POSTEXECUTE(api.postWorkflowRunHandler, AllowServices(true)))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/latest", r.GET(api.getLatestWorkflowRunHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/tags", r.GET(api.getWorkflowRunTagsHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/num", r.GET(api.getWorkflowRunNumHandler), r.POST(api.postWorkflowRunNumHandler))
@@ -208,6 +221,9 @@ func (api *API) InitRouter() {
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeRunID}", r.GET(api.getWorkflowNodeRunHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeRunID}/stop", r.POST(api.stopWorkflowNodeRunHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeID}/history", r.GET(api.getWorkflowNodeRunHistoryHandler))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/approvals", r.GET(api.getWorkflowRunApprovalsHandler))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeID}/approve", r.POSTEXECUTE(api.postWorkflowNodeApproveHandler))
+	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeID}/reject", r.POSTEXECUTE(api.postWorkflowNodeRejectHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/{nodeName}/commits", r.GET(api.getWorkflowCommitsHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeRunID}/job/{runJobId}/step/{stepOrder}", r.GET(api.getWorkflowNodeRunJobStepHandler))
 	r.Handle("/project/{key}/workflows/{permWorkflowName}/runs/{number}/nodes/{nodeRunID}/artifacts", r.GET(api.getWorkflowNodeRunArtifactsHandler))
@@ -233,6 +249,8 @@ func (api *API) InitRouter() {
 	r.Handle("/project/{key}/environment/import/{permEnvironmentName}", r.POST(api.importIntoEnvironmentHandler, DEPRECATED))
 	r.Handle("/project/{key}/environment/{permEnvironmentName}", r.GET(api.getEnvironmentHandler), r.PUT(api.updateEnvironmentHandler), r.DELETE(api.deleteEnvironmentHandler))
 	r.Handle("/project/{key}/environment/{permEnvironmentName}/usage", r.GET(api.getEnvironmentUsageHandler))
+	r.Handle("/project/{key}/environment/{permEnvironmentName}/deployments", r.GET(api.getEnvironmentDeploymentsHandler))
+	r.Handle("/project/{key}/environment/{permEnvironmentName}/deployments/history", r.GET(api.getEnvironmentDeploymentsHistoryHandler))
 	r.Handle("/project/{key}/environment/{permEnvironmentName}/keys", r.GET(api.getKeysInEnvironmentHandler), r.POST(api.addKeyInEnvironmentHandler))
 	r.Handle("/project/{key}/environment/{permEnvironmentName}/keys/{name}", r.DELETE(api.deleteKeyInEnvironmentHandler))
 	r.Handle("/project/{key}/environment/{permEnvironmentName}/clone/{cloneName}", r.POST(api.cloneEnvironmentHandler))
@@ -293,10 +311,12 @@ func (api *API) InitRouter() {
 	r.Handle("/queue/workflows/{permID}/test", r.POSTEXECUTE(api.postWorkflowJobTestsResultsHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/tag", r.POSTEXECUTE(api.postWorkflowJobTagsHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/variable", r.POSTEXECUTE(api.postWorkflowJobVariableHandler, NeedWorker()))
+	r.Handle("/queue/workflows/{permID}/output", r.POSTEXECUTE(api.postWorkflowJobStepOutputHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/step", r.POSTEXECUTE(api.postWorkflowJobStepStatusHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/artifact/{tag}", r.POSTEXECUTE(api.postWorkflowJobArtifactHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/artifact/{tag}/url", r.POSTEXECUTE(api.postWorkflowJobArtifacWithTempURLHandler, NeedWorker()))
 	r.Handle("/queue/workflows/{permID}/artifact/{tag}/url/callback", r.POSTEXECUTE(api.postWorkflowJobArtifactWithTempURLCallbackHandler, NeedWorker()))
+	r.Handle("/queue/workflows/{permID}/artifact/{tag}/reference", r.POSTEXECUTE(api.postWorkflowJobArtifactReferenceHandler, NeedWorker()))
 
 	r.Handle("/variable/type", r.GET(api.getVariableTypeHandler))
 	r.Handle("/parameter/type", r.GET(api.getParameterTypeHandler))
@@ -367,6 +387,7 @@ func (api *API) InitRouter() {
 	r.Handle("/worker/model/capability/type", r.GET(api.getRequirementTypesHandler))
 
 	// Workflows
+	r.Handle("/workflow/concurrency/{group}", r.GET(api.getWorkflowConcurrencyGroupHandler))
 	r.Handle("/workflow/hook", r.GET(api.getWorkflowHooksHandler, NeedService()))
 	r.Handle("/workflow/hook/model/{model}", r.GET(api.getWorkflowHookModelHandler), r.POST(api.postWorkflowHookModelHandler, NeedAdmin(true)), r.PUT(api.putWorkflowHookModelHandler, NeedAdmin(true)))
 
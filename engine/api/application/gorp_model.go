@@ -18,9 +18,9 @@ type dbApplicationVariableAudit sdk.ApplicationVariableAudit
 type dbApplicationKey sdk.ApplicationKey
 
 func init() {
-	gorpmapping.Register(gorpmapping.New(dbApplication{}, "application", true, "id"))
-	gorpmapping.Register(gorpmapping.New(dbApplicationVariableAudit{}, "application_variable_audit", true, "id"))
-	gorpmapping.Register(gorpmapping.New(dbApplicationKey{}, "application_key", false))
+	gorpmapping.Register(gorpmapping.New(dbApplication{}, "application", true, "id").Encrypted("vcs_strategy.password"))
+	gorpmapping.Register(gorpmapping.New(dbApplicationVariableAudit{}, "application_variable_audit", true, "id").Encrypted("variable_before.value", "variable_after.value"))
+	gorpmapping.Register(gorpmapping.New(dbApplicationKey{}, "application_key", false).Encrypted("private"))
 }
 
 type sqlApplicationJSON struct {
@@ -282,6 +282,9 @@ func (api *API) updateVariableInApplicationHandler() Handler {
 		if newVar.Name != varName {
 			return sdk.ErrWrongRequest
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		app, err := application.LoadByName(api.mustDB(), api.Cache, key, appName, getUser(ctx))
 		if err != nil {
@@ -326,6 +329,9 @@ func (api *API) addVariableInApplicationHandler() Handler {
 		if newVar.Name != varName {
 			return sdk.ErrWrongRequest
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		app, err := application.LoadByName(api.mustDB(), api.Cache, key, appName, getUser(ctx))
 		if err != nil {
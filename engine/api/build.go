@@ -280,7 +280,12 @@ func (api *API) addQueueResultHandler() Handler {
 
 		// Update action status
 		log.Debug("addQueueResultHandler> Updating %d to %s in queue", id, res.Status)
-		if err := pipeline.UpdatePipelineBuildJobStatus(tx, pbJob, sdk.Status(res.Status)); err != nil {
+		status := sdk.Status(res.Status)
+		// pipeline builds have no warning status, the failed steps continued on error
+		if status == sdk.StatusWarning {
+			status = sdk.StatusSuccess
+		}
+		if err := pipeline.UpdatePipelineBuildJobStatus(tx, pbJob, status); err != nil {
 			return sdk.WrapError(err, "addQueueResultHandler> Cannot update %d status", id)
 		}
 
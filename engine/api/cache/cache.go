@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
 )
 
 // PubSub represents a subscriber
@@ -38,7 +39,11 @@ type Store interface {
 	SetScan(key string, members ...interface{}) error
 }
 
-//New init a cache
+//New init a cache, it is a local cache without redis host
 func New(redisHost, redisPassword string, TTL int) (Store, error) {
+	if redisHost == "" {
+		log.Info("cache> no redis host, using a local cache")
+		return NewLocalStore(TTL), nil
+	}
 	return NewRedisStore(redisHost, redisPassword, TTL)
 }
@@ -0,0 +1,360 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// localSubscriptionBuffer is the number of messages kept for a subscriber which does not read them
+const localSubscriptionBuffer = 1000
+
+//LocalStore is an in memory store, for a CDS made of a unique instance. Values are stored as json,
+//like in redis, so the cached objects are never shared with the callers
+type LocalStore struct {
+	sync.Mutex
+	ttl         int
+	data        *gocache.Cache
+	queues      map[string]*list.List
+	waiters     map[string]chan struct{}
+	sets        map[string]*list.List
+	subscribers map[string][]*localPubSub
+}
+
+type localPubSub struct {
+	store    *LocalStore
+	channels []string
+	messages chan string
+}
+
+//NewLocalStore initiate a new in memory store with a default ttl
+func NewLocalStore(ttl int) *LocalStore {
+	return &LocalStore{
+		ttl:         ttl,
+		data:        gocache.New(gocache.NoExpiration, time.Minute),
+		queues:      map[string]*list.List{},
+		waiters:     map[string]chan struct{}{},
+		sets:        map[string]*list.List{},
+		subscribers: map[string][]*localPubSub{},
+	}
+}
+
+//Get a key from local store
+func (s *LocalStore) Get(key string, value interface{}) bool {
+	v, ok := s.data.Get(key)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(v.([]byte), value); err != nil {
+		log.Warning("local> Cannot unmarshal %s :%s", key, err)
+		return false
+	}
+	return true
+}
+
+//SetWithTTL a value in local store (0 for eternity)
+func (s *LocalStore) SetWithTTL(key string, value interface{}, ttl int) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		log.Warning("local> Error caching %s: %s", key, err)
+		return
+	}
+	expiration := gocache.NoExpiration
+	if ttl > 0 {
+		expiration = time.Duration(ttl) * time.Second
+	}
+	s.data.Set(key, b, expiration)
+}
+
+//Set a value in local store
+func (s *LocalStore) Set(key string, value interface{}) {
+	s.SetWithTTL(key, value, s.ttl)
+}
+
+//Delete a key in local store
+func (s *LocalStore) Delete(key string) {
+	s.data.Delete(key)
+}
+
+//DeleteAll delete all mathing keys in local store, the pattern has the syntax of the redis KEYS command
+func (s *LocalStore) DeleteAll(pattern string) {
+	r, err := globToRegexp(pattern)
+	if err != nil {
+		log.Warning("local> Error deleting %s : %s", pattern, err)
+		return
+	}
+	for k := range s.data.Items() {
+		if r.MatchString(k) {
+			s.data.Delete(k)
+		}
+	}
+}
+
+//Enqueue pushes to queue
+func (s *LocalStore) Enqueue(queueName string, value interface{}) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		log.Warning("local> Error queueing %s:%s", queueName, err)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	q, ok := s.queues[queueName]
+	if !ok {
+		q = list.New()
+		s.queues[queueName] = q
+	}
+	q.PushBack(b)
+
+	// wake up the consumers waiting on the queue
+	if c, ok := s.waiters[queueName]; ok {
+		close(c)
+		delete(s.waiters, queueName)
+	}
+}
+
+//Dequeue gets from queue This is blocking while there is nothing in the queue
+func (s *LocalStore) Dequeue(queueName string, value interface{}) {
+	s.DequeueWithContext(context.Background(), queueName, value)
+}
+
+//DequeueWithContext gets from queue This is blocking while there is nothing in the queue, it can be cancelled with a context.Context
+func (s *LocalStore) DequeueWithContext(c context.Context, queueName string, value interface{}) {
+	for {
+		s.Lock()
+		if q, ok := s.queues[queueName]; ok && q.Len() > 0 {
+			b := q.Remove(q.Front()).([]byte)
+			s.Unlock()
+			if err := json.Unmarshal(b, value); err != nil {
+				log.Warning("local> Cannot unmarshal %s :%s", queueName, err)
+			}
+			return
+		}
+		wait, ok := s.waiters[queueName]
+		if !ok {
+			wait = make(chan struct{})
+			s.waiters[queueName] = wait
+		}
+		s.Unlock()
+
+		select {
+		case <-wait:
+		case <-c.Done():
+			return
+		}
+	}
+}
+
+//QueueLen returns the length of a queue
+func (s *LocalStore) QueueLen(queueName string) int {
+	s.Lock()
+	defer s.Unlock()
+	q, ok := s.queues[queueName]
+	if !ok {
+		return 0
+	}
+	return q.Len()
+}
+
+// Publish a msg in a channel
+func (s *LocalStore) Publish(channel string, value interface{}) {
+	msg, err := json.Marshal(value)
+	if err != nil {
+		log.Warning("local.Publish> Marshall error, cannot push in channel %s: %v, %s", channel, value, err)
+		return
+	}
+	// Like with redis, the message is the unquoted json string
+	iUnquoted, err := strconv.Unquote(string(msg))
+	if err != nil {
+		log.Warning("local.Publish> Unquote error, cannot push in channel %s: %v, %s", channel, string(msg), err)
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	for _, ps := range s.subscribers[channel] {
+		select {
+		case ps.messages <- iUnquoted:
+		default:
+			log.Warning("local.Publish> subscriber of channel %s is full, message dropped", channel)
+		}
+	}
+}
+
+// Subscribe to a channel
+func (s *LocalStore) Subscribe(channel string) PubSub {
+	ps := &localPubSub{
+		store:    s,
+		channels: []string{channel},
+		messages: make(chan string, localSubscriptionBuffer),
+	}
+	s.Lock()
+	s.subscribers[channel] = append(s.subscribers[channel], ps)
+	s.Unlock()
+	return ps
+}
+
+// Unsubscribe removes the subscription of the channels, all channels without argument
+func (ps *localPubSub) Unsubscribe(channels ...string) error {
+	if len(channels) == 0 {
+		channels = ps.channels
+	}
+	ps.store.Lock()
+	defer ps.store.Unlock()
+	for _, channel := range channels {
+		subscribers := ps.store.subscribers[channel]
+		for i := range subscribers {
+			if subscribers[i] == ps {
+				ps.store.subscribers[channel] = append(subscribers[:i], subscribers[i+1:]...)
+				break
+			}
+		}
+		if len(ps.store.subscribers[channel]) == 0 {
+			delete(ps.store.subscribers, channel)
+		}
+	}
+	return nil
+}
+
+// GetMessageFromSubscription from a local PubSub
+func (s *LocalStore) GetMessageFromSubscription(c context.Context, pb PubSub) (string, error) {
+	ps, ok := pb.(*localPubSub)
+	if !ok {
+		return "", fmt.Errorf("local.GetMessage> PubSub is not a local PubSub. Got %T", pb)
+	}
+
+	select {
+	case msg := <-ps.messages:
+		return msg, nil
+	case <-c.Done():
+		return "", nil
+	}
+}
+
+// Status returns the status of the local cache
+func (s *LocalStore) Status() sdk.MonitoringStatusLine {
+	return sdk.MonitoringStatusLine{Component: "Cache", Value: fmt.Sprintf("Local: %d keys", s.data.ItemCount()), Status: sdk.MonitoringStatusOK}
+}
+
+// SetAdd add a member (identified by a key) in the cached set
+func (s *LocalStore) SetAdd(rootKey string, memberKey string, member interface{}) {
+	s.Lock()
+	set, ok := s.sets[rootKey]
+	if !ok {
+		set = list.New()
+		s.sets[rootKey] = set
+	}
+	// as with the redis sorted sets, a member added again moves at the end of the set
+	removeFromSet(set, memberKey)
+	set.PushBack(memberKey)
+	s.Unlock()
+	s.SetWithTTL(Key(rootKey, memberKey), member, -1)
+}
+
+// SetRemove removes a member from a set
+func (s *LocalStore) SetRemove(rootKey string, memberKey string, member interface{}) {
+	s.Lock()
+	if set, ok := s.sets[rootKey]; ok {
+		removeFromSet(set, memberKey)
+		if set.Len() == 0 {
+			delete(s.sets, rootKey)
+		}
+	}
+	s.Unlock()
+	s.Delete(Key(rootKey, memberKey))
+}
+
+// SetCard returns the cardinality of a set
+func (s *LocalStore) SetCard(key string) int {
+	s.Lock()
+	defer s.Unlock()
+	set, ok := s.sets[key]
+	if !ok {
+		return 0
+	}
+	return set.Len()
+}
+
+// SetScan scans a set
+func (s *LocalStore) SetScan(key string, members ...interface{}) error {
+	s.Lock()
+	var values []string
+	if set, ok := s.sets[key]; ok {
+		for e := set.Front(); e != nil; e = e.Next() {
+			values = append(values, e.Value.(string))
+		}
+	}
+	s.Unlock()
+
+	for i := range members {
+		if i >= len(values) {
+			break
+		}
+		memKey := Key(key, values[i])
+		if !s.Get(memKey, members[i]) {
+			return fmt.Errorf("Member (%s) not found", memKey)
+		}
+	}
+	return nil
+}
+
+func removeFromSet(set *list.List, memberKey string) {
+	for e := set.Front(); e != nil; e = e.Next() {
+		if e.Value.(string) == memberKey {
+			set.Remove(e)
+			return
+		}
+	}
+}
+
+// globToRegexp converts a redis glob-style pattern to a regexp: * matches any string, ? any character
+// and [...] a set of characters
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var r bytes.Buffer
+	r.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			r.WriteString(".*")
+		case '?':
+			r.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				r.WriteString(regexp.QuoteMeta(pattern[i:]))
+				i = len(pattern)
+				continue
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^" + strings.Replace(class[1:], `\`, `\\`, -1)
+			} else {
+				class = strings.Replace(class, `\`, `\\`, -1)
+			}
+			r.WriteString("[" + class + "]")
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				r.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			r.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	r.WriteString("$")
+	return regexp.Compile(r.String())
+}
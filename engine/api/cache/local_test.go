@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localTestItem struct {
+	Name string
+}
+
+func TestLocalStoreGetSet(t *testing.T) {
+	s := NewLocalStore(60)
+
+	s.Set("foo", localTestItem{Name: "bar"})
+	var i localTestItem
+	assert.True(t, s.Get("foo", &i))
+	assert.Equal(t, "bar", i.Name)
+
+	s.Delete("foo")
+	assert.False(t, s.Get("foo", &i))
+
+	s.SetWithTTL("expired", localTestItem{Name: "bar"}, 1)
+	time.Sleep(1100 * time.Millisecond)
+	assert.False(t, s.Get("expired", &i))
+}
+
+func TestLocalStoreDeleteAll(t *testing.T) {
+	s := NewLocalStore(60)
+	for _, k := range []string{"cds:project:a", "cds:project:b", "cds:application:a", "cds:pxoject:c"} {
+		s.Set(k, k)
+	}
+
+	s.DeleteAll("cds:project:*")
+	var v string
+	assert.False(t, s.Get("cds:project:a", &v))
+	assert.False(t, s.Get("cds:project:b", &v))
+	assert.True(t, s.Get("cds:application:a", &v))
+	assert.True(t, s.Get("cds:pxoject:c", &v))
+
+	s.DeleteAll("cds:p[^r]oject:?")
+	assert.False(t, s.Get("cds:pxoject:c", &v))
+	assert.True(t, s.Get("cds:application:a", &v))
+}
+
+func TestLocalStoreQueue(t *testing.T) {
+	s := NewLocalStore(60)
+	s.Enqueue("queue", localTestItem{Name: "1"})
+	s.Enqueue("queue", localTestItem{Name: "2"})
+	assert.Equal(t, 2, s.QueueLen("queue"))
+
+	var i localTestItem
+	s.Dequeue("queue", &i)
+	assert.Equal(t, "1", i.Name)
+	s.Dequeue("queue", &i)
+	assert.Equal(t, "2", i.Name)
+	assert.Equal(t, 0, s.QueueLen("queue"))
+
+	// a consumer waits for the next item
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		s.Enqueue("queue", localTestItem{Name: "3"})
+	}()
+	s.Dequeue("queue", &i)
+	assert.Equal(t, "3", i.Name)
+
+	// a cancelled consumer does not wait anymore
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	i = localTestItem{}
+	s.DequeueWithContext(ctx, "queue", &i)
+	assert.Equal(t, "", i.Name)
+}
+
+func TestLocalStorePubSub(t *testing.T) {
+	s := NewLocalStore(60)
+	ps := s.Subscribe("events")
+	s.Publish("events", "message")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := s.GetMessageFromSubscription(ctx, ps)
+	assert.NoError(t, err)
+	assert.Equal(t, "message", msg)
+
+	assert.NoError(t, ps.Unsubscribe())
+	s.Publish("events", "message")
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	msg, err = s.GetMessageFromSubscription(ctx, ps)
+	assert.NoError(t, err)
+	assert.Equal(t, "", msg)
+}
+
+func TestLocalStoreSet(t *testing.T) {
+	s := NewLocalStore(60)
+	s.SetAdd("set", "a", localTestItem{Name: "a"})
+	s.SetAdd("set", "b", localTestItem{Name: "b"})
+	s.SetAdd("set", "a", localTestItem{Name: "a2"})
+	assert.Equal(t, 2, s.SetCard("set"))
+
+	items := []*localTestItem{{}, {}}
+	assert.NoError(t, s.SetScan("set", items[0], items[1]))
+	assert.Equal(t, "b", items[0].Name)
+	assert.Equal(t, "a2", items[1].Name)
+
+	s.SetRemove("set", "b", nil)
+	assert.Equal(t, 1, s.SetCard("set"))
+	s.SetRemove("set", "a", nil)
+	assert.Equal(t, 0, s.SetCard("set"))
+}
@@ -11,11 +11,20 @@ type TableMapping struct {
 	Name          string
 	AutoIncrement bool
 	Keys          []string
+	// EncryptedFields are the columns storing data encrypted with the secrets key. A field of a JSON column
+	// is given as column.field, its value is encrypted and base64 encoded
+	EncryptedFields []string
 }
 
 // New initialize a TableMapping
 func New(t interface{}, n string, b bool, k ...string) TableMapping {
-	return TableMapping{t, n, b, k}
+	return TableMapping{Target: t, Name: n, AutoIncrement: b, Keys: k}
+}
+
+// Encrypted declares the columns, or fields of JSON columns, storing data encrypted with the secrets key
+func (t TableMapping) Encrypted(fields ...string) TableMapping {
+	t.EncryptedFields = append(t.EncryptedFields, fields...)
+	return t
 }
 
 // Mapping is the global var for all registered mapping
@@ -0,0 +1,51 @@
+package environment
+
+import (
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// InsertDeployment inserts a deployment on an environment
+func InsertDeployment(db gorp.SqlExecutor, d *sdk.EnvironmentDeployment) error {
+	dbDeployment := dbEnvironmentDeployment(*d)
+	if err := db.Insert(&dbDeployment); err != nil {
+		return sdk.WrapError(err, "InsertDeployment> Cannot insert deployment on environment %d", d.EnvironmentID)
+	}
+	*d = sdk.EnvironmentDeployment(dbDeployment)
+	return nil
+}
+
+// LoadCurrentDeployments loads the last deployment of each application on an environment
+func LoadCurrentDeployments(db gorp.SqlExecutor, envID int64) ([]sdk.EnvironmentDeployment, error) {
+	query := `
+	SELECT * FROM (
+		SELECT DISTINCT ON (application_id) * FROM environment_deployment
+		WHERE environment_id = $1
+		ORDER BY application_id, date DESC
+	) AS current ORDER BY application_name`
+	return loadDeployments(db, query, envID)
+}
+
+// LoadDeploymentsHistory loads the deployments on an environment, from the most recent. If appName is set, only the deployments of this application are loaded
+func LoadDeploymentsHistory(db gorp.SqlExecutor, envID int64, appName string, offset, limit int) ([]sdk.EnvironmentDeployment, error) {
+	query := `
+	SELECT * FROM environment_deployment
+	WHERE environment_id = $1 AND ($2 = '' OR application_name = $2)
+	ORDER BY date DESC
+	OFFSET $3 LIMIT $4`
+	return loadDeployments(db, query, envID, appName, offset, limit)
+}
+
+func loadDeployments(db gorp.SqlExecutor, query string, args ...interface{}) ([]sdk.EnvironmentDeployment, error) {
+	var res []dbEnvironmentDeployment
+	if _, err := db.Select(&res, query, args...); err != nil {
+		return nil, sdk.WrapError(err, "loadDeployments> Cannot load deployments")
+	}
+
+	deployments := make([]sdk.EnvironmentDeployment, len(res))
+	for i := range res {
+		deployments[i] = sdk.EnvironmentDeployment(res[i])
+	}
+	return deployments, nil
+}
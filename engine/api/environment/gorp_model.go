@@ -14,10 +14,12 @@ import (
 
 type dbEnvironmentVariableAudit sdk.EnvironmentVariableAudit
 type dbEnvironmentKey sdk.EnvironmentKey
+type dbEnvironmentDeployment sdk.EnvironmentDeployment
 
 func init() {
-	gorpmapping.Register(gorpmapping.New(dbEnvironmentVariableAudit{}, "environment_variable_audit", true, "id"))
-	gorpmapping.Register(gorpmapping.New(dbEnvironmentKey{}, "environment_key", false))
+	gorpmapping.Register(gorpmapping.New(dbEnvironmentVariableAudit{}, "environment_variable_audit", true, "id").Encrypted("variable_before.value", "variable_after.value"))
+	gorpmapping.Register(gorpmapping.New(dbEnvironmentKey{}, "environment_key", false).Encrypted("private"))
+	gorpmapping.Register(gorpmapping.New(dbEnvironmentDeployment{}, "environment_deployment", true, "id"))
 }
 
 // PostGet is a db hook
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getEnvironmentDeploymentsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		projectKey := vars["key"]
+		environmentName := vars["permEnvironmentName"]
+
+		env, errEnv := environment.LoadEnvironmentByName(api.mustDB(), projectKey, environmentName)
+		if errEnv != nil {
+			return sdk.WrapError(errEnv, "getEnvironmentDeploymentsHandler> Cannot load environment %s for project %s from db", environmentName, projectKey)
+		}
+
+		deployments, err := environment.LoadCurrentDeployments(api.mustDB(), env.ID)
+		if err != nil {
+			return sdk.WrapError(err, "getEnvironmentDeploymentsHandler> Cannot load deployments on environment %s", environmentName)
+		}
+		api.setDeploymentsURL(projectKey, deployments)
+
+		return WriteJSON(w, r, deployments, http.StatusOK)
+	}
+}
+
+func (api *API) getEnvironmentDeploymentsHistoryHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		projectKey := vars["key"]
+		environmentName := vars["permEnvironmentName"]
+		appName := FormString(r, "application")
+
+		offset, limit := 0, 20
+		if s := FormString(r, "offset"); s != "" {
+			var err error
+			offset, err = strconv.Atoi(s)
+			if err != nil || offset < 0 {
+				return sdk.WrapError(sdk.ErrWrongRequest, "getEnvironmentDeploymentsHistoryHandler> Invalid offset %s", s)
+			}
+		}
+		if s := FormString(r, "limit"); s != "" {
+			var err error
+			limit, err = strconv.Atoi(s)
+			if err != nil || limit <= 0 {
+				return sdk.WrapError(sdk.ErrWrongRequest, "getEnvironmentDeploymentsHistoryHandler> Invalid limit %s", s)
+			}
+		}
+		if limit > rangeMax {
+			limit = rangeMax
+		}
+
+		env, errEnv := environment.LoadEnvironmentByName(api.mustDB(), projectKey, environmentName)
+		if errEnv != nil {
+			return sdk.WrapError(errEnv, "getEnvironmentDeploymentsHistoryHandler> Cannot load environment %s for project %s from db", environmentName, projectKey)
+		}
+
+		deployments, err := environment.LoadDeploymentsHistory(api.mustDB(), env.ID, appName, offset, limit)
+		if err != nil {
+			return sdk.WrapError(err, "getEnvironmentDeploymentsHistoryHandler> Cannot load deployments on environment %s", environmentName)
+		}
+		api.setDeploymentsURL(projectKey, deployments)
+
+		return WriteJSON(w, r, deployments, http.StatusOK)
+	}
+}
+
+// setDeploymentsURL sets the link to the workflow run of each deployment
+func (api *API) setDeploymentsURL(projectKey string, deployments []sdk.EnvironmentDeployment) {
+	for i := range deployments {
+		d := &deployments[i]
+		d.URL = fmt.Sprintf("%s/project/%s/workflow/%s/run/%d", api.Config.URL.UI, projectKey, d.WorkflowName, d.WorkflowRunNumber)
+	}
+}
@@ -226,6 +226,9 @@ func (api *API) updateVariableInEnvironmentHandler() Handler {
 		if err := UnmarshalBody(r, &newVar); err != nil {
 			return sdk.ErrWrongRequest
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		env, errEnv := environment.LoadEnvironmentByName(api.mustDB(), key, envName)
 		if errEnv != nil {
@@ -280,6 +283,9 @@ func (api *API) addVariableInEnvironmentHandler() Handler {
 		if err := UnmarshalBody(r, &newVar); err != nil {
 			return sdk.ErrWrongRequest
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		if newVar.Name != varName {
 			return sdk.ErrWrongRequest
@@ -10,6 +10,8 @@ It is generated from these files:
 	workflowqueue.proto
 
 It has these top-level messages:
+	JobStopRequest
+	JobStop
 */
 package grpc
 
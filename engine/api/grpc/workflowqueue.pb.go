@@ -21,6 +21,61 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+// JobStopRequest is sent by a worker to be notified of the stop of the job it runs
+type JobStopRequest struct {
+	JobID int64 `protobuf:"varint,1,opt,name=jobID" json:"jobID,omitempty"`
+}
+
+func (m *JobStopRequest) Reset()                    { *m = JobStopRequest{} }
+func (m *JobStopRequest) String() string            { return proto.CompactTextString(m) }
+func (*JobStopRequest) ProtoMessage()               {}
+func (*JobStopRequest) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{0} }
+
+func (m *JobStopRequest) GetJobID() int64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+// JobStop is sent to the worker running a job when the job is stopped
+type JobStop struct {
+	JobID     int64  `protobuf:"varint,1,opt,name=jobID" json:"jobID,omitempty"`
+	Reason    string `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	StoppedBy string `protobuf:"bytes,3,opt,name=stoppedBy" json:"stoppedBy,omitempty"`
+}
+
+func (m *JobStop) Reset()                    { *m = JobStop{} }
+func (m *JobStop) String() string            { return proto.CompactTextString(m) }
+func (*JobStop) ProtoMessage()               {}
+func (*JobStop) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+func (m *JobStop) GetJobID() int64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+func (m *JobStop) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *JobStop) GetStoppedBy() string {
+	if m != nil {
+		return m.StoppedBy
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*JobStopRequest)(nil), "grpc.JobStopRequest")
+	proto.RegisterType((*JobStop)(nil), "grpc.JobStop")
+}
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ context.Context
 var _ grpc1.ClientConn
@@ -34,6 +89,7 @@ const _ = grpc1.SupportPackageIsVersion4
 type WorkflowQueueClient interface {
 	SendLog(ctx context.Context, opts ...grpc1.CallOption) (WorkflowQueue_SendLogClient, error)
 	SendResult(ctx context.Context, in *github_com_ovh_cds_sdk1.Result, opts ...grpc1.CallOption) (*google_protobuf1.Empty, error)
+	WatchJobStop(ctx context.Context, in *JobStopRequest, opts ...grpc1.CallOption) (WorkflowQueue_WatchJobStopClient, error)
 }
 
 type workflowQueueClient struct {
@@ -87,11 +143,44 @@ func (c *workflowQueueClient) SendResult(ctx context.Context, in *github_com_ovh
 	return out, nil
 }
 
+func (c *workflowQueueClient) WatchJobStop(ctx context.Context, in *JobStopRequest, opts ...grpc1.CallOption) (WorkflowQueue_WatchJobStopClient, error) {
+	stream, err := grpc1.NewClientStream(ctx, &_WorkflowQueue_serviceDesc.Streams[1], c.cc, "/grpc.WorkflowQueue/WatchJobStop", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &workflowQueueWatchJobStopClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WorkflowQueue_WatchJobStopClient interface {
+	Recv() (*JobStop, error)
+	grpc1.ClientStream
+}
+
+type workflowQueueWatchJobStopClient struct {
+	grpc1.ClientStream
+}
+
+func (x *workflowQueueWatchJobStopClient) Recv() (*JobStop, error) {
+	m := new(JobStop)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // Server API for WorkflowQueue service
 
 type WorkflowQueueServer interface {
 	SendLog(WorkflowQueue_SendLogServer) error
 	SendResult(context.Context, *github_com_ovh_cds_sdk1.Result) (*google_protobuf1.Empty, error)
+	WatchJobStop(*JobStopRequest, WorkflowQueue_WatchJobStopServer) error
 }
 
 func RegisterWorkflowQueueServer(s *grpc1.Server, srv WorkflowQueueServer) {
@@ -142,6 +231,27 @@ func _WorkflowQueue_SendResult_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WorkflowQueue_WatchJobStop_Handler(srv interface{}, stream grpc1.ServerStream) error {
+	m := new(JobStopRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WorkflowQueueServer).WatchJobStop(m, &workflowQueueWatchJobStopServer{stream})
+}
+
+type WorkflowQueue_WatchJobStopServer interface {
+	Send(*JobStop) error
+	grpc1.ServerStream
+}
+
+type workflowQueueWatchJobStopServer struct {
+	grpc1.ServerStream
+}
+
+func (x *workflowQueueWatchJobStopServer) Send(m *JobStop) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _WorkflowQueue_serviceDesc = grpc1.ServiceDesc{
 	ServiceName: "grpc.WorkflowQueue",
 	HandlerType: (*WorkflowQueueServer)(nil),
@@ -157,6 +267,11 @@ var _WorkflowQueue_serviceDesc = grpc1.ServiceDesc{
 			Handler:       _WorkflowQueue_SendLog_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchJobStop",
+			Handler:       _WorkflowQueue_WatchJobStop_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "workflowqueue.proto",
 }
@@ -164,18 +279,24 @@ var _WorkflowQueue_serviceDesc = grpc1.ServiceDesc{
 func init() { proto.RegisterFile("workflowqueue.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 196 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0xce, 0xbf, 0xca, 0xc2, 0x30,
-	0x14, 0x05, 0xf0, 0xaf, 0xf0, 0xa1, 0x10, 0x70, 0x89, 0xe0, 0xd0, 0x82, 0x88, 0x2e, 0x4e, 0x37,
-	0xa0, 0x6f, 0x20, 0xe8, 0xd4, 0x45, 0x1d, 0xdc, 0x9b, 0xa4, 0xa9, 0x34, 0xf5, 0xd6, 0xfc, 0x69,
-	0xf1, 0x59, 0x7c, 0x59, 0x69, 0x53, 0x71, 0xea, 0x78, 0xef, 0x39, 0xfc, 0x38, 0x64, 0xde, 0xa2,
-	0x29, 0x73, 0x8d, 0xed, 0xd3, 0x4b, 0x2f, 0xa1, 0x36, 0xe8, 0x90, 0xfe, 0x2b, 0x53, 0xf3, 0x78,
-	0xa5, 0xee, 0xae, 0xf0, 0x19, 0x70, 0xac, 0x18, 0x36, 0x05, 0xe3, 0xc2, 0x32, 0x2b, 0x4a, 0xa6,
-	0x51, 0x85, 0x5e, 0xbc, 0x19, 0x69, 0x18, 0x69, 0xbd, 0x76, 0x43, 0x29, 0x51, 0x88, 0x4a, 0x4b,
-	0xd6, 0x5f, 0x99, 0xcf, 0x99, 0xac, 0x6a, 0xf7, 0x0a, 0xe1, 0xee, 0x1d, 0x91, 0xd9, 0x6d, 0x58,
-	0x70, 0xee, 0x16, 0xd0, 0x03, 0x99, 0x5e, 0xe5, 0x43, 0xa4, 0xa8, 0x68, 0x02, 0x3f, 0x1f, 0xb0,
-	0x29, 0x80, 0x0b, 0x0b, 0x56, 0x94, 0x90, 0xa2, 0x8a, 0x17, 0x10, 0x5c, 0xf8, 0xba, 0x70, 0xec,
-	0xdc, 0xf5, 0xdf, 0x36, 0xa2, 0x27, 0x42, 0x3a, 0xe3, 0xd2, 0xcf, 0xa0, 0xcb, 0x31, 0x26, 0xe4,
-	0xe3, 0x52, 0x36, 0xe9, 0x3f, 0xfb, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xaa, 0x1f, 0xda, 0xec,
-	0x25, 0x01, 0x00, 0x00,
+	// 290 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xcf, 0x4a, 0xf3, 0x40,
+	0x14, 0xc5, 0x3b, 0x5f, 0x3f, 0x5b, 0x3a, 0x58, 0x17, 0xa3, 0x94, 0x92, 0x8a, 0x94, 0x08, 0x92,
+	0xd5, 0x1d, 0xd1, 0x85, 0xfb, 0xa2, 0x82, 0xd2, 0x8d, 0x29, 0xd2, 0x75, 0x93, 0x99, 0x4e, 0x34,
+	0x69, 0xef, 0x34, 0x33, 0xd3, 0xd2, 0xf7, 0xf4, 0x81, 0x24, 0x7f, 0xb4, 0x14, 0xcc, 0xf2, 0xdc,
+	0x73, 0x38, 0xf0, 0x3b, 0x97, 0x9e, 0xef, 0x30, 0x4f, 0x97, 0x19, 0xee, 0x36, 0x4e, 0x3a, 0x09,
+	0x3a, 0x47, 0x8b, 0xec, 0xbf, 0xca, 0x75, 0xec, 0x8d, 0xd5, 0x87, 0x4d, 0x5c, 0x04, 0x31, 0xae,
+	0x38, 0x6e, 0x13, 0x1e, 0x0b, 0xc3, 0x8d, 0x48, 0x79, 0x86, 0xaa, 0xca, 0x79, 0xd7, 0x0d, 0x89,
+	0x5c, 0x1a, 0x97, 0xd9, 0x3a, 0x34, 0x52, 0x88, 0x2a, 0x93, 0xbc, 0x54, 0x91, 0x5b, 0x72, 0xb9,
+	0xd2, 0x76, 0x5f, 0x99, 0xfe, 0x0d, 0x3d, 0x7b, 0xc5, 0x68, 0x66, 0x51, 0x87, 0x72, 0xe3, 0xa4,
+	0xb1, 0xec, 0x82, 0x9e, 0x7c, 0x62, 0xf4, 0xf2, 0x38, 0x24, 0x63, 0x12, 0xb4, 0xc3, 0x4a, 0xf8,
+	0xef, 0xb4, 0x5b, 0xe7, 0xfe, 0x0e, 0xb0, 0x01, 0xed, 0xe4, 0x72, 0x61, 0x70, 0x3d, 0xfc, 0x37,
+	0x26, 0x41, 0x2f, 0xac, 0x15, 0xbb, 0xa4, 0x3d, 0x63, 0x51, 0x6b, 0x29, 0x26, 0xfb, 0x61, 0xbb,
+	0xb4, 0x0e, 0x87, 0xbb, 0x2f, 0x42, 0xfb, 0xf3, 0x7a, 0x80, 0xb7, 0x62, 0x00, 0x36, 0xa1, 0xdd,
+	0x99, 0x5c, 0x8b, 0x29, 0x2a, 0x36, 0x82, 0x03, 0x1e, 0xe0, 0x36, 0x81, 0x58, 0x18, 0x30, 0x22,
+	0x85, 0x29, 0x2a, 0x6f, 0x00, 0x15, 0x16, 0xfc, 0x60, 0xc1, 0x53, 0x81, 0xe5, 0xb7, 0x02, 0xc2,
+	0x9e, 0x29, 0x2d, 0x3a, 0xc2, 0x72, 0x05, 0x76, 0xd5, 0x54, 0x53, 0xf9, 0xcd, 0x4d, 0xec, 0x81,
+	0x9e, 0xce, 0x17, 0x36, 0x4e, 0x7e, 0xc9, 0xa1, 0xf8, 0x0b, 0x1c, 0x0f, 0xe6, 0xf5, 0x8f, 0xae,
+	0x7e, 0xeb, 0x96, 0x44, 0x9d, 0xb2, 0xea, 0xfe, 0x7b, 0x00, 0x67, 0x1d, 0x6a, 0xfa, 0xdd, 0x01,
+	0x00, 0x00,
 }
@@ -57,7 +57,12 @@ func (h *grpcHandlers) SendLog(stream grpc.WorkflowQueue_SendLogServer) error {
 		log.Debug("grpc.SendLog> Got %+v", in)
 
 		db := h.dbConnectionFactory.GetDBMap()
-		if err := workflow.AddLog(db, nil, in); err != nil {
+		secrets, errS := loadJobLogSecrets(db, h.store, in.PipelineBuildJobID)
+		if errS != nil {
+			return sdk.WrapError(errS, "grpc.SendLog> Cannot load secrets of job %d", in.PipelineBuildJobID)
+		}
+
+		if err := workflow.AddLog(db, h.store, nil, in, secrets); err != nil {
 			return sdk.WrapError(err, "grpc.SendLog> Unable to insert log ")
 		}
 	}
@@ -108,3 +113,32 @@ func (h *grpcHandlers) SendResult(c context.Context, res *sdk.Result) (*empty.Em
 
 	return new(empty.Empty), nil
 }
+
+//WatchJobStop is the WorkflowQueueServer implementation
+func (h *grpcHandlers) WatchJobStop(req *grpc.JobStopRequest, stream grpc.WorkflowQueue_WatchJobStopServer) error {
+	log.Debug("grpc.WatchJobStop> begin %d", req.JobID)
+	defer log.Debug("grpc.WatchJobStop> end %d", req.JobID)
+
+	//Only the worker running the job is notified
+	workerID, ok := stream.Context().Value(keyWorkerID).(string)
+	if !ok {
+		return sdk.ErrForbidden
+	}
+	job, err := workflow.LoadNodeJobRun(h.dbConnectionFactory.GetDBMap(), h.store, req.JobID)
+	if err != nil {
+		return sdk.WrapError(err, "grpc.WatchJobStop> Cannot load job %d", req.JobID)
+	}
+	if job.Job.WorkerID != workerID {
+		return sdk.WrapError(sdk.ErrForbidden, "grpc.WatchJobStop> Job %d is not run by worker %s", req.JobID, workerID)
+	}
+
+	stop := workflow.WaitNodeJobRunStop(stream.Context(), req.JobID)
+	if stop == nil {
+		return nil
+	}
+	return stream.Send(&grpc.JobStop{
+		JobID:     stop.JobID,
+		Reason:    stop.Reason,
+		StoppedBy: stop.StoppedBy,
+	})
+}
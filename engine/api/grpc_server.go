@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/ovh/cds/engine/api/auth"
+	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/database"
 	cdsgrpc "github.com/ovh/cds/engine/api/grpc"
 	"github.com/ovh/cds/sdk"
@@ -17,7 +18,7 @@ import (
 )
 
 // grpcInit initialize all GRPC services
-func grpcInit(dbConnectionFactory *database.DBConnectionFactory, port int, tls bool, certFile, keyFile string) error {
+func grpcInit(dbConnectionFactory *database.DBConnectionFactory, store cache.Store, port int, tls bool, certFile, keyFile string) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return err
@@ -27,6 +28,7 @@ func grpcInit(dbConnectionFactory *database.DBConnectionFactory, port int, tls b
 
 	grpcHandlers := &grpcHandlers{
 		dbConnectionFactory: dbConnectionFactory,
+		store:               store,
 	}
 
 	opts := []grpc.ServerOption{
@@ -67,7 +69,20 @@ func (h *grpcHandlers) streamInterceptor(srv interface{}, stream grpc.ServerStre
 	m := metadata.Pairs(string(keyWorkerID), w.ID, string(keyWorkerName), w.Name)
 	stream.SendHeader(m)
 
-	return handler(srv, stream)
+	c = context.WithValue(c, keyWorkerID, w.ID)
+	c = context.WithValue(c, keyWorkerName, w.Name)
+	return handler(srv, &workerServerStream{ServerStream: stream, ctx: c})
+}
+
+// workerServerStream is a server stream authorized for a worker
+type workerServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream with the worker values
+func (s *workerServerStream) Context() context.Context {
+	return s.ctx
 }
 
 func (h *grpcHandlers) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
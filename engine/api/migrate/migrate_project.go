@@ -0,0 +1,427 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/repositoriesmanager"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/api/workflowv0"
+	"github.com/ovh/cds/sdk"
+)
+
+// projectMigration is the migration of the applications of a project to workflows
+type projectMigration struct {
+	db     gorp.SqlExecutor
+	store  cache.Store
+	proj   *sdk.Project
+	u      *sdk.User
+	report *sdk.ProjectMigrationReport
+	// webhooks keeps by repository manager the reason why it does not support webhooks
+	webhooks map[string]string
+	// converted are the migrations of the workflows to insert
+	converted []*workflowMigration
+}
+
+// MigrateProject converts to workflows the pipelines of the applications of a project which are not migrated yet,
+// with their triggers, schedulers, pollers and repository hooks. The report lists what is converted and what cannot
+// be. With dryRun nothing is saved, and with force the existing workflows with the names of the new ones are replaced
+func MigrateProject(db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, u *sdk.User, dryRun, force bool) (*sdk.ProjectMigrationReport, error) {
+	pm := &projectMigration{
+		db:       db,
+		store:    store,
+		proj:     proj,
+		u:        u,
+		report:   &sdk.ProjectMigrationReport{ProjectKey: proj.Key, DryRun: dryRun, Items: []sdk.ProjectMigrationItem{}},
+		webhooks: map[string]string{},
+	}
+
+	var workflows []sdk.Workflow
+	migrated := map[int64]sdk.Application{}
+	toClean := map[int64]sdk.Application{}
+	for _, app := range proj.Applications {
+		if app.WorkflowMigration != STATUS_INIT && app.WorkflowMigration != "" {
+			pm.report.Items = append(pm.report.Items, sdk.ProjectMigrationItem{
+				Kind:        sdk.ProjectMigrationItemApplication,
+				Application: app.Name,
+				Reason:      fmt.Sprintf("migration already %s", strings.ToLower(app.WorkflowMigration)),
+			})
+			continue
+		}
+
+		cdTree, err := workflowv0.LoadCDTree(db, store, proj.Key, app.Name, u, "", "", 0)
+		if err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject> Cannot load cd tree of application %s", app.Name)
+		}
+
+		ws, subApplications, err := pm.migrateApplication(app, cdTree, force)
+		if err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject> Cannot migrate application %s", app.Name)
+		}
+		if ws == nil {
+			continue
+		}
+		workflows = append(workflows, ws...)
+		if len(ws) == 0 {
+			toClean[app.ID] = app
+		} else {
+			migrated[app.ID] = app
+		}
+		for id, a := range subApplications {
+			toClean[id] = a
+		}
+	}
+
+	if dryRun {
+		return pm.report, nil
+	}
+
+	for i := range workflows {
+		if err := pm.insertWorkflow(&workflows[i], force); err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject> Cannot insert workflow %s", workflows[i].Name)
+		}
+	}
+	for _, m := range pm.converted {
+		if err := m.disableLegacyTriggers(db); err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject")
+		}
+	}
+
+	// the applications whose pipelines are the roots of the workflows are cleaned once the migration is validated,
+	// the other ones are cleaned now
+	for id, app := range toClean {
+		if _, ok := migrated[id]; ok {
+			continue
+		}
+		app.WorkflowMigration = STATUS_CLEANING
+		app.ProjectID = proj.ID
+		if err := application.Update(db, store, &app, u); err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject> Cannot update application %s", app.Name)
+		}
+	}
+	for _, app := range migrated {
+		app.WorkflowMigration = STATUS_START
+		app.ProjectID = proj.ID
+		if err := application.Update(db, store, &app, u); err != nil {
+			return nil, sdk.WrapError(err, "MigrateProject> Cannot update application %s", app.Name)
+		}
+	}
+
+	return pm.report, nil
+}
+
+// migrateApplication converts the pipelines of an application to workflows, named as by MigrateToWorkflow. It
+// returns nil if the application cannot be migrated, and the other applications of the triggered pipelines
+func (pm *projectMigration) migrateApplication(app sdk.Application, cdTree []sdk.CDPipeline, force bool) ([]sdk.Workflow, map[int64]sdk.Application, error) {
+	appItem := sdk.ProjectMigrationItem{
+		Kind:        sdk.ProjectMigrationItemApplication,
+		Application: app.Name,
+	}
+
+	workflows := []sdk.Workflow{}
+	subApplications := map[int64]sdk.Application{}
+	var items []sdk.ProjectMigrationItem
+	var migrations []*workflowMigration
+	for i := range cdTree {
+		oldW := cdTree[i]
+		name := "w" + app.Name
+		if len(cdTree) > 1 {
+			name = fmt.Sprintf("%s_%d", name, i)
+		}
+
+		exists, err := workflow.Exists(pm.db, pm.proj.Key, name)
+		if err != nil {
+			return nil, nil, sdk.WrapError(err, "migrateApplication")
+		}
+		if exists && !force {
+			appItem.Reason = fmt.Sprintf("workflow %s already exists", name)
+			pm.report.Items = append(pm.report.Items, appItem)
+			return nil, nil, nil
+		}
+
+		newW := sdk.Workflow{
+			Name:       name,
+			ProjectID:  pm.proj.ID,
+			ProjectKey: pm.proj.Key,
+		}
+		if err := addGroupOnWorkflow(pm.db, &newW, &oldW.Application); err != nil {
+			return nil, nil, sdk.WrapError(err, "migrateApplication")
+		}
+
+		m := newWorkflowMigration()
+		n, err := migratePipeline(pm.db, pm.store, pm.proj, oldW, app.ID, pm.u, m)
+		if err != nil {
+			return nil, nil, sdk.WrapError(err, "migrateApplication")
+		}
+		newW.Root = n
+
+		pm.migrateSchedulers(oldW, n, m)
+		pm.migrateRepositoryHooks(app, oldW, n, m)
+		for _, child := range oldW.SubPipelines {
+			pm.skipSubPipelineHooks(child, m)
+		}
+
+		for _, item := range m.items {
+			item.Workflow = name
+			items = append(items, item)
+		}
+		for id, a := range m.subApplications {
+			subApplications[id] = a
+		}
+		workflows = append(workflows, newW)
+		migrations = append(migrations, m)
+	}
+	pm.converted = append(pm.converted, migrations...)
+
+	appItem.Converted = true
+	if len(workflows) == 0 {
+		appItem.Reason = "no pipeline to migrate"
+	}
+	for _, w := range workflows {
+		if appItem.Workflow != "" {
+			appItem.Workflow += ", "
+		}
+		appItem.Workflow += w.Name
+	}
+	pm.report.Items = append(pm.report.Items, appItem)
+	pm.report.Items = append(pm.report.Items, items...)
+	return workflows, subApplications, nil
+}
+
+// schedulerHook converts a pipeline scheduler to a scheduler hook
+func schedulerHook(s sdk.PipelineScheduler) (sdk.WorkflowNodeHook, error) {
+	h := sdk.WorkflowNodeHook{
+		WorkflowHookModel: sdk.SchedulerModel,
+		Config:            sdk.WorkflowNodeHookConfig{},
+	}
+	for k, v := range sdk.SchedulerModel.DefaultConfig {
+		h.Config[k] = v
+	}
+
+	timezone := s.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	h.Config["cron"] = sdk.WorkflowNodeHookConfigValue{Value: s.Crontab, Configurable: true}
+	h.Config["timezone"] = sdk.WorkflowNodeHookConfigValue{Value: timezone, Configurable: true}
+
+	if _, err := sdk.ParseSchedulerHookConfig(h.Config); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+// sameParameters returns true if two lists of parameters have the same values
+func sameParameters(a, b []sdk.Parameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ma := sdk.ParametersToMap(a)
+	for _, p := range b {
+		if v, ok := ma[p.Name]; !ok || v != p.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// migrateSchedulers converts the schedulers of the root pipeline of a workflow to scheduler hooks. A workflow hook
+// has no parameters: the arguments of the schedulers become the default parameters of the root pipeline, so all the
+// schedulers of a pipeline must have the same arguments
+func (pm *projectMigration) migrateSchedulers(oldW sdk.CDPipeline, n *sdk.WorkflowNode, m *workflowMigration) {
+	var args []sdk.Parameter
+	var hasArgs bool
+	for _, s := range oldW.Schedulers {
+		// the schedulers of the other environments are converted with the pipeline of their environment
+		if s.EnvironmentID != oldW.Environment.ID {
+			continue
+		}
+
+		item := sdk.ProjectMigrationItem{
+			Kind:        sdk.ProjectMigrationItemScheduler,
+			Application: oldW.Application.Name,
+			Pipeline:    oldW.Pipeline.Name,
+			Environment: oldW.Environment.Name,
+		}
+
+		h, err := schedulerHook(s)
+		switch {
+		case s.Disabled:
+			item.Reason = "scheduler is disabled"
+		case err != nil:
+			item.Reason = err.Error()
+		case hasArgs && !sameParameters(args, s.Args):
+			item.Reason = "arguments differ from the ones of another scheduler of the pipeline"
+		default:
+			item.Converted = true
+			if !hasArgs && len(s.Args) > 0 {
+				item.Reason = "arguments are set as the default parameters of the pipeline"
+			}
+			hasArgs = true
+			args = s.Args
+			n.Hooks = append(n.Hooks, h)
+			m.schedulers = append(m.schedulers, s)
+		}
+		m.items = append(m.items, item)
+	}
+
+	for _, a := range args {
+		if sdk.ParameterFind(&n.Pipeline.Parameter, a.Name) != nil {
+			n.Context.DefaultPipelineParameters = append(n.Context.DefaultPipelineParameters, a)
+		}
+	}
+}
+
+// migrateRepositoryHooks converts the poller and the repository hooks of the root pipeline of a workflow to a
+// repository webhook
+func (pm *projectMigration) migrateRepositoryHooks(app sdk.Application, oldW sdk.CDPipeline, n *sdk.WorkflowNode, m *workflowMigration) {
+	var items []sdk.ProjectMigrationItem
+	if oldW.Poller != nil {
+		items = append(items, sdk.ProjectMigrationItem{
+			Kind:      sdk.ProjectMigrationItemPoller,
+			Converted: oldW.Poller.Enabled,
+		})
+	}
+	for _, h := range oldW.Hooks {
+		items = append(items, sdk.ProjectMigrationItem{
+			Kind:      sdk.ProjectMigrationItemHook,
+			Converted: h.Enabled,
+		})
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	reason := pm.webhooksUnsupported(app)
+	var converted bool
+	for _, item := range items {
+		item.Application = oldW.Application.Name
+		item.Pipeline = oldW.Pipeline.Name
+		item.Environment = oldW.Environment.Name
+		switch {
+		case !item.Converted:
+			item.Reason = item.Kind + " is disabled"
+		case reason != "":
+			item.Converted = false
+			item.Reason = reason
+		default:
+			converted = true
+			item.Reason = "converted to a repository webhook"
+		}
+		m.items = append(m.items, item)
+	}
+	if !converted {
+		return
+	}
+
+	if oldW.Poller != nil && oldW.Poller.Enabled {
+		p := *oldW.Poller
+		p.Application.ID = oldW.Application.ID
+		p.Pipeline.ID = oldW.Pipeline.ID
+		m.pollers = append(m.pollers, p)
+	}
+	// the hooks of a pipeline are loaded without their application and pipeline
+	for _, h := range oldW.Hooks {
+		if h.Enabled {
+			h.ApplicationID = oldW.Application.ID
+			h.Pipeline.ID = oldW.Pipeline.ID
+			m.hooks = append(m.hooks, h)
+		}
+	}
+	n.Context.Application = &app
+	n.Hooks = append(n.Hooks, sdk.WorkflowNodeHook{
+		WorkflowHookModel: sdk.RepositoryWebHookModel,
+		Config:            sdk.WorkflowNodeHookConfig{},
+	})
+}
+
+// webhooksUnsupported returns the reason why a repository webhook cannot be created on the repository of an
+// application, or an empty string
+func (pm *projectMigration) webhooksUnsupported(app sdk.Application) string {
+	if app.VCSServer == "" || app.RepositoryFullname == "" {
+		return "application is not linked to a repository"
+	}
+	if reason, ok := pm.webhooks[app.VCSServer]; ok {
+		return reason
+	}
+
+	var reason string
+	vcsServer := repositoriesmanager.GetProjectVCSServer(pm.proj, app.VCSServer)
+	if vcsServer == nil {
+		reason = fmt.Sprintf("repository manager %s is not linked to the project", app.VCSServer)
+	} else if client, err := repositoriesmanager.AuthorizedClient(pm.db, pm.store, vcsServer); err != nil {
+		reason = fmt.Sprintf("cannot reach repository manager %s: %v", app.VCSServer, err)
+	} else if infos, err := repositoriesmanager.GetWebhooksInfos(client); err != nil {
+		reason = fmt.Sprintf("cannot reach repository manager %s: %v", app.VCSServer, err)
+	} else if !infos.WebhooksSupported || infos.WebhooksDisabled {
+		reason = fmt.Sprintf("repository manager %s does not support webhooks", app.VCSServer)
+	}
+	pm.webhooks[app.VCSServer] = reason
+	return reason
+}
+
+// skipSubPipelineHooks reports the schedulers, poller and hooks of the triggered pipelines, which cannot be converted
+// since a workflow hook always starts the workflow from its root pipeline
+func (pm *projectMigration) skipSubPipelineHooks(oldPipeline sdk.CDPipeline, m *workflowMigration) {
+	item := sdk.ProjectMigrationItem{
+		Application: oldPipeline.Application.Name,
+		Pipeline:    oldPipeline.Pipeline.Name,
+		Environment: oldPipeline.Environment.Name,
+		Reason:      "pipeline is triggered by another pipeline, a hook can only start the root pipeline of a workflow",
+	}
+	for _, s := range oldPipeline.Schedulers {
+		if s.EnvironmentID == oldPipeline.Environment.ID {
+			item.Kind = sdk.ProjectMigrationItemScheduler
+			m.items = append(m.items, item)
+		}
+	}
+	if oldPipeline.Poller != nil {
+		item.Kind = sdk.ProjectMigrationItemPoller
+		m.items = append(m.items, item)
+	}
+	for range oldPipeline.Hooks {
+		item.Kind = sdk.ProjectMigrationItemHook
+		m.items = append(m.items, item)
+	}
+
+	for _, child := range oldPipeline.SubPipelines {
+		pm.skipSubPipelineHooks(child, m)
+	}
+}
+
+// insertWorkflow inserts a migrated workflow and registers its hooks
+func (pm *projectMigration) insertWorkflow(w *sdk.Workflow, force bool) error {
+	if force {
+		oldW, err := workflow.Load(pm.db, pm.store, pm.proj.Key, w.Name, pm.u, workflow.LoadOptions{})
+		if err == nil {
+			if err := workflow.Delete(pm.db, pm.store, pm.proj, oldW, pm.u); err != nil {
+				return sdk.WrapError(err, "insertWorkflow")
+			}
+		}
+	}
+
+	if err := workflow.Insert(pm.db, pm.store, w, pm.proj, pm.u); err != nil {
+		return sdk.WrapError(err, "insertWorkflow")
+	}
+	for _, g := range w.Groups {
+		if err := workflow.AddGroup(pm.db, w, g); err != nil {
+			return sdk.WrapError(err, "insertWorkflow> Cannot add group")
+		}
+	}
+
+	defaultPayload, err := workflow.HookRegistration(pm.db, pm.store, nil, *w, pm.proj)
+	if err != nil {
+		return sdk.WrapError(err, "insertWorkflow")
+	}
+	if defaultPayload != nil && w.Root.Context.DefaultPayload == nil {
+		w.Root.Context.DefaultPayload = *defaultPayload
+		if err := workflow.UpdateNodeContext(pm.db, w.Root.Context); err != nil {
+			return sdk.WrapError(err, "insertWorkflow> Cannot update default payload")
+		}
+	}
+	return nil
+}
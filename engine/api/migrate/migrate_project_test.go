@@ -0,0 +1,94 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_triggerCondition(t *testing.T) {
+	tests := []struct {
+		pre       sdk.Prerequisite
+		want      sdk.WorkflowNodeCondition
+		converted bool
+	}{
+		{
+			pre:       sdk.Prerequisite{Parameter: "git.branch", ExpectedValue: "master"},
+			want:      sdk.WorkflowNodeCondition{Variable: "git.branch", Value: "master", Operator: sdk.WorkflowConditionsOperatorEquals},
+			converted: true,
+		},
+		{
+			pre:       sdk.Prerequisite{Parameter: "git.branch", ExpectedValue: "not master"},
+			want:      sdk.WorkflowNodeCondition{Variable: "git.branch", Value: "master", Operator: sdk.WorkflowConditionsOperatorNotEquals},
+			converted: true,
+		},
+		{
+			pre:       sdk.Prerequisite{Parameter: "git.branch", ExpectedValue: "release/.*"},
+			want:      sdk.WorkflowNodeCondition{Variable: "git.branch", Value: "^release/.*$", Operator: sdk.WorkflowConditionsOperatorRegex},
+			converted: true,
+		},
+		{
+			pre:       sdk.Prerequisite{Parameter: "git.branch", ExpectedValue: "not release/.*"},
+			converted: false,
+		},
+	}
+	for _, tt := range tests {
+		got, ok := triggerCondition(tt.pre)
+		assert.Equal(t, tt.converted, ok, tt.pre.ExpectedValue)
+		if ok {
+			assert.Equal(t, tt.want, got, tt.pre.ExpectedValue)
+		}
+	}
+}
+
+func Test_schedulerHook(t *testing.T) {
+	h, err := schedulerHook(sdk.PipelineScheduler{Crontab: "0 2 * * *", Timezone: "Europe/Paris"})
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.SchedulerModelName, h.WorkflowHookModel.Name)
+	assert.Equal(t, "0 2 * * *", h.Config["cron"].Value)
+	assert.Equal(t, "Europe/Paris", h.Config["timezone"].Value)
+	assert.Equal(t, sdk.SchedulerCatchUpLast, h.Config["catch_up"].Value)
+
+	h, err = schedulerHook(sdk.PipelineScheduler{Crontab: "0 2 * * *"})
+	assert.NoError(t, err)
+	assert.Equal(t, "UTC", h.Config["timezone"].Value)
+
+	_, err = schedulerHook(sdk.PipelineScheduler{Crontab: "0 2 * * *", Timezone: "Europe/Nowhere"})
+	assert.Error(t, err)
+
+	// the default config of the model is not modified
+	assert.Equal(t, "0 * * * *", sdk.SchedulerModel.DefaultConfig["cron"].Value)
+}
+
+func Test_sameParameters(t *testing.T) {
+	a := []sdk.Parameter{{Name: "env", Value: "prod"}, {Name: "region", Value: "gra"}}
+	assert.True(t, sameParameters(a, []sdk.Parameter{{Name: "region", Value: "gra"}, {Name: "env", Value: "prod"}}))
+	assert.False(t, sameParameters(a, []sdk.Parameter{{Name: "env", Value: "prod"}}))
+	assert.False(t, sameParameters(a, []sdk.Parameter{{Name: "env", Value: "dev"}, {Name: "region", Value: "gra"}}))
+	assert.True(t, sameParameters(nil, []sdk.Parameter{}))
+}
+
+func Test_migrateSchedulers(t *testing.T) {
+	pm := &projectMigration{}
+	oldW := sdk.CDPipeline{
+		Application: sdk.Application{Name: "app"},
+		Pipeline:    sdk.Pipeline{Name: "build"},
+		Environment: sdk.Environment{ID: 1, Name: "prod"},
+		Schedulers: []sdk.PipelineScheduler{
+			{ID: 1, EnvironmentID: 1, Crontab: "0 2 * * *"},
+			{ID: 2, EnvironmentID: 1, Crontab: "0 3 * * *", Disabled: true},
+			{ID: 3, EnvironmentID: 2, Crontab: "0 4 * * *"},
+		},
+	}
+	n := &sdk.WorkflowNode{Context: &sdk.WorkflowNodeContext{}}
+	m := newWorkflowMigration()
+	pm.migrateSchedulers(oldW, n, m)
+
+	// only the converted scheduler is disabled with the insertion of the workflow
+	assert.Len(t, n.Hooks, 1)
+	if assert.Len(t, m.schedulers, 1) {
+		assert.Equal(t, int64(1), m.schedulers[0].ID)
+	}
+}
@@ -2,17 +2,20 @@ package migrate
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/hook"
 	"github.com/ovh/cds/engine/api/permission"
 	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/poller"
+	"github.com/ovh/cds/engine/api/scheduler"
 	"github.com/ovh/cds/engine/api/workflow"
 	"github.com/ovh/cds/sdk"
-	"github.com/ovh/cds/sdk/log"
 )
 
 const (
@@ -41,10 +44,14 @@ func MigrateToWorkflow(db gorp.SqlExecutor, store cache.Store, cdTree []sdk.CDPi
 
 		currentApplicationID := oldW.Application.ID
 
-		n, err := migratePipeline(db, store, proj, oldW, currentApplicationID, u)
+		m := newWorkflowMigration()
+		n, err := migratePipeline(db, store, proj, oldW, currentApplicationID, u, m)
 		if err != nil {
 			return sdk.WrapError(err, "MigrateToWorkflow")
 		}
+		if err := m.updateSubApplications(db, store, proj, u); err != nil {
+			return sdk.WrapError(err, "MigrateToWorkflow")
+		}
 		newW.Root = n
 
 		if force {
@@ -82,7 +89,91 @@ func addGroupOnWorkflow(db gorp.SqlExecutor, w *sdk.Workflow, app *sdk.Applicati
 	return nil
 }
 
-func migratePipeline(db gorp.SqlExecutor, store cache.Store, p *sdk.Project, oldPipeline sdk.CDPipeline, appID int64, u *sdk.User) (*sdk.WorkflowNode, error) {
+// workflowMigration collects what is found while converting the pipelines of an application to a workflow
+type workflowMigration struct {
+	// subApplications are the other applications of the triggered pipelines
+	subApplications map[int64]sdk.Application
+	items           []sdk.ProjectMigrationItem
+	// schedulers, pollers and hooks are the legacy triggers converted to workflow hooks, they are disabled when
+	// the workflow is inserted so that the pipelines are not started twice
+	schedulers []sdk.PipelineScheduler
+	pollers    []sdk.RepositoryPoller
+	hooks      []sdk.Hook
+}
+
+func newWorkflowMigration() *workflowMigration {
+	return &workflowMigration{subApplications: map[int64]sdk.Application{}}
+}
+
+// updateSubApplications marks the other applications of the triggered pipelines as to be cleaned
+func (m *workflowMigration) updateSubApplications(db gorp.SqlExecutor, store cache.Store, p *sdk.Project, u *sdk.User) error {
+	for _, app := range m.subApplications {
+		app.WorkflowMigration = STATUS_CLEANING
+		app.ProjectID = p.ID
+		if err := application.Update(db, store, &app, u); err != nil {
+			return sdk.WrapError(err, "updateSubApplications> Cannot update subapplication %s", app.Name)
+		}
+	}
+	return nil
+}
+
+// disableLegacyTriggers disables the schedulers, pollers and hooks converted to workflow hooks. They are deleted
+// with their application once the migration is validated
+func (m *workflowMigration) disableLegacyTriggers(db gorp.SqlExecutor) error {
+	for i := range m.schedulers {
+		s := &m.schedulers[i]
+		s.Disabled = true
+		if err := scheduler.Update(db, s); err != nil {
+			return sdk.WrapError(err, "disableLegacyTriggers> Cannot disable scheduler %d", s.ID)
+		}
+	}
+	for i := range m.pollers {
+		p := &m.pollers[i]
+		p.Enabled = false
+		if err := poller.Update(db, p); err != nil {
+			return sdk.WrapError(err, "disableLegacyTriggers> Cannot disable poller %s", p.Name)
+		}
+	}
+	for i := range m.hooks {
+		h := &m.hooks[i]
+		h.Enabled = false
+		if err := hook.UpdateHook(db, *h); err != nil {
+			return sdk.WrapError(err, "disableLegacyTriggers> Cannot disable hook %d", h.ID)
+		}
+	}
+	return nil
+}
+
+// triggerCondition converts the prerequisite of a trigger, which is a regular expression matching the whole value
+// of a parameter, negated by a "not " prefix. It returns false if the prerequisite cannot be converted
+func triggerCondition(pre sdk.Prerequisite) (sdk.WorkflowNodeCondition, bool) {
+	c := sdk.WorkflowNodeCondition{
+		Variable: pre.Parameter,
+		Value:    pre.ExpectedValue,
+		Operator: sdk.WorkflowConditionsOperatorEquals,
+	}
+
+	not := strings.HasPrefix(c.Value, "not ")
+	if not {
+		c.Value = strings.TrimPrefix(c.Value, "not ")
+	}
+	literal := regexp.QuoteMeta(c.Value) == c.Value
+
+	switch {
+	case literal && not:
+		c.Operator = sdk.WorkflowConditionsOperatorNotEquals
+	case literal:
+	case not:
+		// there is no operator for a value not matching a regular expression
+		return c, false
+	default:
+		c.Operator = sdk.WorkflowConditionsOperatorRegex
+		c.Value = "^" + c.Value + "$"
+	}
+	return c, true
+}
+
+func migratePipeline(db gorp.SqlExecutor, store cache.Store, p *sdk.Project, oldPipeline sdk.CDPipeline, appID int64, u *sdk.User, m *workflowMigration) (*sdk.WorkflowNode, error) {
 	newNode := &sdk.WorkflowNode{
 		PipelineID: oldPipeline.Pipeline.ID,
 		Context:    &sdk.WorkflowNodeContext{},
@@ -93,6 +184,7 @@ func migratePipeline(db gorp.SqlExecutor, store cache.Store, p *sdk.Project, old
 	if err != nil {
 		return nil, sdk.WrapError(err, "migratePipeline> Cannot load pipeline")
 	}
+	newNode.Pipeline = *pip
 	foundApp := false
 bigloop:
 	for _, s := range pip.Stages {
@@ -131,29 +223,53 @@ bigloop:
 	// Add trigger
 	if len(oldPipeline.SubPipelines) > 0 {
 		for _, childPip := range oldPipeline.SubPipelines {
+			item := sdk.ProjectMigrationItem{
+				Kind:        sdk.ProjectMigrationItemTrigger,
+				Application: childPip.Application.Name,
+				Pipeline:    childPip.Pipeline.Name,
+				Environment: childPip.Environment.Name,
+				Converted:   true,
+			}
+
+			// Convert the prerequisites first, a trigger without one of them would run the pipeline too often
+			var conditions []sdk.WorkflowNodeCondition
+			for _, c := range childPip.Trigger.Prerequisites {
+				cond, ok := triggerCondition(c)
+				if !ok {
+					item.Converted = false
+					item.Reason = fmt.Sprintf("condition %s=%s cannot be converted, the pipeline is not triggered", c.Parameter, c.ExpectedValue)
+					break
+				}
+				conditions = append(conditions, cond)
+			}
+			if !item.Converted {
+				m.items = append(m.items, item)
+				continue
+			}
+
 			// Create new trigger
 			t := sdk.WorkflowNodeTrigger{}
 
 			// Migrate child pipeline
-			n, err := migratePipeline(db, store, p, childPip, appID, u)
+			n, err := migratePipeline(db, store, p, childPip, appID, u, m)
 			if err != nil {
 				return nil, err
 			}
 
 			// Migrate pipeline parameter
-			log.Warning("%+v", childPip.Trigger.Parameters)
-			n.Context.DefaultPipelineParameters = childPip.Trigger.Parameters
+			n.Context.DefaultPipelineParameters = nil
+			for _, param := range childPip.Trigger.Parameters {
+				if sdk.ParameterFind(&n.Pipeline.Parameter, param.Name) == nil {
+					item.Reason = fmt.Sprintf("parameter %s is not a parameter of the pipeline", param.Name)
+					continue
+				}
+				n.Context.DefaultPipelineParameters = append(n.Context.DefaultPipelineParameters, param)
+			}
 
 			t.WorkflowDestNode = *n
 
 			// Add Condition on trigger
-			for _, c := range childPip.Trigger.Prerequisites {
-				t.WorkflowDestNode.Context.Conditions.PlainConditions = append(t.WorkflowDestNode.Context.Conditions.PlainConditions, sdk.WorkflowNodeCondition{
-					Variable: c.Parameter,
-					Value:    c.ExpectedValue,
-					Operator: "eq",
-				})
-			}
+			t.WorkflowDestNode.Context.Conditions.PlainConditions = append(t.WorkflowDestNode.Context.Conditions.PlainConditions, conditions...)
 			t.WorkflowDestNode.Context.Conditions.PlainConditions = append(t.WorkflowDestNode.Context.Conditions.PlainConditions, sdk.WorkflowNodeCondition{
 				Variable: "cds.status",
 				Value:    "Success",
@@ -166,14 +282,11 @@ bigloop:
 					Operator: "eq",
 				})
 			}
+			m.items = append(m.items, item)
 
 			// is sub App
 			if childPip.Application.ID != 0 && childPip.Application.ID != appID {
-				childPip.Application.WorkflowMigration = STATUS_CLEANING
-				childPip.Application.ProjectID = p.ID
-				if errA := application.Update(db, store, &childPip.Application, u); errA != nil {
-					return nil, sdk.WrapError(errA, "Cannot update subapplication %s", childPip.Application.Name)
-				}
+				m.subApplications[childPip.Application.ID] = childPip.Application
 			}
 
 			// Add trigger
@@ -111,3 +111,93 @@ func Test_MigrateToWorkflow(t *testing.T) {
 	assert.Equal(t, 3, len(wf.Root.Triggers[0].WorkflowDestNode.Context.Conditions.PlainConditions))
 
 }
+
+func Test_migratePipelineWithUnconvertedTrigger(t *testing.T) {
+	db, cache := test.SetupPG(t)
+	u, _ := assets.InsertAdminUser(db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key, u)
+
+	app1 := &sdk.Application{
+		Name: sdk.RandomString(10),
+	}
+	app2 := &sdk.Application{
+		Name: sdk.RandomString(10),
+	}
+	test.NoError(t, application.Insert(db, cache, proj, app1, u))
+	test.NoError(t, application.Insert(db, cache, proj, app2, u))
+
+	pip1 := &sdk.Pipeline{
+		Name:      sdk.RandomString(10),
+		Type:      "build",
+		ProjectID: proj.ID,
+	}
+	pip2 := &sdk.Pipeline{
+		Name:      sdk.RandomString(10),
+		Type:      "deployment",
+		ProjectID: proj.ID,
+	}
+	pip3 := &sdk.Pipeline{
+		Name:      sdk.RandomString(10),
+		Type:      "deployment",
+		ProjectID: proj.ID,
+	}
+	test.NoError(t, pipeline.InsertPipeline(db, cache, proj, pip1, u))
+	test.NoError(t, pipeline.InsertPipeline(db, cache, proj, pip2, u))
+	test.NoError(t, pipeline.InsertPipeline(db, cache, proj, pip3, u))
+
+	oldW := sdk.CDPipeline{
+		Application: *app1,
+		Pipeline:    *pip1,
+		SubPipelines: []sdk.CDPipeline{
+			{
+				Application: *app2,
+				Pipeline:    *pip2,
+				Trigger: sdk.PipelineTrigger{
+					Prerequisites: []sdk.Prerequisite{
+						{
+							Parameter:     "git.branch",
+							ExpectedValue: "master",
+						},
+						{
+							Parameter:     "git.author",
+							ExpectedValue: "not bot-.*",
+						},
+					},
+				},
+			},
+			{
+				Application: *app1,
+				Pipeline:    *pip3,
+				Trigger: sdk.PipelineTrigger{
+					Prerequisites: []sdk.Prerequisite{
+						{
+							Parameter:     "git.branch",
+							ExpectedValue: "release/.*",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	m := newWorkflowMigration()
+	n, err := migratePipeline(db, cache, proj, oldW, app1.ID, u, m)
+	test.NoError(t, err)
+
+	// the trigger with a negated regular expression is not migrated, it would run the pipeline on any author
+	assert.Equal(t, 1, len(n.Triggers))
+	assert.Equal(t, pip3.ID, n.Triggers[0].WorkflowDestNode.Pipeline.ID)
+	assert.Equal(t, []sdk.WorkflowNodeCondition{
+		{Variable: "git.branch", Value: "^release/.*$", Operator: sdk.WorkflowConditionsOperatorRegex},
+		{Variable: "cds.status", Value: "Success", Operator: "eq"},
+	}, n.Triggers[0].WorkflowDestNode.Context.Conditions.PlainConditions)
+	assert.Equal(t, 0, len(m.subApplications))
+
+	assert.Equal(t, 2, len(m.items))
+	assert.Equal(t, pip2.Name, m.items[0].Pipeline)
+	assert.False(t, m.items[0].Converted)
+	assert.Equal(t, "condition git.author=not bot-.* cannot be converted, the pipeline is not triggered", m.items[0].Reason)
+	assert.Equal(t, pip3.Name, m.items[1].Pipeline)
+	assert.True(t, m.items[1].Converted)
+}
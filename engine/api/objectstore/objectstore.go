@@ -94,6 +94,30 @@ func DeletePlugin(art sdk.ActionPlugin) error {
 	return fmt.Errorf("store not initialized")
 }
 
+//StorePluginBinary call Store on the common driver
+func StorePluginBinary(b sdk.PluginBinary, data io.ReadCloser) (string, error) {
+	if storage != nil {
+		return storage.Store(&b, data)
+	}
+	return "", fmt.Errorf("store not initialized")
+}
+
+//FetchPluginBinary call Fetch on the common driver
+func FetchPluginBinary(b sdk.PluginBinary) (io.ReadCloser, error) {
+	if storage != nil {
+		return storage.Fetch(&b)
+	}
+	return nil, fmt.Errorf("store not initialized")
+}
+
+//DeletePluginBinary call Delete on the common driver
+func DeletePluginBinary(b sdk.PluginBinary) error {
+	if storage != nil {
+		return storage.Delete(&b)
+	}
+	return fmt.Errorf("store not initialized")
+}
+
 // Driver allows artifact to be stored and retrieve the same way to any backend
 // - Openstack / Swift
 // - Filesystem
@@ -34,7 +34,10 @@ func permissionFunc(api *API) map[string]PermCheckFunc {
 	}
 }
 
-func getPermissionByMethod(method string, isExecution bool) int {
+func getPermissionByMethod(method string, isExecution, isRead bool) int {
+	if isRead {
+		return permission.PermissionRead
+	}
 	switch method {
 	case "POST":
 		if isExecution {
@@ -170,7 +173,7 @@ func (api *API) authMiddleware(ctx context.Context, w http.ResponseWriter, req *
 	}
 
 	if rc.Options["needAdmin"] != "true" {
-		permissionOk := api.checkPermission(ctx, mux.Vars(req), getPermissionByMethod(req.Method, rc.Options["isExecution"] == "true"))
+		permissionOk := api.checkPermission(ctx, mux.Vars(req), getPermissionByMethod(req.Method, rc.Options["isExecution"] == "true", rc.Options["isRead"] == "true"))
 		if !permissionOk {
 			return ctx, sdk.WrapError(sdk.ErrForbidden, "Router> User not authorized")
 		}
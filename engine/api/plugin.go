@@ -7,10 +7,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
-	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/go-gorp/gorp"
 	"github.com/gorilla/mux"
 
 	"github.com/ovh/cds/engine/api/action"
@@ -21,13 +22,15 @@ import (
 	"github.com/ovh/cds/sdk/plugin"
 )
 
-func fileUploadAndGetPlugin(w http.ResponseWriter, r *http.Request) (*sdk.ActionPlugin, *plugin.Parameters, io.ReadCloser, func(), error) {
+//fileUpload writes the uploaded file in a temporary directory. The returned func removes the directory
+func fileUpload(r *http.Request) (string, string, func(), error) {
 	r.ParseMultipartForm(64 << 20)
 	file, handler, err := r.FormFile("UploadFile")
 	if err != nil {
-		log.Debug("fileUploadAndGetPlugin> %v", r.Header)
-		return nil, nil, nil, nil, sdk.WrapError(err, "fileUploadAndGetPlugin> err on formFile")
+		log.Debug("fileUpload> %v", r.Header)
+		return "", "", nil, sdk.WrapError(err, "fileUpload> err on formFile")
 	}
+	defer file.Close()
 
 	filename := handler.Filename
 	t := strings.Split(handler.Filename, "/")
@@ -35,53 +38,124 @@ func fileUploadAndGetPlugin(w http.ResponseWriter, r *http.Request) (*sdk.Action
 		filename = t[len(t)-1]
 	}
 
-	log.Debug("fileUploadAndGetPlugin> file upload detected : %s", filename)
-	defer file.Close()
+	log.Debug("fileUpload> file upload detected : %s", filename)
 
 	tmp, err := ioutil.TempDir("", "cds-plugin")
 	if err != nil {
-		return nil, nil, nil, nil, sdk.WrapError(err, "fileUploadAndGetPlugin> err on temp dir.")
+		return "", "", nil, sdk.WrapError(err, "fileUpload> err on temp dir.")
 	}
 	deferFunc := func() {
-		log.Debug("fileUploadAndGetPlugin> deleting file %s", tmp)
+		log.Debug("fileUpload> deleting file %s", tmp)
 		os.RemoveAll(tmp)
 	}
 
-	log.Debug("fileUploadAndGetPlugin> creating temporary directory")
 	tmpfn := filepath.Join(tmp, filename)
 	f, err := os.OpenFile(tmpfn, os.O_WRONLY|os.O_CREATE, 0700)
 	if err != nil {
-		return nil, nil, nil, deferFunc, sdk.WrapError(err, "fileUploadAndGetPlugin> err on openFile")
+		return "", "", deferFunc, sdk.WrapError(err, "fileUpload> err on openFile")
 	}
 
-	log.Debug("fileUploadAndGetPlugin> writing file %s", tmpfn)
-	io.Copy(f, file)
+	log.Debug("fileUpload> writing file %s", tmpfn)
+	_, err = io.Copy(f, file)
 	f.Close()
+	if err != nil {
+		return "", "", deferFunc, sdk.WrapError(err, "fileUpload> err on write")
+	}
 
-	content, err := os.Open(tmpfn)
+	return filename, tmpfn, deferFunc, nil
+}
+
+//fileUploadAndGetPlugin writes the uploaded plugin in a temporary file, given as ap.Path, and gets its information
+func fileUploadAndGetPlugin(w http.ResponseWriter, r *http.Request) (*sdk.ActionPlugin, *plugin.Parameters, func(), error) {
+	filename, tmpfn, deferFunc, err := fileUpload(r)
 	if err != nil {
-		return nil, nil, nil, deferFunc, sdk.WrapError(err, "fileUploadAndGetPlugin> err on Open")
+		return nil, nil, deferFunc, err
 	}
 
 	ap, params, err := actionplugin.Get(filename, tmpfn)
 	if err != nil {
-		return nil, nil, nil, deferFunc, sdk.WrapError(sdk.ErrPluginInvalid, "fileUploadAndGetPlugin> unable to get plugin info: %s", err)
+		return nil, nil, deferFunc, sdk.WrapError(sdk.ErrPluginInvalid, "fileUploadAndGetPlugin> unable to get plugin info: %s", err)
+	}
+	ap.Path = tmpfn
+
+	return ap, params, deferFunc, nil
+}
+
+//storePluginBinary uploads the binary to the objectstore and inserts it in database. The binary is stored under a new
+//name, the replaced binary is returned to be deleted from the objectstore once the transaction is committed
+func storePluginBinary(db gorp.SqlExecutor, name, version, goos, goarch, path string) (*sdk.PluginBinary, *sdk.PluginBinary, error) {
+	b, err := actionplugin.NewBinary(name, version, goos, goarch, path)
+	if err != nil {
+		return nil, nil, sdk.WrapError(err, "storePluginBinary> Unable to read binary %s", path)
+	}
+
+	// the same binary is already stored under the same name
+	current, err := actionplugin.LoadBinary(db, name, version, goos, goarch)
+	if err != nil && err != sdk.ErrPluginNotFound {
+		return nil, nil, sdk.WrapError(err, "storePluginBinary> Unable to load binary %s", b.GetName())
+	}
+	stored := current != nil && current.GetName() == b.GetName()
+
+	if stored {
+		b.ObjectPath = current.ObjectPath
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, sdk.WrapError(err, "storePluginBinary> Unable to open binary %s", path)
+		}
+		defer f.Close()
+
+		objectPath, err := objectstore.StorePluginBinary(*b, f)
+		if err != nil {
+			return nil, nil, sdk.WrapError(err, "storePluginBinary> Error while uploading to object store %s", b.GetName())
+		}
+		b.ObjectPath = objectPath
+	}
+
+	old, err := actionplugin.InsertBinary(db, b)
+	if err != nil {
+		if !stored {
+			objectstore.DeletePluginBinary(*b)
+		}
+		return nil, nil, sdk.WrapError(err, "storePluginBinary> Unable to insert binary %s", b.GetName())
 	}
+	if stored {
+		old = nil
+	}
+	return b, old, nil
+}
+
+//deletePluginBinary deletes from the objectstore a binary replaced by a committed transaction
+func deletePluginBinary(old *sdk.PluginBinary) {
+	if old == nil {
+		return
+	}
+	if err := objectstore.DeletePluginBinary(*old); err != nil {
+		log.Warning("deletePluginBinary> Unable to delete previous binary %s: %v", old.GetName(), err)
+	}
+}
 
-	return ap, params, content, deferFunc, nil
+//pluginVersion returns the version given in the form, or the version of the manifest
+func pluginVersion(r *http.Request, ap *sdk.ActionPlugin) string {
+	if v := r.FormValue("version"); v != "" {
+		return v
+	}
+	if ap.Version != "" {
+		return ap.Version
+	}
+	return sdk.DefaultPluginVersion
 }
 
 func (api *API) addPluginHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		//Upload file and get plugin information
-		ap, params, file, deferFunc, err := fileUploadAndGetPlugin(w, r)
+		ap, params, deferFunc, err := fileUploadAndGetPlugin(w, r)
 		if deferFunc != nil {
 			defer deferFunc()
 		}
 		if err != nil {
 			return sdk.WrapError(err, "addPluginHandler>%T", err)
 		}
-		defer file.Close()
 
 		// Check that action does not already exists
 		conflict, err := action.Exists(api.mustDB(), ap.Name)
@@ -92,13 +166,6 @@ func (api *API) addPluginHandler() Handler {
 			return sdk.ErrConflict
 		}
 
-		//Upload it to objectstore
-		objectPath, err := objectstore.StorePlugin(*ap, file)
-		if err != nil {
-			return sdk.WrapError(err, "addPluginHandler> Error while uploading to object store %s", ap.Name)
-		}
-		ap.ObjectPath = objectPath
-
 		tx, err := api.mustDB().Begin()
 		if err != nil {
 			return sdk.WrapError(err, "addPluginHandler> Cannot start transaction")
@@ -108,13 +175,19 @@ func (api *API) addPluginHandler() Handler {
 		//Insert in database
 		a, err := actionplugin.Insert(tx, ap, params)
 		if err != nil {
-			objectstore.DeletePlugin(*ap)
 			return sdk.WrapError(err, "addPluginHandler> Error while inserting action %s in database", ap.Name)
 		}
 
+		//The binary has been run by the API, so it is built for its OS and architecture
+		_, old, err := storePluginBinary(tx, ap.Name, pluginVersion(r, ap), runtime.GOOS, runtime.GOARCH, ap.Path)
+		if err != nil {
+			return sdk.WrapError(err, "addPluginHandler> Cannot store binary")
+		}
+
 		if err := tx.Commit(); err != nil {
 			return sdk.WrapError(err, "addPluginHandler> Cannot commit transaction")
 		}
+		deletePluginBinary(old)
 
 		return WriteJSON(w, r, a, http.StatusCreated)
 	}
@@ -123,7 +196,7 @@ func (api *API) addPluginHandler() Handler {
 func (api *API) updatePluginHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		//Upload file and get plugin information
-		ap, params, file, deferFunc, errUpload := fileUploadAndGetPlugin(w, r)
+		ap, params, deferFunc, errUpload := fileUploadAndGetPlugin(w, r)
 		if deferFunc != nil {
 			defer deferFunc()
 		}
@@ -140,53 +213,6 @@ func (api *API) updatePluginHandler() Handler {
 			return sdk.WrapError(sdk.ErrNoAction, "updatePluginHandler")
 		}
 
-		//Store previous file from objectstore
-		buf, errFetch := objectstore.FetchPlugin(*ap)
-		if errFetch != nil {
-			log.Warning("updatePluginHandler>Unable to fetch plugin: %s", errFetch)
-			// do no raise error... it just mean that we cannot fetch the old version of the plugin
-		}
-
-		var tmpFile string
-		if buf != nil && errFetch == nil {
-			defer buf.Close()
-			//Read it
-			btes, errr := ioutil.ReadAll(buf)
-			if errr != nil {
-				return sdk.WrapError(errr, "updatePluginHandler> Unable to read old plugin buffer")
-			}
-			//Get a dir
-			tmpDir, errtmp := ioutil.TempDir("", "old-plugin")
-			if errtmp != nil {
-				return sdk.WrapError(errtmp, "updatePluginHandler> error with tempdir")
-			}
-			os.MkdirAll(tmpDir, os.FileMode(0700))
-
-			//Get a temp file
-			tmpFile = path.Join(tmpDir, ap.Name)
-
-			//Write it
-			log.Debug("updatePluginHandler>store oldfile %s in case of error", tmpFile)
-			if err := ioutil.WriteFile(tmpFile, btes, os.FileMode(0600)); err != nil {
-				return sdk.WrapError(err, "updatePluginHandler>Error writing file %s", tmpFile)
-			}
-			defer func() {
-				log.Debug("updatePluginHandler> deleting file %s", tmpFile)
-				os.RemoveAll(tmpFile)
-			}()
-			//Delete previous file from objectstore
-			if err := objectstore.DeletePlugin(*ap); err != nil {
-				return sdk.WrapError(err, "updatePluginHandler>Error deleting file")
-			}
-		}
-
-		//Upload it to objectstore
-		objectPath, errStore := objectstore.StorePlugin(*ap, file)
-		if errStore != nil {
-			return sdk.WrapError(errStore, "updatePluginHandler> Error while uploading to object store %s", ap.Name)
-		}
-		ap.ObjectPath = objectPath
-
 		tx, errBegin := api.mustDB().Begin()
 		if errBegin != nil {
 			return sdk.WrapError(errBegin, "updatePluginHandler> Cannot start transaction")
@@ -195,28 +221,131 @@ func (api *API) updatePluginHandler() Handler {
 
 		//Update in database
 		a, errDB := actionplugin.Update(tx, ap, params, getUser(ctx).ID)
-		if errDB != nil && tmpFile != "" {
-			log.Warning("updatePluginHandler> Error while updating action %s in database: %s\n", ap.Name, errDB)
-			//Restore previous file
-			oldFile, errO := os.Open(tmpFile)
-			if errO != nil {
-				return sdk.WrapError(errO, "updatePluginHandler>Error opening file %s ", tmpFile)
-			}
-			//re-store the old plugin file
-			if _, errStore := objectstore.StorePlugin(*ap, oldFile); errStore != nil {
-				return sdk.WrapError(errStore, "updatePluginHandler> Error while uploading to object store %s", ap.Name)
-			}
+		if errDB != nil {
+			return sdk.WrapError(errDB, "updatePluginHandler> Unable to update plugin %s", ap.Name)
+		}
 
-			return sdk.WrapError(errDB, "updatePluginHandler> Unable to update plugin", ap.Name)
+		//The previous versions are kept, the binary of the same version is replaced
+		_, old, err := storePluginBinary(tx, ap.Name, pluginVersion(r, ap), runtime.GOOS, runtime.GOARCH, ap.Path)
+		if err != nil {
+			return sdk.WrapError(err, "updatePluginHandler> Cannot store binary")
 		}
+
 		if err := tx.Commit(); err != nil {
 			return sdk.WrapError(err, "updatePluginHandler> Cannot commit transaction")
 		}
+		deletePluginBinary(old)
 
 		return WriteJSON(w, r, a, http.StatusOK)
 	}
 }
 
+func (api *API) addPluginBinaryHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		name := vars["name"]
+
+		_, path, deferFunc, err := fileUpload(r)
+		if deferFunc != nil {
+			defer deferFunc()
+		}
+		if err != nil {
+			return sdk.WrapError(err, "addPluginBinaryHandler> Unable to upload binary")
+		}
+
+		version := r.FormValue("version")
+		goos := r.FormValue("os")
+		goarch := r.FormValue("arch")
+		if version == "" || !isValidOSArch(goos, goarch) {
+			return sdk.WrapError(sdk.ErrWrongRequest, "addPluginBinaryHandler> Invalid version %s or platform %s/%s", version, goos, goarch)
+		}
+
+		exists, err := action.Exists(api.mustDB(), name)
+		if err != nil {
+			return sdk.WrapError(err, "addPluginBinaryHandler> unable to check if action %s exists", name)
+		}
+		if !exists {
+			return sdk.WrapError(sdk.ErrNoAction, "addPluginBinaryHandler")
+		}
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WrapError(err, "addPluginBinaryHandler> Cannot start transaction")
+		}
+		defer tx.Rollback()
+
+		b, old, err := storePluginBinary(tx, name, version, goos, goarch, path)
+		if err != nil {
+			return sdk.WrapError(err, "addPluginBinaryHandler> Cannot store binary")
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WrapError(err, "addPluginBinaryHandler> Cannot commit transaction")
+		}
+		deletePluginBinary(old)
+
+		return WriteJSON(w, r, b, http.StatusCreated)
+	}
+}
+
+func isValidOSArch(goos, goarch string) bool {
+	for _, v := range sdk.OSArchRequirementValues.Values() {
+		if v == goos+"/"+goarch {
+			return true
+		}
+	}
+	return false
+}
+
+func (api *API) getPluginBinariesHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		name := vars["name"]
+
+		bs, err := actionplugin.LoadBinaries(api.mustDB(), name)
+		if err != nil {
+			return sdk.WrapError(err, "getPluginBinariesHandler> Cannot load binaries of %s", name)
+		}
+		return WriteJSON(w, r, bs, http.StatusOK)
+	}
+}
+
+func (api *API) getPluginBinaryHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		name := vars["name"]
+
+		b, err := actionplugin.LoadBinary(api.mustDB(), name, r.FormValue("version"), vars["os"], vars["arch"])
+		if err != nil {
+			return sdk.WrapError(err, "getPluginBinaryHandler> Cannot load binary of %s for %s/%s", name, vars["os"], vars["arch"])
+		}
+		return WriteJSON(w, r, b, http.StatusOK)
+	}
+}
+
+func (api *API) deletePluginBinariesHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		name := vars["name"]
+		version := vars["version"]
+
+		bs, err := actionplugin.DeleteBinaries(api.mustDB(), name, version)
+		if err != nil {
+			return sdk.WrapError(err, "deletePluginBinariesHandler> Cannot delete binaries %s@%s", name, version)
+		}
+		if len(bs) == 0 {
+			return sdk.WrapError(sdk.ErrPluginNotFound, "deletePluginBinariesHandler> No binary %s@%s", name, version)
+		}
+
+		for _, b := range bs {
+			if err := objectstore.DeletePluginBinary(b); err != nil {
+				log.Warning("deletePluginBinariesHandler> Unable to delete binary %s from objectstore: %v", b.GetName(), err)
+			}
+		}
+		return nil
+	}
+}
+
 func (api *API) deletePluginHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -227,13 +356,19 @@ func (api *API) deletePluginHandler() Handler {
 		}
 
 		//Delete in database
-		if err := actionplugin.Delete(api.mustDB(), name, getUser(ctx).ID); err != nil {
+		bs, err := actionplugin.Delete(api.mustDB(), name, getUser(ctx).ID)
+		if err != nil {
 			return sdk.WrapError(err, "deletePluginHandler> Error while deleting action %s in database", name)
 		}
 
 		//Delete from objectstore
+		for _, b := range bs {
+			if err := objectstore.DeletePluginBinary(b); err != nil {
+				log.Warning("deletePluginHandler> Unable to delete binary %s from objectstore: %v", b.GetName(), err)
+			}
+		}
 		if err := objectstore.DeletePlugin(sdk.ActionPlugin{Name: name}); err != nil {
-			return sdk.WrapError(err, "deletePluginHandler> Error while deleting action %s in objectstore", name)
+			log.Debug("deletePluginHandler> No plugin %s in objectstore: %v", name, err)
 		}
 		return nil
 	}
@@ -247,11 +382,35 @@ func (api *API) downloadPluginHandler() Handler {
 		if name == "" {
 			return sdk.ErrWrongRequest
 		}
-		p := sdk.ActionPlugin{Name: name}
+		goos := r.FormValue("os")
+		if goos == "" {
+			goos = runtime.GOOS
+		}
+		goarch := r.FormValue("arch")
+		if goarch == "" {
+			goarch = runtime.GOARCH
+		}
+		version := r.FormValue("version")
+
+		var object objectstore.Object
+		var fetch func() (io.ReadCloser, error)
+		b, err := actionplugin.LoadBinary(api.mustDB(), name, version, goos, goarch)
+		switch {
+		case err == nil:
+			object = b
+			fetch = func() (io.ReadCloser, error) { return objectstore.FetchPluginBinary(*b) }
+		case err == sdk.ErrPluginNotFound && version == "":
+			// plugins uploaded before the binaries registry
+			p := sdk.ActionPlugin{Name: name}
+			object = &p
+			fetch = func() (io.ReadCloser, error) { return objectstore.FetchPlugin(p) }
+		default:
+			return sdk.WrapError(err, "downloadPluginHandler> Cannot load binary of %s for %s/%s", name, goos, goarch)
+		}
 
 		acceptRedirect := FormBool(r, "accept-redirect")
 		if acceptRedirect {
-			url, err := objectstore.FetchTempURL(&p)
+			url, err := objectstore.FetchTempURL(object)
 			if url != "" {
 				http.Redirect(w, r, url, http.StatusTemporaryRedirect)
 				return nil
@@ -261,9 +420,9 @@ func (api *API) downloadPluginHandler() Handler {
 			}
 		}
 
-		f, err := objectstore.FetchPlugin(p)
+		f, err := fetch()
 		if err != nil {
-			return sdk.WrapError(err, "downloadPluginHandler> Error while fetching plugin", name)
+			return sdk.WrapError(err, "downloadPluginHandler> Error while fetching plugin %s", name)
 		}
 
 		w.Header().Add("Content-Type", "application/octet-stream")
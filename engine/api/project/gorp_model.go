@@ -20,9 +20,9 @@ type dbProjectVariableAudit sdk.ProjectVariableAudit
 type dbProjectKey sdk.ProjectKey
 
 func init() {
-	gorpmapping.Register(gorpmapping.New(dbProject{}, "project", true, "id"))
-	gorpmapping.Register(gorpmapping.New(dbProjectVariableAudit{}, "project_variable_audit", true, "id"))
-	gorpmapping.Register(gorpmapping.New(dbProjectKey{}, "project_key", false))
+	gorpmapping.Register(gorpmapping.New(dbProject{}, "project", true, "id").Encrypted("vcs_servers"))
+	gorpmapping.Register(gorpmapping.New(dbProjectVariableAudit{}, "project_variable_audit", true, "id").Encrypted("variable_before.value", "variable_after.value"))
+	gorpmapping.Register(gorpmapping.New(dbProjectKey{}, "project_key", false).Encrypted("private"))
 }
 
 // PostGet is a db hook
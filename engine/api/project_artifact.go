@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getProjectArtifactSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "getProjectArtifactSettingsHandler> Cannot load project %s", key)
+		}
+
+		s, err := workflow.LoadProjectArtifactSettings(api.mustDB(), p.ID)
+		if err != nil {
+			return sdk.WrapError(err, "getProjectArtifactSettingsHandler> Cannot load artifact settings of project %s", key)
+		}
+		return WriteJSON(w, r, s, http.StatusOK)
+	}
+}
+
+func (api *API) putProjectArtifactSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "putProjectArtifactSettingsHandler> Cannot load project %s", key)
+		}
+
+		var s sdk.ProjectArtifactSettings
+		if err := UnmarshalBody(r, &s); err != nil {
+			return sdk.WrapError(err, "putProjectArtifactSettingsHandler> Cannot unmarshal artifact settings")
+		}
+		if err := s.IsValid(); err != nil {
+			return sdk.WrapError(sdk.ErrWrongRequest, "putProjectArtifactSettingsHandler> %v", err)
+		}
+		s.ProjectID = p.ID
+
+		if err := workflow.UpsertProjectArtifactSettings(api.mustDB(), s); err != nil {
+			return sdk.WrapError(err, "putProjectArtifactSettingsHandler> Cannot save artifact settings of project %s", key)
+		}
+		return WriteJSON(w, r, s, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectArtifactSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "deleteProjectArtifactSettingsHandler> Cannot load project %s", key)
+		}
+
+		if err := workflow.DeleteProjectArtifactSettings(api.mustDB(), p.ID); err != nil {
+			return sdk.WrapError(err, "deleteProjectArtifactSettingsHandler> Cannot delete artifact settings of project %s", key)
+		}
+		return WriteJSON(w, r, nil, http.StatusOK)
+	}
+}
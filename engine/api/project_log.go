@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getProjectLogSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "getProjectLogSettingsHandler> Cannot load project %s", key)
+		}
+
+		s, err := workflow.LoadProjectLogSettings(api.mustDB(), p.ID)
+		if err != nil {
+			return sdk.WrapError(err, "getProjectLogSettingsHandler> Cannot load log settings of project %s", key)
+		}
+		return WriteJSON(w, r, s, http.StatusOK)
+	}
+}
+
+func (api *API) putProjectLogSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "putProjectLogSettingsHandler> Cannot load project %s", key)
+		}
+
+		var s sdk.ProjectLogSettings
+		if err := UnmarshalBody(r, &s); err != nil {
+			return sdk.WrapError(err, "putProjectLogSettingsHandler> Cannot unmarshal log settings")
+		}
+		if err := s.IsValid(); err != nil {
+			return sdk.WrapError(sdk.ErrWrongRequest, "putProjectLogSettingsHandler> %v", err)
+		}
+		s.ProjectID = p.ID
+
+		if err := workflow.UpsertProjectLogSettings(api.mustDB(), s); err != nil {
+			return sdk.WrapError(err, "putProjectLogSettingsHandler> Cannot save log settings of project %s", key)
+		}
+		return WriteJSON(w, r, s, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectLogSettingsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "deleteProjectLogSettingsHandler> Cannot load project %s", key)
+		}
+
+		if err := workflow.DeleteProjectLogSettings(api.mustDB(), p.ID); err != nil {
+			return sdk.WrapError(err, "deleteProjectLogSettingsHandler> Cannot delete log settings of project %s", key)
+		}
+		return WriteJSON(w, r, nil, http.StatusOK)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/migrate"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) postProjectMigrationHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+		dryRun := r.FormValue("dryRun") == "true"
+		force := r.FormValue("force") == "true"
+
+		p, errP := project.Load(api.mustDB(), api.Cache, key, getUser(ctx), project.LoadOptions.WithPipelines, project.LoadOptions.WithApplications, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups, project.LoadOptions.WithPermission)
+		if errP != nil {
+			return sdk.WrapError(errP, "postProjectMigrationHandler> Cannot load project %s", key)
+		}
+
+		tx, errT := api.mustDB().Begin()
+		if errT != nil {
+			return sdk.WrapError(errT, "postProjectMigrationHandler> Cannot start transaction")
+		}
+		defer tx.Rollback()
+
+		report, errM := migrate.MigrateProject(tx, api.Cache, p, getUser(ctx), dryRun, force)
+		if errM != nil {
+			return sdk.WrapError(errM, "postProjectMigrationHandler> Cannot migrate project %s", key)
+		}
+		if dryRun {
+			return WriteJSON(w, r, report, http.StatusOK)
+		}
+
+		p.WorkflowMigration = migrate.STATUS_START
+		if err := project.Update(tx, api.Cache, p, getUser(ctx)); err != nil {
+			return sdk.WrapError(err, "postProjectMigrationHandler")
+		}
+
+		if err := project.UpdateLastModified(tx, api.Cache, getUser(ctx), p, sdk.ProjectLastModificationType); err != nil {
+			return sdk.WrapError(err, "postProjectMigrationHandler")
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WrapError(err, "postProjectMigrationHandler> Cannot commit transaction")
+		}
+		return WriteJSON(w, r, report, http.StatusOK)
+	}
+}
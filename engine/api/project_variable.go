@@ -278,6 +278,9 @@ func (api *API) updateVariableInProjectHandler() Handler {
 			return sdk.ErrWrongRequest
 
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx), project.LoadOptions.Default)
 		if err != nil {
@@ -348,6 +351,9 @@ func (api *API) addVariableInProjectHandler() Handler {
 			return sdk.ErrWrongRequest
 
 		}
+		if err := newVar.IsValid(); err != nil {
+			return err
+		}
 
 		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx), project.LoadOptions.Default)
 		if err != nil {
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/secret"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getProjectVaultHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "getProjectVaultHandler> Cannot load project %s", key)
+		}
+
+		v, err := secret.LoadProjectVault(api.mustDB(), p.ID, false)
+		if err != nil {
+			return sdk.WrapError(err, "getProjectVaultHandler> Cannot load vault of project %s", key)
+		}
+		return WriteJSON(w, r, v, http.StatusOK)
+	}
+}
+
+func (api *API) putProjectVaultHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+		if err := checkProjectVaultWritePermission(ctx, key); err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot update vault of project %s", key)
+		}
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot load project %s", key)
+		}
+
+		var v sdk.ProjectVault
+		if err := UnmarshalBody(r, &v); err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot unmarshal vault")
+		}
+		if err := v.IsValid(); err != nil {
+			return sdk.WrapError(sdk.ErrWrongRequest, "putProjectVaultHandler> %v", err)
+		}
+		v.ProjectID = p.ID
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot start transaction")
+		}
+		defer tx.Rollback()
+
+		if err := secret.UpsertProjectVault(tx, &v); err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot save vault of project %s", key)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WrapError(err, "putProjectVaultHandler> Cannot commit transaction")
+		}
+
+		v.SetCredential(sdk.PasswordPlaceholder)
+		return WriteJSON(w, r, v, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectVaultHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+		if err := checkProjectVaultWritePermission(ctx, key); err != nil {
+			return sdk.WrapError(err, "deleteProjectVaultHandler> Cannot update vault of project %s", key)
+		}
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "deleteProjectVaultHandler> Cannot load project %s", key)
+		}
+
+		if err := secret.DeleteProjectVault(api.mustDB(), p.ID); err != nil {
+			return sdk.WrapError(err, "deleteProjectVaultHandler> Cannot delete vault of project %s", key)
+		}
+		return WriteJSON(w, r, nil, http.StatusOK)
+	}
+}
+
+// checkProjectVaultWritePermission allows only the CDS administrators and the users with the write permission on the
+// project to change its vault configuration, the workers and the hatcheries of the project groups are never allowed
+func checkProjectVaultWritePermission(ctx context.Context, key string) error {
+	if getWorker(ctx) != nil || getHatchery(ctx) != nil {
+		return sdk.ErrForbidden
+	}
+	u := getUser(ctx)
+	if u == nil {
+		return sdk.ErrForbidden
+	}
+	if !u.Admin && permission.ProjectPermission(key, u) < permission.PermissionReadWriteExecute {
+		return sdk.ErrForbidden
+	}
+	return nil
+}
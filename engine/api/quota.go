@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/quota"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getQuotasHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		usages, err := quota.LoadUsages(api.mustDB())
+		if err != nil {
+			return sdk.WrapError(err, "getQuotasHandler> Cannot load quotas")
+		}
+		return WriteJSON(w, r, usages, http.StatusOK)
+	}
+}
+
+func (api *API) getProjectQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "getProjectQuotaHandler> Cannot load project %s", key)
+		}
+
+		usages, err := quota.LoadProjectUsages(api.mustDB(), p.ID)
+		if err != nil {
+			return sdk.WrapError(err, "getProjectQuotaHandler> Cannot load quotas of project %s", key)
+		}
+		return WriteJSON(w, r, usages, http.StatusOK)
+	}
+}
+
+func (api *API) putProjectQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "putProjectQuotaHandler> Cannot load project %s", key)
+		}
+
+		var q sdk.Quota
+		if err := UnmarshalBody(r, &q); err != nil {
+			return sdk.WrapError(err, "putProjectQuotaHandler> Cannot unmarshal quota")
+		}
+		if err := q.IsValid(); err != nil {
+			return sdk.WrapError(sdk.ErrWrongRequest, "putProjectQuotaHandler> %v", err)
+		}
+		q.ProjectID, q.ProjectKey = p.ID, p.Key
+		q.GroupID, q.GroupName = 0, ""
+
+		if err := quota.Upsert(api.mustDB(), &q); err != nil {
+			return sdk.WrapError(err, "putProjectQuotaHandler> Cannot save quota of project %s", key)
+		}
+		return WriteJSON(w, r, q, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if err != nil {
+			return sdk.WrapError(err, "deleteProjectQuotaHandler> Cannot load project %s", key)
+		}
+
+		q, err := quota.LoadByProjectID(api.mustDB(), p.ID)
+		if err != nil {
+			return sdk.WrapError(err, "deleteProjectQuotaHandler> Cannot load quota of project %s", key)
+		}
+		if err := quota.Delete(api.mustDB(), q.ID); err != nil {
+			return sdk.WrapError(err, "deleteProjectQuotaHandler> Cannot delete quota of project %s", key)
+		}
+		return WriteJSON(w, r, nil, http.StatusOK)
+	}
+}
+
+func (api *API) getGroupQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		name := mux.Vars(r)["permGroupName"]
+
+		g, err := group.LoadGroup(api.mustDB(), name)
+		if err != nil {
+			return sdk.WrapError(err, "getGroupQuotaHandler> Cannot load group %s", name)
+		}
+
+		usage, err := quota.LoadGroupUsage(api.mustDB(), g.ID)
+		if err != nil {
+			return sdk.WrapError(err, "getGroupQuotaHandler> Cannot load quota of group %s", name)
+		}
+		return WriteJSON(w, r, usage, http.StatusOK)
+	}
+}
+
+func (api *API) putGroupQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		name := mux.Vars(r)["permGroupName"]
+
+		g, err := group.LoadGroup(api.mustDB(), name)
+		if err != nil {
+			return sdk.WrapError(err, "putGroupQuotaHandler> Cannot load group %s", name)
+		}
+
+		var q sdk.Quota
+		if err := UnmarshalBody(r, &q); err != nil {
+			return sdk.WrapError(err, "putGroupQuotaHandler> Cannot unmarshal quota")
+		}
+		if err := q.IsValid(); err != nil {
+			return sdk.WrapError(sdk.ErrWrongRequest, "putGroupQuotaHandler> %v", err)
+		}
+		q.GroupID, q.GroupName = g.ID, g.Name
+		q.ProjectID, q.ProjectKey = 0, ""
+
+		if err := quota.Upsert(api.mustDB(), &q); err != nil {
+			return sdk.WrapError(err, "putGroupQuotaHandler> Cannot save quota of group %s", name)
+		}
+		return WriteJSON(w, r, q, http.StatusOK)
+	}
+}
+
+func (api *API) deleteGroupQuotaHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		name := mux.Vars(r)["permGroupName"]
+
+		g, err := group.LoadGroup(api.mustDB(), name)
+		if err != nil {
+			return sdk.WrapError(err, "deleteGroupQuotaHandler> Cannot load group %s", name)
+		}
+
+		q, err := quota.LoadByGroupID(api.mustDB(), g.ID)
+		if err != nil {
+			return sdk.WrapError(err, "deleteGroupQuotaHandler> Cannot load quota of group %s", name)
+		}
+		if err := quota.Delete(api.mustDB(), q.ID); err != nil {
+			return sdk.WrapError(err, "deleteGroupQuotaHandler> Cannot delete quota of group %s", name)
+		}
+		return WriteJSON(w, r, nil, http.StatusOK)
+	}
+}
@@ -0,0 +1,121 @@
+package quota
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+const quotaQuery = `
+	SELECT quota.id, COALESCE(quota.project_id, 0), COALESCE(project.projectkey, ''), COALESCE(quota.group_id, 0), COALESCE("group".name, ''),
+		quota.max_concurrent_jobs, quota.max_daily_worker_minutes, quota.priority
+	FROM quota
+	LEFT JOIN project ON project.id = quota.project_id
+	LEFT JOIN "group" ON "group".id = quota.group_id`
+
+// LoadAll loads all the quotas
+func LoadAll(db gorp.SqlExecutor) ([]sdk.Quota, error) {
+	return loadQuotas(db, quotaQuery+" ORDER BY project.projectkey, \"group\".name")
+}
+
+// LoadByProjectID loads the quota of a project
+func LoadByProjectID(db gorp.SqlExecutor, projectID int64) (*sdk.Quota, error) {
+	return loadQuota(db, quotaQuery+" WHERE quota.project_id = $1", projectID)
+}
+
+// LoadByGroupID loads the quota of a group
+func LoadByGroupID(db gorp.SqlExecutor, groupID int64) (*sdk.Quota, error) {
+	return loadQuota(db, quotaQuery+" WHERE quota.group_id = $1", groupID)
+}
+
+func loadQuota(db gorp.SqlExecutor, query string, args ...interface{}) (*sdk.Quota, error) {
+	quotas, err := loadQuotas(db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return nil, sdk.ErrQuotaNotFound
+	}
+	return &quotas[0], nil
+}
+
+func loadQuotas(db gorp.SqlExecutor, query string, args ...interface{}) ([]sdk.Quota, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadQuotas> Cannot load quotas")
+	}
+	defer rows.Close()
+
+	quotas := []sdk.Quota{}
+	for rows.Next() {
+		var q sdk.Quota
+		if err := rows.Scan(&q.ID, &q.ProjectID, &q.ProjectKey, &q.GroupID, &q.GroupName, &q.MaxConcurrentJobs, &q.MaxDailyWorkerMinutes, &q.Priority); err != nil {
+			return nil, sdk.WrapError(err, "loadQuotas> Cannot scan quota")
+		}
+		quotas = append(quotas, q)
+	}
+	return quotas, nil
+}
+
+// Upsert inserts or updates the quota of a project or of a group
+func Upsert(db gorp.SqlExecutor, q *sdk.Quota) error {
+	var projectID, groupID sql.NullInt64
+	if q.ProjectID != 0 {
+		projectID = sql.NullInt64{Int64: q.ProjectID, Valid: true}
+	}
+	if q.GroupID != 0 {
+		groupID = sql.NullInt64{Int64: q.GroupID, Valid: true}
+	}
+
+	query := `
+	UPDATE quota SET max_concurrent_jobs = $3, max_daily_worker_minutes = $4, priority = $5
+	WHERE project_id = $1 OR group_id = $2
+	RETURNING id`
+	err := db.QueryRow(query, projectID, groupID, q.MaxConcurrentJobs, q.MaxDailyWorkerMinutes, q.Priority).Scan(&q.ID)
+	if err == nil {
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return sdk.WrapError(err, "quota.Upsert> Cannot update quota")
+	}
+
+	query = `
+	INSERT INTO quota (project_id, group_id, max_concurrent_jobs, max_daily_worker_minutes, priority)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id`
+	if err := db.QueryRow(query, projectID, groupID, q.MaxConcurrentJobs, q.MaxDailyWorkerMinutes, q.Priority).Scan(&q.ID); err != nil {
+		return sdk.WrapError(err, "quota.Upsert> Cannot insert quota")
+	}
+	return nil
+}
+
+// Delete deletes a quota
+func Delete(db gorp.SqlExecutor, id int64) error {
+	if _, err := db.Exec("DELETE FROM quota WHERE id = $1", id); err != nil {
+		return sdk.WrapError(err, "quota.Delete> Cannot delete quota %d", id)
+	}
+	return nil
+}
+
+// InsertWorkerUsage records the time spent by a worker on a job of a project
+func InsertWorkerUsage(db gorp.SqlExecutor, projectID int64, job *sdk.WorkflowNodeJobRun) error {
+	if job.Start.IsZero() || job.Done.Before(job.Start) {
+		return nil
+	}
+	query := `INSERT INTO worker_usage (project_id, workflow_node_run_job_id, start, done, seconds) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := db.Exec(query, projectID, job.ID, job.Start, job.Done, int64(job.Done.Sub(job.Start)/time.Second)); err != nil {
+		return sdk.WrapError(err, "quota.InsertWorkerUsage> Cannot insert worker usage of job %d", job.ID)
+	}
+	return nil
+}
+
+// DeleteWorkerUsages deletes the worker usages older than the given date
+func DeleteWorkerUsages(db gorp.SqlExecutor, before time.Time) error {
+	if _, err := db.Exec("DELETE FROM worker_usage WHERE done < $1", before); err != nil {
+		return sdk.WrapError(err, "quota.DeleteWorkerUsages> Cannot delete worker usages")
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// usageWindow is the period on which worker minutes are counted
+const usageWindow = 24 * time.Hour
+
+// usages is the consumption of all the quotas, with the groups quotas applied to each project
+type usages struct {
+	projects      map[int64]*sdk.QuotaUsage
+	groups        map[int64]*sdk.QuotaUsage
+	projectGroups map[int64][]int64
+}
+
+type projectUsage struct {
+	jobs    int
+	seconds int64
+}
+
+// quotas returns all the quotas applied on a project
+func (u *usages) quotas(projectID int64) []*sdk.QuotaUsage {
+	res := []*sdk.QuotaUsage{}
+	if q, ok := u.projects[projectID]; ok {
+		res = append(res, q)
+	}
+	for _, groupID := range u.projectGroups[projectID] {
+		if q, ok := u.groups[groupID]; ok {
+			res = append(res, q)
+		}
+	}
+	return res
+}
+
+// priority returns the highest priority of the quotas applied on a project
+func (u *usages) priority(projectID int64) int {
+	var p int
+	for i, q := range u.quotas(projectID) {
+		if i == 0 || q.Priority > p {
+			p = q.Priority
+		}
+	}
+	return p
+}
+
+// exceeded returns true if one of the quotas applied on a project is exceeded
+func (u *usages) exceeded(projectID int64) bool {
+	for _, q := range u.quotas(projectID) {
+		if q.Exceeded() {
+			return true
+		}
+	}
+	return false
+}
+
+// filter sorts the jobs by priority then by queued date, and keeps the ones which can be started without exceeding the quotas
+func (u *usages) filter(jobs []sdk.WorkflowNodeJobRun, jobProjects map[int64]int64) []sdk.WorkflowNodeJobRun {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		pi, pj := u.priority(jobProjects[jobs[i].ID]), u.priority(jobProjects[jobs[j].ID])
+		if pi != pj {
+			return pi > pj
+		}
+		return jobs[i].Queued.Before(jobs[j].Queued)
+	})
+
+	res := make([]sdk.WorkflowNodeJobRun, 0, len(jobs))
+	for _, j := range jobs {
+		projectID := jobProjects[j.ID]
+		if u.exceeded(projectID) {
+			continue
+		}
+		// The job will be taken by a worker, count it so that the following jobs are checked against the remaining slots
+		for _, q := range u.quotas(projectID) {
+			q.ConcurrentJobs++
+		}
+		res = append(res, j)
+	}
+	return res
+}
+
+func newUsages(quotas []sdk.Quota, byProject map[int64]projectUsage, projectGroups map[int64][]int64) *usages {
+	u := &usages{
+		projects:      map[int64]*sdk.QuotaUsage{},
+		groups:        map[int64]*sdk.QuotaUsage{},
+		projectGroups: projectGroups,
+	}
+
+	groupProjects := map[int64][]int64{}
+	for projectID, groups := range projectGroups {
+		for _, groupID := range groups {
+			groupProjects[groupID] = append(groupProjects[groupID], projectID)
+		}
+	}
+
+	for _, q := range quotas {
+		qu := &sdk.QuotaUsage{Quota: q}
+		switch {
+		case q.ProjectID != 0:
+			pu := byProject[q.ProjectID]
+			qu.ConcurrentJobs = pu.jobs
+			qu.DailyWorkerMinutes = pu.seconds / 60
+			u.projects[q.ProjectID] = qu
+		case q.GroupID != 0:
+			var seconds int64
+			for _, projectID := range groupProjects[q.GroupID] {
+				pu := byProject[projectID]
+				qu.ConcurrentJobs += pu.jobs
+				seconds += pu.seconds
+			}
+			qu.DailyWorkerMinutes = seconds / 60
+			u.groups[q.GroupID] = qu
+		}
+	}
+	return u
+}
+
+func loadUsages(db gorp.SqlExecutor, quotas []sdk.Quota) (*usages, error) {
+	projectGroups, err := loadQuotaProjectGroups(db, quotas)
+	if err != nil {
+		return nil, err
+	}
+	byProject, err := loadProjectUsages(db, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newUsages(quotas, byProject, projectGroups), nil
+}
+
+// loadProjectUsages loads the running jobs and the worker usage of projects, of all the projects if projectIDs is nil
+func loadProjectUsages(db gorp.SqlExecutor, projectIDs []int64) (map[int64]projectUsage, error) {
+	byProject := map[int64]projectUsage{}
+
+	// the usages are filtered on the project column of each query
+	filter := func(column string) string { return "" }
+	args := []interface{}{}
+	if projectIDs != nil {
+		ids := make([]string, len(projectIDs))
+		for i := range projectIDs {
+			ids[i] = fmt.Sprintf("%d", projectIDs[i])
+		}
+		filter = func(column string) string {
+			return " AND " + column + " = ANY(string_to_array($2, ',')::bigint[])"
+		}
+		args = append(args, strings.Join(ids, ","))
+	}
+
+	// Jobs currently running
+	query := `
+	SELECT workflow_run.project_id, COUNT(workflow_node_run_job.id), COALESCE(SUM(EXTRACT(EPOCH FROM (now() - workflow_node_run_job.start))), 0)
+	FROM workflow_node_run_job
+	JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_job.workflow_node_run_id
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	WHERE workflow_node_run_job.status = $1` + filter("workflow_run.project_id") + `
+	GROUP BY workflow_run.project_id`
+	rows, err := db.Query(query, append([]interface{}{sdk.StatusBuilding.String()}, args...)...)
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadProjectUsages> Cannot count running jobs")
+	}
+	for rows.Next() {
+		var projectID int64
+		var jobs int
+		var seconds float64
+		if err := rows.Scan(&projectID, &jobs, &seconds); err != nil {
+			rows.Close()
+			return nil, sdk.WrapError(err, "loadProjectUsages> Cannot scan running jobs")
+		}
+		byProject[projectID] = projectUsage{jobs: jobs, seconds: int64(seconds)}
+	}
+	rows.Close()
+
+	// Jobs done during the usage window
+	query = `SELECT project_id, COALESCE(SUM(seconds), 0) FROM worker_usage WHERE done > $1` + filter("project_id") + ` GROUP BY project_id`
+	rows, err = db.Query(query, append([]interface{}{time.Now().Add(-usageWindow)}, args...)...)
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadProjectUsages> Cannot load worker usages")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var projectID, seconds int64
+		if err := rows.Scan(&projectID, &seconds); err != nil {
+			return nil, sdk.WrapError(err, "loadProjectUsages> Cannot scan worker usages")
+		}
+		pu := byProject[projectID]
+		pu.seconds += seconds
+		byProject[projectID] = pu
+	}
+	return byProject, nil
+}
+
+// loadQuotaProjectGroups loads by project the groups of the quotas. Group quotas apply to all the projects on
+// which the group has the write permission
+func loadQuotaProjectGroups(db gorp.SqlExecutor, quotas []sdk.Quota) (map[int64][]int64, error) {
+	projectGroups := map[int64][]int64{}
+	var groupIDs []string
+	for _, q := range quotas {
+		if q.GroupID != 0 {
+			groupIDs = append(groupIDs, fmt.Sprintf("%d", q.GroupID))
+		}
+	}
+	if len(groupIDs) == 0 {
+		return projectGroups, nil
+	}
+
+	query := `
+	SELECT project_id, group_id FROM project_group
+	WHERE role = $1 AND group_id = ANY(string_to_array($2, ',')::bigint[])`
+	rows, err := db.Query(query, permission.PermissionReadWriteExecute, strings.Join(groupIDs, ","))
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadQuotaProjectGroups> Cannot load project groups")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var projectID, groupID int64
+		if err := rows.Scan(&projectID, &groupID); err != nil {
+			return nil, sdk.WrapError(err, "loadQuotaProjectGroups> Cannot scan project groups")
+		}
+		projectGroups[projectID] = append(projectGroups[projectID], groupID)
+	}
+	return projectGroups, nil
+}
+
+// LoadUsages loads the consumption of all the quotas
+func LoadUsages(db gorp.SqlExecutor) ([]sdk.QuotaUsage, error) {
+	quotas, err := LoadAll(db)
+	if err != nil {
+		return nil, err
+	}
+	u, err := loadUsages(db, quotas)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]sdk.QuotaUsage, 0, len(quotas))
+	for _, q := range quotas {
+		if q.ProjectID != 0 {
+			res = append(res, *u.projects[q.ProjectID])
+		} else {
+			res = append(res, *u.groups[q.GroupID])
+		}
+	}
+	return res, nil
+}
+
+// LoadProjectUsages loads the consumption of the quotas applied on a project: its own quota and the quotas of its groups
+func LoadProjectUsages(db gorp.SqlExecutor, projectID int64) ([]sdk.QuotaUsage, error) {
+	quotas, err := LoadAll(db)
+	if err != nil {
+		return nil, err
+	}
+	u, err := loadUsages(db, quotas)
+	if err != nil {
+		return nil, err
+	}
+	res := []sdk.QuotaUsage{}
+	for _, q := range u.quotas(projectID) {
+		res = append(res, *q)
+	}
+	return res, nil
+}
+
+// LoadGroupUsage loads the consumption of the quota of a group
+func LoadGroupUsage(db gorp.SqlExecutor, groupID int64) (*sdk.QuotaUsage, error) {
+	quotas, err := LoadAll(db)
+	if err != nil {
+		return nil, err
+	}
+	u, err := loadUsages(db, quotas)
+	if err != nil {
+		return nil, err
+	}
+	q, ok := u.groups[groupID]
+	if !ok {
+		return nil, sdk.ErrQuotaNotFound
+	}
+	return q, nil
+}
+
+// Apply orders the waiting jobs by priority and removes the ones which would exceed the quotas of their project
+func Apply(db gorp.SqlExecutor, jobs []sdk.WorkflowNodeJobRun) ([]sdk.WorkflowNodeJobRun, error) {
+	if len(jobs) == 0 {
+		return jobs, nil
+	}
+
+	quotas, err := LoadAll(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return jobs, nil
+	}
+
+	u, err := loadUsages(db, quotas)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(jobs))
+	for i := range jobs {
+		ids[i] = fmt.Sprintf("%d", jobs[i].ID)
+	}
+	jobProjects, err := loadJobProjects(db, strings.Join(ids, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	return u.filter(jobs, jobProjects), nil
+}
+
+// Check returns sdk.ErrQuotaExceeded if the job cannot be started without exceeding the quotas of its project.
+// It must be called inside the transaction taking the job: the checks of a project, and of the projects sharing
+// a group quota with it, are serialized until the end of the transaction
+func Check(db gorp.SqlExecutor, jobID int64) error {
+	var projectID int64
+	var projectKey string
+	query := `
+	SELECT project.id, project.projectkey
+	FROM workflow_node_run_job
+	JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_job.workflow_node_run_id
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	JOIN project ON project.id = workflow_run.project_id
+	WHERE workflow_node_run_job.id = $1`
+	if err := db.QueryRow(query, jobID).Scan(&projectID, &projectKey); err != nil {
+		return sdk.WrapError(err, "quota.Check> Cannot load project of job %d", jobID)
+	}
+
+	quotas, err := loadQuotas(db, quotaQuery+` WHERE quota.project_id = $1
+		OR quota.group_id IN (SELECT group_id FROM project_group WHERE project_id = $1 AND role = $2)
+		ORDER BY quota.group_id NULLS LAST`, projectID, permission.PermissionReadWriteExecute)
+	if err != nil {
+		return err
+	}
+	if len(quotas) == 0 {
+		return nil
+	}
+
+	// the group quotas are locked before the project, in the order of the groups, so that the checks do not deadlock
+	for _, q := range quotas {
+		lock := "SELECT pg_advisory_xact_lock(hashtext('quota-' || $1))"
+		arg := projectKey
+		if q.GroupID != 0 {
+			lock = "SELECT pg_advisory_xact_lock(hashtext('quota-group-' || $1))"
+			arg = fmt.Sprintf("%d", q.GroupID)
+		}
+		if _, err := db.Exec(lock, arg); err != nil {
+			return sdk.WrapError(err, "quota.Check> Cannot lock quotas of project %s", projectKey)
+		}
+	}
+
+	projectGroups, err := loadQuotaProjectGroups(db, quotas)
+	if err != nil {
+		return err
+	}
+	projectIDs := []int64{projectID}
+	for id := range projectGroups {
+		if id != projectID {
+			projectIDs = append(projectIDs, id)
+		}
+	}
+	byProject, err := loadProjectUsages(db, projectIDs)
+	if err != nil {
+		return err
+	}
+
+	if newUsages(quotas, byProject, projectGroups).exceeded(projectID) {
+		return sdk.ErrQuotaExceeded
+	}
+	return nil
+}
+
+func loadJobProjects(db gorp.SqlExecutor, ids string) (map[int64]int64, error) {
+	query := `
+	SELECT workflow_node_run_job.id, workflow_run.project_id
+	FROM workflow_node_run_job
+	JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_job.workflow_node_run_id
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	WHERE workflow_node_run_job.id = ANY(string_to_array($1, ',')::bigint[])`
+	rows, err := db.Query(query, ids)
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadJobProjects> Cannot load projects of jobs")
+	}
+	defer rows.Close()
+
+	res := map[int64]int64{}
+	for rows.Next() {
+		var jobID, projectID int64
+		if err := rows.Scan(&jobID, &projectID); err != nil {
+			return nil, sdk.WrapError(err, "loadJobProjects> Cannot scan projects of jobs")
+		}
+		res[jobID] = projectID
+	}
+	return res, nil
+}
+
+// CleanWorkerUsages deletes periodically the worker usages which are no longer counted in quotas
+func CleanWorkerUsages(c context.Context, DBFunc func() *gorp.DbMap) {
+	tick := time.NewTicker(time.Hour).C
+	for {
+		select {
+		case <-c.Done():
+			if c.Err() != nil {
+				log.Error("Exiting quota.CleanWorkerUsages: %v", c.Err())
+				return
+			}
+		case <-tick:
+			db := DBFunc()
+			if db == nil {
+				continue
+			}
+			if err := DeleteWorkerUsages(db, time.Now().Add(-2*usageWindow)); err != nil {
+				log.Warning("quota.CleanWorkerUsages> %v", err)
+			}
+		}
+	}
+}
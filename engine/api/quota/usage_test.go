@@ -0,0 +1,58 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestUsagesFilter(t *testing.T) {
+	quotas := []sdk.Quota{
+		{ProjectID: 1, MaxConcurrentJobs: 2},
+		{ProjectID: 2, Priority: 10},
+		{GroupID: 100, MaxDailyWorkerMinutes: 60},
+	}
+	byProject := map[int64]projectUsage{
+		1: {jobs: 1},
+		3: {seconds: 3600},
+	}
+	projectGroups := map[int64][]int64{
+		3: {100},
+		4: {100},
+	}
+	u := newUsages(quotas, byProject, projectGroups)
+
+	assert.Equal(t, int64(60), u.groups[100].DailyWorkerMinutes)
+	assert.True(t, u.exceeded(3))
+	assert.True(t, u.exceeded(4))
+	assert.False(t, u.exceeded(1))
+
+	now := time.Now()
+	jobs := []sdk.WorkflowNodeJobRun{
+		{ID: 11, Queued: now.Add(-3 * time.Minute)},
+		{ID: 12, Queued: now.Add(-2 * time.Minute)},
+		{ID: 21, Queued: now},
+		{ID: 31, Queued: now.Add(-time.Hour)},
+		{ID: 51, Queued: now.Add(-time.Minute)},
+	}
+	jobProjects := map[int64]int64{11: 1, 12: 1, 21: 2, 31: 3, 51: 5}
+
+	res := u.filter(jobs, jobProjects)
+	ids := []int64{}
+	for _, j := range res {
+		ids = append(ids, j.ID)
+	}
+	// Project 2 has the highest priority, project 1 has only one slot left, project 3 is over its group quota
+	assert.Equal(t, []int64{21, 11, 51}, ids)
+}
+
+func TestQuotaUsageExceeded(t *testing.T) {
+	var nilUsage *sdk.QuotaUsage
+	assert.False(t, nilUsage.Exceeded())
+	assert.False(t, (&sdk.QuotaUsage{ConcurrentJobs: 10}).Exceeded())
+	assert.True(t, (&sdk.QuotaUsage{Quota: sdk.Quota{MaxConcurrentJobs: 2}, ConcurrentJobs: 2}).Exceeded())
+	assert.True(t, (&sdk.QuotaUsage{Quota: sdk.Quota{MaxDailyWorkerMinutes: 10}, DailyWorkerMinutes: 12}).Exceeded())
+}
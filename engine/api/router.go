@@ -396,6 +396,14 @@ func NeedAdmin(admin bool) HandlerConfigParam {
 	return f
 }
 
+// NeedReadPermission set the route for the users with the read permission only, for the POST which change nothing
+func NeedReadPermission() HandlerConfigParam {
+	f := func(rc *HandlerConfig) {
+		rc.Options["isRead"] = "true"
+	}
+	return f
+}
+
 // NeedUsernameOrAdmin set the route for cds admin or current user = username called on route
 func NeedUsernameOrAdmin(need bool) HandlerConfigParam {
 	f := func(rc *HandlerConfig) {
@@ -19,6 +19,13 @@ const (
 	EnvironmentVariableUsedInApplicationDoesNotExist
 	InvalidVariableFormatUsedInApplication
 	MissingEnvironment
+	WorkflowProjectVariableDoesNotExist
+	WorkflowApplicationVariableDoesNotExist
+	WorkflowEnvironmentVariableDoesNotExist
+	WorkflowNodeUnreachable
+	WorkflowConditionOnUnknownVariable
+	WorkflowHookWithoutRepository
+	WorkerModelDoesNotExist
 )
 
 var messageAmericanEnglish = map[int64]string{
@@ -37,4 +44,12 @@ var messageAmericanEnglish = map[int64]string{
 	GitURLWithoutKey:                                 `Action {{index . "ActionName"}}{{if index . "PipelineName"}} in pipeline {{index . "ProjectKey"}}/{{index . "PipelineName"}}{{end}} is used but no ssh key were found. Git clone will failed`,
 	MissingEnvironment:                               `Application {{index . "ApplicationName"}}: At least one environment with one variable should be defined`,
 	EnvironmentVariableUsedInApplicationDoesNotExist: `Application {{index . "ApplicationName"}}: Environment variable {{index . "VarName"}} used but doesn't exist in all environments`,
-	InvalidVariableFormatUsedInApplication:           `Application {{index . "ApplicationName"}}: Invalid variable format '{{index . "VarName"}}'`}
+	InvalidVariableFormatUsedInApplication:           `Application {{index . "ApplicationName"}}: Invalid variable format '{{index . "VarName"}}'`,
+	WorkflowProjectVariableDoesNotExist:              `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: Project variable '{{index . "VarName"}}' used but doesn't exist`,
+	WorkflowApplicationVariableDoesNotExist:          `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: Application variable '{{index . "VarName"}}' used but doesn't exist{{if index . "AppName"}} in application '{{index . "AppName"}}'{{else}}, the node has no application{{end}}`,
+	WorkflowEnvironmentVariableDoesNotExist:          `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: Environment variable '{{index . "VarName"}}' used but doesn't exist{{if index . "EnvName"}} in environment '{{index . "EnvName"}}'{{else}}, the node has no environment{{end}}`,
+	WorkflowNodeUnreachable:                          `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: The node will never be triggered: {{index . "Reason"}}`,
+	WorkflowConditionOnUnknownVariable:               `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: Condition on variable '{{index . "VarName"}}' which doesn't exist`,
+	WorkflowHookWithoutRepository:                    `Workflow {{index . "WorkflowName"}}, node {{index . "NodeName"}}: Hook {{index . "HookName"}} needs an application linked to a repository`,
+	WorkerModelDoesNotExist:                          `Action {{index . "ActionName"}}{{if index . "PipelineName"}} in pipeline {{index . "ProjectKey"}}/{{index . "PipelineName"}}{{end}}: Model {{index . "ModelName"}} doesn't exist. It will never start building.`,
+}
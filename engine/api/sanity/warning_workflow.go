@@ -0,0 +1,426 @@
+package sanity
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/worker"
+	"github.com/ovh/cds/sdk"
+)
+
+// LintWorkflow checks a workflow for configuration errors. The workflow pipelines must be fully loaded.
+// Warnings are not stored, they are returned with their message in the accepted language
+func LintWorkflow(db gorp.SqlExecutor, proj *sdk.Project, wf *sdk.Workflow, al string) ([]sdk.Warning, error) {
+	var err error
+	proj.Variable, err = project.GetAllVariableInProject(db, proj.ID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LintWorkflow> Cannot load variables of project %s", proj.Key)
+	}
+
+	wms, err := worker.LoadWorkerModels(db)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LintWorkflow> Cannot load worker models")
+	}
+
+	var errL error
+	wf.Visit(func(n *sdk.WorkflowNode) {
+		if errL != nil || n.Context == nil {
+			return
+		}
+		if app := n.Context.Application; app != nil && app.ID != 0 {
+			if app.Variable, err = application.GetAllVariableByID(db, app.ID); err != nil {
+				errL = sdk.WrapError(err, "LintWorkflow> Cannot load variables of application %s", app.Name)
+				return
+			}
+		}
+		if env := n.Context.Environment; env != nil && env.ID != 0 {
+			if env.Variable, err = environment.GetAllVariableByID(db, env.ID); err != nil {
+				errL = sdk.WrapError(err, "LintWorkflow> Cannot load variables of environment %s", env.Name)
+			}
+		}
+	})
+	if errL != nil {
+		return nil, errL
+	}
+
+	warnings := CheckWorkflow(proj, wf, wms)
+	for i := range warnings {
+		if err := processWarning(&warnings[i], al); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+// CheckWorkflow checks:
+// - variables used by nodes are defined on the project, the application or the environment of the node
+// - all nodes can be triggered
+// - conditions are on existing variables
+// - repository hooks are set on nodes with an application linked to a repository
+// - jobs requirements can be satisfied by the registered worker models
+func CheckWorkflow(proj *sdk.Project, wf *sdk.Workflow, wms []sdk.Model) []sdk.Warning {
+	warnings := []sdk.Warning{}
+	if wf.Root == nil {
+		return warnings
+	}
+
+	// Pipelines can be used by several nodes, requirements are checked once by pipeline
+	checkedPipelines := map[string]bool{}
+	wf.Visit(func(n *sdk.WorkflowNode) {
+		warnings = append(warnings, checkWorkflowNodeVariables(proj, wf, n)...)
+		warnings = append(warnings, checkWorkflowNodeConditions(proj, wf, n)...)
+		warnings = append(warnings, checkWorkflowNodeHooks(wf, n)...)
+		if !checkedPipelines[n.Pipeline.Name] {
+			checkedPipelines[n.Pipeline.Name] = true
+			warnings = append(warnings, checkWorkflowNodeRequirements(proj, n, wms)...)
+		}
+	})
+	warnings = append(warnings, checkWorkflowUnreachableNodes(wf)...)
+
+	for i := range warnings {
+		warnings[i].Project.ID = proj.ID
+		warnings[i].Project.Key = proj.Key
+	}
+	return warnings
+}
+
+func newWorkflowWarning(id int64, wf *sdk.Workflow, n *sdk.WorkflowNode, params map[string]string) sdk.Warning {
+	w := sdk.Warning{
+		ID: id,
+		MessageParam: map[string]string{
+			"WorkflowName": wf.Name,
+			"NodeName":     n.Name,
+		},
+	}
+	for k, v := range params {
+		w.MessageParam[k] = v
+	}
+	w.Pipeline.ID = n.Pipeline.ID
+	w.Pipeline.Name = n.Pipeline.Name
+	if n.Context != nil && n.Context.Application != nil {
+		w.Application.ID = n.Context.Application.ID
+		w.Application.Name = n.Context.Application.Name
+	}
+	if n.Context != nil && n.Context.Environment != nil {
+		w.Environment.ID = n.Context.Environment.ID
+		w.Environment.Name = n.Context.Environment.Name
+	}
+	return w
+}
+
+func hasVariable(vars []sdk.Variable, name string) bool {
+	for _, v := range vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasParameter(params []sdk.Parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeApplication(n *sdk.WorkflowNode) *sdk.Application {
+	if n.Context == nil {
+		return nil
+	}
+	return n.Context.Application
+}
+
+func nodeEnvironment(n *sdk.WorkflowNode) *sdk.Environment {
+	if n.Context == nil {
+		return nil
+	}
+	return n.Context.Environment
+}
+
+// checkWorkflowNodeVariables checks the project, application and environment variables used by the node pipeline and its parameters
+func checkWorkflowNodeVariables(proj *sdk.Project, wf *sdk.Workflow, n *sdk.WorkflowNode) []sdk.Warning {
+	var values []string
+	for _, p := range n.Pipeline.Parameter {
+		values = append(values, p.Value)
+	}
+	if n.Context != nil {
+		for _, p := range n.Context.DefaultPipelineParameters {
+			values = append(values, p.Value)
+		}
+	}
+
+	pmap := map[string]bool{}
+	amap := map[string]bool{}
+	emap := map[string]bool{}
+	var pvars, avars, evars []string
+	add := func(m map[string]bool, list *[]string, vars []string) {
+		for _, v := range vars {
+			if !m[v] {
+				m[v] = true
+				*list = append(*list, v)
+			}
+		}
+	}
+
+	resChan := make(chan usedVariablesResponse, 1)
+	for _, v := range values {
+		loadUsedVariablesFromValue(v, resChan)
+		res := <-resChan
+		add(pmap, &pvars, res.pvars)
+		add(amap, &avars, res.avars)
+		add(emap, &evars, res.evars)
+	}
+	close(resChan)
+
+	for _, s := range n.Pipeline.Stages {
+		for i := range s.Jobs {
+			p, a, e, _, _ := loadUsedVariables(&s.Jobs[i].Action)
+			add(pmap, &pvars, p)
+			add(amap, &avars, a)
+			add(emap, &evars, e)
+		}
+	}
+
+	var warnings []sdk.Warning
+	for _, v := range pvars {
+		if !hasVariable(proj.Variable, v) {
+			warnings = append(warnings, newWorkflowWarning(WorkflowProjectVariableDoesNotExist, wf, n, map[string]string{"VarName": v}))
+		}
+	}
+
+	app := nodeApplication(n)
+	for _, v := range avars {
+		if app == nil {
+			warnings = append(warnings, newWorkflowWarning(WorkflowApplicationVariableDoesNotExist, wf, n, map[string]string{"VarName": v}))
+		} else if !hasVariable(app.Variable, v) {
+			warnings = append(warnings, newWorkflowWarning(WorkflowApplicationVariableDoesNotExist, wf, n, map[string]string{"VarName": v, "AppName": app.Name}))
+		}
+	}
+
+	env := nodeEnvironment(n)
+	for _, v := range evars {
+		if env == nil {
+			warnings = append(warnings, newWorkflowWarning(WorkflowEnvironmentVariableDoesNotExist, wf, n, map[string]string{"VarName": v}))
+		} else if !hasVariable(env.Variable, v) {
+			warnings = append(warnings, newWorkflowWarning(WorkflowEnvironmentVariableDoesNotExist, wf, n, map[string]string{"VarName": v, "EnvName": env.Name}))
+		}
+	}
+	return warnings
+}
+
+// checkWorkflowNodeConditions checks the conditions of a node are on known variables.
+// Only the project, application, environment and pipeline variables can be checked, other variables depend on the run
+func checkWorkflowNodeConditions(proj *sdk.Project, wf *sdk.Workflow, n *sdk.WorkflowNode) []sdk.Warning {
+	if n.Context == nil {
+		return nil
+	}
+
+	var warnings []sdk.Warning
+	for _, c := range n.Context.Conditions.PlainConditions {
+		var known = true
+		switch {
+		case strings.HasPrefix(c.Variable, "cds.proj."):
+			known = hasVariable(proj.Variable, strings.TrimPrefix(c.Variable, "cds.proj."))
+		case strings.HasPrefix(c.Variable, "cds.app."):
+			app := nodeApplication(n)
+			known = app != nil && hasVariable(app.Variable, strings.TrimPrefix(c.Variable, "cds.app."))
+		case strings.HasPrefix(c.Variable, "cds.env."):
+			env := nodeEnvironment(n)
+			known = env != nil && hasVariable(env.Variable, strings.TrimPrefix(c.Variable, "cds.env."))
+		case strings.HasPrefix(c.Variable, "cds.pip."):
+			known = hasParameter(n.Pipeline.Parameter, strings.TrimPrefix(c.Variable, "cds.pip."))
+		}
+		if !known {
+			warnings = append(warnings, newWorkflowWarning(WorkflowConditionOnUnknownVariable, wf, n, map[string]string{"VarName": c.Variable}))
+		}
+	}
+	return warnings
+}
+
+// checkWorkflowNodeHooks checks the hooks triggered by a repository are on nodes with an application linked to a repository
+func checkWorkflowNodeHooks(wf *sdk.Workflow, n *sdk.WorkflowNode) []sdk.Warning {
+	var warnings []sdk.Warning
+	for _, h := range n.Hooks {
+		if h.WorkflowHookModel.Name != sdk.RepositoryWebHookModelName && h.WorkflowHookModel.Name != sdk.GitPollerModelName {
+			continue
+		}
+		app := nodeApplication(n)
+		if app == nil || app.VCSServer == "" || app.RepositoryFullname == "" {
+			warnings = append(warnings, newWorkflowWarning(WorkflowHookWithoutRepository, wf, n, map[string]string{"HookName": h.WorkflowHookModel.Name}))
+		}
+	}
+	return warnings
+}
+
+// checkWorkflowNodeRequirements checks the requirements of all the jobs of the node pipeline.
+// Jobs with requirements using variables are skipped, they can only be checked at runtime
+func checkWorkflowNodeRequirements(proj *sdk.Project, n *sdk.WorkflowNode, wms []sdk.Model) []sdk.Warning {
+	var warnings []sdk.Warning
+jobs:
+	for _, s := range n.Pipeline.Stages {
+		for i := range s.Jobs {
+			a := &s.Jobs[i].Action
+			for _, r := range a.Requirements {
+				if strings.Contains(r.Value, "{{") {
+					continue jobs
+				}
+			}
+
+			w, err := checkActionRequirements(a, proj.Key, n.Pipeline.Name, wms)
+			if err == sdk.ErrNoWorkerModel {
+				var modelName string
+				for _, r := range a.Requirements {
+					if r.Type == sdk.ModelRequirement {
+						modelName = r.Value
+					}
+				}
+				w = append(w, sdk.Warning{
+					ID: WorkerModelDoesNotExist,
+					MessageParam: map[string]string{
+						"ActionName":   a.Name,
+						"PipelineName": n.Pipeline.Name,
+						"ProjectKey":   proj.Key,
+						"ModelName":    modelName,
+					},
+				})
+			}
+			for j := range w {
+				w[j].Action.Name = a.Name
+				w[j].Pipeline.ID = n.Pipeline.ID
+				w[j].Pipeline.Name = n.Pipeline.Name
+			}
+			warnings = append(warnings, w...)
+		}
+	}
+	return warnings
+}
+
+// contradictoryConditions returns a description of the first conditions which can't be true at the same time
+func contradictoryConditions(conditions []sdk.WorkflowNodeCondition) string {
+	equals := map[string]string{}
+	for _, c := range conditions {
+		if c.Operator != sdk.WorkflowConditionsOperatorEquals {
+			continue
+		}
+		if v, ok := equals[c.Variable]; ok && v != c.Value {
+			return fmt.Sprintf("%s can't be equal to %s and %s", c.Variable, v, c.Value)
+		}
+		equals[c.Variable] = c.Value
+	}
+	for _, c := range conditions {
+		if c.Operator != sdk.WorkflowConditionsOperatorNotEquals {
+			continue
+		}
+		if v, ok := equals[c.Variable]; ok && v == c.Value {
+			return fmt.Sprintf("%s can't be equal and not equal to %s", c.Variable, v)
+		}
+	}
+	return ""
+}
+
+// checkWorkflowUnreachableNodes looks for nodes which will never be triggered:
+// nodes with contradictory conditions, and nodes triggered by a join with an unknown or unreachable source.
+// Only the first unreachable node of a branch is reported
+func checkWorkflowUnreachableNodes(wf *sdk.Workflow) []sdk.Warning {
+	var warnings []sdk.Warning
+	// Nodes are identified by their name, which is unique in a workflow
+	visited := map[string]bool{}
+	unreachable := map[string]bool{}
+
+	var visit func(n *sdk.WorkflowNode, parentUnreachable bool)
+	visit = func(n *sdk.WorkflowNode, parentUnreachable bool) {
+		isUnreachable := parentUnreachable
+		if !parentUnreachable && n.Context != nil {
+			if reason := contradictoryConditions(n.Context.Conditions.PlainConditions); reason != "" {
+				warnings = append(warnings, newWorkflowWarning(WorkflowNodeUnreachable, wf, n, map[string]string{"Reason": reason}))
+				isUnreachable = true
+			}
+		}
+		visited[n.Name] = true
+		unreachable[n.Name] = isUnreachable
+		for i := range n.Triggers {
+			visit(&n.Triggers[i].WorkflowDestNode, isUnreachable)
+		}
+	}
+	visit(wf.Root, false)
+
+	// A join can depend on the nodes triggered by another join
+	done := map[int]bool{}
+	for progress := true; progress; {
+		progress = false
+		for i := range wf.Joins {
+			if done[i] {
+				continue
+			}
+			j := &wf.Joins[i]
+
+			sources, reason := joinSources(wf, j)
+			var pending bool
+			for _, s := range sources {
+				if !visited[s] {
+					pending = true
+				} else if unreachable[s] && reason == "" {
+					reason = fmt.Sprintf("the join source %s will never be triggered", s)
+				}
+			}
+			if reason == "" && pending {
+				continue
+			}
+
+			done[i] = true
+			progress = true
+			for k := range j.Triggers {
+				n := &j.Triggers[k].WorkflowDestNode
+				if reason != "" {
+					warnings = append(warnings, newWorkflowWarning(WorkflowNodeUnreachable, wf, n, map[string]string{"Reason": reason}))
+				}
+				visit(n, reason != "")
+			}
+		}
+	}
+
+	// Remaining joins depend on each other
+	for i := range wf.Joins {
+		if done[i] {
+			continue
+		}
+		for k := range wf.Joins[i].Triggers {
+			n := &wf.Joins[i].Triggers[k].WorkflowDestNode
+			warnings = append(warnings, newWorkflowWarning(WorkflowNodeUnreachable, wf, n, map[string]string{"Reason": "the join sources depend on the node itself"}))
+		}
+	}
+	return warnings
+}
+
+// joinSources returns the names of the source nodes of a join, or the reason why they can't be found
+func joinSources(wf *sdk.Workflow, j *sdk.WorkflowNodeJoin) ([]string, string) {
+	var sources []string
+	if len(j.SourceNodeIDs) > 0 {
+		for _, id := range j.SourceNodeIDs {
+			n := wf.GetNode(id)
+			if n == nil {
+				return nil, fmt.Sprintf("the join source %d doesn't exist", id)
+			}
+			sources = append(sources, n.Name)
+		}
+	} else {
+		for _, ref := range j.SourceNodeRefs {
+			if wf.GetNodeByName(ref) == nil {
+				return nil, fmt.Sprintf("the join source %s doesn't exist", ref)
+			}
+			sources = append(sources, ref)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, "the join has no source"
+	}
+	return sources, ""
+}
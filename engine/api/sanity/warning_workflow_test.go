@@ -0,0 +1,165 @@
+package sanity
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestCheckWorkflow(t *testing.T) {
+	proj := &sdk.Project{
+		Key:      "PROJ",
+		Variable: []sdk.Variable{{Name: "defined"}},
+	}
+	wms := []sdk.Model{
+		{Name: "go", Type: sdk.Docker, Capabilities: []sdk.Requirement{{Type: sdk.BinaryRequirement, Value: "go"}}},
+	}
+
+	buildPip := sdk.Pipeline{
+		Name: "build",
+		Stages: []sdk.Stage{{
+			Jobs: []sdk.Job{{
+				Action: sdk.Action{
+					Name:         "compile",
+					Requirements: []sdk.Requirement{{Type: sdk.BinaryRequirement, Value: "rustc"}},
+					Actions: []sdk.Action{{
+						Parameters: []sdk.Parameter{{Name: "script", Value: "echo {{.cds.proj.defined}} {{.cds.proj.missing}} {{.cds.app.token}}"}},
+					}},
+				},
+			}},
+		}},
+	}
+	deployPip := sdk.Pipeline{
+		Name:      "deploy",
+		Parameter: []sdk.Parameter{{Name: "target", Value: "{{.cds.env.url}}"}},
+		Stages: []sdk.Stage{{
+			Jobs: []sdk.Job{{
+				Action: sdk.Action{
+					Name:         "push",
+					Requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "unknown-model"}},
+				},
+			}},
+		}},
+	}
+
+	wf := &sdk.Workflow{
+		Name: "my-workflow",
+		Root: &sdk.WorkflowNode{
+			Name:     "build",
+			Pipeline: buildPip,
+			Context: &sdk.WorkflowNodeContext{
+				Application: &sdk.Application{Name: "my-app", Variable: []sdk.Variable{{Name: "token"}}},
+			},
+			Hooks: []sdk.WorkflowNodeHook{{WorkflowHookModel: sdk.RepositoryWebHookModel}},
+			Triggers: []sdk.WorkflowNodeTrigger{{
+				WorkflowDestNode: sdk.WorkflowNode{
+					Name:     "deploy",
+					Pipeline: deployPip,
+					Context: &sdk.WorkflowNodeContext{
+						Environment: &sdk.Environment{Name: "prod"},
+						Conditions: sdk.WorkflowNodeConditions{
+							PlainConditions: []sdk.WorkflowNodeCondition{
+								{Variable: "cds.status", Operator: sdk.WorkflowConditionsOperatorEquals, Value: sdk.StatusSuccess.String()},
+								{Variable: "cds.proj.unknown", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "true"},
+								{Variable: "cds.manual", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "true"},
+								{Variable: "cds.manual", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "false"},
+							},
+						},
+					},
+					Triggers: []sdk.WorkflowNodeTrigger{{
+						WorkflowDestNode: sdk.WorkflowNode{Name: "deploy-child", Pipeline: sdk.Pipeline{Name: "notify"}},
+					}},
+				},
+			}},
+		},
+		Joins: []sdk.WorkflowNodeJoin{
+			{
+				SourceNodeRefs: []string{"build", "deploy"},
+				Triggers: []sdk.WorkflowNodeJoinTrigger{{
+					WorkflowDestNode: sdk.WorkflowNode{Name: "after-deploy", Pipeline: sdk.Pipeline{Name: "notify"}},
+				}},
+			},
+			{
+				SourceNodeRefs: []string{"build", "unknown"},
+				Triggers: []sdk.WorkflowNodeJoinTrigger{{
+					WorkflowDestNode: sdk.WorkflowNode{Name: "orphan", Pipeline: sdk.Pipeline{Name: "notify"}},
+				}},
+			},
+		},
+	}
+
+	warnings := CheckWorkflow(proj, wf, wms)
+
+	type warning struct {
+		id    int64
+		param string
+	}
+	got := []warning{}
+	for _, w := range warnings {
+		param := w.MessageParam["NodeName"] + " " + w.MessageParam["VarName"] + w.MessageParam["HookName"] + w.MessageParam["ModelName"]
+		if w.MessageParam["ActionName"] != "" {
+			param = w.MessageParam["ActionName"] + " " + w.MessageParam["ModelName"]
+		}
+		got = append(got, warning{w.ID, param})
+		assert.Equal(t, "PROJ", w.Project.Key)
+	}
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].id != got[j].id {
+			return got[i].id < got[j].id
+		}
+		return got[i].param < got[j].param
+	})
+
+	assert.Equal(t, []warning{
+		{NoWorkerModelMatchRequirement, "compile "},
+		{WorkflowProjectVariableDoesNotExist, "build missing"},
+		{WorkflowEnvironmentVariableDoesNotExist, "deploy url"},
+		{WorkflowNodeUnreachable, "after-deploy "},
+		{WorkflowNodeUnreachable, "deploy "},
+		{WorkflowNodeUnreachable, "orphan "},
+		{WorkflowConditionOnUnknownVariable, "deploy cds.proj.unknown"},
+		{WorkflowHookWithoutRepository, "build RepositoryWebHook"},
+		{WorkerModelDoesNotExist, "push unknown-model"},
+	}, got)
+}
+
+func TestContradictoryConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []sdk.WorkflowNodeCondition
+		want       bool
+	}{
+		{
+			name: "compatible",
+			conditions: []sdk.WorkflowNodeCondition{
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "master"},
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "master"},
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorNotEquals, Value: "develop"},
+			},
+		},
+		{
+			name: "two values",
+			conditions: []sdk.WorkflowNodeCondition{
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "master"},
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "develop"},
+			},
+			want: true,
+		},
+		{
+			name: "equal and not equal",
+			conditions: []sdk.WorkflowNodeCondition{
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorNotEquals, Value: "master"},
+				{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "master"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, contradictoryConditions(tt.conditions) != "")
+		})
+	}
+}
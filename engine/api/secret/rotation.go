@@ -0,0 +1,201 @@
+package secret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// EncryptedColumn is a database column storing data encrypted with the secrets key.
+// If JSONField is set, the column is a JSON object and its field JSONField stores the encrypted data, base64 encoded
+type EncryptedColumn struct {
+	Table     string
+	IDColumn  string
+	Column    string
+	JSONField string
+}
+
+// String returns the name of the column, with its JSON field
+func (c EncryptedColumn) String() string {
+	if c.JSONField != "" {
+		return c.Table + "." + c.Column + "." + c.JSONField
+	}
+	return c.Table + "." + c.Column
+}
+
+// EncryptedColumns lists the columns re-encrypted after a key rotation.
+// The encrypted columns of the tables mapped with gorp must also be declared on their mapping
+var EncryptedColumns = []EncryptedColumn{
+	{Table: "project", IDColumn: "id", Column: "vcs_servers"},
+	{Table: "project_variable", IDColumn: "id", Column: "cipher_value"},
+	{Table: "application_variable", IDColumn: "id", Column: "cipher_value"},
+	{Table: "environment_variable", IDColumn: "id", Column: "cipher_value"},
+	{Table: "project_variable_audit", IDColumn: "id", Column: "variable_before", JSONField: "value"},
+	{Table: "project_variable_audit", IDColumn: "id", Column: "variable_after", JSONField: "value"},
+	{Table: "application_variable_audit", IDColumn: "id", Column: "variable_before", JSONField: "value"},
+	{Table: "application_variable_audit", IDColumn: "id", Column: "variable_after", JSONField: "value"},
+	{Table: "environment_variable_audit", IDColumn: "id", Column: "variable_before", JSONField: "value"},
+	{Table: "environment_variable_audit", IDColumn: "id", Column: "variable_after", JSONField: "value"},
+	{Table: "application", IDColumn: "id", Column: "vcs_strategy", JSONField: "password"},
+	{Table: "project_key", IDColumn: "id", Column: "private"},
+	{Table: "application_key", IDColumn: "id", Column: "private"},
+	{Table: "environment_key", IDColumn: "id", Column: "private"},
+	{Table: "project_vault", IDColumn: "project_id", Column: "credential"},
+}
+
+// ReencryptProgress reports the progress of the re-encryption of a column
+type ReencryptProgress struct {
+	Column      EncryptedColumn
+	Total       int64
+	Done        int64
+	Reencrypted int64
+}
+
+// Reencrypt re-encrypts with the current key all the data of a column encrypted with a previous key.
+// Rows are processed in batches of batchSize rows, each batch in its own transaction; progress is called after each batch
+func Reencrypt(db *gorp.DbMap, c EncryptedColumn, batchSize int, progress func(ReencryptProgress)) error {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	p := ReencryptProgress{Column: c}
+	var err error
+	p.Total, err = db.SelectInt(fmt.Sprintf("SELECT COUNT(1) FROM %s WHERE %s IS NOT NULL", c.Table, c.Column))
+	if err != nil {
+		return sdk.WrapError(err, "Reencrypt> Cannot count rows of %s", c.Table)
+	}
+
+	var lastID int64
+	for {
+		n, last, reencrypted, err := reencryptBatch(db, c, lastID, batchSize)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		lastID = last
+		p.Done += int64(n)
+		p.Reencrypted += int64(reencrypted)
+		if progress != nil {
+			progress(p)
+		}
+	}
+}
+
+// reencryptBatch re-encrypts the rows following lastID. It returns the number of rows processed, the id of the last one and the number of rows re-encrypted
+func reencryptBatch(db *gorp.DbMap, c EncryptedColumn, lastID int64, batchSize int) (int, int64, int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot start transaction")
+	}
+	defer tx.Rollback()
+
+	column := c.Column
+	if c.JSONField != "" {
+		column += "::text"
+	}
+	query := fmt.Sprintf(`SELECT %[2]s, %[4]s FROM %[1]s WHERE %[3]s IS NOT NULL AND %[2]s > $1 ORDER BY %[2]s LIMIT $2 FOR UPDATE`, c.Table, c.IDColumn, c.Column, column)
+	rows, err := tx.Query(query, lastID, batchSize)
+	if err != nil {
+		return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot load rows of %s", c.Table)
+	}
+
+	type row struct {
+		id   int64
+		data []byte
+	}
+	toUpdate := []row{}
+	var n int
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.data); err != nil {
+			rows.Close()
+			return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot scan row of %s", c.Table)
+		}
+		n++
+		lastID = r.id
+		if c.JSONField != "" {
+			if NeedReencrypt(jsonFieldData(r.data, c.JSONField)) {
+				toUpdate = append(toUpdate, r)
+			}
+		} else if NeedReencrypt(r.data) {
+			toUpdate = append(toUpdate, r)
+		}
+	}
+	rows.Close()
+
+	update := fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s = $2`, c.Table, c.Column, c.IDColumn)
+	for _, r := range toUpdate {
+		var value interface{}
+		var err error
+		if c.JSONField != "" {
+			value, err = reencryptJSONField(r.data, c.JSONField)
+		} else {
+			value, err = reencrypt(r.data)
+		}
+		if err != nil {
+			return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot re-encrypt %s %d", c, r.id)
+		}
+		if _, err := tx.Exec(update, value, r.id); err != nil {
+			return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot update %s %d", c.Table, r.id)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, 0, sdk.WrapError(err, "reencryptBatch> Cannot commit transaction")
+	}
+	return n, lastID, len(toUpdate), nil
+}
+
+// reencrypt decrypts data and encrypts them with the current key
+func reencrypt(data []byte) ([]byte, error) {
+	clear, err := Decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(clear)
+}
+
+// jsonFieldData returns the encrypted data of the field of a JSON object, nil if the field is not encrypted
+func jsonFieldData(data []byte, field string) []byte {
+	var o map[string]interface{}
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil
+	}
+	s, ok := o[field].(string)
+	if !ok {
+		return nil
+	}
+	d, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// reencryptJSONField re-encrypts the field of a JSON object with the current key, the other fields are kept as is
+func reencryptJSONField(data []byte, field string) (string, error) {
+	var o map[string]json.RawMessage
+	if err := json.Unmarshal(data, &o); err != nil {
+		return "", err
+	}
+	encrypted, err := reencrypt(jsonFieldData(data, field))
+	if err != nil {
+		return "", err
+	}
+	v, err := json.Marshal(base64.StdEncoding.EncodeToString(encrypted))
+	if err != nil {
+		return "", err
+	}
+	o[field] = v
+	b, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,52 @@
+package secret_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	_ "github.com/ovh/cds/engine/api/environment"
+	_ "github.com/ovh/cds/engine/api/pipeline"
+	_ "github.com/ovh/cds/engine/api/poller"
+	_ "github.com/ovh/cds/engine/api/project"
+	_ "github.com/ovh/cds/engine/api/scheduler"
+	"github.com/ovh/cds/engine/api/secret"
+	_ "github.com/ovh/cds/engine/api/services"
+	_ "github.com/ovh/cds/engine/api/user"
+	_ "github.com/ovh/cds/engine/api/worker"
+	_ "github.com/ovh/cds/engine/api/workflow"
+)
+
+// TestEncryptedColumnsMapping checks that all the encrypted fields of the gorp mappings are re-encrypted after a key rotation
+func TestEncryptedColumnsMapping(t *testing.T) {
+	assert.NotEmpty(t, gorpmapping.Mapping)
+
+	columns := map[string]bool{}
+	for _, c := range secret.EncryptedColumns {
+		columns[c.String()] = true
+	}
+
+	mapped := map[string]bool{}
+	for _, m := range gorpmapping.Mapping {
+		for _, f := range m.EncryptedFields {
+			c := secret.EncryptedColumn{Table: m.Name, Column: f}
+			if i := strings.Index(f, "."); i > 0 {
+				c.Column, c.JSONField = f[:i], f[i+1:]
+			}
+			mapped[c.String()] = true
+			assert.True(t, columns[c.String()], "encrypted field %s is not re-encrypted after a key rotation", c)
+		}
+	}
+
+	// the encrypted columns of the mapped tables must be declared on their mapping
+	for _, m := range gorpmapping.Mapping {
+		for _, c := range secret.EncryptedColumns {
+			if c.Table == m.Name {
+				assert.True(t, mapped[c.String()], "encrypted field %s is not declared on the mapping of %s", c, m.Name)
+			}
+		}
+	}
+}
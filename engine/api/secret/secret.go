@@ -24,9 +24,18 @@ const (
 	ckeySize  = 32
 )
 
+// DefaultKeyID identifies the key used when no key id is configured.
+// Data encrypted before key rotation was introduced are decrypted with this key
+const DefaultKeyID = "default"
+
 var (
-	key    []byte
-	prefix = "3DICC3It"
+	key   []byte
+	keyID = DefaultKeyID
+	// previousKeys are the keys used before a rotation, by key id. They are only used to decrypt data
+	previousKeys = map[string][]byte{}
+	prefix       = "3DICC3It"
+	// versionedPrefix marks data encrypted with a key id
+	versionedPrefix = "3DICC3v2"
 )
 
 type Secret struct {
@@ -35,9 +44,48 @@ type Secret struct {
 }
 
 // Init secrets: cipherKey
-// cipherKey is set from viper configuration
-func Init(cipherKey string) {
+// cipherKey is set from viper configuration, it is identified by cipherKeyID in the encrypted data.
+// oldKeys are the keys used before a rotation, by key id
+func Init(cipherKey, cipherKeyID string, oldKeys map[string]string) error {
+	if cipherKeyID == "" {
+		cipherKeyID = DefaultKeyID
+	}
+	if len(cipherKeyID) > 255 {
+		return fmt.Errorf("Invalid secret key id %s: it is too long", cipherKeyID)
+	}
+	if _, ok := oldKeys[cipherKeyID]; ok {
+		return fmt.Errorf("Invalid secret key id %s: it is already used by a previous key", cipherKeyID)
+	}
+
+	keys := make(map[string][]byte, len(oldKeys))
+	for id, k := range oldKeys {
+		if len(k) != ckeySize {
+			return fmt.Errorf("Invalid previous secret key %s. It should be %d bits (%d)", id, ckeySize, len(k))
+		}
+		keys[id] = []byte(k)
+	}
+
 	key = []byte(cipherKey)
+	keyID = cipherKeyID
+	previousKeys = keys
+	return nil
+}
+
+// ParseKeys parses a list of keys formatted as keyID1:key1,keyID2:key2
+func ParseKeys(s string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		t := strings.SplitN(kv, ":", 2)
+		if len(t) != 2 || t[0] == "" {
+			return nil, fmt.Errorf("Invalid secret key. It should be formatted as keyID:key")
+		}
+		keys[t[0]] = t[1]
+	}
+	return keys, nil
 }
 
 // Create new secret client
@@ -76,6 +124,7 @@ func (secret *Secret) GetFromVault(s string) (string, error) {
 
 // Encrypt data using aes+hmac algorithm
 // Init() must be called before any encryption
+// The id of the current key is written in the encrypted data, so that it can still be decrypted after a key rotation
 func Encrypt(data []byte) ([]byte, error) {
 	// Check key is ready
 	if key == nil {
@@ -96,27 +145,69 @@ func Encrypt(data []byte) ([]byte, error) {
 	// encrypt data
 	ct := make([]byte, len(data))
 	ctr.XORKeyStream(ct, data)
-	// add hmac
-	h := hmac.New(sha256.New, key[ckeySize:])
-	ct = append(nonce, ct...)
+	// prepend key id and nonce, then add hmac
+	header := append([]byte{byte(len(keyID))}, keyID...)
+	ct = append(append(header, nonce...), ct...)
+	h := hmac.New(sha256.New, macKey(key))
 	h.Write(ct)
 	ct = h.Sum(ct)
 
-	return append([]byte(prefix), ct...), nil
+	return append([]byte(versionedPrefix), ct...), nil
 }
 
 // Decrypt data using aes+hmac algorithm
 // Init() must be called before any decryption
+// Data are decrypted with the key they have been encrypted with, current or previous one
 func Decrypt(data []byte) ([]byte, error) {
-
-	if !strings.HasPrefix(string(data), prefix) {
+	switch {
+	case strings.HasPrefix(string(data), versionedPrefix):
+		return decryptVersioned(data[len(versionedPrefix):])
+	case strings.HasPrefix(string(data), prefix):
+		return decryptLegacy(data[len(prefix):])
+	default:
 		return data, nil
 	}
-	data = []byte(strings.TrimPrefix(string(data), prefix))
+}
 
-	if key == nil {
-		log.Error("Missing key, init failed?")
-		return nil, sdk.ErrSecretKeyFetchFailed
+// decryptVersioned decrypts data encrypted with a key id
+func decryptVersioned(data []byte) ([]byte, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0])+nonceSize+macSize {
+		log.Error("cannot decrypt secret, got invalid data")
+		return nil, sdk.ErrInvalidSecretFormat
+	}
+	idLen := int(data[0])
+	k, err := lookupKey(string(data[1 : 1+idLen]))
+	if err != nil {
+		return nil, err
+	}
+
+	// Split actual data and hmac
+	macStart := len(data) - macSize
+	tag := data[macStart:]
+	data = data[:macStart]
+	// check hmac
+	h := hmac.New(sha256.New, macKey(k))
+	h.Write(data)
+	if !hmac.Equal(h.Sum(nil), tag) {
+		return nil, fmt.Errorf("invalid hmac")
+	}
+	// uncipher data
+	data = data[1+idLen:]
+	c, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data)-nonceSize)
+	ctr := cipher.NewCTR(c, data[:nonceSize])
+	ctr.XORKeyStream(out, data[nonceSize:])
+	return out, nil
+}
+
+// decryptLegacy decrypts data encrypted before key rotation was introduced, with the default key
+func decryptLegacy(data []byte) ([]byte, error) {
+	k, err := lookupKey(DefaultKeyID)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(data) < (nonceSize + macSize) {
@@ -130,14 +221,14 @@ func Decrypt(data []byte) ([]byte, error) {
 	out := make([]byte, macStart-nonceSize)
 	data = data[:macStart]
 	// check hmac
-	h := hmac.New(sha256.New, key[ckeySize:])
+	h := hmac.New(sha256.New, k[ckeySize:])
 	h.Write(data)
 	mac := h.Sum(nil)
 	if !hmac.Equal(mac, tag) {
 		return nil, fmt.Errorf("invalid hmac")
 	}
 	// uncipher data
-	c, err := aes.NewCipher(key[:ckeySize])
+	c, err := aes.NewCipher(k[:ckeySize])
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +237,54 @@ func Decrypt(data []byte) ([]byte, error) {
 	return out, nil
 }
 
+// KeyID returns the id of the key used to encrypt data. It returns false if data are not encrypted
+func KeyID(data []byte) (string, bool) {
+	switch {
+	case strings.HasPrefix(string(data), versionedPrefix):
+		data = data[len(versionedPrefix):]
+		if len(data) < 1 || len(data) < 1+int(data[0]) {
+			return "", false
+		}
+		return string(data[1 : 1+int(data[0])]), true
+	case strings.HasPrefix(string(data), prefix):
+		return DefaultKeyID, true
+	default:
+		return "", false
+	}
+}
+
+// NeedReencrypt returns true if data are encrypted with a previous key, or without key id
+func NeedReencrypt(data []byte) bool {
+	if !strings.HasPrefix(string(data), versionedPrefix) {
+		return strings.HasPrefix(string(data), prefix)
+	}
+	id, ok := KeyID(data)
+	return ok && id != keyID
+}
+
+// lookupKey returns the key identified by id, current or previous one
+func lookupKey(id string) ([]byte, error) {
+	if key == nil {
+		log.Error("Missing key, init failed?")
+		return nil, sdk.ErrSecretKeyFetchFailed
+	}
+	if id == keyID {
+		return key, nil
+	}
+	k, ok := previousKeys[id]
+	if !ok {
+		log.Error("cannot decrypt secret, unknown key %s", id)
+		return nil, sdk.ErrSecretKeyFetchFailed
+	}
+	return k, nil
+}
+
+// macKey derives the hmac key from the cipher key
+func macKey(k []byte) []byte {
+	h := sha256.Sum256(append([]byte("cds-secret-hmac"), k...))
+	return h[:]
+}
+
 //DecryptVariable decrypts variable value using aes+hmac algorithm
 func DecryptVariable(v *sdk.Variable) error {
 	if !sdk.NeedPlaceholder(v.Type) {
@@ -2,9 +2,16 @@ package secret
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/ovh/cds/sdk"
 )
 
@@ -99,3 +106,115 @@ func TestDecryptS(t *testing.T) {
 	}
 
 }
+
+// encryptLegacy encrypts data as it was done before the key id was written in the encrypted data
+func encryptLegacy(t *testing.T, k, data []byte) []byte {
+	nonce := make([]byte, nonceSize)
+	c, err := aes.NewCipher(k)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %s", err)
+	}
+	ct := make([]byte, len(data))
+	cipher.NewCTR(c, nonce).XORKeyStream(ct, data)
+	h := hmac.New(sha256.New, k[ckeySize:])
+	ct = append(nonce, ct...)
+	h.Write(ct)
+	return append([]byte(prefix), h.Sum(ct)...)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey := "78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf"
+	newKey := "3dojuwevn94y7orh5e3t4ejtmbtstest"
+	data := []byte("Hello world !")
+
+	assert.NoError(t, Init(oldKey, "", nil))
+	legacy := encryptLegacy(t, key, data)
+	ctOld, err := Encrypt(data)
+	assert.NoError(t, err)
+	id, ok := KeyID(ctOld)
+	assert.True(t, ok)
+	assert.Equal(t, DefaultKeyID, id)
+	assert.True(t, NeedReencrypt(legacy))
+	assert.False(t, NeedReencrypt(ctOld))
+	assert.False(t, NeedReencrypt(data))
+
+	// Rotate the key
+	assert.NoError(t, Init(newKey, "v2", map[string]string{DefaultKeyID: oldKey}))
+	assert.True(t, NeedReencrypt(ctOld))
+
+	for _, ct := range [][]byte{legacy, ctOld} {
+		clear, err := Decrypt(ct)
+		assert.NoError(t, err)
+		assert.Equal(t, data, clear)
+	}
+
+	ctNew, err := Encrypt(data)
+	assert.NoError(t, err)
+	id, _ = KeyID(ctNew)
+	assert.Equal(t, "v2", id)
+	assert.False(t, NeedReencrypt(ctNew))
+	clear, err := Decrypt(ctNew)
+	assert.NoError(t, err)
+	assert.Equal(t, data, clear)
+
+	// Remove the previous key
+	assert.NoError(t, Init(newKey, "v2", nil))
+	_, err = Decrypt(ctOld)
+	assert.Error(t, err)
+	_, err = Decrypt(legacy)
+	assert.Error(t, err)
+
+	// Tampered data
+	ctNew[len(ctNew)-macSize-1] ^= 0xff
+	_, err = Decrypt(ctNew)
+	assert.Error(t, err)
+}
+
+func TestReencryptJSONField(t *testing.T) {
+	oldKey := "78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf"
+	newKey := "3dojuwevn94y7orh5e3t4ejtmbtstest"
+
+	assert.NoError(t, Init(oldKey, "", nil))
+	ct, err := Encrypt([]byte("my password"))
+	assert.NoError(t, err)
+	data := []byte(`{"connection_type": "https", "password": "` + base64.StdEncoding.EncodeToString(ct) + `"}`)
+	assert.False(t, NeedReencrypt(jsonFieldData(data, "password")))
+	assert.Nil(t, jsonFieldData(data, "connection_type"))
+	assert.Nil(t, jsonFieldData([]byte(`{"value": "clear value"}`), "value"))
+
+	assert.NoError(t, Init(newKey, "v2", map[string]string{DefaultKeyID: oldKey}))
+	assert.True(t, NeedReencrypt(jsonFieldData(data, "password")))
+
+	s, err := reencryptJSONField(data, "password")
+	assert.NoError(t, err)
+	assert.Contains(t, s, `"connection_type":"https"`)
+	reencrypted := jsonFieldData([]byte(s), "password")
+	id, _ := KeyID(reencrypted)
+	assert.Equal(t, "v2", id)
+	clear, err := Decrypt(reencrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "my password", string(clear))
+}
+
+func TestInitErrors(t *testing.T) {
+	k := "78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf"
+	assert.Error(t, Init(k, "v2", map[string]string{"v2": k}))
+	assert.Error(t, Init(k, "v2", map[string]string{"v1": "tooshort"}))
+	assert.NoError(t, Init(k, "v2", map[string]string{"v1": k}))
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys("v1:78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf, v2:3dojuwevn94y7orh5e3t4ejtmbtstest")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"v1": "78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf",
+		"v2": "3dojuwevn94y7orh5e3t4ejtmbtstest",
+	}, keys)
+
+	keys, err = ParseKeys("")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 0)
+
+	_, err = ParseKeys("78eKVxCGLm6gwoH9LAQ15ZD5AOABo1Xf")
+	assert.Error(t, err)
+}
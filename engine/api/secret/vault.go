@@ -0,0 +1,214 @@
+package secret
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	vault "github.com/hashicorp/vault/api"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadProjectVault loads the vault configuration of a project.
+// The credential is replaced by a placeholder unless clear is true
+func LoadProjectVault(db gorp.SqlExecutor, projectID int64, clear bool) (*sdk.ProjectVault, error) {
+	v := sdk.ProjectVault{ProjectID: projectID}
+	var roleID sql.NullString
+	var credential []byte
+	query := `SELECT address, auth_method, role_id, credential FROM project_vault WHERE project_id = $1`
+	if err := db.QueryRow(query, projectID).Scan(&v.Address, &v.AuthMethod, &roleID, &credential); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sdk.ErrProjectVaultNotFound
+		}
+		return nil, sdk.WrapError(err, "LoadProjectVault> Cannot load vault of project %d", projectID)
+	}
+	v.RoleID = roleID.String
+
+	if !clear {
+		v.SetCredential(sdk.PasswordPlaceholder)
+		return &v, nil
+	}
+
+	c, err := Decrypt(credential)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadProjectVault> Cannot decrypt vault credential of project %d", projectID)
+	}
+	v.SetCredential(string(c))
+	return &v, nil
+}
+
+// UpsertProjectVault saves the vault configuration of a project.
+// If the credential is a placeholder, the current credential is kept
+func UpsertProjectVault(db gorp.SqlExecutor, v *sdk.ProjectVault) error {
+	if v.Credential() == sdk.PasswordPlaceholder {
+		old, err := LoadProjectVault(db, v.ProjectID, true)
+		if err != nil {
+			return sdk.WrapError(err, "UpsertProjectVault> Cannot load current vault of project %d", v.ProjectID)
+		}
+		if old.AuthMethod != v.AuthMethod {
+			return sdk.WrapError(sdk.ErrWrongRequest, "UpsertProjectVault> Credential is mandatory when changing the authentication method")
+		}
+		v.SetCredential(old.Credential())
+	}
+
+	credential, err := Encrypt([]byte(v.Credential()))
+	if err != nil {
+		return sdk.WrapError(err, "UpsertProjectVault> Cannot encrypt vault credential")
+	}
+
+	if err := DeleteProjectVault(db, v.ProjectID); err != nil {
+		return err
+	}
+	query := `INSERT INTO project_vault (project_id, address, auth_method, role_id, credential) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := db.Exec(query, v.ProjectID, v.Address, v.AuthMethod, v.RoleID, credential); err != nil {
+		return sdk.WrapError(err, "UpsertProjectVault> Cannot insert vault of project %d", v.ProjectID)
+	}
+	return nil
+}
+
+// DeleteProjectVault deletes the vault configuration of a project
+func DeleteProjectVault(db gorp.SqlExecutor, projectID int64) error {
+	if _, err := db.Exec(`DELETE FROM project_vault WHERE project_id = $1`, projectID); err != nil {
+		return sdk.WrapError(err, "DeleteProjectVault> Cannot delete vault of project %d", projectID)
+	}
+	return nil
+}
+
+// vaultToken is a token returned by an AppRole login, kept until its expiration
+type vaultToken struct {
+	token   string
+	expires time.Time
+}
+
+var (
+	vaultTokensMutex sync.Mutex
+	vaultTokens      = map[string]vaultToken{}
+)
+
+// vaultTokenKey identifies the AppRole credential of a vault configuration, a change of credential misses the cache
+func vaultTokenKey(v sdk.ProjectVault) string {
+	h := sha256.Sum256([]byte(v.Address + "\n" + v.RoleID + "\n" + v.SecretID))
+	return hex.EncodeToString(h[:])
+}
+
+func getVaultToken(v sdk.ProjectVault) (string, bool) {
+	vaultTokensMutex.Lock()
+	defer vaultTokensMutex.Unlock()
+	k := vaultTokenKey(v)
+	t, ok := vaultTokens[k]
+	if !ok {
+		return "", false
+	}
+	if !t.expires.IsZero() && time.Now().After(t.expires) {
+		delete(vaultTokens, k)
+		return "", false
+	}
+	return t.token, true
+}
+
+func setVaultToken(v sdk.ProjectVault, token string, leaseDuration int) {
+	t := vaultToken{token: token}
+	// The token is dropped a bit before its expiration so that it is not used while it expires
+	if leaseDuration > 0 {
+		ttl := time.Duration(leaseDuration) * time.Second
+		t.expires = time.Now().Add(ttl - ttl/10)
+	}
+	vaultTokensMutex.Lock()
+	defer vaultTokensMutex.Unlock()
+	vaultTokens[vaultTokenKey(v)] = t
+}
+
+// ForgetProjectVaultToken drops the token of the AppRole login of a project, to login again on the next client
+// if the token has been revoked
+func ForgetProjectVaultToken(v sdk.ProjectVault) {
+	vaultTokensMutex.Lock()
+	defer vaultTokensMutex.Unlock()
+	delete(vaultTokens, vaultTokenKey(v))
+}
+
+// NewProjectVaultClient returns a vault client authenticated with the configuration of a project.
+// The token of an AppRole login is reused until its expiration
+func NewProjectVaultClient(v sdk.ProjectVault) (*vault.Client, error) {
+	client, err := vault.NewClient(vault.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetAddress(v.Address); err != nil {
+		return nil, err
+	}
+
+	switch v.AuthMethod {
+	case sdk.VaultAuthToken:
+		client.SetToken(v.Token)
+	case sdk.VaultAuthAppRole:
+		if token, ok := getVaultToken(v); ok {
+			client.SetToken(token)
+			return client, nil
+		}
+		auth, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
+			"role_id":   v.RoleID,
+			"secret_id": v.SecretID,
+		})
+		if err != nil {
+			return nil, sdk.WrapError(err, "NewProjectVaultClient> Cannot login with AppRole on %s", v.Address)
+		}
+		if auth == nil || auth.Auth == nil || auth.Auth.ClientToken == "" {
+			return nil, fmt.Errorf("NewProjectVaultClient> No token returned by AppRole login on %s", v.Address)
+		}
+		setVaultToken(v, auth.Auth.ClientToken, auth.Auth.LeaseDuration)
+		client.SetToken(auth.Auth.ClientToken)
+	default:
+		return nil, fmt.Errorf("NewProjectVaultClient> Unknown vault authentication method %s", v.AuthMethod)
+	}
+	return client, nil
+}
+
+// ResolveVaultVariables reads the secrets referenced by vault variables.
+// The resolved variables are returned as password variables, their values are never stored
+func ResolveVaultVariables(client *vault.Client, vars []sdk.Variable) ([]sdk.Variable, error) {
+	res := make([]sdk.Variable, 0, len(vars))
+	// Several variables may reference the same secret
+	secrets := map[string]map[string]interface{}{}
+	for _, v := range vars {
+		path, field, err := sdk.ParseVaultReference(v.Value)
+		if err != nil {
+			return nil, sdk.WrapError(err, "ResolveVaultVariables> Invalid variable %s", v.Name)
+		}
+
+		data, ok := secrets[path]
+		if !ok {
+			s, err := client.Logical().Read(path)
+			if err != nil {
+				return nil, sdk.WrapError(err, "ResolveVaultVariables> Cannot read %s for variable %s", path, v.Name)
+			}
+			if s == nil {
+				return nil, fmt.Errorf("ResolveVaultVariables> No secret found at %s for variable %s", path, v.Name)
+			}
+			data = s.Data
+			// Secrets from a kv version 2 engine are wrapped in a data field
+			if wrapped, isWrapped := data["data"].(map[string]interface{}); isWrapped {
+				if _, hasMetadata := data["metadata"]; hasMetadata {
+					data = wrapped
+				}
+			}
+			secrets[path] = data
+		}
+
+		value, ok := data[field]
+		if !ok {
+			return nil, fmt.Errorf("ResolveVaultVariables> No field %s found at %s for variable %s", field, path, v.Name)
+		}
+		res = append(res, sdk.Variable{
+			ID:    v.ID,
+			Name:  v.Name,
+			Type:  sdk.SecretVariable,
+			Value: fmt.Sprintf("%v", value),
+		})
+	}
+	return res, nil
+}
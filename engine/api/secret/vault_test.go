@@ -0,0 +1,148 @@
+package secret
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// newDevVault starts a stand-in of a vault dev server, with a kv version 2 engine mounted on secret/
+// and a kv version 1 engine mounted on kv/. The AppRole logins are counted in logins
+func newDevVault(t *testing.T, logins *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/auth/approle/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body["role_id"] != "my-role" || body["secret_id"] != "my-secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["invalid secret id"]}`))
+			return
+		}
+		if logins != nil {
+			*logins++
+		}
+		w.Write([]byte(`{"auth":{"client_token":"approle-token","lease_duration":3600}}`))
+	})
+	mux.HandleFunc("/v1/secret/data/myapp", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("X-Vault-Token")
+		if token != "root-token" && token != "approle-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"password":"p4ssw0rd","user":"admin"},"metadata":{"version":1}}}`))
+	})
+	mux.HandleFunc("/v1/kv/legacy", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"token":"legacy-token"}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestResolveVaultVariables(t *testing.T) {
+	srv := newDevVault(t, nil)
+	defer srv.Close()
+
+	vars := []sdk.Variable{
+		{Name: "cds.proj.db.password", Type: sdk.VaultVariable, Value: "secret/data/myapp#password"},
+		{Name: "cds.app.db.user", Type: sdk.VaultVariable, Value: "secret/data/myapp#user"},
+		{Name: "cds.env.token", Type: sdk.VaultVariable, Value: "/kv/legacy#token"},
+	}
+
+	tests := []struct {
+		name  string
+		vault sdk.ProjectVault
+	}{
+		{
+			name:  "token",
+			vault: sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthToken, Token: "root-token"},
+		},
+		{
+			name:  "approle",
+			vault: sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthAppRole, RoleID: "my-role", SecretID: "my-secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewProjectVaultClient(tt.vault)
+			assert.NoError(t, err)
+
+			res, err := ResolveVaultVariables(client, vars)
+			assert.NoError(t, err)
+			assert.Equal(t, []sdk.Variable{
+				{Name: "cds.proj.db.password", Type: sdk.SecretVariable, Value: "p4ssw0rd"},
+				{Name: "cds.app.db.user", Type: sdk.SecretVariable, Value: "admin"},
+				{Name: "cds.env.token", Type: sdk.SecretVariable, Value: "legacy-token"},
+			}, res)
+		})
+	}
+}
+
+func TestResolveVaultVariablesErrors(t *testing.T) {
+	srv := newDevVault(t, nil)
+	defer srv.Close()
+
+	_, err := NewProjectVaultClient(sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthAppRole, RoleID: "my-role", SecretID: "wrong"})
+	assert.Error(t, err)
+
+	client, err := NewProjectVaultClient(sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthToken, Token: "wrong"})
+	assert.NoError(t, err)
+	_, err = ResolveVaultVariables(client, []sdk.Variable{{Name: "password", Type: sdk.VaultVariable, Value: "secret/data/myapp#password"}})
+	assert.Error(t, err, "permission denied")
+
+	client, err = NewProjectVaultClient(sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthToken, Token: "root-token"})
+	assert.NoError(t, err)
+	_, err = ResolveVaultVariables(client, []sdk.Variable{{Name: "password", Type: sdk.VaultVariable, Value: "secret/data/myapp#unknown"}})
+	assert.Error(t, err, "unknown field")
+	_, err = ResolveVaultVariables(client, []sdk.Variable{{Name: "password", Type: sdk.VaultVariable, Value: "secret/data/unknown#password"}})
+	assert.Error(t, err, "unknown secret")
+	_, err = ResolveVaultVariables(client, []sdk.Variable{{Name: "password", Type: sdk.VaultVariable, Value: "secret/data/myapp"}})
+	assert.Error(t, err, "invalid reference")
+}
+
+func TestNewProjectVaultClientCachesAppRoleToken(t *testing.T) {
+	var logins int
+	srv := newDevVault(t, &logins)
+	defer srv.Close()
+
+	v := sdk.ProjectVault{Address: srv.URL, AuthMethod: sdk.VaultAuthAppRole, RoleID: "my-role", SecretID: "my-secret"}
+	defer ForgetProjectVaultToken(v)
+	for i := 0; i < 3; i++ {
+		client, err := NewProjectVaultClient(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "approle-token", client.Token())
+	}
+	assert.Equal(t, 1, logins)
+
+	ForgetProjectVaultToken(v)
+	_, err := NewProjectVaultClient(v)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, logins)
+
+	vaultTokens[vaultTokenKey(v)] = vaultToken{token: "expired-token", expires: time.Now().Add(-time.Second)}
+	client, err := NewProjectVaultClient(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "approle-token", client.Token())
+	assert.Equal(t, 3, logins)
+}
+
+func TestParseVaultReference(t *testing.T) {
+	path, field, err := sdk.ParseVaultReference("/secret/data/myapp#password")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret/data/myapp", path)
+	assert.Equal(t, "password", field)
+
+	for _, ref := range []string{"", "secret/data/myapp", "#password", "secret/data/myapp#"} {
+		_, _, err := sdk.ParseVaultReference(ref)
+		assert.Error(t, err, ref)
+	}
+}
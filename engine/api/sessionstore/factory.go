@@ -10,8 +10,13 @@ import (
 //Status for session store
 var Status sdk.MonitoringStatusLine
 
-//Get is a factory
+//Get is a factory, it returns an in memory store without redis host
 func Get(c context.Context, redisHost, redisPassword string, ttl int) (Store, error) {
+	if redisHost == "" {
+		Status = sdk.MonitoringStatusLine{Component: "Sessions-Store", Value: "Local", Status: sdk.MonitoringStatusOK}
+		return NewLocal(c, ttl), nil
+	}
+
 	r, err := NewRedis(c, redisHost, redisPassword, ttl)
 	if err != nil {
 		log.Error("sessionstore.factory> unable to connect to redis %s : %s", redisHost, err)
@@ -0,0 +1,144 @@
+package sessionstore
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+//Local is an in memory session store, for a CDS made of a unique API instance
+type Local struct {
+	sync.Mutex
+	ttl      int
+	sessions map[SessionKey]*localSession
+}
+
+type localSession struct {
+	expire time.Time
+	data   map[string][]byte
+}
+
+//NewLocal creates a ready to use in memory store
+func NewLocal(c context.Context, ttl int) *Local {
+	log.Info("Local> Store ready")
+	localStore := &Local{ttl: ttl * 1440, sessions: map[SessionKey]*localSession{}}
+	go localStore.vacuumCleaner(c)
+	return localStore
+}
+
+//Remove the expired sessions which are not used anymore
+func (s *Local) vacuumCleaner(c context.Context) {
+	tick := time.NewTicker(5 * time.Minute).C
+	for {
+		select {
+		case <-c.Done():
+			if c.Err() != nil {
+				log.Error("Exiting sessionstore.vacuumCleaner: %v", c.Err())
+				return
+			}
+		case <-tick:
+			s.vacuum(time.Now())
+		}
+	}
+}
+
+// vacuum removes the sessions expired at t
+func (s *Local) vacuum(t time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	for token, sess := range s.sessions {
+		if t.After(sess.expire) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// session returns a valid session and extends its expiration, expired sessions are removed
+func (s *Local) session(token SessionKey) *localSession {
+	sess, ok := s.sessions[token]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(sess.expire) {
+		delete(s.sessions, token)
+		return nil
+	}
+	sess.expire = time.Now().Add(time.Duration(s.ttl) * time.Minute)
+	return sess
+}
+
+//New creates a new session
+func (s *Local) New(k SessionKey) (SessionKey, error) {
+	token := k
+	if token == "" {
+		var err error
+		token, err = NewSessionKey()
+		if err != nil {
+			log.Error("Local> unable to generate session key : %s", err)
+			return "", err
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.sessions[token] = &localSession{
+		expire: time.Now().Add(time.Duration(s.ttl) * time.Minute),
+		data:   map[string][]byte{},
+	}
+	return token, nil
+}
+
+//Exists check if session exists
+func (s *Local) Exists(token SessionKey) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.session(token) != nil, nil
+}
+
+//Set set a value in session with a key
+func (s *Local) Set(token SessionKey, f string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return sdk.WrapError(err, "Local> error marshal %s %s", token, f)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	sess := s.session(token)
+	if sess == nil {
+		return sdk.ErrSessionNotFound
+	}
+	sess.data[f] = b
+	return nil
+}
+
+//Get returns the value corresponding to key for the session
+func (s *Local) Get(token SessionKey, f string, data interface{}) error {
+	s.Lock()
+	sess := s.session(token)
+	if sess == nil {
+		s.Unlock()
+		return sdk.ErrSessionNotFound
+	}
+	b, ok := sess.data[f]
+	s.Unlock()
+
+	if ok {
+		if err := json.Unmarshal(b, data); err != nil {
+			return sdk.WrapError(err, "Local> Cannot unmarshal %s %s", token, f)
+		}
+	}
+	return nil
+}
+
+//Delete delete a session
+func (s *Local) Delete(token SessionKey) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.sessions, token)
+	return nil
+}
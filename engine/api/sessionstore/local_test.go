@@ -0,0 +1,33 @@
+package sessionstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalVacuum(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewLocal(ctx, 1)
+	expired, err := s.New("")
+	assert.NoError(t, err)
+	valid, err := s.New("")
+	assert.NoError(t, err)
+
+	s.Lock()
+	s.sessions[expired].expire = time.Now().Add(-time.Minute)
+	s.Unlock()
+
+	s.vacuum(time.Now())
+	s.Lock()
+	assert.Len(t, s.sessions, 1)
+	s.Unlock()
+
+	exists, err := s.Exists(valid)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
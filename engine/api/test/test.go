@@ -59,7 +59,7 @@ func SetupPG(t *testing.T, bootstrapFunc ...Bootstrapf) (*gorp.DbMap, cache.Stor
 	RedisHost = cfg["redisHost"]
 	RedisPassword = cfg["redisPassword"]
 
-	secret.Init("3dojuwevn94y7orh5e3t4ejtmbtstest")
+	secret.Init("3dojuwevn94y7orh5e3t4ejtmbtstest", secret.DefaultKeyID, nil)
 
 	if DBDriver == "" {
 		t.Fatalf("This should be run with a database")
@@ -81,7 +81,8 @@ func SetupPG(t *testing.T, bootstrapFunc ...Bootstrapf) (*gorp.DbMap, cache.Stor
 		}
 	}
 
-	store, err := cache.NewRedisStore(RedisHost, RedisPassword, 60)
+	// without redis host, the tests use a local cache
+	store, err := cache.New(RedisHost, RedisPassword, 60)
 	if err != nil {
 		t.Fatalf("Unable to connect to redis: %v", err)
 	}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/fsamin/go-dump"
 	"github.com/go-gorp/gorp"
@@ -326,3 +328,47 @@ func (api *API) getWorkflowHookHandler() Handler {
 		return nil
 	}
 }
+
+// getWorkflowHookNextExecutionsHandler returns the dates of the next executions of a scheduler hook
+func (api *API) getWorkflowHookNextExecutionsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+		uuid := vars["uuid"]
+
+		count := 10
+		if c := r.FormValue("count"); c != "" {
+			var err error
+			count, err = strconv.Atoi(c)
+			if err != nil || count < 1 || count > 100 {
+				return sdk.WrapError(sdk.ErrWrongRequest, "getWorkflowHookNextExecutionsHandler> count: invalid number, it should be between 1 and 100")
+			}
+		}
+
+		wf, errW := workflow.Load(api.mustDB(), api.Cache, key, name, getUser(ctx), workflow.LoadOptions{})
+		if errW != nil {
+			return sdk.WrapError(errW, "getWorkflowHookNextExecutionsHandler> Cannot load Workflow %s/%s", key, name)
+		}
+
+		h, has := wf.GetHooks()[uuid]
+		if !has {
+			return sdk.WrapError(sdk.ErrNotFound, "getWorkflowHookNextExecutionsHandler> Cannot load Workflow %s/%s hook %s", key, name, uuid)
+		}
+		if h.WorkflowHookModel.Name != sdk.SchedulerModelName {
+			return sdk.WrapError(sdk.ErrWrongRequest, "getWorkflowHookNextExecutionsHandler> Hook %s is not a scheduler", uuid)
+		}
+
+		conf, err := sdk.ParseSchedulerHookConfig(h.Config)
+		if err != nil {
+			return sdk.WrapError(sdk.NewError(sdk.ErrInvalidHookConfiguration, err), "getWorkflowHookNextExecutionsHandler> Invalid scheduler configuration")
+		}
+
+		preview := sdk.SchedulerHookPreview{
+			Timezone:   conf.Location.String(),
+			Jitter:     conf.Jitter.String(),
+			Executions: conf.NextExecutions(time.Now(), count),
+		}
+		return WriteJSON(w, r, preview, http.StatusOK)
+	}
+}
@@ -72,11 +72,12 @@ func (w *Workflow) PostInsert(db gorp.SqlExecutor) error {
 // PostGet is a db hook
 func (w *Workflow) PostGet(db gorp.SqlExecutor) error {
 	var res = struct {
-		Metadata  sql.NullString `db:"metadata"`
-		PurgeTags sql.NullString `db:"purge_tags"`
+		Metadata      sql.NullString `db:"metadata"`
+		PurgeTags     sql.NullString `db:"purge_tags"`
+		RunParameters sql.NullString `db:"run_parameters"`
 	}{}
 
-	if err := db.SelectOne(&res, "SELECT metadata, purge_tags FROM workflow WHERE id = $1", w.ID); err != nil {
+	if err := db.SelectOne(&res, "SELECT metadata, purge_tags, run_parameters FROM workflow WHERE id = $1", w.ID); err != nil {
 		return sdk.WrapError(err, "PostGet> Unable to load marshalled workflow")
 	}
 
@@ -92,6 +93,12 @@ func (w *Workflow) PostGet(db gorp.SqlExecutor) error {
 	}
 	w.PurgeTags = purgeTags
 
+	runParameters := sdk.WorkflowParametersSchema{}
+	if err := gorpmapping.JSONNullString(res.RunParameters, &runParameters); err != nil {
+		return err
+	}
+	w.RunParameters = runParameters
+
 	return nil
 }
 
@@ -113,6 +120,14 @@ func (w *Workflow) PostUpdate(db gorp.SqlExecutor) error {
 		return err
 	}
 
+	rp, errRp := json.Marshal(w.RunParameters)
+	if errRp != nil {
+		return errRp
+	}
+	if _, err := db.Exec("update workflow set run_parameters = $1 where id = $2", rp, w.ID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -573,6 +588,14 @@ func Delete(db gorp.SqlExecutor, store cache.Store, p *sdk.Project, w *sdk.Workf
 		return sdk.WrapError(err, "Delete> Unable to delete workflow root")
 	}
 
+	// The runs are deleted by cascade with the workflow, the objects of their logs are deleted afterwards
+	nodeRunsQuery := `SELECT workflow_node_run.id FROM workflow_node_run
+		JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+		WHERE workflow_run.workflow_id = $1`
+	if err := insertObsoleteNodeRunsLogObjects(db, nodeRunsQuery, w.ID); err != nil {
+		return sdk.WrapError(err, "Delete> Unable to record obsolete log objects")
+	}
+
 	//Delete workflow
 	dbw := Workflow(*w)
 	if _, err := db.Delete(&dbw); err != nil {
@@ -623,6 +646,24 @@ func IsValid(w *sdk.Workflow, proj *sdk.Project) error {
 		}
 	}
 
+	//Check run parameters definitions
+	if err := w.RunParameters.IsValid(); err != nil {
+		return sdk.NewError(sdk.ErrWorkflowInvalid, err)
+	}
+
+	//Check approval rules and concurrency groups
+	for _, n := range w.Nodes(true) {
+		if n.Context == nil {
+			continue
+		}
+		if err := n.Context.Approval.IsValid(); err != nil {
+			return sdk.NewError(sdk.ErrWorkflowInvalid, fmt.Errorf("Invalid approval rule on node %s: %v", n.Name, err))
+		}
+		if err := n.Context.Concurrency.IsValid(); err != nil {
+			return sdk.NewError(sdk.ErrWorkflowInvalid, fmt.Errorf("Invalid concurrency group on node %s: %v", n.Name, err))
+		}
+	}
+
 	//Check refs
 	for _, j := range w.Joins {
 		if len(j.SourceNodeRefs) == 0 {
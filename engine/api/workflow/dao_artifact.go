@@ -1,8 +1,11 @@
 package workflow
 
 import (
+	"database/sql"
+
 	"github.com/go-gorp/gorp"
 
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
 	"github.com/ovh/cds/sdk"
 )
 
@@ -59,3 +62,29 @@ func InsertArtifact(db gorp.SqlExecutor, a *sdk.WorkflowNodeRunArtifact) error {
 	a.ID = wArtifactDB.ID
 	return nil
 }
+
+//PostInsert is a db hook on NodeRunArtifact
+//It stores the metadata of the artifact in JSONB column metadata
+func (a *NodeRunArtifact) PostInsert(db gorp.SqlExecutor) error {
+	m, err := gorpmapping.JSONToNullString(a.Metadata)
+	if err != nil {
+		return sdk.WrapError(err, "NodeRunArtifact.PostInsert> Unable to marshal metadata")
+	}
+	if _, err := db.Exec("UPDATE workflow_node_run_artifacts SET metadata = $2 WHERE id = $1", a.ID, m); err != nil {
+		return sdk.WrapError(err, "NodeRunArtifact.PostInsert> Unable to store metadata")
+	}
+	return nil
+}
+
+//PostGet is a db hook on NodeRunArtifact
+//It loads the metadata of the artifact from JSONB column metadata
+func (a *NodeRunArtifact) PostGet(db gorp.SqlExecutor) error {
+	var m sql.NullString
+	if err := db.QueryRow("SELECT metadata FROM workflow_node_run_artifacts WHERE id = $1", a.ID).Scan(&m); err != nil {
+		return sdk.WrapError(err, "NodeRunArtifact.PostGet> Unable to load metadata")
+	}
+	if err := gorpmapping.JSONNullString(m, &a.Metadata); err != nil {
+		return sdk.WrapError(err, "NodeRunArtifact.PostGet> Unable to unmarshal metadata")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// LoadProjectArtifactSettings loads the artifact settings of a project, the default settings if the project has none
+func LoadProjectArtifactSettings(db gorp.SqlExecutor, projectID int64) (*sdk.ProjectArtifactSettings, error) {
+	s := &sdk.ProjectArtifactSettings{ProjectID: projectID}
+	query := `SELECT retention_days, tagged_only, keep_releases FROM project_artifact_settings WHERE project_id = $1`
+	if err := db.QueryRow(query, projectID).Scan(&s.RetentionDays, &s.TaggedOnly, &s.KeepReleases); err != nil && err != sql.ErrNoRows {
+		return nil, sdk.WrapError(err, "LoadProjectArtifactSettings> Cannot load artifact settings of project %d", projectID)
+	}
+	return s, nil
+}
+
+// UpsertProjectArtifactSettings inserts or updates the artifact settings of a project
+func UpsertProjectArtifactSettings(db gorp.SqlExecutor, s sdk.ProjectArtifactSettings) error {
+	query := `UPDATE project_artifact_settings SET retention_days = $2, tagged_only = $3, keep_releases = $4 WHERE project_id = $1`
+	res, err := db.Exec(query, s.ProjectID, s.RetentionDays, s.TaggedOnly, s.KeepReleases)
+	if err != nil {
+		return sdk.WrapError(err, "UpsertProjectArtifactSettings> Cannot update artifact settings of project %d", s.ProjectID)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	query = `INSERT INTO project_artifact_settings (project_id, retention_days, tagged_only, keep_releases) VALUES ($1, $2, $3, $4)`
+	if _, err := db.Exec(query, s.ProjectID, s.RetentionDays, s.TaggedOnly, s.KeepReleases); err != nil {
+		return sdk.WrapError(err, "UpsertProjectArtifactSettings> Cannot insert artifact settings of project %d", s.ProjectID)
+	}
+	return nil
+}
+
+// DeleteProjectArtifactSettings deletes the artifact settings of a project, the artifacts are kept with their
+// workflow run
+func DeleteProjectArtifactSettings(db gorp.SqlExecutor, projectID int64) error {
+	if _, err := db.Exec(`DELETE FROM project_artifact_settings WHERE project_id = $1`, projectID); err != nil {
+		return sdk.WrapError(err, "DeleteProjectArtifactSettings> Cannot delete artifact settings of project %d", projectID)
+	}
+	return nil
+}
+
+// purgeArtifacts deletes the artifacts which are not kept by the retention rules of their project: the artifacts
+// older than the retention, and the artifacts of the runs over without the tag to keep. The artifacts of the runs
+// which made a release are kept forever if the project keeps the releases
+func purgeArtifacts(db *gorp.DbMap) error {
+	query := `SELECT workflow_node_run_artifacts.*
+		FROM workflow_node_run_artifacts
+		JOIN workflow_run ON workflow_run.id = workflow_node_run_artifacts.workflow_run_id
+		JOIN project_artifact_settings ON project_artifact_settings.project_id = workflow_run.project_id
+		WHERE NOT (
+			project_artifact_settings.keep_releases
+			AND EXISTS (SELECT 1 FROM workflow_run_tag WHERE workflow_run_tag.workflow_run_id = workflow_run.id AND workflow_run_tag.tag = $1)
+		)
+		AND (
+			(
+				project_artifact_settings.retention_days > 0
+				AND workflow_node_run_artifacts.created < now() - project_artifact_settings.retention_days * interval '1 day'
+			)
+			OR (
+				project_artifact_settings.tagged_only <> ''
+				AND workflow_run.status IN ($2, $3, $4)
+				AND NOT EXISTS (SELECT 1 FROM workflow_run_tag WHERE workflow_run_tag.workflow_run_id = workflow_run.id AND workflow_run_tag.tag = project_artifact_settings.tagged_only)
+			)
+		)
+		LIMIT 1000`
+	for {
+		var artsGorp []NodeRunArtifact
+		if _, err := db.Select(&artsGorp, query, sdk.WorkflowRunTagRelease, sdk.StatusSuccess.String(), sdk.StatusFail.String(), sdk.StatusStopped.String()); err != nil {
+			return sdk.WrapError(err, "purgeArtifacts> Cannot load artifacts")
+		}
+		if len(artsGorp) == 0 {
+			return nil
+		}
+		for i := range artsGorp {
+			a := sdk.WorkflowNodeRunArtifact(artsGorp[i])
+			if err := deleteArtifact(db, &a); err != nil {
+				return err
+			}
+		}
+		log.Debug("purgeArtifacts> %d artifacts deleted", len(artsGorp))
+	}
+}
+
+// deleteArtifact deletes an artifact and its stored object, unless the object is shared with the copy of the
+// artifact in a resumed node run. The references to external resources have no stored object
+func deleteArtifact(db gorp.SqlExecutor, a *sdk.WorkflowNodeRunArtifact) error {
+	if _, err := db.Exec(`DELETE FROM workflow_node_run_artifacts WHERE id = $1`, a.ID); err != nil {
+		return sdk.WrapError(err, "deleteArtifact> Cannot delete artifact %d", a.ID)
+	}
+	if a.IsReference() {
+		return nil
+	}
+
+	// the artifacts sharing the stored object have the same path and name in the store
+	query := `SELECT COUNT(1) FROM workflow_node_run_artifacts
+		WHERE workflow_run_id = $1 AND tag = $2 AND name = $3 AND ref = ''
+		AND (CASE WHEN storage_node_run_id <> 0 THEN storage_node_run_id ELSE workflow_node_run_id END) = $4`
+	n, err := db.SelectInt(query, a.WorkflowID, a.Tag, a.Name, a.GetStorageNodeRunID())
+	if err != nil {
+		return sdk.WrapError(err, "deleteArtifact> Cannot count copies of artifact %d", a.ID)
+	}
+	if n > 0 {
+		return nil
+	}
+	if err := objectstore.DeleteArtifact(a); err != nil && !strings.Contains(err.Error(), "404") {
+		return sdk.WrapError(err, "deleteArtifact> Cannot delete artifact %d in store", a.ID)
+	}
+	return nil
+}
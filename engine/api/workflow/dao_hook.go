@@ -2,7 +2,6 @@ package workflow
 
 import (
 	"database/sql"
-	"fmt"
 
 	"github.com/go-gorp/gorp"
 
@@ -48,15 +47,20 @@ func insertHook(db gorp.SqlExecutor, node *sdk.WorkflowNode, hook *sdk.WorkflowN
 	}
 	hook.WorkflowHookModelID = hook.WorkflowHookModel.ID
 
-	errmu := sdk.MultiError{}
-	// Check configuration of the hook vs the model
-	for k := range hook.WorkflowHookModel.DefaultConfig {
+	// Complete the configuration of the hook with the keys added to the model after the creation of the hook
+	if hook.Config == nil {
+		hook.Config = sdk.WorkflowNodeHookConfig{}
+	}
+	for k, v := range hook.WorkflowHookModel.DefaultConfig {
 		if _, ok := hook.Config[k]; !ok {
-			errmu = append(errmu, fmt.Errorf("Missing configuration key: %s", k))
+			hook.Config[k] = v
 		}
 	}
-	if len(errmu) > 0 {
-		return sdk.WrapError(&errmu, "insertHook> Invalid hook configuration")
+
+	if hook.WorkflowHookModel.Name == sdk.SchedulerModelName {
+		if _, err := sdk.ValidateSchedulerHookConfig(hook.Config); err != nil {
+			return sdk.WrapError(sdk.NewError(sdk.ErrInvalidHookConfiguration, err), "insertHook> Invalid scheduler configuration")
+		}
 	}
 
 	// if it's a new hook
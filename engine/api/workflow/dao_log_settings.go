@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"database/sql"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadProjectLogSettings loads the log settings of a project, the default settings if the project has none
+func LoadProjectLogSettings(db gorp.SqlExecutor, projectID int64) (*sdk.ProjectLogSettings, error) {
+	s := &sdk.ProjectLogSettings{ProjectID: projectID}
+	query := `SELECT step_max_size, retention_days FROM project_log_settings WHERE project_id = $1`
+	if err := db.QueryRow(query, projectID).Scan(&s.StepMaxSize, &s.RetentionDays); err != nil && err != sql.ErrNoRows {
+		return nil, sdk.WrapError(err, "LoadProjectLogSettings> Cannot load log settings of project %d", projectID)
+	}
+	return s, nil
+}
+
+// UpsertProjectLogSettings inserts or updates the log settings of a project
+func UpsertProjectLogSettings(db gorp.SqlExecutor, s sdk.ProjectLogSettings) error {
+	query := `UPDATE project_log_settings SET step_max_size = $2, retention_days = $3 WHERE project_id = $1`
+	res, err := db.Exec(query, s.ProjectID, s.StepMaxSize, s.RetentionDays)
+	if err != nil {
+		return sdk.WrapError(err, "UpsertProjectLogSettings> Cannot update log settings of project %d", s.ProjectID)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	query = `INSERT INTO project_log_settings (project_id, step_max_size, retention_days) VALUES ($1, $2, $3)`
+	if _, err := db.Exec(query, s.ProjectID, s.StepMaxSize, s.RetentionDays); err != nil {
+		return sdk.WrapError(err, "UpsertProjectLogSettings> Cannot insert log settings of project %d", s.ProjectID)
+	}
+	return nil
+}
+
+// DeleteProjectLogSettings deletes the log settings of a project, the default settings are used
+func DeleteProjectLogSettings(db gorp.SqlExecutor, projectID int64) error {
+	if _, err := db.Exec(`DELETE FROM project_log_settings WHERE project_id = $1`, projectID); err != nil {
+		return sdk.WrapError(err, "DeleteProjectLogSettings> Cannot delete log settings of project %d", projectID)
+	}
+	return nil
+}
+
+// loadNodeRunLogMaxSize returns the maximum size of the logs of a step of a node run
+func loadNodeRunLogMaxSize(db gorp.SqlExecutor, nodeRunID int64) (int64, error) {
+	query := `
+	SELECT COALESCE(project_log_settings.step_max_size, 0)
+	FROM workflow_node_run
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	LEFT JOIN project_log_settings ON project_log_settings.project_id = workflow_run.project_id
+	WHERE workflow_node_run.id = $1`
+	var maxSize int64
+	if err := db.QueryRow(query, nodeRunID).Scan(&maxSize); err != nil && err != sql.ErrNoRows {
+		return 0, sdk.WrapError(err, "loadNodeRunLogMaxSize> Cannot load log settings of node run %d", nodeRunID)
+	}
+	if maxSize == 0 {
+		maxSize = logStepMaxSize
+	}
+	return maxSize, nil
+}
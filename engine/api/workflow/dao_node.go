@@ -196,6 +196,8 @@ type sqlContext struct {
 	DefaultPipelineParameters sql.NullString `db:"default_pipeline_parameters"`
 	Conditions                sql.NullString `db:"conditions"`
 	Mutex                     sql.NullBool   `db:"mutex"`
+	Approval                  sql.NullString `db:"approval"`
+	Concurrency               sql.NullString `db:"concurrency"`
 }
 
 // UpdateNodeContext updates the node context in database
@@ -239,6 +241,22 @@ func UpdateNodeContext(db gorp.SqlExecutor, c *sdk.WorkflowNodeContext) error {
 		return sdk.WrapError(errC, "updateNodeContext> Unable to marshall workflow node context(%d) conditions", c.ID)
 	}
 
+	if c.Approval != nil {
+		var errA error
+		sqlContext.Approval, errA = gorpmapping.JSONToNullString(c.Approval)
+		if errA != nil {
+			return sdk.WrapError(errA, "updateNodeContext> Unable to marshall workflow node context(%d) approval", c.ID)
+		}
+	}
+
+	if c.Concurrency != nil {
+		var errCc error
+		sqlContext.Concurrency, errCc = gorpmapping.JSONToNullString(c.Concurrency)
+		if errCc != nil {
+			return sdk.WrapError(errCc, "updateNodeContext> Unable to marshall workflow node context(%d) concurrency", c.ID)
+		}
+	}
+
 	if _, err := db.Update(&sqlContext); err != nil {
 		return sdk.WrapError(err, "updateNodeContext> Unable to update workflow node context(%d)", c.ID)
 	}
@@ -354,7 +372,7 @@ func LoadNodeContext(db gorp.SqlExecutor, store cache.Store, projectKey string,
 func postLoadNodeContext(db gorp.SqlExecutor, store cache.Store, projectKey string, u *sdk.User, ctx *sdk.WorkflowNodeContext, opts LoadOptions) error {
 	var sqlContext = sqlContext{}
 	if err := db.SelectOne(&sqlContext,
-		"select application_id, environment_id, default_payload, default_pipeline_parameters, conditions, mutex from workflow_node_context where id = $1", ctx.ID); err != nil {
+		"select application_id, environment_id, default_payload, default_pipeline_parameters, conditions, mutex, approval, concurrency from workflow_node_context where id = $1", ctx.ID); err != nil {
 		return err
 	}
 	if sqlContext.AppID.Valid {
@@ -423,6 +441,20 @@ func postLoadNodeContext(db gorp.SqlExecutor, store cache.Store, projectKey stri
 		return sdk.WrapError(err, "postLoadNodeContext> Unable to unmarshall context %d conditions", ctx.ID)
 	}
 
+	if sqlContext.Approval.Valid {
+		ctx.Approval = &sdk.WorkflowNodeApprovalRule{}
+		if err := gorpmapping.JSONNullString(sqlContext.Approval, ctx.Approval); err != nil {
+			return sdk.WrapError(err, "postLoadNodeContext> Unable to unmarshall context %d approval", ctx.ID)
+		}
+	}
+
+	if sqlContext.Concurrency.Valid {
+		ctx.Concurrency = &sdk.WorkflowNodeConcurrency{}
+		if err := gorpmapping.JSONNullString(sqlContext.Concurrency, ctx.Concurrency); err != nil {
+			return sdk.WrapError(err, "postLoadNodeContext> Unable to unmarshall context %d concurrency", ctx.ID)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+func insertNodeApproval(db gorp.SqlExecutor, a *sdk.WorkflowNodeApproval) error {
+	dbApproval := NodeApproval(*a)
+	if err := db.Insert(&dbApproval); err != nil {
+		return sdk.WrapError(err, "insertNodeApproval> Unable to insert approval request on node %d", a.WorkflowNodeID)
+	}
+	a.ID = dbApproval.ID
+	return nil
+}
+
+func updateNodeApprovalStatus(db gorp.SqlExecutor, a *sdk.WorkflowNodeApproval) error {
+	if _, err := db.Exec("UPDATE workflow_node_approval SET status = $1 WHERE id = $2", a.Status, a.ID); err != nil {
+		return sdk.WrapError(err, "updateNodeApprovalStatus> Unable to update approval request %d", a.ID)
+	}
+	return nil
+}
+
+func insertNodeApprovalDecision(db gorp.SqlExecutor, d *sdk.WorkflowNodeApprovalDecision) error {
+	dbDecision := NodeApprovalDecision(*d)
+	if err := db.Insert(&dbDecision); err != nil {
+		return sdk.WrapError(err, "insertNodeApprovalDecision> Unable to insert decision on approval request %d", d.ApprovalID)
+	}
+	d.ID = dbDecision.ID
+	return nil
+}
+
+func loadNodeApproval(db gorp.SqlExecutor, query string, args ...interface{}) (*sdk.WorkflowNodeApproval, error) {
+	dbApproval := NodeApproval{}
+	if err := db.SelectOne(&dbApproval, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, sdk.WrapError(err, "loadNodeApproval> Unable to load approval request")
+	}
+	a := sdk.WorkflowNodeApproval(dbApproval)
+	return &a, nil
+}
+
+// loadNodeApprovalBySubNumber loads the approval request of a node for a subnumber of a workflow run. It returns nil if there is no request
+func loadNodeApprovalBySubNumber(db gorp.SqlExecutor, runID, nodeID int64, subnumber int64) (*sdk.WorkflowNodeApproval, error) {
+	query := "SELECT * FROM workflow_node_approval WHERE workflow_run_id = $1 AND workflow_node_id = $2 AND sub_num = $3"
+	return loadNodeApproval(db, query, runID, nodeID, subnumber)
+}
+
+// LoadAndLockWaitingNodeApproval loads the last waiting approval request of a node in a workflow run, and locks it
+// until the end of the transaction so that the decisions on the request are taken one at a time
+func LoadAndLockWaitingNodeApproval(db gorp.SqlExecutor, runID, nodeID int64) (*sdk.WorkflowNodeApproval, error) {
+	query := `SELECT * FROM workflow_node_approval
+	WHERE workflow_run_id = $1 AND workflow_node_id = $2 AND status = $3
+	ORDER BY sub_num DESC LIMIT 1
+	FOR UPDATE`
+	a, err := loadNodeApproval(db, query, runID, nodeID, sdk.WorkflowNodeApprovalWaiting)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, sdk.ErrWorkflowNodeApprovalNotFound
+	}
+	return a, nil
+}
+
+// LoadNodeApprovals loads all the approval requests of a workflow run
+func LoadNodeApprovals(db gorp.SqlExecutor, runID int64) ([]sdk.WorkflowNodeApproval, error) {
+	dbApprovals := []NodeApproval{}
+	if _, err := db.Select(&dbApprovals, "SELECT * FROM workflow_node_approval WHERE workflow_run_id = $1 ORDER BY id", runID); err != nil {
+		return nil, sdk.WrapError(err, "LoadNodeApprovals> Unable to load approval requests of workflow run %d", runID)
+	}
+	approvals := make([]sdk.WorkflowNodeApproval, len(dbApprovals))
+	for i := range dbApprovals {
+		approvals[i] = sdk.WorkflowNodeApproval(dbApprovals[i])
+	}
+	return approvals, nil
+}
+
+func loadExpiredNodeApprovals(db gorp.SqlExecutor) ([]sdk.WorkflowNodeApproval, error) {
+	dbApprovals := []NodeApproval{}
+	query := "SELECT * FROM workflow_node_approval WHERE status = $1 AND expire_at < $2"
+	if _, err := db.Select(&dbApprovals, query, sdk.WorkflowNodeApprovalWaiting, time.Now()); err != nil {
+		return nil, sdk.WrapError(err, "loadExpiredNodeApprovals> Unable to load expired approval requests")
+	}
+	approvals := make([]sdk.WorkflowNodeApproval, len(dbApprovals))
+	for i := range dbApprovals {
+		approvals[i] = sdk.WorkflowNodeApproval(dbApprovals[i])
+	}
+	return approvals, nil
+}
+
+func countWaitingNodeApprovals(db gorp.SqlExecutor, runID int64) (int64, error) {
+	n, err := db.SelectInt("SELECT count(1) FROM workflow_node_approval WHERE workflow_run_id = $1 AND status = $2", runID, sdk.WorkflowNodeApprovalWaiting)
+	if err != nil {
+		return 0, sdk.WrapError(err, "countWaitingNodeApprovals> Unable to count approval requests of workflow run %d", runID)
+	}
+	return n, nil
+}
+
+// PostInsert is a db hook
+func (a *NodeApproval) PostInsert(db gorp.SqlExecutor) error {
+	rule, errR := gorpmapping.JSONToNullString(a.Rule)
+	if errR != nil {
+		return errR
+	}
+	sources, errS := gorpmapping.JSONToNullString(a.SourceNodeRuns)
+	if errS != nil {
+		return errS
+	}
+	hookEvent, errH := gorpmapping.JSONToNullString(a.HookEvent)
+	if errH != nil {
+		return errH
+	}
+	manual, errM := gorpmapping.JSONToNullString(a.Manual)
+	if errM != nil {
+		return errM
+	}
+
+	query := "UPDATE workflow_node_approval SET rule = $1, source_node_runs = $2, hook_event = $3, manual = $4 WHERE id = $5"
+	if _, err := db.Exec(query, rule, sources, hookEvent, manual, a.ID); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostInsert> Unable to update approval request %d", a.ID)
+	}
+	return nil
+}
+
+// PostGet is a db hook
+func (a *NodeApproval) PostGet(db gorp.SqlExecutor) error {
+	var res = struct {
+		Rule           sql.NullString `db:"rule"`
+		SourceNodeRuns sql.NullString `db:"source_node_runs"`
+		HookEvent      sql.NullString `db:"hook_event"`
+		Manual         sql.NullString `db:"manual"`
+	}{}
+
+	query := "SELECT rule, source_node_runs, hook_event, manual FROM workflow_node_approval WHERE id = $1"
+	if err := db.SelectOne(&res, query, a.ID); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to load approval request %d", a.ID)
+	}
+
+	if err := gorpmapping.JSONNullString(res.Rule, &a.Rule); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to unmarshal rule")
+	}
+	if err := gorpmapping.JSONNullString(res.SourceNodeRuns, &a.SourceNodeRuns); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to unmarshal source node runs")
+	}
+	if err := gorpmapping.JSONNullString(res.HookEvent, &a.HookEvent); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to unmarshal hook event")
+	}
+	if err := gorpmapping.JSONNullString(res.Manual, &a.Manual); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to unmarshal manual event")
+	}
+
+	dbDecisions := []NodeApprovalDecision{}
+	if _, err := db.Select(&dbDecisions, "SELECT * FROM workflow_node_approval_decision WHERE workflow_node_approval_id = $1 ORDER BY date", a.ID); err != nil {
+		return sdk.WrapError(err, "NodeApproval.PostGet> Unable to load decisions of approval request %d", a.ID)
+	}
+	a.Decisions = make([]sdk.WorkflowNodeApprovalDecision, len(dbDecisions))
+	for i := range dbDecisions {
+		a.Decisions[i] = sdk.WorkflowNodeApprovalDecision(dbDecisions[i])
+	}
+
+	return nil
+}
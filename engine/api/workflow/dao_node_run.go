@@ -170,6 +170,11 @@ func fromDBNodeRun(rr NodeRun) (*sdk.WorkflowNodeRun, error) {
 	if err := gorpmapping.JSONNullString(rr.BuildParameters, &r.BuildParameters); err != nil {
 		return nil, sdk.WrapError(err, "fromDBNodeRun>Error loading node run %d: BuildParameters", r.ID)
 	}
+	if rr.Outputs.Valid {
+		if err := gorpmapping.JSONNullString(rr.Outputs, &r.Outputs); err != nil {
+			return nil, sdk.WrapError(err, "fromDBNodeRun>Error loading node run %d: Outputs", r.ID)
+		}
+	}
 	if rr.PipelineParameters.Valid {
 		if err := gorpmapping.JSONNullString(rr.PipelineParameters, &r.PipelineParameters); err != nil {
 			return nil, sdk.WrapError(err, "fromDBNodeRun>Error loading node run %d: PipelineParameters", r.ID)
@@ -261,6 +266,13 @@ func makeDBNodeRun(n sdk.WorkflowNodeRun) (*NodeRun, error) {
 		}
 		nodeRunDB.BuildParameters = s
 	}
+	if n.Outputs != nil {
+		s, err := gorpmapping.JSONToNullString(n.Outputs)
+		if err != nil {
+			return nil, sdk.WrapError(err, "makeDBNodeRun> unable to get json from outputs")
+		}
+		nodeRunDB.Outputs = s
+	}
 	if n.Tests != nil {
 		s, err := gorpmapping.JSONToNullString(n.Tests)
 		if err != nil {
@@ -556,13 +556,26 @@ func PurgeWorkflowRun(db gorp.SqlExecutor, wf sdk.Workflow) error {
 	return nil
 }
 
-// deleteWorkflowRunsHistory is useful to delete all the workflow run marked with to delete flag in db
-func deleteWorkflowRunsHistory(db gorp.SqlExecutor) error {
-	query := `DELETE FROM workflow_run WHERE to_delete = true`
+// deleteWorkflowRunsHistory is useful to delete all the workflow run marked with to delete flag in db.
+// The objects of their logs kept in the objectstore are recorded as obsolete before the logs are deleted by cascade
+func deleteWorkflowRunsHistory(db *gorp.DbMap) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WrapError(err, "deleteWorkflowRunsHistory> Cannot start transaction")
+	}
+	defer tx.Rollback()
 
-	if _, err := db.Exec(query); err != nil {
+	nodeRunsQuery := `SELECT workflow_node_run.id FROM workflow_node_run
+		JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+		WHERE workflow_run.to_delete = true`
+	if err := insertObsoleteNodeRunsLogObjects(tx, nodeRunsQuery); err != nil {
+		return sdk.WrapError(err, "deleteWorkflowRunsHistory> Unable to record obsolete log objects")
+	}
+
+	query := `DELETE FROM workflow_run WHERE to_delete = true`
+	if _, err := tx.Exec(query); err != nil {
 		log.Warning("deleteWorkflowRunsHistory> Unable to delete workflow history %s", err)
 		return err
 	}
-	return nil
+	return tx.Commit()
 }
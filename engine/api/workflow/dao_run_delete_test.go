@@ -0,0 +1,34 @@
+package workflow_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/workflow"
+)
+
+func Test_deleteWorkflowRunsHistoryWithObjectstoreLogs(t *testing.T) {
+	db, cache := test.SetupPG(t)
+	_, _, wr, nodeRun := runResumeTestWorkflow(t, db, cache)
+
+	_, err := db.Exec(`INSERT INTO workflow_node_run_job_logs
+		(workflow_node_run_job_id, workflow_node_run_id, start, last_modified, step_order, value, storage, chunks, first_chunk, compressed, compressed_version)
+		VALUES (42, $1, now(), now(), 0, '', 'objectstore', 2, 3, true, 1)`, nodeRun.ID)
+	test.NoError(t, err)
+
+	_, err = db.Exec(`UPDATE workflow_run SET to_delete = true WHERE id = $1`, wr.ID)
+	test.NoError(t, err)
+	test.NoError(t, workflow.DeleteWorkflowRunsHistory(db))
+
+	n, err := db.SelectInt(`SELECT COUNT(1) FROM workflow_node_run_job_logs WHERE workflow_node_run_id = $1`, nodeRun.ID)
+	test.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	var names []string
+	_, err = db.Select(&names, `SELECT name FROM workflow_node_run_job_logs_obsolete WHERE container = $1 ORDER BY name`, fmt.Sprintf("logs-%d", nodeRun.ID))
+	test.NoError(t, err)
+	assert.Equal(t, []string{"42-0-3.log", "42-0-4.log", "42-0-v1.log.gz"}, names)
+}
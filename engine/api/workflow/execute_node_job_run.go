@@ -1,7 +1,6 @@
 package workflow
 
 import (
-	"database/sql"
 	"encoding/base64"
 	"fmt"
 	"time"
@@ -12,6 +11,7 @@ import (
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/quota"
 	"github.com/ovh/cds/engine/api/secret"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
@@ -48,7 +48,7 @@ func UpdateNodeJobRunStatus(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache
 		job.Start = time.Now()
 		job.Status = status.String()
 
-	case sdk.StatusFail, sdk.StatusSuccess, sdk.StatusDisabled, sdk.StatusSkipped, sdk.StatusStopped:
+	case sdk.StatusFail, sdk.StatusSuccess, sdk.StatusWarning, sdk.StatusDisabled, sdk.StatusSkipped, sdk.StatusStopped:
 		if currentStatus != string(sdk.StatusWaiting) && currentStatus != string(sdk.StatusBuilding) && status != sdk.StatusDisabled && status != sdk.StatusSkipped {
 			log.Debug("workflow.UpdateNodeJobRunStatus> Status is %s, cannot update %d to %s", currentStatus, job.ID, status)
 			// too late, Nate
@@ -66,6 +66,12 @@ func UpdateNodeJobRunStatus(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache
 		if err := UpdateWorkflowRun(db, wf); err != nil {
 			return sdk.WrapError(err, "workflow.UpdateNodeJobRunStatus> Cannot update WorkflowRun %d", wf.ID)
 		}
+
+		if currentStatus == sdk.StatusBuilding.String() {
+			if err := quota.InsertWorkerUsage(db, wf.ProjectID, job); err != nil {
+				return sdk.WrapError(err, "workflow.UpdateNodeJobRunStatus> Cannot record worker usage")
+			}
+		}
 	default:
 		return fmt.Errorf("workflow.UpdateNodeJobRunStatus> Cannot update WorkflowNodeJobRun %d to status %v", job.ID, status.String())
 	}
@@ -266,8 +272,23 @@ func LoadNodeJobRunKeys(db gorp.SqlExecutor, store cache.Store, job *sdk.Workflo
 
 // LoadNodeJobRunSecrets loads all secrets for a job run
 func LoadNodeJobRunSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.WorkflowNodeJobRun, nodeRun *sdk.WorkflowNodeRun, w *sdk.WorkflowRun, pv []sdk.Variable) ([]sdk.Variable, error) {
-	var secrets []sdk.Variable
+	secrets, vaultVariables, err := LoadNodeJobRunDBSecrets(db, store, job, nodeRun, w, pv)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadNodeJobRunSecrets>")
+	}
+	resolved, err := ResolveNodeJobRunVaultVariables(db, w.ProjectID, vaultVariables)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadNodeJobRunSecrets>")
+	}
+	return append(secrets, resolved...), nil
+}
 
+// LoadNodeJobRunDBSecrets loads the secrets of a job run stored in database, and its vault variables which are not
+// resolved
+func LoadNodeJobRunDBSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.WorkflowNodeJobRun, nodeRun *sdk.WorkflowNodeRun, w *sdk.WorkflowRun, pv []sdk.Variable) ([]sdk.Variable, []sdk.Variable, error) {
+	var secrets, vaultVariables []sdk.Variable
+
+	vaultVariables = append(vaultVariables, sdk.VariablesPrefix(sdk.VariablesFilter(pv, sdk.VaultVariable), "cds.proj.")...)
 	pv = sdk.VariablesFilter(pv, sdk.SecretVariable, sdk.KeyVariable)
 	pv = sdk.VariablesPrefix(pv, "cds.proj.")
 	secrets = append(secrets, pv...)
@@ -275,7 +296,7 @@ func LoadNodeJobRunSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.Work
 	//Load node definition
 	n := w.Workflow.GetNode(nodeRun.WorkflowNodeID)
 	if n == nil {
-		return nil, sdk.WrapError(fmt.Errorf("Unable to find node %d in workflow", nodeRun.WorkflowNodeID), "LoadNodeJobRunSecrets>")
+		return nil, nil, sdk.WrapError(fmt.Errorf("Unable to find node %d in workflow", nodeRun.WorkflowNodeID), "LoadNodeJobRunDBSecrets>")
 	}
 
 	//Application variables
@@ -283,8 +304,9 @@ func LoadNodeJobRunSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.Work
 	if n.Context != nil && n.Context.Application != nil {
 		appv, errA := application.GetAllVariableByID(db, n.Context.Application.ID, application.WithClearPassword())
 		if errA != nil {
-			return nil, sdk.WrapError(errA, "LoadNodeJobRunSecrets> Cannot load application variables")
+			return nil, nil, sdk.WrapError(errA, "LoadNodeJobRunDBSecrets> Cannot load application variables")
 		}
+		vaultVariables = append(vaultVariables, sdk.VariablesPrefix(sdk.VariablesFilter(appv, sdk.VaultVariable), "cds.app.")...)
 		av = sdk.VariablesFilter(appv, sdk.SecretVariable, sdk.KeyVariable)
 		av = sdk.VariablesPrefix(av, "cds.app.")
 	}
@@ -295,8 +317,9 @@ func LoadNodeJobRunSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.Work
 	if n.Context != nil && n.Context.Environment != nil {
 		envv, errE := environment.GetAllVariableByID(db, n.Context.Environment.ID, environment.WithClearPassword())
 		if errE != nil {
-			return nil, sdk.WrapError(errE, "LoadNodeJobRunSecrets> Cannot load environment variables")
+			return nil, nil, sdk.WrapError(errE, "LoadNodeJobRunDBSecrets> Cannot load environment variables")
 		}
+		vaultVariables = append(vaultVariables, sdk.VariablesPrefix(sdk.VariablesFilter(envv, sdk.VaultVariable), "cds.env.")...)
 		ev = sdk.VariablesFilter(envv, sdk.SecretVariable, sdk.KeyVariable)
 		ev = sdk.VariablesPrefix(ev, "cds.env.")
 	}
@@ -306,10 +329,33 @@ func LoadNodeJobRunSecrets(db gorp.SqlExecutor, store cache.Store, job *sdk.Work
 	for i := range secrets {
 		s := &secrets[i]
 		if err := secret.DecryptVariable(s); err != nil {
-			return nil, sdk.WrapError(err, "LoadNodeJobRunSecrets> Unable to decrypt variables")
+			return nil, nil, sdk.WrapError(err, "LoadNodeJobRunDBSecrets> Unable to decrypt variables")
 		}
 	}
-	return secrets, nil
+
+	return secrets, vaultVariables, nil
+}
+
+// ResolveNodeJobRunVaultVariables reads the secrets referenced by the vault variables of a job run in the vault of
+// its project
+func ResolveNodeJobRunVaultVariables(db gorp.SqlExecutor, projectID int64, vaultVariables []sdk.Variable) ([]sdk.Variable, error) {
+	if len(vaultVariables) == 0 {
+		return nil, nil
+	}
+	v, errV := secret.LoadProjectVault(db, projectID, true)
+	if errV != nil {
+		return nil, sdk.WrapError(errV, "ResolveNodeJobRunVaultVariables> Cannot load project vault")
+	}
+	client, errC := secret.NewProjectVaultClient(*v)
+	if errC != nil {
+		return nil, sdk.WrapError(errC, "ResolveNodeJobRunVaultVariables> Cannot connect to project vault")
+	}
+	resolved, errR := secret.ResolveVaultVariables(client, vaultVariables)
+	if errR != nil {
+		secret.ForgetProjectVaultToken(*v)
+		return nil, sdk.WrapError(errR, "ResolveNodeJobRunVaultVariables> Unable to resolve vault variables")
+	}
+	return resolved, nil
 }
 
 //BookNodeJobRun  Book a job for a hatchery
@@ -324,30 +370,48 @@ func BookNodeJobRun(store cache.Store, id int64, hatchery *sdk.Hatchery) (*sdk.H
 	return &h, sdk.WrapError(sdk.ErrJobAlreadyBooked, "BookNodeJobRun> job %d already booked by %s (%d)", id, h.Name, h.ID)
 }
 
-//AddLog adds a build log
-func AddLog(db gorp.SqlExecutor, job *sdk.WorkflowNodeJobRun, logs *sdk.Log) error {
+//AddLog adds a build log, the secrets of the job are masked in the log if any
+func AddLog(db *gorp.DbMap, store cache.Store, job *sdk.WorkflowNodeJobRun, logs *sdk.Log, secrets *sdk.SecretMasker) error {
 	if job != nil {
 		logs.PipelineBuildJobID = job.ID
 		logs.PipelineBuildID = job.WorkflowNodeRunID
 	}
 
-	existingLogs, errLog := LoadStepLogs(db, logs.PipelineBuildJobID, logs.StepOrder)
-	if errLog != nil && errLog != sql.ErrNoRows {
+	// the logs of a step can be received at the same time by several API instances, they are appended one after
+	// the other while the log of the step is locked
+	tx, errT := db.Begin()
+	if errT != nil {
+		return sdk.WrapError(errT, "AddLog> Cannot start transaction")
+	}
+	defer tx.Rollback()
+
+	existingLogs, errLog := loadAndLockStepLog(tx, logs.PipelineBuildJobID, logs.StepOrder)
+	if errLog != nil {
 		return sdk.WrapError(errLog, "AddLog> Cannot load existing logs")
 	}
 
-	if existingLogs == nil {
-		if err := insertLog(db, logs); err != nil {
-			return sdk.WrapError(err, "AddLog> Cannot insert log")
+	if secrets != nil {
+		var errM error
+		logs.Val, errM = maskStepLog(store, secrets, logs)
+		if errM != nil {
+			return sdk.WrapError(errM, "AddLog> Cannot mask secrets")
 		}
+	}
+
+	if existingLogs == nil {
+		existingLogs = &stepLog{Log: *logs}
+		existingLogs.Val = ""
 	} else {
-		existingLogs.Val += logs.Val
 		existingLogs.LastModified = logs.LastModified
 		existingLogs.Done = logs.Done
-		if err := updateLog(db, existingLogs); err != nil {
-			return sdk.WrapError(err, "AddLog> Cannot update log")
-		}
 	}
+	if err := appendStepLog(tx, existingLogs, logs.Val); err != nil {
+		return sdk.WrapError(err, "AddLog> Cannot add log")
+	}
+	if err := tx.Commit(); err != nil {
+		return sdk.WrapError(err, "AddLog> Cannot commit transaction")
+	}
+	logs.Id = existingLogs.Id
 	return nil
 }
 
@@ -358,7 +422,7 @@ func RestartWorkflowNodeJob(db gorp.SqlExecutor, wNodeJob sdk.WorkflowNodeJobRun
 		if step.Status == sdk.StatusNeverBuilt.String() || step.Status == sdk.StatusSkipped.String() || step.Status == sdk.StatusDisabled.String() {
 			continue
 		}
-		l, errL := LoadStepLogs(db, wNodeJob.ID, int64(step.StepOrder))
+		l, errL := loadAndLockStepLog(db, wNodeJob.ID, int64(step.StepOrder))
 		if errL != nil {
 			return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while load step logs")
 		}
@@ -367,9 +431,8 @@ func RestartWorkflowNodeJob(db gorp.SqlExecutor, wNodeJob sdk.WorkflowNodeJobRun
 		step.Done = time.Time{}
 		if l != nil { // log could be nil here
 			l.Done = nil
-			l.Val += "\n\n\n-=-=-=-=-=- Worker timeout: job replaced in queue -=-=-=-=-=-\n\n\n"
-			if err := updateLog(db, l); err != nil {
-				return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while update step log")
+			if err := appendStepLog(db, l, "\n\n\n-=-=-=-=-=- Worker timeout: job replaced in queue -=-=-=-=-=-\n\n\n"); err != nil {
+				return sdk.WrapError(err, "RestartWorkflowNodeJob> error while update step log")
 			}
 		}
 	}
@@ -1,84 +1,511 @@
 package workflow
 
 import (
+	"bufio"
+	"bytes"
+	"container/list"
 	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-gorp/gorp"
 	"github.com/golang/protobuf/ptypes"
+
+	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
 )
 
-//LoadStepLogs load logs (workflow_node_run_job_logs) for a job (workflow_node_run_job) for a specific step_order
-func LoadStepLogs(db gorp.SqlExecutor, id int64, order int64) (*sdk.Log, error) {
-	query := `
-		SELECT id, workflow_node_run_job_id, workflow_node_run_id, start, last_modified, done, step_order, value
-		FROM workflow_node_run_job_logs
-		WHERE workflow_node_run_job_id = $1 AND step_order = $2`
-	logs := &sdk.Log{}
-	var s, m, d time.Time
-	if err := db.QueryRow(query, id, order).Scan(&logs.Id, &logs.PipelineBuildJobID, &logs.PipelineBuildID, &s, &m, &d, &logs.StepOrder, &logs.Val); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
+// stepLog is a row of workflow_node_run_job_logs: the metadata of a step log, and the location of its content
+type stepLog struct {
+	sdk.Log
+	Storage           string
+	Size              int64
+	Chunks            int
+	FirstChunk        int
+	Compressed        bool
+	CompressedVersion int
+	MaxSize           int64
+	// Pending is the content buffered in the database by the stores which cannot append to their objects
+	Pending string
+	// obsolete are the objects replaced in the store, deleted once the metadata is updated
+	obsolete []*logObject
+}
+
+// logTruncatedMarker starts the logs of a step truncated to its maximum size
+const logTruncatedMarker = "-=-=-=-=-=- Logs truncated: the logs of a step are limited to %d bytes, only the last lines are kept -=-=-=-=-=-\n"
+
+const stepLogColumns = `id, workflow_node_run_job_id, workflow_node_run_id, start, last_modified, done, step_order,
+	storage, CASE WHEN storage = 'database' THEN octet_length(value) ELSE size END, chunks, first_chunk, compressed,
+	compressed_version, max_size, CASE WHEN storage = 'database' THEN '' ELSE value END`
+
+func scanStepLog(scan func(dest ...interface{}) error) (*stepLog, error) {
+	l := &stepLog{}
+	var start, m, d time.Time
+	if err := scan(&l.Id, &l.PipelineBuildJobID, &l.PipelineBuildID, &start, &m, &d, &l.StepOrder, &l.Storage, &l.Size, &l.Chunks, &l.FirstChunk, &l.Compressed, &l.CompressedVersion, &l.MaxSize, &l.Pending); err != nil {
 		return nil, err
 	}
 	var err error
-	logs.Start, err = ptypes.TimestampProto(s)
+	l.Start, err = ptypes.TimestampProto(start)
 	if err != nil {
 		return nil, err
 	}
-	logs.LastModified, err = ptypes.TimestampProto(m)
+	l.LastModified, err = ptypes.TimestampProto(m)
 	if err != nil {
 		return nil, err
 	}
-	logs.Done, err = ptypes.TimestampProto(d)
+	l.Done, err = ptypes.TimestampProto(d)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// loadStepLog loads the metadata of a step log, nil if there is no log for the step
+func loadStepLog(db gorp.SqlExecutor, id int64, order int64) (*stepLog, error) {
+	query := `SELECT ` + stepLogColumns + `
+		FROM workflow_node_run_job_logs
+		WHERE workflow_node_run_job_id = $1 AND step_order = $2`
+	l, err := scanStepLog(db.QueryRow(query, id, order).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return l, err
+}
+
+// loadAndLockStepLog loads the metadata of a step log and locks it until the end of the transaction, so that the
+// log is appended by one transaction at a time. It returns nil if there is no log for the step
+func loadAndLockStepLog(db gorp.SqlExecutor, id int64, order int64) (*stepLog, error) {
+	query := `SELECT ` + stepLogColumns + `
+		FROM workflow_node_run_job_logs
+		WHERE workflow_node_run_job_id = $1 AND step_order = $2
+		FOR UPDATE`
+	l, err := scanStepLog(db.QueryRow(query, id, order).Scan)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return l, err
+}
+
+// loadStepLogsByQuery loads the metadata of the step logs of a query
+func loadStepLogsByQuery(db gorp.SqlExecutor, query string, args ...interface{}) ([]stepLog, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	var logs []stepLog
+	for rows.Next() {
+		l, err := scanStepLog(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
 	return logs, nil
 }
 
+//LoadStepLogs load logs (workflow_node_run_job_logs) for a job (workflow_node_run_job) for a specific step_order
+func LoadStepLogs(db gorp.SqlExecutor, id int64, order int64) (*sdk.Log, error) {
+	l, err := loadStepLog(db, id, order)
+	if err != nil || l == nil {
+		return nil, err
+	}
+	b, err := l.store().read(db, l, 0, -1)
+	if err != nil {
+		return nil, sdk.WrapError(err, "LoadStepLogs> Cannot read log of step %d of job %d", order, id)
+	}
+	l.Val = string(b)
+	return &l.Log, nil
+}
+
+//LoadStepLogsRange loads limit bytes of the logs of a step from an offset, all the logs from offset with a negative limit.
+//It returns the size of the whole logs of the step
+func LoadStepLogsRange(db gorp.SqlExecutor, id int64, order int64, offset, limit int64) (*sdk.Log, int64, error) {
+	l, err := loadStepLog(db, id, order)
+	if err != nil || l == nil {
+		return nil, 0, err
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= l.Size {
+		return &l.Log, l.Size, nil
+	}
+	b, err := l.store().read(db, l, offset, limit)
+	if err != nil {
+		return nil, 0, sdk.WrapError(err, "LoadStepLogsRange> Cannot read log of step %d of job %d", order, id)
+	}
+	l.Val = string(b)
+	return &l.Log, l.Size, nil
+}
+
+//LoadStepLogsTail loads the last lines of the logs of a step. It returns the size of the whole logs of the step
+func LoadStepLogsTail(db gorp.SqlExecutor, id int64, order int64, lines int) (*sdk.Log, int64, error) {
+	l, err := loadStepLog(db, id, order)
+	if err != nil || l == nil {
+		return nil, 0, err
+	}
+	r, err := l.store().open(db, l)
+	if err != nil {
+		return nil, 0, sdk.WrapError(err, "LoadStepLogsTail> Cannot open log of step %d of job %d", order, id)
+	}
+	defer r.Close()
+
+	val, err := tailLines(r, lines)
+	if err != nil {
+		return nil, 0, sdk.WrapError(err, "LoadStepLogsTail> Cannot read log of step %d of job %d", order, id)
+	}
+	l.Val = val
+	return &l.Log, l.Size, nil
+}
+
+// tailLines returns the last lines of a reader, only these lines are kept in memory
+func tailLines(r io.Reader, lines int) (string, error) {
+	if lines <= 0 {
+		return "", nil
+	}
+	buf := list.New()
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			buf.PushBack(line)
+			if buf.Len() > lines {
+				buf.Remove(buf.Front())
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var val string
+	for e := buf.Front(); e != nil; e = e.Next() {
+		val += e.Value.(string)
+	}
+	return val, nil
+}
+
 //LoadLogs load logs (workflow_node_run_job_logs) for a job (workflow_node_run_job)
 func LoadLogs(db gorp.SqlExecutor, id int64) ([]sdk.Log, error) {
-	query := `
-		SELECT id, workflow_node_run_job_id, workflow_node_run_id, start, last_modified, done, step_order, value
+	query := `SELECT ` + stepLogColumns + `
 		FROM workflow_node_run_job_logs
 		WHERE workflow_node_run_job_id = $1
 		ORDER BY id`
-	rows, err := db.Query(query, id)
+	stepLogs, err := loadStepLogsByQuery(db, query, id)
 	if err != nil {
 		return nil, err
 	}
-	var logs []sdk.Log
-	for rows.Next() {
-		l := &sdk.Log{}
-		var s, m, d time.Time
 
-		if err := rows.Scan(&l.Id, &l.PipelineBuildJobID, &l.PipelineBuildID, &s, &m, &d, &l.StepOrder, &l.Val); err != nil {
-			return nil, err
+	var logs []sdk.Log
+	for i := range stepLogs {
+		l := &stepLogs[i]
+		b, err := l.store().read(db, l, 0, -1)
+		if err != nil {
+			return nil, sdk.WrapError(err, "LoadLogs> Cannot read log of step %d of job %d", l.StepOrder, id)
 		}
+		l.Val = string(b)
+		logs = append(logs, l.Log)
+	}
+	return logs, nil
+}
 
-		var err error
-		l.Start, err = ptypes.TimestampProto(s)
+// appendStepLog appends a value to the content of a step log, creating it if needed, and updates its metadata.
+// The log is completed in its store when it is done
+func appendStepLog(db gorp.SqlExecutor, l *stepLog, value string) error {
+	if l.Id == 0 {
+		l.Storage = logStoreKind
+		maxSize, err := loadNodeRunLogMaxSize(db, l.PipelineBuildID)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		l.LastModified, err = ptypes.TimestampProto(m)
+		l.MaxSize = maxSize
+		inserted, err := insertLog(db, l)
 		if err != nil {
-			return nil, err
+			return sdk.WrapError(err, "appendStepLog> Cannot insert log")
+		}
+		// the log has been inserted by a concurrent first write, the value is appended to it
+		if !inserted {
+			existing, err := loadAndLockStepLog(db, l.PipelineBuildJobID, l.StepOrder)
+			if err != nil {
+				return sdk.WrapError(err, "appendStepLog> Cannot load log")
+			}
+			if existing == nil {
+				return fmt.Errorf("appendStepLog> Log of step %d of job %d not found", l.StepOrder, l.PipelineBuildJobID)
+			}
+			existing.LastModified = l.LastModified
+			existing.Done = l.Done
+			*l = *existing
+		}
+	}
+
+	s := l.store()
+	if l.MaxSize > 0 && l.Size+int64(len(value)) > l.MaxSize {
+		if err := truncateStepLog(db, l, value); err != nil {
+			return sdk.WrapError(err, "appendStepLog> Cannot truncate log in %s", l.Storage)
+		}
+	} else if value != "" {
+		if err := s.append(db, l, value); err != nil {
+			return sdk.WrapError(err, "appendStepLog> Cannot append log in %s", l.Storage)
 		}
-		l.Done, err = ptypes.TimestampProto(d)
+		l.Size += int64(len(value))
+	}
+
+	if isLogDone(&l.Log) {
+		if err := s.complete(db, l); err != nil {
+			return sdk.WrapError(err, "appendStepLog> Cannot complete log in %s", l.Storage)
+		}
+	}
+
+	if err := updateLog(db, l); err != nil {
+		return sdk.WrapError(err, "appendStepLog> Cannot update log")
+	}
+	return nil
+}
+
+// truncateStepLog replaces the content of a log which would exceed its maximum size with a marker followed by the
+// last lines of the content and of the appended value. Only three quarters of the maximum size are kept, so that
+// the log is not truncated again on each append
+func truncateStepLog(db gorp.SqlExecutor, l *stepLog, value string) error {
+	marker := fmt.Sprintf(logTruncatedMarker, l.MaxSize)
+	keep := l.MaxSize*3/4 - int64(len(marker))
+	if keep < 0 {
+		keep = 0
+	}
+
+	var content []byte
+	if int64(len(value)) >= keep {
+		content = []byte(value)
+	} else {
+		previous, err := l.store().read(db, l, l.Size-(keep-int64(len(value))), -1)
 		if err != nil {
-			return nil, err
+			return err
 		}
+		content = append(previous, value...)
+	}
+	content = keepLogTail(content, keep)
 
-		logs = append(logs, *l)
+	if err := l.store().replace(db, l, marker+string(content)); err != nil {
+		return err
 	}
-	return logs, nil
+	l.Size = int64(len(marker) + len(content))
+	return nil
+}
+
+// keepLogTail returns the last bytes of a log, from the beginning of a line. Without line in the tail, the tail
+// starts at the beginning of a rune so that it is still valid UTF-8
+func keepLogTail(content []byte, keep int64) []byte {
+	if int64(len(content)) <= keep {
+		return content
+	}
+	content = content[int64(len(content))-keep:]
+	if i := bytes.IndexByte(content, '\n'); i >= 0 && i < len(content)-1 {
+		return content[i+1:]
+	}
+	for len(content) > 0 && !utf8.RuneStart(content[0]) {
+		content = content[1:]
+	}
+	return content
+}
+
+// isLogDone returns true on the final log of a step. The logs sent during the step have a zero done date
+func isLogDone(l *sdk.Log) bool {
+	if l.Done == nil {
+		return false
+	}
+	d, err := ptypes.Timestamp(l.Done)
+	return err == nil && !d.IsZero()
+}
+
+// completeNodeRunLogs completes the logs of a node run which are not completed yet, as the logs of the steps
+// which did not send their final log
+func completeNodeRunLogs(db gorp.SqlExecutor, nodeRunID int64) error {
+	query := `SELECT ` + stepLogColumns + `
+		FROM workflow_node_run_job_logs
+		WHERE workflow_node_run_id = $1 AND storage <> 'database' AND (chunks > 0 OR value <> '')
+		FOR UPDATE`
+	logs, err := loadStepLogsByQuery(db, query, nodeRunID)
+	if err != nil {
+		return sdk.WrapError(err, "completeNodeRunLogs> Cannot load logs of node run %d", nodeRunID)
+	}
+	for i := range logs {
+		l := &logs[i]
+		if err := l.store().complete(db, l); err != nil {
+			return sdk.WrapError(err, "completeNodeRunLogs> Cannot complete log of step %d of job %d", l.StepOrder, l.PipelineBuildJobID)
+		}
+		if err := updateLog(db, l); err != nil {
+			return sdk.WrapError(err, "completeNodeRunLogs> Cannot update log")
+		}
+	}
+	return nil
+}
+
+// purgeLogs deletes the logs older than the retention of their project
+func purgeLogs(db *gorp.DbMap) error {
+	query := `SELECT workflow_node_run_job_logs.id
+		FROM workflow_node_run_job_logs
+		JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_job_logs.workflow_node_run_id
+		JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+		JOIN project_log_settings ON project_log_settings.project_id = workflow_run.project_id
+		WHERE project_log_settings.retention_days > 0
+		AND workflow_node_run_job_logs.last_modified < now() - project_log_settings.retention_days * interval '1 day'
+		LIMIT 1000`
+	for {
+		var ids []int64
+		if _, err := db.Select(&ids, query); err != nil {
+			return sdk.WrapError(err, "purgeLogs> Cannot load logs")
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		for _, id := range ids {
+			if err := purgeLog(db, id); err != nil {
+				return err
+			}
+		}
+		log.Debug("purgeLogs> %d logs deleted", len(ids))
+	}
+}
+
+// purgeLog deletes a log, its objects are recorded as obsolete in the same transaction so that they are deleted
+// from the store only once the log is deleted
+func purgeLog(db *gorp.DbMap, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WrapError(err, "purgeLog> Cannot start transaction")
+	}
+	defer tx.Rollback()
+
+	logs, err := loadStepLogsByQuery(tx, `SELECT `+stepLogColumns+` FROM workflow_node_run_job_logs WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		return sdk.WrapError(err, "purgeLog> Cannot load log %d", id)
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	l := &logs[0]
+
+	if _, err := tx.Exec(`DELETE FROM workflow_node_run_job_logs WHERE id = $1`, l.Id); err != nil {
+		return sdk.WrapError(err, "purgeLog> Cannot delete log %d", l.Id)
+	}
+	if err := l.store().remove(tx, l); err != nil {
+		return sdk.WrapError(err, "purgeLog> Cannot remove log %d", l.Id)
+	}
+	if err := insertObsoleteLogObjects(tx, l); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return sdk.WrapError(err, "purgeLog> Cannot commit transaction")
+	}
+	return nil
+}
+
+// insertObsoleteNodeRunsLogObjects records as obsolete the objects of the logs of the node runs returned by a query,
+// the logs have to be deleted in the same transaction
+func insertObsoleteNodeRunsLogObjects(db gorp.SqlExecutor, nodeRunsQuery string, args ...interface{}) error {
+	query := `SELECT ` + stepLogColumns + ` FROM workflow_node_run_job_logs
+		WHERE storage <> 'database' AND workflow_node_run_id IN (` + nodeRunsQuery + `)
+		FOR UPDATE`
+	logs, err := loadStepLogsByQuery(db, query, args...)
+	if err != nil {
+		return sdk.WrapError(err, "insertObsoleteNodeRunsLogObjects> Cannot load logs")
+	}
+	for i := range logs {
+		l := &logs[i]
+		if err := l.store().remove(db, l); err != nil {
+			return sdk.WrapError(err, "insertObsoleteNodeRunsLogObjects> Cannot remove log %d", l.Id)
+		}
+		if err := insertObsoleteLogObjects(db, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateLogs moves the content of the logs stored in the database to the current log store. Only the logs
+// of the node runs which are over are moved, by batches of logs
+func MigrateLogs(db *gorp.DbMap, batchSize int, progress func(done, total int)) error {
+	if logStoreKind == LogStoreDatabase {
+		return sdk.WrapError(sdk.ErrWrongRequest, "MigrateLogs> The current log store is the database")
+	}
+
+	where := `WHERE storage = 'database'
+		AND NOT EXISTS (SELECT 1 FROM workflow_node_run_job WHERE workflow_node_run_job.id = workflow_node_run_job_logs.workflow_node_run_job_id)`
+	total, err := db.SelectInt(`SELECT COUNT(1) FROM workflow_node_run_job_logs ` + where)
+	if err != nil {
+		return sdk.WrapError(err, "MigrateLogs> Cannot count logs")
+	}
+
+	var done int
+	for {
+		query := `SELECT ` + stepLogColumns + ` FROM workflow_node_run_job_logs ` + where + ` ORDER BY id LIMIT $1`
+		logs, err := loadStepLogsByQuery(db, query, batchSize)
+		if err != nil {
+			return sdk.WrapError(err, "MigrateLogs> Cannot load logs")
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+
+		for i := range logs {
+			if err := migrateLog(db, &logs[i]); err != nil {
+				return err
+			}
+			done++
+		}
+		if progress != nil {
+			progress(done, int(total))
+		}
+	}
+}
+
+func migrateLog(db *gorp.DbMap, l *stepLog) error {
+	b, err := l.store().read(db, l, 0, -1)
+	if err != nil {
+		return sdk.WrapError(err, "migrateLog> Cannot read log %d", l.Id)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WrapError(err, "migrateLog> Cannot start transaction")
+	}
+	defer tx.Rollback()
+
+	l.Storage = logStoreKind
+	l.Size = 0
+	s := l.store()
+	if len(b) > 0 {
+		if err := s.append(tx, l, string(b)); err != nil {
+			return sdk.WrapError(err, "migrateLog> Cannot store log %d", l.Id)
+		}
+		l.Size = int64(len(b))
+		if err := s.complete(tx, l); err != nil {
+			return sdk.WrapError(err, "migrateLog> Cannot complete log %d", l.Id)
+		}
+	}
+	// the content in the database is cleared with the update of the storage
+	if err := updateLog(tx, l); err != nil {
+		return sdk.WrapError(err, "migrateLog> Cannot update log %d", l.Id)
+	}
+	if err := tx.Commit(); err != nil {
+		return sdk.WrapError(err, "migrateLog> Cannot commit transaction")
+	}
+	log.Debug("migrateLog> log %d moved to %s", l.Id, l.Storage)
+	return nil
 }
 
-func insertLog(db gorp.SqlExecutor, logs *sdk.Log) error {
+// insertLog inserts the log of a step, it returns false without error if the log of the step already exists
+func insertLog(db gorp.SqlExecutor, logs *stepLog) (bool, error) {
 	if logs.Start == nil {
 		logs.Start, _ = ptypes.TimestampProto(time.Now())
 	}
@@ -89,26 +516,35 @@ func insertLog(db gorp.SqlExecutor, logs *sdk.Log) error {
 		logs.Done, _ = ptypes.TimestampProto(time.Now())
 	}
 	query := `
-		INSERT INTO workflow_node_run_job_logs (workflow_node_run_job_id, workflow_node_run_id, start, last_modified, done, step_order, value)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO workflow_node_run_job_logs (workflow_node_run_job_id, workflow_node_run_id, start, last_modified, done, step_order, value, storage, max_size)
+		VALUES ($1, $2, $3, $4, $5, $6, '', $7, $8)
+		ON CONFLICT (workflow_node_run_job_id, step_order) DO NOTHING
 		RETURNING ID `
 	s, errs := ptypes.Timestamp(logs.Start)
 	if errs != nil {
-		return errs
+		return false, errs
 	}
 	m, errm := ptypes.Timestamp(logs.LastModified)
 	if errm != nil {
-		return errm
+		return false, errm
 	}
 	d, errd := ptypes.Timestamp(logs.Done)
 	if errd != nil {
-		return errd
+		return false, errd
 	}
 
-	return db.QueryRow(query, logs.PipelineBuildJobID, logs.PipelineBuildID, s, m, d, logs.StepOrder, logs.Val).Scan(&logs.Id)
+	if err := db.QueryRow(query, logs.PipelineBuildJobID, logs.PipelineBuildID, s, m, d, logs.StepOrder, logs.Storage, logs.MaxSize).Scan(&logs.Id); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
-func updateLog(db gorp.SqlExecutor, logs *sdk.Log) error {
+// updateLog updates the metadata of a log, its content is updated by its store. The value column holds the buffer
+// of the logs which are not stored in the database. The objects replaced in the store are recorded to be deleted
+func updateLog(db gorp.SqlExecutor, logs *stepLog) error {
 	if logs.Start == nil {
 		logs.Start, _ = ptypes.TimestampProto(time.Now())
 	}
@@ -120,15 +556,18 @@ func updateLog(db gorp.SqlExecutor, logs *sdk.Log) error {
 	}
 
 	query := `
-		UPDATE workflow_node_run_job_logs set 
-			workflow_node_run_job_id = $1,
-			workflow_node_run_id = $2,
-			start = $3,
-			last_modified = $4,
-			done = $5,
-			step_order = $6,
-			value = $7
-		where id = $8`
+		UPDATE workflow_node_run_job_logs set
+			start = $1,
+			last_modified = $2,
+			done = $3,
+			storage = $4,
+			size = $5,
+			chunks = $6,
+			compressed = $7,
+			first_chunk = $8,
+			compressed_version = $9,
+			value = CASE WHEN $4 = 'database' THEN value ELSE $10 END
+		where id = $11`
 
 	s, errs := ptypes.Timestamp(logs.Start)
 	if errs != nil {
@@ -143,8 +582,72 @@ func updateLog(db gorp.SqlExecutor, logs *sdk.Log) error {
 		return errd
 	}
 
-	if _, err := db.Exec(query, logs.PipelineBuildJobID, logs.PipelineBuildID, s, m, d, logs.StepOrder, logs.Val, logs.Id); err != nil {
+	if _, err := db.Exec(query, s, m, d, logs.Storage, logs.Size, logs.Chunks, logs.Compressed, logs.FirstChunk, logs.CompressedVersion, logs.Pending, logs.Id); err != nil {
 		return err
 	}
+	return insertObsoleteLogObjects(db, logs)
+}
+
+// insertObsoleteLogObjects records the objects replaced in the store of a log. They are deleted by
+// deleteObsoleteLogObjects, once the transaction which replaced them is committed
+func insertObsoleteLogObjects(db gorp.SqlExecutor, l *stepLog) error {
+	for _, o := range l.obsolete {
+		if _, err := db.Exec(`INSERT INTO workflow_node_run_job_logs_obsolete (container, name) VALUES ($1, $2)`, o.container, o.name); err != nil {
+			return sdk.WrapError(err, "insertObsoleteLogObjects> Cannot record object %s/%s", o.container, o.name)
+		}
+	}
+	l.obsolete = nil
 	return nil
 }
+
+// deleteObsoleteLogObjects deletes the objects replaced in the store of the logs, by batches of objects
+func deleteObsoleteLogObjects(db *gorp.DbMap) error {
+	for {
+		rows, err := db.Query(`SELECT id, container, name FROM workflow_node_run_job_logs_obsolete ORDER BY id LIMIT 1000`)
+		if err != nil {
+			return sdk.WrapError(err, "deleteObsoleteLogObjects> Cannot load objects")
+		}
+		var ids []int64
+		var objects []*logObject
+		for rows.Next() {
+			var id int64
+			o := &logObject{}
+			if err := rows.Scan(&id, &o.container, &o.name); err != nil {
+				rows.Close()
+				return sdk.WrapError(err, "deleteObsoleteLogObjects> Cannot scan object")
+			}
+			ids = append(ids, id)
+			objects = append(objects, o)
+		}
+		rows.Close()
+		if len(objects) == 0 {
+			return nil
+		}
+
+		for i, o := range objects {
+			if err := objectstore.DeleteArtifact(o); err != nil && !strings.Contains(err.Error(), "404") {
+				return sdk.WrapError(err, "deleteObsoleteLogObjects> Cannot delete object %s/%s", o.container, o.name)
+			}
+			if _, err := db.Exec(`DELETE FROM workflow_node_run_job_logs_obsolete WHERE id = $1`, ids[i]); err != nil {
+				return sdk.WrapError(err, "deleteObsoleteLogObjects> Cannot delete object %d", ids[i])
+			}
+		}
+		log.Debug("deleteObsoleteLogObjects> %d objects deleted", len(objects))
+	}
+}
+
+// readRange reads limit bytes of a reader from an offset, all the bytes from offset with a negative limit
+func readRange(r io.Reader, offset, limit int64) ([]byte, error) {
+	if offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	if limit >= 0 {
+		r = io.LimitReader(r, limit)
+	}
+	return ioutil.ReadAll(r)
+}
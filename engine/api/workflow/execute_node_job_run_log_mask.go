@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"strconv"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/secret"
+	"github.com/ovh/cds/sdk"
+)
+
+// logMaskersTTL is how long, in seconds, the end of a step log which could be the beginning of a secret is kept,
+// waiting for the next log of the step
+const logMaskersTTL = 3600
+
+// logMaskerKey returns the key of the end of the last log received for a step, kept while it could be the
+// beginning of a secret. The logs of a step can be received by any API instance, so it is kept encrypted in the
+// shared cache
+func logMaskerKey(jobID, stepOrder int64) string {
+	return cache.Key("workflows:jobs:logmaskers", strconv.FormatInt(jobID, 10), strconv.FormatInt(stepOrder, 10))
+}
+
+// maskStepLog masks the secrets in a log received for a step. The end of a log which could be the beginning of
+// a secret is added to the next log of the step, or to its final log
+func maskStepLog(store cache.Store, secrets *sdk.SecretMasker, l *sdk.Log) (string, error) {
+	k := logMaskerKey(l.PipelineBuildJobID, l.StepOrder)
+	m := secrets.NewLogMasker()
+	var encrypted []byte
+	if store.Get(k, &encrypted) {
+		pending, err := secret.Decrypt(encrypted)
+		if err != nil {
+			return "", sdk.WrapError(err, "maskStepLog> Cannot decrypt the end of the previous log")
+		}
+		m = secrets.ResumeLogMasker(string(pending))
+	}
+
+	value := m.Mask(l.Val)
+	if isLogDone(l) {
+		value += m.Flush()
+	}
+
+	// only the ends of the logs which could be the beginning of a secret are kept
+	if !m.Pending() {
+		store.Delete(k)
+		return value, nil
+	}
+	encrypted, err := secret.Encrypt([]byte(m.Remainder()))
+	if err != nil {
+		return "", sdk.WrapError(err, "maskStepLog> Cannot encrypt the end of the log")
+	}
+	store.SetWithTTL(k, encrypted, logMaskersTTL)
+	return value, nil
+}
+
+// ResetJobLogMaskers forgets the ends of the logs kept for the steps of a job, when it is taken by a worker
+func ResetJobLogMaskers(store cache.Store, jobID int64) {
+	store.DeleteAll(cache.Key("workflows:jobs:logmaskers", strconv.FormatInt(jobID, 10), "*"))
+}
@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/secret"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_maskStepLog(t *testing.T) {
+	secret.Init("3dojuwevn94y7orh5e3t4ejtmbtstest", "", nil)
+	store := cache.NewLocalStore(60)
+	secrets := sdk.NewSecretMasker([]sdk.Variable{{Name: "cds.app.password", Value: "s3cr3t-value", Type: sdk.SecretVariable}})
+	newLog := func(jobID, step int64, value string, final bool) *sdk.Log {
+		l := sdk.NewLog(jobID, value, 1, int(step))
+		d := time.Time{}
+		if final {
+			d = time.Now()
+		}
+		l.Done, _ = ptypes.TimestampProto(d)
+		return l
+	}
+	mask := func(l *sdk.Log) string {
+		value, err := maskStepLog(store, secrets, l)
+		assert.NoError(t, err)
+		return value
+	}
+
+	// the secret is split across the logs of the step 1 of the job 10, the logs of another step are not affected
+	assert.Equal(t, "the password is ", mask(newLog(10, 1, "the password is s3c", false)))
+	assert.Equal(t, "an other step s3c\n", mask(newLog(10, 2, "an other step s3c\n", true)))
+	assert.Equal(t, "", mask(newLog(10, 1, "r3t", false)))
+
+	// the end of the log is kept encrypted in the store
+	var encrypted []byte
+	assert.True(t, store.Get(logMaskerKey(10, 1), &encrypted))
+	assert.NotContains(t, string(encrypted), "s3cr3t")
+
+	assert.Equal(t, "**cds.app.password**\n", mask(newLog(10, 1, "-value\n", false)))
+	assert.False(t, store.Get(logMaskerKey(10, 1), &encrypted))
+
+	// the end of the log is flushed by the final log
+	assert.Equal(t, "end ", mask(newLog(10, 1, "end s3cr3t", false)))
+	assert.Equal(t, "s3cr3t, end of step\n", mask(newLog(10, 1, ", end of step\n", true)))
+
+	// the ends of the logs are forgotten when the job is taken again
+	mask(newLog(11, 1, "s3cr3t", false))
+	ResetJobLogMaskers(store, 11)
+	assert.Equal(t, "new log\n", mask(newLog(11, 1, "new log\n", false)))
+}
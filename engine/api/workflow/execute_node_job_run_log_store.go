@@ -0,0 +1,315 @@
+package workflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+)
+
+// These are the stores of the content of the step logs
+const (
+	LogStoreDatabase    = "database"
+	LogStoreObjectstore = "objectstore"
+)
+
+// logStoreKind is the store of the new step logs. The store of each log is recorded with its metadata, so
+// changing the store does not affect the existing logs
+var logStoreKind = LogStoreDatabase
+
+// logStepMaxSize is the default maximum size of the logs of a step, without limit if 0
+var logStepMaxSize int64
+
+// InitLogStore sets the store of the new step logs, and the default maximum size of the logs of a step
+func InitLogStore(kind string, stepMaxSize int64) error {
+	logStepMaxSize = stepMaxSize
+	switch kind {
+	case "", LogStoreDatabase:
+		logStoreKind = LogStoreDatabase
+	case LogStoreObjectstore:
+		logStoreKind = LogStoreObjectstore
+	default:
+		return fmt.Errorf("unsupported log store: %s", kind)
+	}
+	return nil
+}
+
+// logStore stores the content of the step logs
+type logStore interface {
+	// append adds a value at the end of the content of a log
+	append(db gorp.SqlExecutor, l *stepLog, value string) error
+	// read returns limit bytes of the content from offset, all the content from offset with a negative limit
+	read(db gorp.SqlExecutor, l *stepLog, offset, limit int64) ([]byte, error)
+	// open returns a reader on the whole content
+	open(db gorp.SqlExecutor, l *stepLog) (io.ReadCloser, error)
+	// complete is called when nothing is expected to be appended to the log
+	complete(db gorp.SqlExecutor, l *stepLog) error
+	// replace replaces the whole content
+	replace(db gorp.SqlExecutor, l *stepLog, value string) error
+	// remove removes the whole content, before the update or the deletion of the metadata
+	remove(db gorp.SqlExecutor, l *stepLog) error
+}
+
+func (l *stepLog) store() logStore {
+	if l.Storage == LogStoreObjectstore {
+		return objectstoreLogStore{}
+	}
+	return databaseLogStore{}
+}
+
+// databaseLogStore stores the content of the logs in the value column of workflow_node_run_job_logs
+type databaseLogStore struct{}
+
+func (databaseLogStore) append(db gorp.SqlExecutor, l *stepLog, value string) error {
+	_, err := db.Exec(`UPDATE workflow_node_run_job_logs SET value = value || $1 WHERE id = $2`, value, l.Id)
+	return err
+}
+
+func (databaseLogStore) read(db gorp.SqlExecutor, l *stepLog, offset, limit int64) ([]byte, error) {
+	// the offset and the limit are in bytes, not in characters
+	query := `SELECT substring(convert_to(value, 'UTF8') from $1 + 1) FROM workflow_node_run_job_logs WHERE id = $2`
+	args := []interface{}{offset, l.Id}
+	if limit >= 0 {
+		query = `SELECT substring(convert_to(value, 'UTF8') from $1 + 1 for $3) FROM workflow_node_run_job_logs WHERE id = $2`
+		args = append(args, limit)
+	}
+	var b []byte
+	if err := db.QueryRow(query, args...).Scan(&b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s databaseLogStore) open(db gorp.SqlExecutor, l *stepLog) (io.ReadCloser, error) {
+	b, err := s.read(db, l, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (databaseLogStore) complete(db gorp.SqlExecutor, l *stepLog) error {
+	return nil
+}
+
+func (databaseLogStore) replace(db gorp.SqlExecutor, l *stepLog, value string) error {
+	_, err := db.Exec(`UPDATE workflow_node_run_job_logs SET value = $1 WHERE id = $2`, value, l.Id)
+	return err
+}
+
+func (databaseLogStore) remove(db gorp.SqlExecutor, l *stepLog) error {
+	return nil
+}
+
+// objectstoreLogStore stores the content of the logs in the objectstore. As the objects cannot be appended,
+// the values are buffered in the value column of the log, and the buffer is stored in a chunk object when it
+// reaches logChunkSize. When the log is completed, the chunks are gathered in a compressed object. The content of
+// a log is its compressed object, if any, followed by its chunks and its buffer.
+// The objects are never overwritten while they are used by a log: the chunks are numbered from first_chunk, which
+// only increases, and the compressed object is numbered by its version. The objects replaced are deleted once the
+// transaction updating the log is committed
+type objectstoreLogStore struct{}
+
+// logChunkSize is the size of the buffer of a log stored in the objectstore
+var logChunkSize = 256 * 1024
+
+// logObject is an object of the content of a log, in a container by node run
+type logObject struct {
+	container, name string
+}
+
+func (o *logObject) GetName() string {
+	return o.name
+}
+
+func (o *logObject) GetPath() string {
+	return o.container
+}
+
+func (objectstoreLogStore) chunk(l *stepLog, i int) *logObject {
+	return &logObject{
+		container: fmt.Sprintf("logs-%d", l.PipelineBuildID),
+		name:      fmt.Sprintf("%d-%d-%d.log", l.PipelineBuildJobID, l.StepOrder, l.FirstChunk+i),
+	}
+}
+
+func (objectstoreLogStore) compressed(l *stepLog) *logObject {
+	o := &logObject{
+		container: fmt.Sprintf("logs-%d", l.PipelineBuildID),
+		name:      fmt.Sprintf("%d-%d.log.gz", l.PipelineBuildJobID, l.StepOrder),
+	}
+	if l.CompressedVersion > 0 {
+		o.name = fmt.Sprintf("%d-%d-v%d.log.gz", l.PipelineBuildJobID, l.StepOrder, l.CompressedVersion)
+	}
+	return o
+}
+
+func (s objectstoreLogStore) append(db gorp.SqlExecutor, l *stepLog, value string) error {
+	l.Pending += value
+	if len(l.Pending) < logChunkSize {
+		return nil
+	}
+	if _, err := objectstore.StoreArtifact(s.chunk(l, l.Chunks), ioutil.NopCloser(strings.NewReader(l.Pending))); err != nil {
+		return err
+	}
+	l.Chunks++
+	l.Pending = ""
+	return nil
+}
+
+func (s objectstoreLogStore) read(db gorp.SqlExecutor, l *stepLog, offset, limit int64) ([]byte, error) {
+	r, err := s.open(db, l)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readRange(r, offset, limit)
+}
+
+func (s objectstoreLogStore) open(db gorp.SqlExecutor, l *stepLog) (io.ReadCloser, error) {
+	r := &logChunksReader{pending: strings.NewReader(l.Pending)}
+	if l.Compressed {
+		r.objects = append(r.objects, s.compressed(l))
+	}
+	for i := 0; i < l.Chunks; i++ {
+		r.objects = append(r.objects, s.chunk(l, i))
+	}
+	r.compressed = l.Compressed
+	return r, nil
+}
+
+func (s objectstoreLogStore) complete(db gorp.SqlExecutor, l *stepLog) error {
+	if l.Chunks == 0 && l.Pending == "" {
+		return nil
+	}
+
+	r, err := s.open(db, l)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// the content is compressed in memory, as the previous compressed object may be read
+	buf := new(bytes.Buffer)
+	w := gzip.NewWriter(buf)
+	if _, err := io.Copy(w, r); err != nil {
+		return sdk.WrapError(err, "objectstoreLogStore.complete> Cannot read log")
+	}
+	if err := w.Close(); err != nil {
+		return sdk.WrapError(err, "objectstoreLogStore.complete> Cannot compress log")
+	}
+
+	s.removeObjects(l)
+	l.CompressedVersion++
+	if _, err := objectstore.StoreArtifact(s.compressed(l), ioutil.NopCloser(buf)); err != nil {
+		return sdk.WrapError(err, "objectstoreLogStore.complete> Cannot store compressed log")
+	}
+	l.Compressed = true
+	return nil
+}
+
+func (s objectstoreLogStore) replace(db gorp.SqlExecutor, l *stepLog, value string) error {
+	if err := s.remove(db, l); err != nil {
+		return err
+	}
+	return s.append(db, l, value)
+}
+
+func (s objectstoreLogStore) remove(db gorp.SqlExecutor, l *stepLog) error {
+	s.removeObjects(l)
+	return nil
+}
+
+// removeObjects empties a log, its objects are deleted once its metadata is updated
+func (s objectstoreLogStore) removeObjects(l *stepLog) {
+	if l.Compressed {
+		l.obsolete = append(l.obsolete, s.compressed(l))
+		l.Compressed = false
+	}
+	for i := 0; i < l.Chunks; i++ {
+		l.obsolete = append(l.obsolete, s.chunk(l, i))
+	}
+	l.FirstChunk += l.Chunks
+	l.Chunks = 0
+	l.Pending = ""
+}
+
+// logChunksReader reads the objects of a log one after the other, then its buffer. The first object is gzipped
+// if compressed is set
+type logChunksReader struct {
+	objects    []*logObject
+	compressed bool
+	current    io.ReadCloser
+	gz         *gzip.Reader
+	pending    io.Reader
+}
+
+func (r *logChunksReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.objects) == 0 {
+				if r.pending == nil {
+					return 0, io.EOF
+				}
+				return r.pending.Read(p)
+			}
+			rc, err := objectstore.FetchArtifact(r.objects[0])
+			if err != nil {
+				return 0, err
+			}
+			r.objects = r.objects[1:]
+			r.current = rc
+			if r.compressed {
+				r.compressed = false
+				gz, err := gzip.NewReader(rc)
+				if err != nil {
+					rc.Close()
+					r.current = nil
+					return 0, err
+				}
+				r.gz = gz
+			}
+		}
+
+		var n int
+		var err error
+		if r.gz != nil {
+			n, err = r.gz.Read(p)
+		} else {
+			n, err = r.current.Read(p)
+		}
+		if err == io.EOF {
+			r.closeCurrent()
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *logChunksReader) closeCurrent() {
+	if r.gz != nil {
+		r.gz.Close()
+		r.gz = nil
+	}
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+}
+
+func (r *logChunksReader) Close() error {
+	r.closeCurrent()
+	r.objects = nil
+	r.pending = nil
+	return nil
+}
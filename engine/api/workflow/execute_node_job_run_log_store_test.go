@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_objectstoreLogStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cds-logs")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	cfg := objectstore.Config{Kind: objectstore.Filesystem, Options: objectstore.ConfigOptions{Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: dir}}}
+	if !assert.NoError(t, objectstore.Initialize(context.Background(), cfg)) {
+		t.FailNow()
+	}
+
+	defer func(size int) { logChunkSize = size }(logChunkSize)
+	logChunkSize = 10
+
+	l := &stepLog{Log: sdk.Log{PipelineBuildID: 1, PipelineBuildJobID: 2, StepOrder: 3}, Storage: LogStoreObjectstore}
+	s := l.store()
+
+	// the values are buffered until they fill a chunk
+	assert.NoError(t, s.append(nil, l, "line 1\n"))
+	assert.Equal(t, 0, l.Chunks)
+	assert.Equal(t, "line 1\n", l.Pending)
+	assert.NoError(t, s.append(nil, l, "line 2\n"))
+	assert.Equal(t, 1, l.Chunks)
+	assert.Equal(t, "", l.Pending)
+	assert.NoError(t, s.append(nil, l, "line 3\n"))
+
+	b, err := s.read(nil, l, 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 1\nline 2\nline 3\n", string(b))
+	b, err = s.read(nil, l, 5, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\nli", string(b))
+	b, err = s.read(nil, l, 12, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "2\nline 3\n", string(b))
+
+	// the chunks are gathered in a compressed object, the chunks are deleted once the log is updated
+	assert.NoError(t, s.complete(nil, l))
+	assert.Equal(t, 0, l.Chunks)
+	assert.Equal(t, 1, l.FirstChunk)
+	assert.Equal(t, "", l.Pending)
+	assert.True(t, l.Compressed)
+	assert.Equal(t, []*logObject{{container: "logs-1", name: "2-3-0.log"}}, l.obsolete)
+	_, err = os.Stat(dir + "/logs-1/2-3-0.log")
+	assert.NoError(t, err)
+	l.obsolete = nil
+
+	// a completed log can be appended again, the new compressed object does not replace the previous one
+	assert.NoError(t, s.append(nil, l, "line 4\n"))
+	b, err = s.read(nil, l, 14, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 3\nline 4\n", string(b))
+
+	assert.NoError(t, s.complete(nil, l))
+	assert.Equal(t, 2, l.CompressedVersion)
+	assert.Equal(t, []*logObject{{container: "logs-1", name: "2-3-v1.log.gz"}}, l.obsolete)
+	r, err := s.open(nil, l)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer r.Close()
+	tail, err := tailLines(r, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "line 3\nline 4\n", tail)
+}
+
+func Test_tailLines(t *testing.T) {
+	tail, err := tailLines(strings.NewReader("1\n2\n3\n4"), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "3\n4", tail)
+
+	tail, err = tailLines(strings.NewReader("1\n2\n"), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n", tail)
+}
+
+func Test_readRange(t *testing.T) {
+	b, err := readRange(strings.NewReader("0123456789"), 8, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "89", string(b))
+
+	b, err = readRange(strings.NewReader("0123456789"), 12, -1)
+	assert.NoError(t, err)
+	assert.Empty(t, b)
+}
+
+func Test_truncateStepLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cds-logs")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	cfg := objectstore.Config{Kind: objectstore.Filesystem, Options: objectstore.ConfigOptions{Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: dir}}}
+	if !assert.NoError(t, objectstore.Initialize(context.Background(), cfg)) {
+		t.FailNow()
+	}
+
+	l := &stepLog{Log: sdk.Log{PipelineBuildID: 1, PipelineBuildJobID: 2, StepOrder: 3}, Storage: LogStoreObjectstore, MaxSize: 400}
+	var lines string
+	for i := 0; i < 20; i++ {
+		lines += fmt.Sprintf("line %02d\n", i)
+	}
+	assert.NoError(t, l.store().append(nil, l, lines))
+	assert.NoError(t, l.store().complete(nil, l))
+	l.Size = int64(len(lines))
+
+	assert.NoError(t, truncateStepLog(nil, l, lines))
+	assert.True(t, l.Size <= l.MaxSize*3/4)
+	assert.Equal(t, 0, l.Chunks)
+	assert.False(t, l.Compressed)
+	assert.Equal(t, []*logObject{{container: "logs-1", name: "2-3-v1.log.gz"}}, l.obsolete)
+
+	b, err := l.store().read(nil, l, 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, l.Size, int64(len(b)))
+	assert.True(t, strings.HasPrefix(string(b), fmt.Sprintf(logTruncatedMarker, 400)))
+	assert.True(t, strings.HasSuffix(string(b), "\nline 18\nline 19\n"))
+}
+
+func Test_keepLogTail(t *testing.T) {
+	assert.Equal(t, "line 2\n", string(keepLogTail([]byte("line 1\nline 2\n"), 9)))
+	assert.Equal(t, "line 1\nline 2\n", string(keepLogTail([]byte("line 1\nline 2\n"), 20)))
+	// a single line is cut
+	assert.Equal(t, "long line", string(keepLogTail([]byte("a very long line"), 9)))
+	// a single line is not cut in the middle of a rune
+	assert.Equal(t, "örld", string(keepLogTail([]byte("héllo wörld"), 5)))
+	assert.Equal(t, "rld", string(keepLogTail([]byte("héllo wörld"), 4)))
+}
@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// jobStopChannel is the channel of the cache where the API instances publish the stop signals of the jobs
+const jobStopChannel = "workflowJobStops"
+
+// jobStopBroker dispatches the stop signals of the jobs to the workers watching them on this API instance
+type jobStopBroker struct {
+	mutex    sync.Mutex
+	watchers map[int64][]chan sdk.WorkflowNodeJobRunStop
+}
+
+var stopBroker = &jobStopBroker{
+	watchers: map[int64][]chan sdk.WorkflowNodeJobRunStop{},
+}
+
+// subscribeJobStops receives the stop signals published by all the API instances, and dispatches them to the workers
+func subscribeJobStops(c context.Context, store cache.Store) {
+	pubSub := store.Subscribe(jobStopChannel)
+	for {
+		msg, err := store.GetMessageFromSubscription(c, pubSub)
+		if c.Err() != nil {
+			log.Error("workflow.subscribeJobStops> Exiting: %v", c.Err())
+			return
+		}
+		if err != nil {
+			log.Warning("workflow.subscribeJobStops> Cannot get message %s: %s", msg, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		if msg == "" {
+			continue
+		}
+
+		var stop sdk.WorkflowNodeJobRunStop
+		if err := json.Unmarshal([]byte(msg), &stop); err != nil {
+			log.Warning("workflow.subscribeJobStops> Cannot unmarshal message %s: %s", msg, err)
+			continue
+		}
+		stopBroker.dispatch(stop)
+	}
+}
+
+func (b *jobStopBroker) dispatch(stop sdk.WorkflowNodeJobRunStop) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, w := range b.watchers[stop.JobID] {
+		select {
+		case w <- stop:
+		default:
+		}
+	}
+}
+
+func (b *jobStopBroker) unwatch(jobID int64, w chan sdk.WorkflowNodeJobRunStop) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	ws := b.watchers[jobID]
+	for i := range ws {
+		if ws[i] == w {
+			b.watchers[jobID] = append(ws[:i], ws[i+1:]...)
+			break
+		}
+	}
+	if len(b.watchers[jobID]) == 0 {
+		delete(b.watchers, jobID)
+	}
+}
+
+// WaitNodeJobRunStop waits for the stop of a job. It returns nil if the context is done before
+func WaitNodeJobRunStop(c context.Context, jobID int64) *sdk.WorkflowNodeJobRunStop {
+	w := make(chan sdk.WorkflowNodeJobRunStop, 1)
+	stopBroker.mutex.Lock()
+	stopBroker.watchers[jobID] = append(stopBroker.watchers[jobID], w)
+	stopBroker.mutex.Unlock()
+	defer stopBroker.unwatch(jobID, w)
+
+	select {
+	case stop := <-w:
+		return &stop
+	case <-c.Done():
+		return nil
+	}
+}
+
+// publishNodeJobRunStop sends the stop signal of a job to the worker running it, through the API instance it is
+// connected to
+func publishNodeJobRunStop(store cache.Store, stop sdk.WorkflowNodeJobRunStop) {
+	b, err := json.Marshal(stop)
+	if err != nil {
+		log.Warning("workflow.publishNodeJobRunStop> Cannot marshal stop of job %d: %s", stop.JobID, err)
+		return
+	}
+	store.Publish(jobStopChannel, string(b))
+}
+
+// disableNodeJobRunWorker disables the worker running a stopped job, so that the hatchery which spawned it kills its
+// instance. The workers not spawned by a hatchery are kept
+func disableNodeJobRunWorker(db gorp.SqlExecutor, store cache.Store, workerID string) error {
+	query := `UPDATE worker SET status = $1 WHERE id = $2 AND hatchery_id <> 0`
+	if _, err := db.Exec(query, sdk.StatusDisabled.String(), workerID); err != nil {
+		return sdk.WrapError(err, "disableNodeJobRunWorker> Unable to disable worker %s", workerID)
+	}
+	store.Delete(cache.Key("worker", workerID))
+	return nil
+}
@@ -0,0 +1,53 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_WaitNodeJobRunStop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stops := make(chan *sdk.WorkflowNodeJobRunStop)
+	go func() {
+		stops <- WaitNodeJobRunStop(ctx, 42)
+	}()
+
+	// Waits for the watcher to be registered
+	for {
+		stopBroker.mutex.Lock()
+		n := len(stopBroker.watchers[42])
+		stopBroker.mutex.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stopBroker.dispatch(sdk.WorkflowNodeJobRunStop{JobID: 43, StoppedBy: "bar"})
+	stopBroker.dispatch(sdk.WorkflowNodeJobRunStop{JobID: 42, Reason: "wrong branch", StoppedBy: "foo"})
+
+	stop := <-stops
+	if assert.NotNil(t, stop) {
+		assert.Equal(t, int64(42), stop.JobID)
+		assert.Equal(t, "wrong branch", stop.Reason)
+		assert.Equal(t, "foo", stop.StoppedBy)
+	}
+
+	stopBroker.mutex.Lock()
+	_, ok := stopBroker.watchers[42]
+	stopBroker.mutex.Unlock()
+	assert.False(t, ok)
+}
+
+func Test_WaitNodeJobRunStopContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, WaitNodeJobRunStop(ctx, 44))
+}
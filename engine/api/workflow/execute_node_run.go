@@ -11,6 +11,7 @@ import (
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/environment"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
@@ -205,6 +206,14 @@ func execute(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.
 			chanEvent <- *n
 		}
 
+		node := updatedWorkflowRun.Workflow.GetNode(n.WorkflowNodeID)
+		//Record the deployment if the node is bound to an environment
+		if node != nil && n.Status == sdk.StatusSuccess.String() {
+			if err := insertNodeRunDeployment(db, updatedWorkflowRun, node, n); err != nil {
+				return sdk.WrapError(err, "workflow.execute> Unable to record deployment of node run %d", n.ID)
+			}
+		}
+
 		if n.Status != sdk.StatusStopped.String() {
 			if _, err := processWorkflowRun(dbCopy, db, store, p, updatedWorkflowRun, nil, nil, nil, chanEvent); err != nil {
 				return sdk.WrapError(err, "workflow.execute> Unable to reprocess workflow !")
@@ -216,7 +225,16 @@ func execute(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.
 			return sdk.WrapError(err, "workflow.execute> Unable to delete node %d job runs ", n.ID)
 		}
 
-		node := updatedWorkflowRun.Workflow.GetNode(n.WorkflowNodeID)
+		//Complete the logs of the steps which did not send their final log
+		if err := completeNodeRunLogs(db, n.ID); err != nil {
+			log.Warning("workflow.execute> Unable to complete logs of node %d: %v", n.ID, err)
+		}
+
+		//Release the concurrency group held by the node run
+		if err := releaseConcurrencyGroup(dbCopy, db, store, p, n); err != nil {
+			return sdk.WrapError(err, "workflow.execute> Unable to release concurrency group of node %d", n.ID)
+		}
+
 		//Do we release a mutex ?
 		//Try to find one node run of the same node from the same workflow at status Waiting
 		if node != nil && node.Context != nil && node.Context.Mutex {
@@ -262,6 +280,16 @@ func execute(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.
 				return sdk.WrapError(err, "workflow.execute> Unable to update workflow run %d after mutex release", workflowRun.ID)
 			}
 
+			if node.Context.Concurrency != nil {
+				queued, err := enterConcurrencyGroup(db, workflowRun, node, waitingRun)
+				if err != nil {
+					return sdk.WrapError(err, "workflow.execute> Unable to enter concurrency group")
+				}
+				if queued {
+					return nil
+				}
+			}
+
 			log.Debug("workflow.execute> process the node run %d because mutex has been released", waitingRun.ID)
 			//TODO: how to manage the chanEvent ? Need to discuss about it
 			if err := execute(dbCopy, db, store, p, waitingRun, nil); err != nil {
@@ -272,6 +300,44 @@ func execute(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.
 	return nil
 }
 
+// insertNodeRunDeployment records a deployment on the environment of the node for a successful node run
+func insertNodeRunDeployment(db gorp.SqlExecutor, wr *sdk.WorkflowRun, node *sdk.WorkflowNode, n *sdk.WorkflowNodeRun) error {
+	if node.Context == nil || node.Context.Application == nil || node.Context.Environment == nil {
+		return nil
+	}
+	if node.Context.EnvironmentID == 0 || node.Context.EnvironmentID == sdk.DefaultEnv.ID {
+		return nil
+	}
+
+	params := sdk.ParametersToMap(n.BuildParameters)
+	d := sdk.EnvironmentDeployment{
+		EnvironmentID:     node.Context.EnvironmentID,
+		ApplicationID:     node.Context.ApplicationID,
+		ApplicationName:   node.Context.Application.Name,
+		Version:           params["cds.version"],
+		VCSBranch:         n.VCSBranch,
+		VCSHash:           n.VCSHash,
+		WorkflowName:      wr.Workflow.Name,
+		WorkflowRunNumber: n.Number,
+		WorkflowNodeName:  node.Name,
+		WorkflowNodeRunID: n.ID,
+		Date:              n.Done,
+	}
+	switch {
+	case n.Manual != nil && n.Manual.User.Username != "":
+		d.Username = n.Manual.User.Username
+	case params["cds.triggered_by.username"] != "":
+		d.Username = params["cds.triggered_by.username"]
+	default:
+		d.Username = params[tagGitAuthor]
+	}
+	if d.Date.IsZero() {
+		d.Date = time.Now()
+	}
+
+	return environment.InsertDeployment(db, &d)
+}
+
 func addJobsToQueue(db gorp.SqlExecutor, stage *sdk.Stage, run *sdk.WorkflowNodeRun, chanEvent chan<- interface{}) error {
 	log.Debug("addJobsToQueue> add %d in stage %s", run.ID, stage.Name)
 	conditionsOK, err := sdk.WorkflowCheckConditions(stage.Conditions(), run.BuildParameters)
@@ -287,9 +353,15 @@ func addJobsToQueue(db gorp.SqlExecutor, stage *sdk.Stage, run *sdk.WorkflowNode
 	}
 
 	skippedOrDisabledJobs := 0
+	reusedJobs := 0
 	//Browse the jobs
 	for j := range stage.Jobs {
 		job := &stage.Jobs[j]
+		//The jobs reused from a previous node run are not run again
+		if isJobReused(stage, *job) {
+			reusedJobs++
+			continue
+		}
 		errs := sdk.MultiError{}
 		//Process variables for the jobs
 		jobParams, errParam := getNodeJobRunParameters(db, *job, run, stage)
@@ -355,7 +427,7 @@ func addJobsToQueue(db gorp.SqlExecutor, stage *sdk.Stage, run *sdk.WorkflowNode
 		stage.RunJobs = append(stage.RunJobs, wjob)
 	}
 
-	if skippedOrDisabledJobs == len(stage.Jobs) {
+	if reusedJobs == 0 && skippedOrDisabledJobs == len(stage.Jobs) {
 		stage.Status = sdk.StatusSkipped
 	}
 
@@ -417,7 +489,8 @@ func syncStage(db gorp.SqlExecutor, store cache.Store, stage *sdk.Stage) (bool,
 			case sdk.StatusFail.String():
 				finalStatus = sdk.StatusFail
 				break finalStageLoop
-			case sdk.StatusSuccess.String():
+			case sdk.StatusSuccess.String(), sdk.StatusWarning.String():
+				// a job in warning has failed steps which continue on error, it does not fail the stage
 				if finalStatus != sdk.StatusFail {
 					finalStatus = sdk.StatusSuccess
 				}
@@ -490,8 +563,9 @@ func NodeBuildParametersFromWorkflow(db gorp.SqlExecutor, store cache.Store, pro
 	return res, nil
 }
 
-// StopWorkflowNodeRun to stop a workflow node run with a specific spawn info
-func StopWorkflowNodeRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, nodeRun sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo, chanEvent chan<- interface{}) error {
+// StopWorkflowNodeRun to stop a workflow node run with a specific spawn info. The workers running its jobs are
+// notified with the user who stopped it and the reason of the stop
+func StopWorkflowNodeRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, nodeRun sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo, stop sdk.WorkflowNodeJobRunStop, chanEvent chan<- interface{}) error {
 	const stopWorkflowNodeRunNBWorker = 5
 	var wg sync.WaitGroup
 	// Load node job run ID
@@ -504,7 +578,7 @@ func StopWorkflowNodeRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, n
 	chanNodeJobRunDone := make(chan bool, stopWorkflowNodeRunNBWorker)
 	chanErr := make(chan error, stopWorkflowNodeRunNBWorker)
 	for i := 0; i < stopWorkflowNodeRunNBWorker && i < len(ids); i++ {
-		go stopWorkflowNodeJobRun(db, store, proj, &nodeRun, stopInfos, chanNjrID, chanEvent, chanErr, chanNodeJobRunDone, &wg)
+		go stopWorkflowNodeJobRun(db, store, proj, &nodeRun, stopInfos, stop, chanNjrID, chanEvent, chanErr, chanNodeJobRunDone, &wg)
 	}
 
 	wg.Add(len(ids))
@@ -530,6 +604,8 @@ func StopWorkflowNodeRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, n
 			if !sdk.StatusIsTerminated(runj.Status) {
 				runj.Status = sdk.StatusStopped.String()
 				runj.Done = time.Now()
+				runj.StopReason = stop.Reason
+				runj.StoppedBy = stop.StoppedBy
 			}
 			for iStep := range runj.Job.StepStatus {
 				stepStat := &runj.Job.StepStatus[iStep]
@@ -553,7 +629,7 @@ func StopWorkflowNodeRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, n
 	return nil
 }
 
-func stopWorkflowNodeJobRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, nodeRun *sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo, chanNjrID <-chan int64, chanNodeJobRun chan<- interface{}, chanErr chan<- error, chanDone chan<- bool, wg *sync.WaitGroup) {
+func stopWorkflowNodeJobRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project, nodeRun *sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo, stop sdk.WorkflowNodeJobRunStop, chanNjrID <-chan int64, chanNodeJobRun chan<- interface{}, chanErr chan<- error, chanDone chan<- bool, wg *sync.WaitGroup) {
 	for njrID := range chanNjrID {
 		tx, errTx := db.Begin()
 		if errTx != nil {
@@ -578,6 +654,9 @@ func stopWorkflowNodeJobRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project
 		}
 
 		njr.SpawnInfos = append(njr.SpawnInfos, stopInfos)
+		wasBuilding := njr.Status == sdk.StatusBuilding.String()
+		njr.StopReason = stop.Reason
+		njr.StoppedBy = stop.StoppedBy
 		if err := UpdateNodeJobRunStatus(db, tx, store, proj, njr, sdk.StatusStopped, chanNodeJobRun); err != nil {
 			chanErr <- sdk.WrapError(err, "StopWorkflowNodeRun> Cannot update node job run")
 			tx.Rollback()
@@ -585,12 +664,27 @@ func stopWorkflowNodeJobRun(db *gorp.DbMap, store cache.Store, proj *sdk.Project
 			return
 		}
 
+		if wasBuilding && njr.Job.WorkerID != "" {
+			if err := disableNodeJobRunWorker(tx, store, njr.Job.WorkerID); err != nil {
+				chanErr <- sdk.WrapError(err, "StopWorkflowNodeRun> Cannot disable worker of node job run %d", njr.ID)
+				tx.Rollback()
+				wg.Done()
+				return
+			}
+		}
+
 		if err := tx.Commit(); err != nil {
 			chanErr <- sdk.WrapError(err, "StopWorkflowNodeRun> Cannot commit transaction")
 			tx.Rollback()
 			wg.Done()
 			return
 		}
+
+		if wasBuilding {
+			jobStop := stop
+			jobStop.JobID = njr.ID
+			publishNodeJobRunStop(store, jobStop)
+		}
 		chanDone <- true
 		wg.Done()
 	}
@@ -0,0 +1,325 @@
+package workflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// lockConcurrencyGroup locks the concurrency group until the end of the transaction
+func lockConcurrencyGroup(db gorp.SqlExecutor, group string) error {
+	if _, err := db.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", "workflow_node_run_concurrency:"+group); err != nil {
+		return sdk.WrapError(err, "lockConcurrencyGroup> Unable to lock concurrency group %s", group)
+	}
+	return nil
+}
+
+func insertNodeRunConcurrency(db gorp.SqlExecutor, c *sdk.WorkflowNodeRunConcurrency) error {
+	dbConcurrency := NodeRunConcurrency(*c)
+	if err := db.Insert(&dbConcurrency); err != nil {
+		return sdk.WrapError(err, "insertNodeRunConcurrency> Unable to insert node run %d in concurrency group %s", c.WorkflowNodeRunID, c.Group)
+	}
+	return nil
+}
+
+func updateNodeRunConcurrencyStatus(db gorp.SqlExecutor, c *sdk.WorkflowNodeRunConcurrency) error {
+	if _, err := db.Exec("UPDATE workflow_node_run_concurrency SET status = $1 WHERE workflow_node_run_id = $2", c.Status, c.WorkflowNodeRunID); err != nil {
+		return sdk.WrapError(err, "updateNodeRunConcurrencyStatus> Unable to update node run %d in concurrency group %s", c.WorkflowNodeRunID, c.Group)
+	}
+	return nil
+}
+
+func deleteNodeRunConcurrency(db gorp.SqlExecutor, nodeRunID int64) error {
+	if _, err := db.Exec("DELETE FROM workflow_node_run_concurrency WHERE workflow_node_run_id = $1", nodeRunID); err != nil {
+		return sdk.WrapError(err, "deleteNodeRunConcurrency> Unable to delete node run %d from concurrency group", nodeRunID)
+	}
+	return nil
+}
+
+func loadNodeRunConcurrency(db gorp.SqlExecutor, nodeRunID int64) (*sdk.WorkflowNodeRunConcurrency, error) {
+	dbConcurrency := NodeRunConcurrency{}
+	if err := db.SelectOne(&dbConcurrency, "SELECT * FROM workflow_node_run_concurrency WHERE workflow_node_run_id = $1", nodeRunID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, sdk.WrapError(err, "loadNodeRunConcurrency> Unable to load concurrency of node run %d", nodeRunID)
+	}
+	c := sdk.WorkflowNodeRunConcurrency(dbConcurrency)
+	return &c, nil
+}
+
+// countNodeRunConcurrency counts the node runs in a concurrency group. If waiting is true, it counts the node runs
+// waiting for the group, otherwise it counts the node runs holding the group
+func countNodeRunConcurrency(db gorp.SqlExecutor, group string, waiting bool) (int, error) {
+	query := "SELECT count(1) FROM workflow_node_run_concurrency WHERE group_name = $1 AND status <> $2"
+	if waiting {
+		query = "SELECT count(1) FROM workflow_node_run_concurrency WHERE group_name = $1 AND status = $2"
+	}
+	n, err := db.SelectInt(query, group, sdk.WorkflowNodeConcurrencyWaiting)
+	if err != nil {
+		return 0, sdk.WrapError(err, "countNodeRunConcurrency> Unable to count node runs in concurrency group %s", group)
+	}
+	return int(n), nil
+}
+
+// LoadConcurrencyGroup loads the node runs holding or waiting for a concurrency group, from the oldest
+func LoadConcurrencyGroup(db gorp.SqlExecutor, group string) ([]sdk.WorkflowNodeRunConcurrency, error) {
+	return loadNodeRunsConcurrency(db, "workflow_node_run_concurrency.group_name = $1", group)
+}
+
+// LoadReadyConcurrencyNodeRuns loads the node runs which have been released from their concurrency group but not yet executed
+func LoadReadyConcurrencyNodeRuns(db gorp.SqlExecutor) ([]sdk.WorkflowNodeRunConcurrency, error) {
+	return loadNodeRunsConcurrency(db, "workflow_node_run_concurrency.status = $1", sdk.WorkflowNodeConcurrencyReady)
+}
+
+func loadNodeRunsConcurrency(db gorp.SqlExecutor, where string, args ...interface{}) ([]sdk.WorkflowNodeRunConcurrency, error) {
+	var res []struct {
+		NodeRunConcurrency
+		ProjectKey       string `db:"projectkey"`
+		WorkflowName     string `db:"workflow_name"`
+		Number           int64  `db:"num"`
+		WorkflowNodeName string `db:"workflow_node_name"`
+	}
+	query := `SELECT workflow_node_run_concurrency.*, project.projectkey, workflow.name AS workflow_name, workflow_node_run.num, workflow_node_run.workflow_node_name
+	FROM workflow_node_run_concurrency
+	JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_concurrency.workflow_node_run_id
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	JOIN workflow ON workflow.id = workflow_run.workflow_id
+	JOIN project ON project.id = workflow.project_id
+	WHERE ` + where + `
+	ORDER BY workflow_node_run_concurrency.queued`
+	if _, err := db.Select(&res, query, args...); err != nil {
+		return nil, sdk.WrapError(err, "loadNodeRunsConcurrency> Unable to load node runs in concurrency group")
+	}
+
+	concurrencies := make([]sdk.WorkflowNodeRunConcurrency, len(res))
+	for i := range res {
+		concurrencies[i] = sdk.WorkflowNodeRunConcurrency(res[i].NodeRunConcurrency)
+		concurrencies[i].ProjectKey = res[i].ProjectKey
+		concurrencies[i].WorkflowName = res[i].WorkflowName
+		concurrencies[i].Number = res[i].Number
+		concurrencies[i].WorkflowNodeName = res[i].WorkflowNodeName
+	}
+	return concurrencies, nil
+}
+
+// enterConcurrencyGroup adds the node run in the concurrency group of the node. It returns true if the node run
+// has been queued because the group is full: it will be executed when the group is released
+func enterConcurrencyGroup(db gorp.SqlExecutor, w *sdk.WorkflowRun, n *sdk.WorkflowNode, run *sdk.WorkflowNodeRun) (bool, error) {
+	c := n.Context.Concurrency
+	if err := lockConcurrencyGroup(db, c.Group); err != nil {
+		return false, err
+	}
+
+	if c.CancelPending {
+		if err := cancelPendingConcurrency(db, w, n, run); err != nil {
+			return false, err
+		}
+	}
+
+	nbRunning, errR := countNodeRunConcurrency(db, c.Group, false)
+	if errR != nil {
+		return false, errR
+	}
+	nbWaiting, errW := countNodeRunConcurrency(db, c.Group, true)
+	if errW != nil {
+		return false, errW
+	}
+
+	rc := sdk.WorkflowNodeRunConcurrency{
+		WorkflowNodeRunID: run.ID,
+		Group:             c.Group,
+		Max:               c.Limit(),
+		Status:            sdk.WorkflowNodeConcurrencyRunning,
+		Queued:            time.Now(),
+	}
+	if nbRunning >= c.Limit() || nbWaiting > 0 {
+		rc.Status = sdk.WorkflowNodeConcurrencyWaiting
+	}
+	if err := insertNodeRunConcurrency(db, &rc); err != nil {
+		return false, err
+	}
+
+	if rc.Status == sdk.WorkflowNodeConcurrencyRunning {
+		return false, nil
+	}
+
+	log.Debug("enterConcurrencyGroup> Noderun %s processed but not executed because of concurrency group %s", n.Name, c.Group)
+	AddWorkflowRunInfo(w, false, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeConcurrencyQueued.ID,
+		Args: []interface{}{n.Name, c.Group, nbWaiting + 1},
+	})
+	if err := UpdateWorkflowRun(db, w); err != nil {
+		return false, sdk.WrapError(err, "enterConcurrencyGroup> Unable to update workflow run")
+	}
+	return true, nil
+}
+
+// cancelPendingConcurrency stops the older node runs of the same workflow node which are waiting for the concurrency group
+func cancelPendingConcurrency(db gorp.SqlExecutor, w *sdk.WorkflowRun, n *sdk.WorkflowNode, run *sdk.WorkflowNodeRun) error {
+	query := `SELECT workflow_node_run_concurrency.workflow_node_run_id
+	FROM workflow_node_run_concurrency
+	JOIN workflow_node_run ON workflow_node_run.id = workflow_node_run_concurrency.workflow_node_run_id
+	JOIN workflow_run ON workflow_run.id = workflow_node_run.workflow_run_id
+	WHERE workflow_node_run_concurrency.group_name = $1
+	AND workflow_node_run_concurrency.status = $2
+	AND workflow_run.workflow_id = $3
+	AND workflow_node_run.workflow_node_name = $4
+	AND workflow_node_run.id <> $5`
+	var ids []int64
+	if _, err := db.Select(&ids, query, n.Context.Concurrency.Group, sdk.WorkflowNodeConcurrencyWaiting, w.WorkflowID, n.Name, run.ID); err != nil {
+		return sdk.WrapError(err, "cancelPendingConcurrency> Unable to load pending node runs")
+	}
+
+	for _, id := range ids {
+		pending, errL := LoadNodeRunByID(db, id, false)
+		if errL != nil {
+			return sdk.WrapError(errL, "cancelPendingConcurrency> Unable to load node run %d", id)
+		}
+		if err := deleteNodeRunConcurrency(db, id); err != nil {
+			return err
+		}
+		if sdk.StatusIsTerminated(pending.Status) {
+			continue
+		}
+
+		pending.Status = sdk.StatusStopped.String()
+		pending.Done = time.Now()
+		if err := UpdateNodeRun(db, pending); err != nil {
+			return sdk.WrapError(err, "cancelPendingConcurrency> Unable to stop node run %d", id)
+		}
+
+		pendingRun := w
+		if pending.WorkflowRunID != w.ID {
+			var errR error
+			pendingRun, errR = LoadRunByID(db, pending.WorkflowRunID, false)
+			if errR != nil {
+				return sdk.WrapError(errR, "cancelPendingConcurrency> Unable to load workflow run %d", pending.WorkflowRunID)
+			}
+		}
+		AddWorkflowRunInfo(pendingRun, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeConcurrencyCanceled.ID,
+			Args: []interface{}{pending.WorkflowNodeName, n.Context.Concurrency.Group},
+		})
+		if err := UpdateWorkflowRun(db, pendingRun); err != nil {
+			return sdk.WrapError(err, "cancelPendingConcurrency> Unable to update workflow run %d", pendingRun.ID)
+		}
+		if pendingRun.ID != w.ID {
+			if err := ResyncWorkflowRunStatus(db, pendingRun, make(chan interface{}, 1)); err != nil {
+				return sdk.WrapError(err, "cancelPendingConcurrency> Unable to resync workflow run %d", pendingRun.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseConcurrencyGroup removes the node run from its concurrency group and executes the next waiting node runs
+func releaseConcurrencyGroup(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, run *sdk.WorkflowNodeRun) error {
+	rc, err := loadNodeRunConcurrency(db, run.ID)
+	if err != nil || rc == nil {
+		return err
+	}
+
+	if err := lockConcurrencyGroup(db, rc.Group); err != nil {
+		return err
+	}
+	if err := deleteNodeRunConcurrency(db, run.ID); err != nil {
+		return err
+	}
+
+	for {
+		waitings, errW := loadNodeRunsConcurrency(db, "workflow_node_run_concurrency.group_name = $1 AND workflow_node_run_concurrency.status = $2", rc.Group, sdk.WorkflowNodeConcurrencyWaiting)
+		if errW != nil {
+			return errW
+		}
+		if len(waitings) == 0 {
+			return nil
+		}
+		next := &waitings[0]
+
+		nbRunning, errR := countNodeRunConcurrency(db, rc.Group, false)
+		if errR != nil {
+			return errR
+		}
+		if nbRunning >= next.Max {
+			return nil
+		}
+
+		nodeRun, errL := LoadNodeRunByID(db, next.WorkflowNodeRunID, false)
+		if errL != nil {
+			return sdk.WrapError(errL, "releaseConcurrencyGroup> Unable to load node run %d", next.WorkflowNodeRunID)
+		}
+		// The node run may have been stopped while it was waiting
+		if sdk.StatusIsTerminated(nodeRun.Status) {
+			if err := deleteNodeRunConcurrency(db, nodeRun.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		workflowRun, errWR := LoadRunByID(db, nodeRun.WorkflowRunID, false)
+		if errWR != nil {
+			return sdk.WrapError(errWR, "releaseConcurrencyGroup> Unable to load workflow run %d", nodeRun.WorkflowRunID)
+		}
+		AddWorkflowRunInfo(workflowRun, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeConcurrencyRelease.ID,
+			Args: []interface{}{rc.Group, nodeRun.WorkflowNodeName},
+		})
+		if err := UpdateWorkflowRun(db, workflowRun); err != nil {
+			return sdk.WrapError(err, "releaseConcurrencyGroup> Unable to update workflow run %d", workflowRun.ID)
+		}
+
+		// Node runs of other projects are executed by the API with their own project
+		if p == nil || p.Key != next.ProjectKey {
+			next.Status = sdk.WorkflowNodeConcurrencyReady
+			if err := updateNodeRunConcurrencyStatus(db, next); err != nil {
+				return err
+			}
+			continue
+		}
+
+		next.Status = sdk.WorkflowNodeConcurrencyRunning
+		if err := updateNodeRunConcurrencyStatus(db, next); err != nil {
+			return err
+		}
+		log.Debug("releaseConcurrencyGroup> process the node run %d because concurrency group %s has been released", nodeRun.ID, rc.Group)
+		if err := execute(dbCopy, db, store, p, nodeRun, nil); err != nil {
+			return sdk.WrapError(err, "releaseConcurrencyGroup> Unable to execute node run %d", nodeRun.ID)
+		}
+	}
+}
+
+// ExecuteReadyConcurrencyNodeRun executes a node run released from its concurrency group
+func ExecuteReadyConcurrencyNodeRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, rc *sdk.WorkflowNodeRunConcurrency) error {
+	if err := lockConcurrencyGroup(db, rc.Group); err != nil {
+		return err
+	}
+
+	current, errC := loadNodeRunConcurrency(db, rc.WorkflowNodeRunID)
+	if errC != nil {
+		return errC
+	}
+	if current == nil || current.Status != sdk.WorkflowNodeConcurrencyReady {
+		return nil
+	}
+
+	nodeRun, errL := LoadNodeRunByID(db, rc.WorkflowNodeRunID, false)
+	if errL != nil {
+		return sdk.WrapError(errL, "ExecuteReadyConcurrencyNodeRun> Unable to load node run %d", rc.WorkflowNodeRunID)
+	}
+
+	current.Status = sdk.WorkflowNodeConcurrencyRunning
+	if err := updateNodeRunConcurrencyStatus(db, current); err != nil {
+		return err
+	}
+
+	if sdk.StatusIsTerminated(nodeRun.Status) {
+		return releaseConcurrencyGroup(dbCopy, db, store, p, nodeRun)
+	}
+	return execute(dbCopy, db, store, p, nodeRun, nil)
+}
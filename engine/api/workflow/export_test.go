@@ -0,0 +1,7 @@
+package workflow
+
+// CopyNodeRunArtifacts exposes copyNodeRunArtifacts to the tests of package workflow_test
+var CopyNodeRunArtifacts = copyNodeRunArtifacts
+
+// DeleteWorkflowRunsHistory exposes deleteWorkflowRunsHistory to the tests of package workflow_test
+var DeleteWorkflowRunsHistory = deleteWorkflowRunsHistory
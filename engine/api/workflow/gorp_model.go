@@ -51,6 +51,7 @@ type NodeRun struct {
 	Payload            sql.NullString `db:"payload"`
 	PipelineParameters sql.NullString `db:"pipeline_parameters"`
 	BuildParameters    sql.NullString `db:"build_parameters"`
+	Outputs            sql.NullString `db:"outputs"`
 	Tests              sql.NullString `db:"tests"`
 	Commits            sql.NullString `db:"commits"`
 	Stages             sql.NullString `db:"stages"`
@@ -75,6 +76,15 @@ type NodeHook sdk.WorkflowNodeHook
 // NodeHookModel is a gorp wrapper around sdk.WorkflowHookModel
 type NodeHookModel sdk.WorkflowHookModel
 
+// NodeApproval is a gorp wrapper around sdk.WorkflowNodeApproval
+type NodeApproval sdk.WorkflowNodeApproval
+
+// NodeApprovalDecision is a gorp wrapper around sdk.WorkflowNodeApprovalDecision
+type NodeApprovalDecision sdk.WorkflowNodeApprovalDecision
+
+// NodeRunConcurrency is a gorp wrapper around sdk.WorkflowNodeRunConcurrency
+type NodeRunConcurrency sdk.WorkflowNodeRunConcurrency
+
 func init() {
 	gorpmapping.Register(gorpmapping.New(Workflow{}, "workflow", true, "id"))
 	gorpmapping.Register(gorpmapping.New(Node{}, "workflow_node", true, "id"))
@@ -91,4 +101,7 @@ func init() {
 	gorpmapping.Register(gorpmapping.New(RunTag{}, "workflow_run_tag", false, "workflow_run_id", "tag"))
 	gorpmapping.Register(gorpmapping.New(NodeHookModel{}, "workflow_hook_model", true, "id"))
 	gorpmapping.Register(gorpmapping.New(Notification{}, "workflow_notification", true, "id"))
+	gorpmapping.Register(gorpmapping.New(NodeApproval{}, "workflow_node_approval", true, "id"))
+	gorpmapping.Register(gorpmapping.New(NodeApprovalDecision{}, "workflow_node_approval_decision", true, "id"))
+	gorpmapping.Register(gorpmapping.New(NodeRunConcurrency{}, "workflow_node_run_concurrency", false, "workflow_node_run_id"))
 }
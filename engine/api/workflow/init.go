@@ -14,7 +14,9 @@ import (
 //Initialize starts goroutines for workflows
 func Initialize(c context.Context, store cache.Store, DBFunc func() *gorp.DbMap) {
 	rand.Seed(time.Now().Unix())
+	go subscribeJobStops(c, store)
 	tickPurge := time.NewTicker(1 * time.Hour)
+	tickApproval := time.NewTicker(1 * time.Minute)
 
 	for {
 		time.Sleep(time.Duration(rand.Intn(500)) * time.Millisecond)
@@ -30,6 +32,19 @@ func Initialize(c context.Context, store cache.Store, DBFunc func() *gorp.DbMap)
 			if err := deleteWorkflowRunsHistory(DBFunc()); err != nil {
 				log.Warning("scheduler.Purge> Error : %s", err)
 			}
+			if err := purgeLogs(DBFunc()); err != nil {
+				log.Warning("workflow.Initialize> Unable to purge logs: %s", err)
+			}
+			if err := deleteObsoleteLogObjects(DBFunc()); err != nil {
+				log.Warning("workflow.Initialize> Unable to delete obsolete log objects: %s", err)
+			}
+			if err := purgeArtifacts(DBFunc()); err != nil {
+				log.Warning("workflow.Initialize> Unable to purge artifacts: %s", err)
+			}
+		case <-tickApproval.C:
+			if err := expireNodeApprovals(DBFunc(), store); err != nil {
+				log.Warning("workflow.Initialize> Unable to expire approval requests: %s", err)
+			}
 		}
 	}
 }
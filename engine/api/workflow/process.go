@@ -286,7 +286,11 @@ func processWorkflowRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Sto
 		}
 	}
 
-	w.Status = getRunStatus(nodesRunSuccess, nodesRunBuilding, nodesRunFailed, nodesRunStopped, nodesRunSkipped, nodesRunDisabled)
+	status, errS := runStatusWithApprovals(db, w, getRunStatus(nodesRunSuccess, nodesRunBuilding, nodesRunFailed, nodesRunStopped, nodesRunSkipped, nodesRunDisabled))
+	if errS != nil {
+		return false, sdk.WrapError(errS, "processWorkflowRun>")
+	}
+	w.Status = status
 	if err := UpdateWorkflowRun(db, w); err != nil {
 		return false, sdk.WrapError(err, "processWorkflowRun>")
 	}
@@ -476,6 +480,17 @@ func processWorkflowNodeRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache
 		}
 	}
 
+	//Check the approval request if the node needs approvals
+	if n.Context != nil && n.Context.Approval != nil {
+		approved, waiting, errA := checkNodeApproval(db, w, n, subnumber, sourceNodeRuns, h, m)
+		if errA != nil {
+			return false, sdk.WrapError(errA, "processWorkflowNodeRun> Unable to check approval request")
+		}
+		if !approved {
+			return waiting, nil
+		}
+	}
+
 	//Parse job params to get the VCS infos
 	gitValues := map[string]string{}
 	for _, param := range jobParams {
@@ -532,9 +547,24 @@ func processWorkflowNodeRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache
 		}
 	}
 
+	//Resume the previous run of the node
+	var previousRun *sdk.WorkflowNodeRun
+	if m != nil && m.ResumeFrom != nil {
+		var errResume error
+		previousRun, errResume = resumeNodeRun(w, run, *m.ResumeFrom)
+		if errResume != nil {
+			return false, sdk.WrapError(errResume, "processWorkflowNodeRun> Unable to resume node %s", n.Name)
+		}
+	}
+
 	if err := insertWorkflowNodeRun(db, run); err != nil {
 		return true, sdk.WrapError(err, "processWorkflowNodeRun> unable to insert run")
 	}
+	if previousRun != nil {
+		if err := copyNodeRunArtifacts(db, previousRun.ID, run); err != nil {
+			return true, sdk.WrapError(err, "processWorkflowNodeRun> Unable to reuse artifacts")
+		}
+	}
 	if chanEvent != nil {
 		chanEvent <- *run
 	}
@@ -582,6 +612,18 @@ func processWorkflowNodeRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache
 		//Mutex is free, continue
 	}
 
+	//Check the context.concurrency to know if the concurrency group allows us to run it
+	if n.Context.Concurrency != nil {
+		queued, err := enterConcurrencyGroup(db, w, n, run)
+		if err != nil {
+			return true, sdk.WrapError(err, "processWorkflowNodeRun> unable to enter concurrency group")
+		}
+		if queued {
+			//Concurrency group is full. exit without error
+			return true, nil
+		}
+	}
+
 	//Execute the node run !
 	if err := execute(dbCopy, db, store, p, run, chanEvent); err != nil {
 		return true, sdk.WrapError(err, "processWorkflowNodeRun> unable to execute workflow run")
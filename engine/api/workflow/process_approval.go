@@ -0,0 +1,292 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// checkNodeApproval checks the approval request of a node before creating its node run.
+// A new approval request is created if there is none for this subnumber. It returns
+// approved=true if the node run can be created, waiting=true if the request is still waiting for approvals.
+func checkNodeApproval(db gorp.SqlExecutor, w *sdk.WorkflowRun, n *sdk.WorkflowNode, subnumber int, sourceNodeRuns []int64, h *sdk.WorkflowNodeRunHookEvent, m *sdk.WorkflowNodeRunManual) (approved bool, waiting bool, err error) {
+	a, errL := loadNodeApprovalBySubNumber(db, w.ID, n.ID, int64(subnumber))
+	if errL != nil {
+		return false, false, sdk.WrapError(errL, "checkNodeApproval> Unable to load approval request")
+	}
+
+	if a == nil {
+		now := time.Now()
+		a = &sdk.WorkflowNodeApproval{
+			WorkflowRunID:    w.ID,
+			WorkflowNodeID:   n.ID,
+			WorkflowNodeName: n.Name,
+			SubNumber:        int64(subnumber),
+			Status:           sdk.WorkflowNodeApprovalWaiting,
+			Created:          now,
+			ExpireAt:         n.Context.Approval.ExpireAt(now),
+			Rule:             *n.Context.Approval,
+			SourceNodeRuns:   sourceNodeRuns,
+			HookEvent:        h,
+			Manual:           m,
+		}
+		if err := insertNodeApproval(db, a); err != nil {
+			return false, false, err
+		}
+
+		log.Debug("checkNodeApproval> Node %s of workflow run %d is waiting for approval", n.Name, w.ID)
+		AddWorkflowRunInfo(w, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeApprovalWaiting.ID,
+			Args: []interface{}{n.Pipeline.Name, a.Rule.Required()},
+		})
+		if err := UpdateWorkflowRun(db, w); err != nil {
+			return false, false, sdk.WrapError(err, "checkNodeApproval> Unable to update workflow run")
+		}
+		return false, true, nil
+	}
+
+	switch a.Status {
+	case sdk.WorkflowNodeApprovalApproved:
+		return true, false, nil
+	case sdk.WorkflowNodeApprovalWaiting:
+		if a.IsExpired() {
+			if err := expireNodeApproval(db, w, a); err != nil {
+				return false, false, err
+			}
+			return false, false, nil
+		}
+		return false, true, nil
+	}
+	return false, false, nil
+}
+
+func expireNodeApproval(db gorp.SqlExecutor, w *sdk.WorkflowRun, a *sdk.WorkflowNodeApproval) error {
+	a.Status = sdk.WorkflowNodeApprovalExpired
+	if err := updateNodeApprovalStatus(db, a); err != nil {
+		return err
+	}
+
+	AddWorkflowRunInfo(w, false, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeApprovalExpired.ID,
+		Args: []interface{}{approvalPipelineName(w, a)},
+	})
+	if err := UpdateWorkflowRun(db, w); err != nil {
+		return sdk.WrapError(err, "expireNodeApproval> Unable to update workflow run")
+	}
+	return nil
+}
+
+func approvalPipelineName(w *sdk.WorkflowRun, a *sdk.WorkflowNodeApproval) string {
+	if n := w.Workflow.GetNode(a.WorkflowNodeID); n != nil {
+		return n.Pipeline.Name
+	}
+	return a.WorkflowNodeName
+}
+
+// DecideNodeApproval approves or rejects the waiting approval request of a node.
+// The node run is created as soon as the request gets enough approvals
+func DecideNodeApproval(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, wr *sdk.WorkflowRun, nodeID int64, u *sdk.User, approved bool, comment string, chanEvent chan<- interface{}) (*sdk.WorkflowNodeApproval, error) {
+	n := wr.Workflow.GetNode(nodeID)
+	if n == nil {
+		return nil, sdk.WrapError(sdk.ErrWorkflowNodeNotFound, "DecideNodeApproval> Unable to find node %d", nodeID)
+	}
+
+	a, errL := LoadAndLockWaitingNodeApproval(db, wr.ID, nodeID)
+	if errL != nil {
+		return nil, sdk.WrapError(errL, "DecideNodeApproval> Unable to load approval request on node %d", nodeID)
+	}
+
+	if a.IsExpired() {
+		if err := expireNodeApproval(db, wr, a); err != nil {
+			return nil, sdk.WrapError(err, "DecideNodeApproval> Unable to expire approval request %d", a.ID)
+		}
+		if err := resyncRunAfterApproval(dbCopy, db, store, p, wr, chanEvent); err != nil {
+			return nil, err
+		}
+		return a, sdk.ErrWorkflowNodeApprovalClosed
+	}
+
+	if !canDecideNodeApproval(p, u, a.Rule) {
+		return nil, sdk.WrapError(sdk.ErrForbidden, "DecideNodeApproval> User %s is not allowed to approve node %s", u.Username, n.Name)
+	}
+
+	d := sdk.WorkflowNodeApprovalDecision{
+		ApprovalID: a.ID,
+		Username:   u.Username,
+		Approved:   approved,
+		Comment:    comment,
+		Date:       time.Now(),
+	}
+	if err := a.Decide(d); err != nil {
+		return nil, err
+	}
+	if err := insertNodeApprovalDecision(db, &d); err != nil {
+		return nil, err
+	}
+	a.Decisions[len(a.Decisions)-1] = d
+
+	if a.Status == sdk.WorkflowNodeApprovalWaiting {
+		return a, nil
+	}
+
+	if err := updateNodeApprovalStatus(db, a); err != nil {
+		return nil, err
+	}
+
+	msg := sdk.MsgWorkflowNodeApprovalApproved
+	if a.Status == sdk.WorkflowNodeApprovalRejected {
+		msg = sdk.MsgWorkflowNodeApprovalRejected
+	}
+	AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+		ID:   msg.ID,
+		Args: []interface{}{n.Pipeline.Name, u.Username},
+	})
+	if err := UpdateWorkflowRun(db, wr); err != nil {
+		return nil, sdk.WrapError(err, "DecideNodeApproval> Unable to update workflow run")
+	}
+
+	if a.Status == sdk.WorkflowNodeApprovalApproved {
+		if _, err := processWorkflowNodeRun(dbCopy, db, store, p, wr, n, int(a.SubNumber), a.SourceNodeRuns, a.HookEvent, a.Manual, chanEvent); err != nil {
+			return nil, sdk.WrapError(err, "DecideNodeApproval> Unable to process node %s", n.Name)
+		}
+		return a, nil
+	}
+
+	if err := resyncRunAfterApproval(dbCopy, db, store, p, wr, chanEvent); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// canDecideNodeApproval returns true if the user is allowed to approve or reject a request. Without groups in the
+// rule, the request is decided by the users allowed to write in the project
+func canDecideNodeApproval(p *sdk.Project, u *sdk.User, rule sdk.WorkflowNodeApprovalRule) bool {
+	if len(rule.Groups) == 0 {
+		return permission.ProjectPermission(p.Key, u) >= permission.PermissionReadWriteExecute
+	}
+	return rule.CanBeApprovedBy(u)
+}
+
+// resyncRunAfterApproval recomputes the status of a workflow run after a closed approval request
+func resyncRunAfterApproval(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, wr *sdk.WorkflowRun, chanEvent chan<- interface{}) error {
+	if len(wr.WorkflowNodeRuns) > 0 {
+		if _, err := processWorkflowRun(dbCopy, db, store, p, wr, nil, nil, nil, chanEvent); err != nil {
+			return sdk.WrapError(err, "resyncRunAfterApproval> Unable to process workflow run %d", wr.ID)
+		}
+		return nil
+	}
+
+	nbWaiting, errC := countWaitingNodeApprovals(db, wr.ID)
+	if errC != nil {
+		return errC
+	}
+	if nbWaiting > 0 {
+		return nil
+	}
+	wr.Status = sdk.StatusNeverBuilt.String()
+	if err := UpdateWorkflowRunStatus(db, wr); err != nil {
+		return sdk.WrapError(err, "resyncRunAfterApproval> Unable to update workflow run %d", wr.ID)
+	}
+	if chanEvent != nil {
+		chanEvent <- *wr
+	}
+	return nil
+}
+
+// expireNodeApprovals closes all the approval requests which are expired
+func expireNodeApprovals(db *gorp.DbMap, store cache.Store) error {
+	approvals, err := loadExpiredNodeApprovals(db)
+	if err != nil {
+		return err
+	}
+
+	for i := range approvals {
+		a := &approvals[i]
+		chanEvent := make(chan interface{}, 1)
+		chanError := make(chan error, 1)
+
+		var key string
+		go func() {
+			defer close(chanEvent)
+			defer close(chanError)
+			wr, err := expireRunNodeApproval(db, store, a, chanEvent)
+			if err != nil {
+				chanError <- err
+				return
+			}
+			key = wr.Workflow.ProjectKey
+		}()
+
+		workflowRuns, workflowNodeRuns, workflowNodeJobRuns, errE := GetWorkflowRunEventData(chanError, chanEvent)
+		if errE != nil {
+			log.Warning("expireNodeApprovals> Unable to expire approval request %d: %v", a.ID, errE)
+			continue
+		}
+		go SendEvent(db, workflowRuns, workflowNodeRuns, workflowNodeJobRuns, key)
+	}
+	return nil
+}
+
+// expireRunNodeApproval closes an expired approval request and recomputes the status of its workflow run
+func expireRunNodeApproval(db *gorp.DbMap, store cache.Store, a *sdk.WorkflowNodeApproval, chanEvent chan<- interface{}) (*sdk.WorkflowRun, error) {
+	tx, errT := db.Begin()
+	if errT != nil {
+		return nil, sdk.WrapError(errT, "expireRunNodeApproval> Unable to start transaction")
+	}
+	defer tx.Rollback()
+
+	wr, errR := LoadRunByID(tx, a.WorkflowRunID, false)
+	if errR != nil {
+		return nil, sdk.WrapError(errR, "expireRunNodeApproval> Unable to load workflow run %d", a.WorkflowRunID)
+	}
+
+	if err := expireNodeApproval(tx, wr, a); err != nil {
+		return nil, sdk.WrapError(err, "expireRunNodeApproval> Unable to expire approval request %d", a.ID)
+	}
+
+	if len(wr.WorkflowNodeRuns) == 0 {
+		if err := resyncRunAfterApproval(db, tx, store, nil, wr, chanEvent); err != nil {
+			return nil, sdk.WrapError(err, "expireRunNodeApproval> Unable to resync workflow run %d", wr.ID)
+		}
+	} else if err := ResyncWorkflowRunStatus(tx, wr, chanEvent); err != nil {
+		return nil, sdk.WrapError(err, "expireRunNodeApproval> Unable to resync workflow run %d", wr.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, sdk.WrapError(err, "expireRunNodeApproval> Unable to commit transaction")
+	}
+	return wr, nil
+}
+
+// runStatusWithApprovals returns the status of a workflow run computed from its node runs. The run is waiting while
+// one of its nodes waits for an approval, unless one of its node runs is building, failed or stopped
+func runStatusWithApprovals(db gorp.SqlExecutor, wr *sdk.WorkflowRun, status string) (string, error) {
+	switch status {
+	case sdk.StatusBuilding.String(), sdk.StatusFail.String(), sdk.StatusStopped.String():
+		return status, nil
+	}
+
+	var withApproval bool
+	wr.Workflow.Visit(func(n *sdk.WorkflowNode) {
+		if n.Context != nil && n.Context.Approval != nil {
+			withApproval = true
+		}
+	})
+	if !withApproval {
+		return status, nil
+	}
+
+	nbWaiting, err := countWaitingNodeApprovals(db, wr.ID)
+	if err != nil {
+		return "", err
+	}
+	if nbWaiting > 0 {
+		return sdk.StatusWaiting.String(), nil
+	}
+	return status, nil
+}
@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_canDecideNodeApproval(t *testing.T) {
+	p := &sdk.Project{Key: "PROJ"}
+	newUser := func(name string, perm int, groups ...string) *sdk.User {
+		u := &sdk.User{Username: name}
+		u.Permissions.ProjectsPerm = map[string]int{p.Key: perm}
+		for _, g := range groups {
+			u.Groups = append(u.Groups, sdk.Group{Name: g})
+		}
+		return u
+	}
+
+	// without groups, the request is decided by the users allowed to write in the project
+	rule := sdk.WorkflowNodeApprovalRule{}
+	assert.True(t, canDecideNodeApproval(p, newUser("john", permission.PermissionReadWriteExecute), rule))
+	assert.False(t, canDecideNodeApproval(p, newUser("jane", permission.PermissionReadExecute), rule))
+	assert.False(t, canDecideNodeApproval(p, &sdk.User{Username: "bob"}, rule))
+
+	// with groups, only their members can decide whatever their permission on the project
+	rule.Groups = []string{"ops"}
+	assert.False(t, canDecideNodeApproval(p, newUser("john", permission.PermissionReadWriteExecute, "dev"), rule))
+	assert.True(t, canDecideNodeApproval(p, newUser("jane", permission.PermissionRead, "ops"), rule))
+	assert.True(t, canDecideNodeApproval(p, &sdk.User{Username: "admin", Admin: true}, rule))
+}
+
+func Test_runStatusWithApprovals(t *testing.T) {
+	// the approval requests are only counted for a workflow with approval rules, and a run which is not building,
+	// failed or stopped: the database is not used here
+	wr := &sdk.WorkflowRun{
+		Workflow: sdk.Workflow{
+			Root: &sdk.WorkflowNode{
+				Context: &sdk.WorkflowNodeContext{},
+				Triggers: []sdk.WorkflowNodeTrigger{
+					{WorkflowDestNode: sdk.WorkflowNode{Context: &sdk.WorkflowNodeContext{}}},
+				},
+			},
+		},
+	}
+	for _, s := range []sdk.Status{sdk.StatusSuccess, sdk.StatusBuilding, sdk.StatusFail} {
+		status, err := runStatusWithApprovals(nil, wr, s.String())
+		assert.NoError(t, err)
+		assert.Equal(t, s.String(), status)
+	}
+
+	wr.Workflow.Root.Triggers[0].WorkflowDestNode.Context.Approval = &sdk.WorkflowNodeApprovalRule{}
+	for _, s := range []sdk.Status{sdk.StatusBuilding, sdk.StatusFail, sdk.StatusStopped} {
+		status, err := runStatusWithApprovals(nil, wr, s.String())
+		assert.NoError(t, err)
+		assert.Equal(t, s.String(), status)
+	}
+}
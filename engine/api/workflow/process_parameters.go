@@ -23,6 +23,11 @@ func getNodeJobRunParameters(db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNo
 	tmp["cds.job"] = j.Action.Name
 	errm := &sdk.MultiError{}
 
+	// outputs of the steps of the previous stages and of the parent pipelines
+	for _, p := range sdk.StepOutputsToParameters(run.Outputs) {
+		sdk.ParameterAddOrSetValue(&params, p.Name, p.Type, p.Value)
+	}
+
 	for k, v := range tmp {
 		s, err := interpolate.Do(v, tmp)
 		if err != nil {
@@ -156,6 +161,8 @@ func GetNodeBuildParameters(db gorp.SqlExecutor, store cache.Store, proj *sdk.Pr
 	return params, errdump
 }
 
+// getParentParameters returns the build parameters of the parent node runs, prefixed by workflow.<node>.
+// The node run inherits the step outputs of its parents
 func getParentParameters(db gorp.SqlExecutor, run *sdk.WorkflowNodeRun, nodeRunIds []int64, payload map[string]string) ([]sdk.Parameter, error) {
 	//Load workflow run
 	w, err := LoadRunByID(db, run.WorkflowRunID, false)
@@ -175,6 +182,9 @@ func getParentParameters(db gorp.SqlExecutor, run *sdk.WorkflowNodeRun, nodeRunI
 			return nil, sdk.WrapError(fmt.Errorf("Unable to find node %d in workflow", parentNodeRun.WorkflowNodeID), "getParentParameters>")
 		}
 
+		// The step outputs are inherited with the same name
+		run.Outputs = sdk.MergeStepOutputs(run.Outputs, parentNodeRun.Outputs...)
+
 		for i := range parentNodeRun.BuildParameters {
 			p := &parentNodeRun.BuildParameters[i]
 
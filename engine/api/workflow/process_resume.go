@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/sessionstore"
+	"github.com/ovh/cds/sdk"
+)
+
+// lastNodeRun returns the node run with the highest sub number
+func lastNodeRun(nodeRuns []sdk.WorkflowNodeRun) *sdk.WorkflowNodeRun {
+	var last *sdk.WorkflowNodeRun
+	for i := range nodeRuns {
+		if last == nil || nodeRuns[i].SubNumber > last.SubNumber {
+			last = &nodeRuns[i]
+		}
+	}
+	return last
+}
+
+// isStageDone returns true if a stage of a previous node run does not have to be run again
+func isStageDone(s sdk.Stage) bool {
+	switch s.Status {
+	case sdk.StatusSuccess, sdk.StatusSkipped, sdk.StatusDisabled:
+		return true
+	}
+	return false
+}
+
+// isRunJobReusable returns true if a job of a previous node run does not have to be run again
+func isRunJobReusable(rj sdk.WorkflowNodeJobRun) bool {
+	return rj.Status == sdk.StatusSuccess.String() || rj.Status == sdk.StatusWarning.String()
+}
+
+// resumeStages returns the stages of a node run resuming the previous one from its first failed stage, or from the
+// stage of a job if it comes first. The stages before are reused with their jobs, as well as the successful jobs of
+// the resumed stage except the job to resume from
+func resumeStages(stages []sdk.Stage, previous sdk.WorkflowNodeRun, job string) ([]sdk.Stage, error) {
+	if !sdk.StatusIsTerminated(previous.Status) {
+		return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("pipeline %s is still running", previous.WorkflowNodeName))
+	}
+	if len(previous.Stages) != len(stages) {
+		return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("stages of pipeline %s have changed", previous.WorkflowNodeName))
+	}
+
+	resumeIndex := -1
+	for i, s := range previous.Stages {
+		if s.ID != stages[i].ID {
+			return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("stages of pipeline %s have changed", previous.WorkflowNodeName))
+		}
+		if resumeIndex == -1 && !isStageDone(s) {
+			resumeIndex = i
+		}
+	}
+
+	if job != "" {
+		jobIndex := -1
+		for i, s := range stages {
+			for _, j := range s.Jobs {
+				if j.Action.Name == job {
+					jobIndex = i
+				}
+			}
+		}
+		if jobIndex == -1 {
+			return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("job %s not found in pipeline %s", job, previous.WorkflowNodeName))
+		}
+		if resumeIndex == -1 || jobIndex < resumeIndex {
+			resumeIndex = jobIndex
+		}
+	}
+	if resumeIndex == -1 {
+		return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("pipeline %s has no failed stage", previous.WorkflowNodeName))
+	}
+
+	resumed := make([]sdk.Stage, len(stages))
+	copy(resumed, stages)
+	for i := 0; i < resumeIndex; i++ {
+		resumed[i].Status = previous.Stages[i].Status
+		resumed[i].RunJobs = append([]sdk.WorkflowNodeJobRun{}, previous.Stages[i].RunJobs...)
+	}
+	resumed[resumeIndex].RunJobs = nil
+	for _, rj := range previous.Stages[resumeIndex].RunJobs {
+		if isRunJobReusable(rj) && rj.Job.Action.Name != job {
+			resumed[resumeIndex].RunJobs = append(resumed[resumeIndex].RunJobs, rj)
+		}
+	}
+	return resumed, nil
+}
+
+// isJobReused returns true if a job of a stage is reused from a previous node run
+func isJobReused(stage *sdk.Stage, job sdk.Job) bool {
+	for _, rj := range stage.RunJobs {
+		if rj.Job.PipelineActionID == job.PipelineActionID {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeNodeRun prepares a node run resuming the previous run of its node: it reuses the stages and jobs which do
+// not have to be run again, and the variables exported by the jobs of the previous node run. It returns the
+// previous node run
+func resumeNodeRun(w *sdk.WorkflowRun, run *sdk.WorkflowNodeRun, resume sdk.WorkflowNodeRunResume) (*sdk.WorkflowNodeRun, error) {
+	previous := lastNodeRun(w.WorkflowNodeRuns[run.WorkflowNodeID])
+	if previous == nil {
+		return nil, sdk.NewError(sdk.ErrWorkflowNodeRunNotResumable, fmt.Errorf("pipeline %s has not been run", run.WorkflowNodeName))
+	}
+
+	stages, err := resumeStages(run.Stages, *previous, resume.Job)
+	if err != nil {
+		return nil, err
+	}
+	run.Stages = stages
+
+	for _, p := range previous.BuildParameters {
+		if strings.HasPrefix(p.Name, "cds.build.") {
+			sdk.ParameterAddOrSetValue(&run.BuildParameters, p.Name, p.Type, p.Value)
+		}
+	}
+	run.Outputs = sdk.MergeStepOutputs(run.Outputs, previous.Outputs...)
+	return previous, nil
+}
+
+// copyNodeRunArtifacts makes the artifacts of a previous node run available in a resumed node run. The copies
+// reference the same stored objects, kept in the storage of the node run which uploaded them
+func copyNodeRunArtifacts(db gorp.SqlExecutor, previousID int64, run *sdk.WorkflowNodeRun) error {
+	arts, err := loadArtifactByNodeRunID(db, previousID)
+	if err != nil {
+		return sdk.WrapError(err, "copyNodeRunArtifacts> Unable to load artifacts of node run %d", previousID)
+	}
+	for i := range arts {
+		a := &arts[i]
+		hash, err := sessionstore.NewSessionKey()
+		if err != nil {
+			return sdk.WrapError(err, "copyNodeRunArtifacts> Unable to generate hash")
+		}
+		a.ID = 0
+		a.DownloadHash = string(hash)
+		a.StorageNodeRunID = a.GetStorageNodeRunID()
+		a.WorkflowNodeRunID = run.ID
+		a.Created = time.Now()
+		if err := InsertArtifact(db, a); err != nil {
+			return sdk.WrapError(err, "copyNodeRunArtifacts> Unable to insert artifact %s", a.Name)
+		}
+	}
+	run.Artifacts = append(run.Artifacts, arts...)
+	return nil
+}
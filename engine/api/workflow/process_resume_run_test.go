@@ -0,0 +1,156 @@
+package workflow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+// runResumeTestWorkflow runs a workflow with a pipeline of two stages, its root node run is returned
+func runResumeTestWorkflow(t *testing.T, db *gorp.DbMap, cache cache.Store) (*sdk.Project, *sdk.User, *sdk.WorkflowRun, *sdk.WorkflowNodeRun) {
+	u, _ := assets.InsertAdminUser(db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key, u)
+
+	pip := sdk.Pipeline{
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		Name:       "pip1",
+		Type:       sdk.BuildPipeline,
+	}
+	test.NoError(t, pipeline.InsertPipeline(db, cache, proj, &pip, u))
+	for _, name := range []string{"build", "test"} {
+		s := sdk.NewStage(name)
+		s.Enabled = true
+		s.PipelineID = pip.ID
+		test.NoError(t, pipeline.InsertStage(db, s))
+		j := &sdk.Job{Enabled: true, Action: sdk.Action{Name: name, Enabled: true}}
+		test.NoError(t, pipeline.InsertJob(db, j, s.ID, &pip))
+	}
+
+	proj, _ = project.LoadByID(db, cache, proj.ID, u, project.LoadOptions.WithPipelines)
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		Root:       &sdk.WorkflowNode{Pipeline: pip},
+	}
+	test.NoError(t, workflow.Insert(db, cache, &w, proj, u))
+	w1, err := workflow.Load(db, cache, key, "test_1", u, workflow.LoadOptions{})
+	test.NoError(t, err)
+
+	wr, err := workflow.ManualRun(db, db, cache, proj, w1, &sdk.WorkflowNodeRunManual{User: *u}, nil)
+	test.NoError(t, err)
+	nodeRuns := wr.WorkflowNodeRuns[wr.Workflow.RootID]
+	assert.Len(t, nodeRuns, 1)
+	return proj, u, wr, &nodeRuns[0]
+}
+
+// insertResumeTestArtifact inserts an artifact uploaded by a node run
+func insertResumeTestArtifact(t *testing.T, db gorp.SqlExecutor, nodeRun *sdk.WorkflowNodeRun) sdk.WorkflowNodeRunArtifact {
+	a := sdk.WorkflowNodeRunArtifact{
+		Name:              "result.txt",
+		Tag:               "1",
+		DownloadHash:      sdk.RandomString(10),
+		WorkflowID:        nodeRun.WorkflowRunID,
+		WorkflowNodeRunID: nodeRun.ID,
+		Created:           time.Now(),
+	}
+	a.ObjectPath = a.GetPath() + "/" + a.GetName()
+	test.NoError(t, workflow.InsertArtifact(db, &a))
+	return a
+}
+
+func Test_copyNodeRunArtifacts(t *testing.T) {
+	db, cache := test.SetupPG(t)
+	proj, u, wr, nodeRun := runResumeTestWorkflow(t, db, cache)
+	original := insertResumeTestArtifact(t, db, nodeRun)
+
+	wr, err := workflow.ManualRunFromNode(db, db, cache, proj, &wr.Workflow, wr.Number, &sdk.WorkflowNodeRunManual{User: *u}, wr.Workflow.RootID, nil)
+	test.NoError(t, err)
+	nodeRuns := wr.WorkflowNodeRuns[wr.Workflow.RootID]
+	assert.Len(t, nodeRuns, 2)
+	var next *sdk.WorkflowNodeRun
+	for i := range nodeRuns {
+		if nodeRuns[i].ID != nodeRun.ID {
+			next = &nodeRuns[i]
+		}
+	}
+
+	test.NoError(t, workflow.CopyNodeRunArtifacts(db, nodeRun.ID, next))
+	next, err = workflow.LoadNodeRunByID(db, next.ID, true)
+	test.NoError(t, err)
+	assert.Len(t, next.Artifacts, 1)
+	copied := next.Artifacts[0]
+	assert.NotEqual(t, original.ID, copied.ID)
+	assert.NotEqual(t, original.DownloadHash, copied.DownloadHash)
+	assert.Equal(t, next.ID, copied.WorkflowNodeRunID)
+	assert.Equal(t, nodeRun.ID, copied.StorageNodeRunID)
+	// the copy is read from the object stored by the node run which uploaded it
+	assert.Equal(t, original.GetPath(), copied.GetPath())
+	assert.Equal(t, original.ObjectPath, copied.ObjectPath)
+
+	// a copy of a copy is still read from the object stored by the node run which uploaded it
+	other := &sdk.WorkflowNodeRun{ID: nodeRun.ID}
+	test.NoError(t, workflow.CopyNodeRunArtifacts(db, next.ID, other))
+	assert.Len(t, other.Artifacts, 1)
+	assert.Equal(t, nodeRun.ID, other.Artifacts[0].StorageNodeRunID)
+	assert.Equal(t, original.GetPath(), other.Artifacts[0].GetPath())
+}
+
+func Test_processWorkflowNodeRunResume(t *testing.T) {
+	db, cache := test.SetupPG(t)
+	proj, u, wr, nodeRun := runResumeTestWorkflow(t, db, cache)
+	original := insertResumeTestArtifact(t, db, nodeRun)
+
+	// the first stage succeeded, the second one failed
+	assert.Len(t, nodeRun.Stages, 2)
+	nodeRun.Status = sdk.StatusFail.String()
+	nodeRun.Stages[0].Status = sdk.StatusSuccess
+	nodeRun.Stages[1].Status = sdk.StatusFail
+	test.NoError(t, workflow.UpdateNodeRun(db, nodeRun))
+
+	wr, err := workflow.ManualRunFromNode(db, db, cache, proj, &wr.Workflow, wr.Number, &sdk.WorkflowNodeRunManual{
+		User:       *u,
+		ResumeFrom: &sdk.WorkflowNodeRunResume{},
+	}, wr.Workflow.RootID, nil)
+	test.NoError(t, err)
+
+	nodeRuns := wr.WorkflowNodeRuns[wr.Workflow.RootID]
+	assert.Len(t, nodeRuns, 2)
+	var resumed *sdk.WorkflowNodeRun
+	for i := range nodeRuns {
+		if nodeRuns[i].ID != nodeRun.ID {
+			resumed = &nodeRuns[i]
+		}
+	}
+	assert.Equal(t, int64(1), resumed.SubNumber)
+	assert.Equal(t, sdk.StatusSuccess, resumed.Stages[0].Status)
+	assert.NotEqual(t, sdk.StatusSuccess, resumed.Stages[1].Status)
+
+	resumed, err = workflow.LoadNodeRunByID(db, resumed.ID, true)
+	test.NoError(t, err)
+	assert.Len(t, resumed.Artifacts, 1)
+	assert.Equal(t, original.Name, resumed.Artifacts[0].Name)
+	assert.Equal(t, original.GetPath(), resumed.Artifacts[0].GetPath())
+
+	// a node run without failed stage cannot be resumed
+	resumed.Status = sdk.StatusSuccess.String()
+	resumed.Stages[1].Status = sdk.StatusSuccess
+	test.NoError(t, workflow.UpdateNodeRun(db, resumed))
+	_, err = workflow.ManualRunFromNode(db, db, cache, proj, &wr.Workflow, wr.Number, &sdk.WorkflowNodeRunManual{
+		User:       *u,
+		ResumeFrom: &sdk.WorkflowNodeRunResume{},
+	}, wr.Workflow.RootID, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func resumeTestRunJob(name string, actionID int64, status sdk.Status) sdk.WorkflowNodeJobRun {
+	return sdk.WorkflowNodeJobRun{
+		Status: status.String(),
+		Job: sdk.ExecutedJob{
+			Job: sdk.Job{PipelineActionID: actionID, Action: sdk.Action{Name: name}},
+		},
+	}
+}
+
+func resumeTestStages() []sdk.Stage {
+	return []sdk.Stage{
+		{ID: 1, Name: "build", Jobs: []sdk.Job{{PipelineActionID: 1, Action: sdk.Action{Name: "compile"}}}},
+		{ID: 2, Name: "test", Jobs: []sdk.Job{
+			{PipelineActionID: 2, Action: sdk.Action{Name: "unit"}},
+			{PipelineActionID: 3, Action: sdk.Action{Name: "integration"}},
+		}},
+		{ID: 3, Name: "deploy", Jobs: []sdk.Job{{PipelineActionID: 4, Action: sdk.Action{Name: "deploy"}}}},
+	}
+}
+
+func resumeTestPrevious() sdk.WorkflowNodeRun {
+	stages := resumeTestStages()
+	stages[0].Status = sdk.StatusSuccess
+	stages[0].RunJobs = []sdk.WorkflowNodeJobRun{resumeTestRunJob("compile", 1, sdk.StatusSuccess)}
+	stages[1].Status = sdk.StatusFail
+	stages[1].RunJobs = []sdk.WorkflowNodeJobRun{
+		resumeTestRunJob("unit", 2, sdk.StatusSuccess),
+		resumeTestRunJob("integration", 3, sdk.StatusFail),
+	}
+	return sdk.WorkflowNodeRun{WorkflowNodeName: "pipeline", Status: sdk.StatusFail.String(), Stages: stages}
+}
+
+func Test_resumeStagesFromFailedStage(t *testing.T) {
+	stages, err := resumeStages(resumeTestStages(), resumeTestPrevious(), "")
+	assert.NoError(t, err)
+	assert.Len(t, stages, 3)
+
+	assert.Equal(t, sdk.StatusSuccess, stages[0].Status)
+	assert.Len(t, stages[0].RunJobs, 1)
+
+	assert.Equal(t, sdk.Status(""), stages[1].Status)
+	assert.Len(t, stages[1].RunJobs, 1)
+	assert.Equal(t, "unit", stages[1].RunJobs[0].Job.Action.Name)
+	assert.True(t, isJobReused(&stages[1], stages[1].Jobs[0]))
+	assert.False(t, isJobReused(&stages[1], stages[1].Jobs[1]))
+
+	assert.Equal(t, sdk.Status(""), stages[2].Status)
+	assert.Len(t, stages[2].RunJobs, 0)
+}
+
+func Test_resumeStagesFromJob(t *testing.T) {
+	stages, err := resumeStages(resumeTestStages(), resumeTestPrevious(), "unit")
+	assert.NoError(t, err)
+	assert.Len(t, stages[1].RunJobs, 0)
+
+	stages, err = resumeStages(resumeTestStages(), resumeTestPrevious(), "compile")
+	assert.NoError(t, err)
+	assert.Equal(t, sdk.Status(""), stages[0].Status)
+	assert.Len(t, stages[0].RunJobs, 0)
+	assert.Len(t, stages[1].RunJobs, 0)
+}
+
+func Test_resumeStagesErrors(t *testing.T) {
+	_, err := resumeStages(resumeTestStages(), resumeTestPrevious(), "unknown")
+	assert.Error(t, err)
+
+	building := resumeTestPrevious()
+	building.Status = sdk.StatusBuilding.String()
+	_, err = resumeStages(resumeTestStages(), building, "")
+	assert.Error(t, err)
+
+	success := resumeTestPrevious()
+	success.Status = sdk.StatusSuccess.String()
+	for i := range success.Stages {
+		success.Stages[i].Status = sdk.StatusSuccess
+	}
+	_, err = resumeStages(resumeTestStages(), success, "")
+	assert.Error(t, err)
+
+	_, err = resumeStages(resumeTestStages()[:2], resumeTestPrevious(), "")
+	assert.Error(t, err)
+}
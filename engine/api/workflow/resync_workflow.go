@@ -78,7 +78,11 @@ func ResyncWorkflowRunStatus(db gorp.SqlExecutor, wr *sdk.WorkflowRun, chEvent c
 	}
 
 	if !isInError {
-		newStatus = getRunStatus(success, building, failed, stopped, skipped, disabled)
+		var err error
+		newStatus, err = runStatusWithApprovals(db, wr, getRunStatus(success, building, failed, stopped, skipped, disabled))
+		if err != nil {
+			return sdk.WrapError(err, "ResyncWorkflowRunStatus>")
+		}
 	}
 
 	if newStatus != wr.Status {
@@ -1,8 +1,10 @@
 package workflow
 
 import (
+	"bytes"
 	"time"
 
+	"github.com/fsamin/go-dump"
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/cache"
@@ -32,6 +34,17 @@ func RunFromHook(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *
 	//Else if will trigger a new subnumber of the last workflow run
 	var number int64
 	if h.WorkflowNodeID == w.RootID {
+		//Check the payload against the run parameters definitions
+		defaults, errP := checkRunParameters(w, e.Payload, nil)
+		if errP != nil {
+			return nil, sdk.WrapError(errP, "RunFromHook> Invalid run parameters")
+		}
+		if len(defaults) > 0 {
+			if e.Payload == nil {
+				e.Payload = map[string]string{}
+			}
+			e.Payload = sdk.ParametersMapMerge(e.Payload, defaults)
+		}
 
 		//Get the next number from our sequence
 		var errnum error
@@ -124,6 +137,19 @@ func ManualRunFromNode(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Stor
 
 //ManualRun is the entry point to trigger a workflow manually
 func ManualRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, w *sdk.Workflow, e *sdk.WorkflowNodeRunManual, chanEvent chan<- interface{}) (*sdk.WorkflowRun, error) {
+	//Check the payload and the parameters against the run parameters definitions
+	defaults, errP := checkRunParameters(w, e.Payload, e.PipelineParameters)
+	if errP != nil {
+		return nil, sdk.WrapError(errP, "ManualRun> Invalid run parameters")
+	}
+	if len(defaults) > 0 {
+		payload, errm := mergePayloadDefaults(e.Payload, defaults)
+		if errm != nil {
+			return nil, sdk.WrapError(errm, "ManualRun> Unable to set default run parameters")
+		}
+		e.Payload = payload
+	}
+
 	number, err := nextRunNumber(db, w)
 	if err != nil {
 		return nil, sdk.WrapError(err, "ManualRun> Unable to get next number")
@@ -155,6 +181,60 @@ func ManualRun(dbCopy *gorp.DbMap, db gorp.SqlExecutor, store cache.Store, p *sd
 	return wr, nil
 }
 
+//checkRunParameters checks the payload and the pipeline parameters of a new run against the run parameters
+//definitions of the workflow. It returns the default values of the missing parameters
+func checkRunParameters(w *sdk.Workflow, payload interface{}, params []sdk.Parameter) (map[string]string, error) {
+	if len(w.RunParameters) == 0 {
+		return nil, nil
+	}
+
+	values, err := payloadToStringMap(payload)
+	if err != nil {
+		return nil, sdk.WrapError(err, "checkRunParameters> Unable to compute payload")
+	}
+	values = sdk.ParametersMapMerge(values, sdk.ParametersToMap(params))
+
+	return w.RunParameters.Check(values)
+}
+
+//mergePayloadDefaults adds the default values of the missing run parameters at the root of a structured payload,
+//the rest of the payload is kept as is
+func mergePayloadDefaults(payload interface{}, defaults map[string]string) (interface{}, error) {
+	switch p := payload.(type) {
+	case nil:
+		return defaults, nil
+	case map[string]string:
+		return sdk.ParametersMapMerge(p, defaults), nil
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(p)+len(defaults))
+		for k, v := range p {
+			res[k] = v
+		}
+		for k, v := range defaults {
+			if _, ok := res[k]; !ok {
+				res[k] = v
+			}
+		}
+		return res, nil
+	}
+	return nil, sdk.WrapError(sdk.ErrWrongRequest, "mergePayloadDefaults> The payload must be an object to set default run parameters")
+}
+
+//payloadToStringMap flattens a payload to check it against the run parameters, the case of the keys is kept to
+//match the names of the run parameters
+func payloadToStringMap(payload interface{}) (map[string]string, error) {
+	if payload == nil {
+		return map[string]string{}, nil
+	}
+	e := dump.NewDefaultEncoder(new(bytes.Buffer))
+	e.Formatters = []dump.KeyFormatterFunc{dump.WithDefaultFormatter()}
+	e.ExtraFields.DetailedMap = false
+	e.ExtraFields.DetailedStruct = false
+	e.ExtraFields.Len = false
+	e.ExtraFields.Type = false
+	return e.ToStringMap(payload)
+}
+
 // GetTag return a specific tag from a list of tags
 func GetTag(tags []sdk.WorkflowRunTag, tag string) sdk.WorkflowRunTag {
 	for _, currentTag := range tags {
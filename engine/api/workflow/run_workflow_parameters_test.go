@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_checkRunParameters(t *testing.T) {
+	w := &sdk.Workflow{
+		RunParameters: sdk.WorkflowParametersSchema{
+			{Name: "MY_PARAM", Required: true},
+			{Name: "build.env", Default: "dev"},
+		},
+	}
+
+	defaults, err := checkRunParameters(w, map[string]interface{}{"MY_PARAM": "foo"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"build.env": "dev"}, defaults)
+
+	_, err = checkRunParameters(w, map[string]interface{}{"my_param": "foo"}, nil)
+	assert.Error(t, err)
+
+	defaults, err = checkRunParameters(w, nil, []sdk.Parameter{{Name: "MY_PARAM", Value: "foo"}, {Name: "build.env", Value: "prod"}})
+	assert.NoError(t, err)
+	assert.Empty(t, defaults)
+}
+
+func Test_mergePayloadDefaults(t *testing.T) {
+	payload := map[string]interface{}{
+		"MY_PARAM": "foo",
+		"git":      map[string]interface{}{"branch": "master"},
+	}
+	res, err := mergePayloadDefaults(payload, map[string]string{"MY_PARAM": "bar", "build.env": "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"MY_PARAM":  "foo",
+		"git":       map[string]interface{}{"branch": "master"},
+		"build.env": "dev",
+	}, res)
+	assert.Len(t, payload, 2)
+
+	res, err = mergePayloadDefaults(nil, map[string]string{"build.env": "dev"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"build.env": "dev"}, res)
+
+	_, err = mergePayloadDefaults([]interface{}{"foo"}, map[string]string{"build.env": "dev"})
+	assert.Error(t, err)
+}
@@ -421,16 +421,16 @@ func TestManualRun3(t *testing.T) {
 		assert.Len(t, secrets, 1)
 
 		//TestAddLog
-		assert.NoError(t, workflow.AddLog(db, j, &sdk.Log{
+		assert.NoError(t, workflow.AddLog(db, cache, j, &sdk.Log{
 			Val: "This is a log",
-		}))
+		}, sdk.NewSecretMasker(secrets)))
 		if t.Failed() {
 			tx.Rollback()
 			t.FailNow()
 		}
-		assert.NoError(t, workflow.AddLog(db, j, &sdk.Log{
+		assert.NoError(t, workflow.AddLog(db, cache, j, &sdk.Log{
 			Val: "This is another log",
-		}))
+		}, sdk.NewSecretMasker(secrets)))
 		if t.Failed() {
 			tx.Rollback()
 			t.FailNow()
@@ -19,69 +19,8 @@ func Import(db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, w *sdk.Wo
 	w.ProjectKey = proj.Key
 	w.ProjectID = proj.ID
 
-	mError := new(sdk.MultiError)
-
-	var pipelineLoader = func(n *sdk.WorkflowNode) {
-		pip, err := pipeline.LoadPipeline(db, proj.Key, n.Pipeline.Name, true)
-		if err != nil {
-			log.Warning("workflow.Import> %s > Pipeline %s not found", w.Name, n.Pipeline.Name)
-			mError.Append(sdk.NewError(sdk.ErrPipelineNotFound, fmt.Errorf("pipeline %s/%s not found", proj.Key, n.Pipeline.Name)))
-			return
-		}
-		n.Pipeline = *pip
-	}
-	w.Visit(pipelineLoader)
-
-	var applicationLoader = func(n *sdk.WorkflowNode) {
-		if n.Context == nil || n.Context.Application == nil || n.Context.Application.Name == "" {
-			return
-		}
-		app, err := application.LoadByName(db, store, proj.Key, n.Context.Application.Name, u)
-		if err != nil {
-			log.Warning("workflow.Import> %s > Application %s not found", w.Name, n.Context.Application.Name)
-			mError.Append(sdk.NewError(sdk.ErrApplicationNotFound, fmt.Errorf("application %s/%s not found", proj.Key, n.Context.Application.Name)))
-			return
-		}
-		n.Context.Application = app
-	}
-	w.Visit(applicationLoader)
-
-	var envLoader = func(n *sdk.WorkflowNode) {
-		if n.Context == nil || n.Context.Environment == nil || n.Context.Environment.Name == "" {
-			return
-		}
-		env, err := environment.LoadEnvironmentByName(db, proj.Key, n.Context.Environment.Name)
-		if err != nil {
-			log.Warning("workflow.Import> %s > Environment %s not found", w.Name, n.Context.Environment.Name)
-			mError.Append(sdk.NewError(sdk.ErrNoEnvironment, fmt.Errorf("environment %s/%s not found", proj.Key, n.Context.Environment.Name)))
-			return
-		}
-		n.Context.Environment = env
-	}
-	w.Visit(envLoader)
-
-	var hookLoad = func(n *sdk.WorkflowNode) {
-		for i := range n.Hooks {
-			h := &n.Hooks[i]
-			m, err := LoadHookModelByName(db, h.WorkflowHookModel.Name)
-			if err != nil {
-				log.Warning("workflow.Import> %s > Hook %s not found", w.Name, h.WorkflowHookModel.Name)
-				mError.Append(sdk.NewError(sdk.ErrNoEnvironment, fmt.Errorf("hook %s not found", h.WorkflowHookModel.Name)))
-				return
-			}
-			h.WorkflowHookModel = *m
-			h.WorkflowHookModelID = m.ID
-			for k, v := range m.DefaultConfig {
-				if _, has := h.Config[k]; !has {
-					h.Config[k] = v
-				}
-			}
-		}
-	}
-	w.Visit(hookLoad)
-
-	if !mError.IsEmpty() {
-		return mError
+	if err := LoadDependencies(db, store, proj, w, u); err != nil {
+		return err
 	}
 
 	doUpdate, errE := Exists(db, proj.Key, w.Name)
@@ -149,3 +88,72 @@ func importWorkflowGroups(db gorp.SqlExecutor, w *sdk.Workflow) error {
 	}
 	return nil
 }
+
+// LoadDependencies loads the pipelines, applications, environments and hook models used by a parsed workflow
+func LoadDependencies(db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, w *sdk.Workflow, u *sdk.User) error {
+	mError := new(sdk.MultiError)
+
+	var pipelineLoader = func(n *sdk.WorkflowNode) {
+		pip, err := pipeline.LoadPipeline(db, proj.Key, n.Pipeline.Name, true)
+		if err != nil {
+			log.Warning("workflow.Import> %s > Pipeline %s not found", w.Name, n.Pipeline.Name)
+			mError.Append(sdk.NewError(sdk.ErrPipelineNotFound, fmt.Errorf("pipeline %s/%s not found", proj.Key, n.Pipeline.Name)))
+			return
+		}
+		n.Pipeline = *pip
+	}
+	w.Visit(pipelineLoader)
+
+	var applicationLoader = func(n *sdk.WorkflowNode) {
+		if n.Context == nil || n.Context.Application == nil || n.Context.Application.Name == "" {
+			return
+		}
+		app, err := application.LoadByName(db, store, proj.Key, n.Context.Application.Name, u)
+		if err != nil {
+			log.Warning("workflow.Import> %s > Application %s not found", w.Name, n.Context.Application.Name)
+			mError.Append(sdk.NewError(sdk.ErrApplicationNotFound, fmt.Errorf("application %s/%s not found", proj.Key, n.Context.Application.Name)))
+			return
+		}
+		n.Context.Application = app
+	}
+	w.Visit(applicationLoader)
+
+	var envLoader = func(n *sdk.WorkflowNode) {
+		if n.Context == nil || n.Context.Environment == nil || n.Context.Environment.Name == "" {
+			return
+		}
+		env, err := environment.LoadEnvironmentByName(db, proj.Key, n.Context.Environment.Name)
+		if err != nil {
+			log.Warning("workflow.Import> %s > Environment %s not found", w.Name, n.Context.Environment.Name)
+			mError.Append(sdk.NewError(sdk.ErrNoEnvironment, fmt.Errorf("environment %s/%s not found", proj.Key, n.Context.Environment.Name)))
+			return
+		}
+		n.Context.Environment = env
+	}
+	w.Visit(envLoader)
+
+	var hookLoad = func(n *sdk.WorkflowNode) {
+		for i := range n.Hooks {
+			h := &n.Hooks[i]
+			m, err := LoadHookModelByName(db, h.WorkflowHookModel.Name)
+			if err != nil {
+				log.Warning("workflow.Import> %s > Hook %s not found", w.Name, h.WorkflowHookModel.Name)
+				mError.Append(sdk.NewError(sdk.ErrNoEnvironment, fmt.Errorf("hook %s not found", h.WorkflowHookModel.Name)))
+				return
+			}
+			h.WorkflowHookModel = *m
+			h.WorkflowHookModelID = m.ID
+			for k, v := range m.DefaultConfig {
+				if _, has := h.Config[k]; !has {
+					h.Config[k] = v
+				}
+			}
+		}
+	}
+	w.Visit(hookLoad)
+
+	if !mError.IsEmpty() {
+		return mError
+	}
+	return nil
+}
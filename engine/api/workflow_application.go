@@ -90,9 +90,18 @@ func (api *API) releaseApplicationWorkflowHandler() Handler {
 			return sdk.WrapError(errRelease, "releaseApplicationWorkflowHandler")
 		}
 
+		// the run is tagged with the release, its artifacts are kept by the projects keeping the releases
+		workflowRun.Tag(sdk.WorkflowRunTagRelease, req.TagName)
+		if err := workflow.UpdateWorkflowRunTags(api.mustDB(), workflowRun); err != nil {
+			return sdk.WrapError(err, "releaseApplicationWorkflowHandler> Cannot tag workflow run %d", workflowRun.ID)
+		}
+
 		// Get artifacts to upload
 		var artifactToUpload []sdk.WorkflowNodeRunArtifact
 		for _, a := range workflowArtifacts {
+			if a.IsReference() {
+				continue
+			}
 			for _, aToUp := range req.Artifacts {
 				ok, errRX := regexp.Match(aToUp, []byte(a.Name))
 				if errRX != nil {
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func (api *API) getWorkflowConcurrencyGroupHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		group := vars["group"]
+
+		runs, err := workflow.LoadConcurrencyGroup(api.mustDB(), group)
+		if err != nil {
+			return sdk.WrapError(err, "getWorkflowConcurrencyGroupHandler> Unable to load concurrency group %s", group)
+		}
+
+		// Hide the node runs of the workflows the user is not allowed to read
+		u := getUser(ctx)
+		for i := range runs {
+			if permission.WorkflowPermission(runs[i].ProjectKey, runs[i].WorkflowName, u) < permission.PermissionRead {
+				runs[i].ProjectKey = ""
+				runs[i].WorkflowName = ""
+				runs[i].WorkflowNodeName = ""
+				runs[i].Number = 0
+				runs[i].WorkflowNodeRunID = 0
+			}
+		}
+
+		return WriteJSON(w, r, runs, http.StatusOK)
+	}
+}
+
+// concurrencyGroupsRoutine executes the workflow node runs released from their concurrency group by a node run of another project
+func concurrencyGroupsRoutine(c context.Context, DBFunc func() *gorp.DbMap, store cache.Store) {
+	tick := time.NewTicker(5 * time.Second).C
+	for {
+		select {
+		case <-c.Done():
+			if c.Err() != nil {
+				log.Error("Exiting concurrencyGroupsRoutine: %v", c.Err())
+				return
+			}
+		case <-tick:
+			db := DBFunc()
+			if db == nil {
+				continue
+			}
+			runs, err := workflow.LoadReadyConcurrencyNodeRuns(db)
+			if err != nil {
+				log.Warning("concurrencyGroupsRoutine> %v", err)
+				continue
+			}
+			for i := range runs {
+				if err := executeReadyConcurrencyNodeRun(db, store, &runs[i]); err != nil {
+					log.Warning("concurrencyGroupsRoutine> Unable to execute node run %d: %v", runs[i].WorkflowNodeRunID, err)
+				}
+			}
+		}
+	}
+}
+
+func executeReadyConcurrencyNodeRun(db *gorp.DbMap, store cache.Store, rc *sdk.WorkflowNodeRunConcurrency) error {
+	p, errP := project.Load(db, store, rc.ProjectKey, nil, project.LoadOptions.WithVariables)
+	if errP != nil {
+		return sdk.WrapError(errP, "executeReadyConcurrencyNodeRun> Cannot load project %s", rc.ProjectKey)
+	}
+
+	tx, errT := db.Begin()
+	if errT != nil {
+		return sdk.WrapError(errT, "executeReadyConcurrencyNodeRun> Unable to start transaction")
+	}
+	defer tx.Rollback()
+
+	if err := workflow.ExecuteReadyConcurrencyNodeRun(db, tx, store, p, rc); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/sanity"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func (api *API) getWorkflowLintHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+
+		proj, errp := project.Load(api.mustDB(), api.Cache, key, getUser(ctx))
+		if errp != nil {
+			return sdk.WrapError(errp, "getWorkflowLintHandler> Unable to load project %s", key)
+		}
+
+		wf, errw := workflow.Load(api.mustDB(), api.Cache, key, name, getUser(ctx), workflow.LoadOptions{DeepPipeline: true})
+		if errw != nil {
+			return sdk.WrapError(errw, "getWorkflowLintHandler> Unable to load workflow %s", name)
+		}
+
+		warnings, errl := sanity.LintWorkflow(api.mustDB(), proj, wf, r.Header.Get("Accept-Language"))
+		if errl != nil {
+			return sdk.WrapError(errl, "getWorkflowLintHandler> Unable to lint workflow %s", name)
+		}
+		return WriteJSON(w, r, warnings, http.StatusOK)
+	}
+}
+
+func (api *API) postWorkflowLintHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)["permProjectKey"]
+
+		proj, errp := project.Load(api.mustDB(), api.Cache, key, getUser(ctx), project.LoadOptions.WithGroups)
+		if errp != nil {
+			return sdk.WrapError(errp, "postWorkflowLintHandler> Unable to load project %s", key)
+		}
+
+		body, errr := ioutil.ReadAll(r.Body)
+		if errr != nil {
+			return sdk.NewError(sdk.ErrWrongRequest, errr)
+		}
+		defer r.Body.Close()
+
+		contentType := r.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(body)
+		}
+
+		var ew = new(exportentities.Workflow)
+		var errw error
+		switch contentType {
+		case "application/json":
+			errw = json.Unmarshal(body, ew)
+		case "application/x-yaml", "text/x-yaml":
+			errw = yaml.Unmarshal(body, ew)
+		default:
+			return sdk.NewError(sdk.ErrWrongRequest, fmt.Errorf("unsupported content-type: %s", contentType))
+		}
+		if errw != nil {
+			return sdk.NewError(sdk.ErrWrongRequest, errw)
+		}
+
+		wf, errpa := workflow.Parse(proj, ew)
+		if errpa != nil {
+			return sdk.WrapError(errpa, "postWorkflowLintHandler> Unable to parse workflow")
+		}
+		if err := workflow.LoadDependencies(api.mustDB(), api.Cache, proj, wf, getUser(ctx)); err != nil {
+			return sdk.WrapError(err, "postWorkflowLintHandler> Unable to load workflow %s dependencies", wf.Name)
+		}
+
+		warnings, errl := sanity.LintWorkflow(api.mustDB(), proj, wf, r.Header.Get("Accept-Language"))
+		if errl != nil {
+			return sdk.WrapError(errl, "postWorkflowLintHandler> Unable to lint workflow %s", wf.Name)
+		}
+		return WriteJSON(w, r, warnings, http.StatusOK)
+	}
+}
@@ -2,17 +2,23 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-gorp/gorp"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/ovh/venom"
+	gocache "github.com/patrickmn/go-cache"
 
 	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/quota"
+	"github.com/ovh/cds/engine/api/secret"
 	"github.com/ovh/cds/engine/api/worker"
 	"github.com/ovh/cds/engine/api/workflow"
 	"github.com/ovh/cds/sdk"
@@ -112,6 +118,12 @@ func takeJob(ctx context.Context, chEvent chan<- interface{}, chError chan<- err
 		})
 	}
 
+	//Check the quotas of the project
+	if err := quota.Check(tx, id); err != nil {
+		chError <- sdk.WrapError(err, "takeJob> Cannot take job %d", id)
+		return
+	}
+
 	//Take node job run
 	job, errTake := workflow.TakeNodeJobRun(db, tx, store, p, id, workerModel, getWorker(ctx).Name, getWorker(ctx).ID, infos, chEvent)
 	if errTake != nil {
@@ -156,18 +168,25 @@ func takeJob(ctx context.Context, chEvent chan<- interface{}, chError chan<- err
 		return
 	}
 
-	secrets, errSecret := workflow.LoadNodeJobRunSecrets(tx, store, job, noderun, workflowRun, pv)
+	secrets, vaultVariables, errSecret := workflow.LoadNodeJobRunDBSecrets(tx, store, job, noderun, workflowRun, pv)
 	if errSecret != nil {
 		chError <- sdk.WrapError(errSecret, "takeJob> Cannot load secrets")
 		return
 	}
+	vaultSecrets, errVault := workflow.ResolveNodeJobRunVaultVariables(tx, workflowRun.ProjectID, vaultVariables)
+	if errVault != nil {
+		chError <- sdk.WrapError(errVault, "takeJob> Cannot load secrets")
+		return
+	}
 
 	//Feed the worker
 
 	wnjri.NodeJobRun = *job
 	wnjri.Number = noderun.Number
 	wnjri.SubNumber = noderun.SubNumber
-	wnjri.Secrets = secrets
+	wnjri.Secrets = make([]sdk.Variable, 0, len(secrets)+len(vaultSecrets))
+	wnjri.Secrets = append(wnjri.Secrets, secrets...)
+	wnjri.Secrets = append(wnjri.Secrets, vaultSecrets...)
 
 	params, secretsKeys, errK := workflow.LoadNodeJobRunKeys(tx, store, job, noderun, workflowRun, p)
 	if errK != nil {
@@ -181,6 +200,20 @@ func takeJob(ctx context.Context, chEvent chan<- interface{}, chError chan<- err
 		chError <- sdk.WrapError(err, "takeJob> Cannot commit transaction")
 		return
 	}
+
+	// the secrets sent to the worker are masked in the logs of the job
+	logSecrets := jobLogSecrets{
+		ProjectID:      workflowRun.ProjectID,
+		Secrets:        append(secrets, secretsKeys...),
+		VaultVariables: vaultVariables,
+	}
+	if err := setJobLogSecrets(store, job.ID, logSecrets); err != nil {
+		log.Warning("takeJob> Cannot keep the secrets of job %d: %v", job.ID, err)
+	}
+	if len(vaultSecrets) > 0 {
+		jobLogVaultSecrets.secrets.SetDefault(strconv.FormatInt(job.ID, 10), vaultSecrets)
+	}
+	workflow.ResetJobLogMaskers(store, job.ID)
 }
 
 func (api *API) postBookWorkflowJobHandler() Handler {
@@ -348,7 +381,12 @@ func (api *API) postWorkflowJobLogsHandler() AsynchronousHandler {
 			return sdk.WrapError(err, "postWorkflowJobLogsHandler> Unable to parse body")
 		}
 
-		if err := workflow.AddLog(api.mustDB(), pbJob, &logs); err != nil {
+		secrets, errS := loadJobLogSecrets(api.mustDB(), api.Cache, pbJob.ID)
+		if errS != nil {
+			return sdk.WrapError(errS, "postWorkflowJobLogsHandler> Cannot load secrets of job %d", id)
+		}
+
+		if err := workflow.AddLog(api.mustDB(), api.Cache, pbJob, &logs, secrets); err != nil {
 			return sdk.WrapError(err, "postWorkflowJobLogsHandler")
 		}
 
@@ -356,6 +394,157 @@ func (api *API) postWorkflowJobLogsHandler() AsynchronousHandler {
 	}
 }
 
+// jobLogSecretsTTL is how long, in seconds, the secrets of a job are kept to mask them in its logs
+const jobLogSecretsTTL = 3600
+
+// jobLogSecrets are the secrets of a job kept to mask them in its logs. The vault variables are only the references
+// of the secrets in the vault of the project, they are resolved by each API instance
+type jobLogSecrets struct {
+	ProjectID      int64          `json:"project_id"`
+	Secrets        []sdk.Variable `json:"secrets"`
+	VaultVariables []sdk.Variable `json:"vault_variables"`
+}
+
+// jobLogVaultSecrets keeps the secrets of the jobs read in vault in the memory of the API instance, they are never
+// written in the shared cache and vault is read once by job
+var jobLogVaultSecrets = struct {
+	sync.Mutex
+	secrets *gocache.Cache
+}{secrets: gocache.New(jobLogSecretsTTL*time.Second, time.Minute)}
+
+// jobLogSecretsKey returns the key of the secrets of a job, kept encrypted in the shared cache so that they are
+// loaded once by job whatever the API instance receiving its logs
+func jobLogSecretsKey(jobID int64) string {
+	return cache.Key("workflows:jobs:logsecrets", strconv.FormatInt(jobID, 10))
+}
+
+// setJobLogSecrets keeps the secrets of a job to mask them in its logs
+func setJobLogSecrets(store cache.Store, jobID int64, secrets jobLogSecrets) error {
+	b, err := json.Marshal(secrets)
+	if err != nil {
+		return sdk.WrapError(err, "setJobLogSecrets> Cannot marshal secrets")
+	}
+	encrypted, err := secret.Encrypt(b)
+	if err != nil {
+		return sdk.WrapError(err, "setJobLogSecrets> Cannot encrypt secrets")
+	}
+	store.SetWithTTL(jobLogSecretsKey(jobID), encrypted, jobLogSecretsTTL)
+	return nil
+}
+
+// getJobLogSecrets returns the secrets of a job kept to mask them in its logs, false if they are not kept
+func getJobLogSecrets(store cache.Store, jobID int64) (*jobLogSecrets, bool) {
+	var encrypted []byte
+	if !store.Get(jobLogSecretsKey(jobID), &encrypted) {
+		return nil, false
+	}
+	b, err := secret.Decrypt(encrypted)
+	if err != nil {
+		log.Warning("getJobLogSecrets> Cannot decrypt secrets of job %d: %v", jobID, err)
+		return nil, false
+	}
+	var secrets jobLogSecrets
+	if err := json.Unmarshal(b, &secrets); err != nil {
+		log.Warning("getJobLogSecrets> Cannot unmarshal secrets of job %d: %v", jobID, err)
+		return nil, false
+	}
+	return &secrets, true
+}
+
+// resolveJobLogVaultSecrets returns the secrets of a job read in vault, they are read once by job and API instance
+func resolveJobLogVaultSecrets(db gorp.SqlExecutor, jobID int64, secrets *jobLogSecrets) ([]sdk.Variable, error) {
+	if len(secrets.VaultVariables) == 0 {
+		return nil, nil
+	}
+
+	jobLogVaultSecrets.Lock()
+	defer jobLogVaultSecrets.Unlock()
+
+	key := strconv.FormatInt(jobID, 10)
+	if resolved, ok := jobLogVaultSecrets.secrets.Get(key); ok {
+		return resolved.([]sdk.Variable), nil
+	}
+	resolved, err := workflow.ResolveNodeJobRunVaultVariables(db, secrets.ProjectID, secrets.VaultVariables)
+	if err != nil {
+		return nil, sdk.WrapError(err, "resolveJobLogVaultSecrets> Cannot resolve vault secrets of job %d", jobID)
+	}
+	jobLogVaultSecrets.secrets.SetDefault(key, resolved)
+	return resolved, nil
+}
+
+// loadJobLogSecrets returns the masker of the secrets of a job in its logs: the password and key variables of
+// its project, application and environment, its vault variables and the keys sent to the worker. It returns nil
+// for a job which does not exist anymore, its logs are only masked by its worker
+func loadJobLogSecrets(db gorp.SqlExecutor, store cache.Store, jobID int64) (*sdk.SecretMasker, error) {
+	secrets, ok := getJobLogSecrets(store, jobID)
+	if !ok {
+		var err error
+		secrets, err = loadJobLogDBSecrets(db, store, jobID)
+		if err != nil {
+			return nil, err
+		}
+		if secrets == nil {
+			return nil, nil
+		}
+		if err := setJobLogSecrets(store, jobID, *secrets); err != nil {
+			log.Warning("loadJobLogSecrets> Cannot keep the secrets of job %d: %v", jobID, err)
+		}
+	}
+
+	vaultSecrets, err := resolveJobLogVaultSecrets(db, jobID, secrets)
+	if err != nil {
+		return nil, sdk.WrapError(err, "loadJobLogSecrets>")
+	}
+	return sdk.NewSecretMasker(append(secrets.Secrets, vaultSecrets...)), nil
+}
+
+// loadJobLogDBSecrets loads the secrets of a job stored in database, nil for a job which does not exist anymore
+func loadJobLogDBSecrets(db gorp.SqlExecutor, store cache.Store, jobID int64) (*jobLogSecrets, error) {
+	job, errJ := workflow.LoadNodeJobRun(db, store, jobID)
+	if errJ != nil {
+		if errJ == sdk.ErrWorkflowNodeRunJobNotFound {
+			return nil, nil
+		}
+		return nil, sdk.WrapError(errJ, "loadJobLogDBSecrets> Cannot load job run %d", jobID)
+	}
+
+	noderun, errN := workflow.LoadNodeRunByID(db, job.WorkflowNodeRunID, false)
+	if errN != nil {
+		return nil, sdk.WrapError(errN, "loadJobLogDBSecrets> Cannot load node run %d", job.WorkflowNodeRunID)
+	}
+
+	workflowRun, errW := workflow.LoadRunByID(db, noderun.WorkflowRunID, false)
+	if errW != nil {
+		return nil, sdk.WrapError(errW, "loadJobLogDBSecrets> Cannot load workflow run %d", noderun.WorkflowRunID)
+	}
+
+	p, errP := project.LoadByID(db, store, workflowRun.ProjectID, nil, project.LoadOptions.WithClearKeys)
+	if errP != nil {
+		return nil, sdk.WrapError(errP, "loadJobLogDBSecrets> Cannot load project %d", workflowRun.ProjectID)
+	}
+
+	pv, errV := project.GetAllVariableInProject(db, p.ID, project.WithClearPassword())
+	if errV != nil {
+		return nil, sdk.WrapError(errV, "loadJobLogDBSecrets> Cannot load project variables")
+	}
+
+	secrets, vaultVariables, errS := workflow.LoadNodeJobRunDBSecrets(db, store, job, noderun, workflowRun, pv)
+	if errS != nil {
+		return nil, sdk.WrapError(errS, "loadJobLogDBSecrets> Cannot load secrets")
+	}
+
+	_, keys, errK := workflow.LoadNodeJobRunKeys(db, store, job, noderun, workflowRun, p)
+	if errK != nil {
+		return nil, sdk.WrapError(errK, "loadJobLogDBSecrets> Cannot load keys")
+	}
+
+	return &jobLogSecrets{
+		ProjectID:      workflowRun.ProjectID,
+		Secrets:        append(secrets, keys...),
+		VaultVariables: vaultVariables,
+	}, nil
+}
+
 func (api *API) postWorkflowJobStepStatusHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		id, errr := requestVarInt(r, "permID")
@@ -467,6 +656,11 @@ func (api *API) getWorkflowJobQueueHandler() Handler {
 			return sdk.WrapError(err, "getWorkflowJobQueueHandler> Unable to load queue")
 		}
 
+		jobs, err = quota.Apply(api.mustDB(), jobs)
+		if err != nil {
+			return sdk.WrapError(err, "getWorkflowJobQueueHandler> Unable to apply quotas on queue")
+		}
+
 		return WriteJSON(w, r, jobs, http.StatusOK)
 	}
 }
@@ -654,3 +848,47 @@ func (api *API) postWorkflowJobVariableHandler() Handler {
 		return nil
 	}
 }
+
+func (api *API) postWorkflowJobStepOutputHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		id, errr := requestVarInt(r, "permID")
+		if errr != nil {
+			return sdk.WrapError(errr, "postWorkflowJobStepOutputHandler> Invalid id")
+		}
+
+		var o sdk.StepOutput
+		if err := UnmarshalBody(r, &o); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobStepOutputHandler")
+		}
+		if o.Step == "" || o.Name == "" {
+			return sdk.WrapError(sdk.ErrWrongRequest, "postWorkflowJobStepOutputHandler> Step and name are mandatory")
+		}
+
+		tx, errb := api.mustDB().Begin()
+		if errb != nil {
+			return sdk.WrapError(errb, "postWorkflowJobStepOutputHandler> Unable to start tx")
+		}
+		defer tx.Rollback()
+
+		job, errj := workflow.LoadNodeJobRun(tx, api.Cache, id)
+		if errj != nil {
+			return sdk.WrapError(errj, "postWorkflowJobStepOutputHandler> Unable to load job %d", id)
+		}
+
+		node, errn := workflow.LoadAndLockNodeRunByID(tx, job.WorkflowNodeRunID, true)
+		if errn != nil {
+			return sdk.WrapError(errn, "postWorkflowJobStepOutputHandler> Unable to load node %d", job.WorkflowNodeRunID)
+		}
+
+		node.Outputs = sdk.MergeStepOutputs(node.Outputs, o)
+		if err := workflow.UpdateNodeRun(tx, node); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobStepOutputHandler> Unable to update node run %d", node.ID)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobStepOutputHandler> Unable to commit tx")
+		}
+
+		return nil
+	}
+}
@@ -2,8 +2,14 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"mime"
 	"net/http"
+	"reflect"
 	"strconv"
 	"time"
 
@@ -43,7 +49,7 @@ func (api *API) postWorkflowJobArtifactHandler() Handler {
 		//get a ref to the parsed multipart form
 		m := r.MultipartForm
 
-		var sizeStr, permStr, md5sum string
+		var sizeStr, permStr, md5sum, sha256sum string
 		if len(m.Value["size"]) > 0 {
 			sizeStr = m.Value["size"][0]
 		}
@@ -53,6 +59,15 @@ func (api *API) postWorkflowJobArtifactHandler() Handler {
 		if len(m.Value["md5sum"]) > 0 {
 			md5sum = m.Value["md5sum"][0]
 		}
+		if len(m.Value["sha256sum"]) > 0 {
+			sha256sum = m.Value["sha256sum"][0]
+		}
+		var metadata map[string]string
+		if len(m.Value["metadata"]) > 0 && m.Value["metadata"][0] != "" {
+			if err := json.Unmarshal([]byte(m.Value["metadata"][0]), &metadata); err != nil {
+				return sdk.WrapError(sdk.ErrWrongRequest, "postWorkflowJobArtifactHandler> Cannot unmarshal metadata: %v", err)
+			}
+		}
 
 		if fileName == "" {
 			log.Warning("uploadArtifactHandler> %s header is not set", "Content-Disposition")
@@ -92,6 +107,7 @@ func (api *API) postWorkflowJobArtifactHandler() Handler {
 			Size:              size,
 			Perm:              uint32(perm),
 			MD5sum:            md5sum,
+			Metadata:          metadata,
 			WorkflowNodeRunID: nodeRun.ID,
 			WorkflowID:        nodeRun.WorkflowRunID,
 			Created:           time.Now(),
@@ -106,11 +122,19 @@ func (api *API) postWorkflowJobArtifactHandler() Handler {
 
 			}
 
-			if err := artifact.SaveWorkflowFile(&art, file); err != nil {
+			// the checksum is computed while the artifact is stored, and checked against the one sent by the worker
+			h := sha256.New()
+			if err := artifact.SaveWorkflowFile(&art, ioutil.NopCloser(io.TeeReader(file, h))); err != nil {
 				file.Close()
 				return sdk.WrapError(err, "postWorkflowJobArtifactHandler> Cannot save artifact in store")
 			}
 			file.Close()
+
+			art.SHA256sum = hex.EncodeToString(h.Sum(nil))
+			if sha256sum != "" && sha256sum != art.SHA256sum {
+				_ = objectstore.DeleteArtifact(&art)
+				return sdk.WrapError(sdk.ErrWorkflowNodeRunArtifactChecksum, "postWorkflowJobArtifactHandler> Artifact %s: expected sha256 %s, got %s", fileName, sha256sum, art.SHA256sum)
+			}
 		}
 
 		nodeRun.Artifacts = append(nodeRun.Artifacts, art)
@@ -122,6 +146,55 @@ func (api *API) postWorkflowJobArtifactHandler() Handler {
 	}
 }
 
+// postWorkflowJobArtifactReferenceHandler records an artifact referencing an image pushed to a docker registry by a job
+func (api *API) postWorkflowJobArtifactReferenceHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		id, errI := requestVarInt(r, "permID")
+		if errI != nil {
+			return sdk.WrapError(sdk.ErrInvalidID, "postWorkflowJobArtifactReferenceHandler> Invalid node job run ID")
+		}
+
+		vars := mux.Vars(r)
+		tag := vars["tag"]
+
+		art := sdk.WorkflowNodeRunArtifact{}
+		if err := UnmarshalBody(r, &art); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobArtifactReferenceHandler")
+		}
+		if art.Name == "" || art.Ref == "" {
+			return sdk.WrapError(sdk.ErrWrongRequest, "postWorkflowJobArtifactReferenceHandler> Name and ref of the artifact are mandatory")
+		}
+
+		nodeJobRun, errJ := workflow.LoadNodeJobRun(api.mustDB(), api.Cache, id)
+		if errJ != nil {
+			return sdk.WrapError(errJ, "postWorkflowJobArtifactReferenceHandler> Cannot load node job run")
+		}
+
+		nodeRun, errR := workflow.LoadNodeRunByID(api.mustDB(), nodeJobRun.WorkflowNodeRunID, true)
+		if errR != nil {
+			return sdk.WrapError(errR, "postWorkflowJobArtifactReferenceHandler> Cannot load node run")
+		}
+
+		hash, errG := generateHash()
+		if errG != nil {
+			return sdk.WrapError(errG, "postWorkflowJobArtifactReferenceHandler> Could not generate hash")
+		}
+
+		art.ID = 0
+		art.Tag = tag
+		art.DownloadHash = hash
+		art.ObjectPath = ""
+		art.WorkflowNodeRunID = nodeRun.ID
+		art.WorkflowID = nodeRun.WorkflowRunID
+		art.Created = time.Now()
+
+		if err := workflow.InsertArtifact(api.mustDB(), &art); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobArtifactReferenceHandler> Cannot insert artifact")
+		}
+		return WriteJSON(w, r, art, http.StatusOK)
+	}
+}
+
 func (api *API) postWorkflowJobArtifacWithTempURLHandler() Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if !objectstore.Instance().TemporaryURLSupported {
@@ -199,7 +272,7 @@ func (api *API) postWorkflowJobArtifactWithTempURLCallbackHandler() Handler {
 			return sdk.WrapError(sdk.ErrNotFound, "postWorkflowJobArtifactWithTempURLCallbackHandler> Unable to find artifact")
 		}
 
-		if art != cachedArt {
+		if !reflect.DeepEqual(art, cachedArt) {
 			return sdk.WrapError(sdk.ErrForbidden, "postWorkflowJobArtifactWithTempURLCallbackHandler> Submitted artifact doesn't match")
 		}
 
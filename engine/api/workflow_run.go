@@ -314,7 +314,7 @@ func (api *API) stopWorkflowRunHandler() Handler {
 		chanEvent := make(chan interface{}, 1)
 		chanError := make(chan error, 1)
 
-		go stopWorkflowRun(chanEvent, chanError, api.mustDB(), api.Cache, proj, run, getUser(ctx))
+		go stopWorkflowRun(chanEvent, chanError, api.mustDB(), api.Cache, proj, run, getUser(ctx), r.FormValue("reason"))
 
 		workflowRuns, workflowNodeRuns, workflowNodeJobRuns, err := workflow.GetWorkflowRunEventData(chanError, chanEvent)
 		if err != nil {
@@ -326,7 +326,16 @@ func (api *API) stopWorkflowRunHandler() Handler {
 	}
 }
 
-func stopWorkflowRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.DbMap, store cache.Store, p *sdk.Project, run *sdk.WorkflowRun, u *sdk.User) {
+// workflowStopMessage returns the stop signal sent to the workers of the stopped jobs, and the message of the stop
+func workflowStopMessage(u *sdk.User, reason string) (sdk.WorkflowNodeJobRunStop, sdk.SpawnMsg) {
+	stop := sdk.WorkflowNodeJobRunStop{Reason: reason, StoppedBy: u.Username}
+	if reason != "" {
+		return stop, sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStopReason.ID, Args: []interface{}{u.Username, reason}}
+	}
+	return stop, sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStop.ID, Args: []interface{}{u.Username}}
+}
+
+func stopWorkflowRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.DbMap, store cache.Store, p *sdk.Project, run *sdk.WorkflowRun, u *sdk.User, reason string) {
 	defer close(chEvent)
 	defer close(chError)
 
@@ -336,7 +345,7 @@ func stopWorkflowRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.
 	}
 	defer tx.Rollback()
 
-	spwnMsg := sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStop.ID, Args: []interface{}{u.Username}}
+	stop, spwnMsg := workflowStopMessage(u, reason)
 
 	stopInfos := sdk.SpawnInfo{
 		APITime:    time.Now(),
@@ -354,7 +363,7 @@ func stopWorkflowRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.
 				continue
 			}
 
-			if errS := workflow.StopWorkflowNodeRun(db, store, p, wnr, stopInfos, chEvent); errS != nil {
+			if errS := workflow.StopWorkflowNodeRun(db, store, p, wnr, stopInfos, stop, chEvent); errS != nil {
 				chError <- sdk.WrapError(errS, "stopWorkflowRunHandler> Unable to stop workflow node run %d", wnr.ID)
 				tx.Rollback()
 			}
@@ -507,7 +516,7 @@ func (api *API) stopWorkflowNodeRunHandler() Handler {
 		chanEvent := make(chan interface{}, 1)
 		chanError := make(chan error, 1)
 
-		go stopWorkflowNodeRun(chanEvent, chanError, api.mustDB(), api.Cache, p, nodeRun, name, getUser(ctx))
+		go stopWorkflowNodeRun(chanEvent, chanError, api.mustDB(), api.Cache, p, nodeRun, name, getUser(ctx), r.FormValue("reason"))
 
 		workflowRuns, workflowNodeRuns, workflowNodeJobRuns, err := workflow.GetWorkflowRunEventData(chanError, chanEvent)
 		if err != nil {
@@ -519,7 +528,7 @@ func (api *API) stopWorkflowNodeRunHandler() Handler {
 	}
 }
 
-func stopWorkflowNodeRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.DbMap, store cache.Store, p *sdk.Project, nodeRun *sdk.WorkflowNodeRun, workflowName string, u *sdk.User) {
+func stopWorkflowNodeRun(chEvent chan<- interface{}, chError chan<- error, db *gorp.DbMap, store cache.Store, p *sdk.Project, nodeRun *sdk.WorkflowNodeRun, workflowName string, u *sdk.User, reason string) {
 	defer close(chEvent)
 	defer close(chError)
 
@@ -530,12 +539,13 @@ func stopWorkflowNodeRun(chEvent chan<- interface{}, chError chan<- error, db *g
 	}
 	defer tx.Rollback()
 
+	stop, spwnMsg := workflowStopMessage(u, reason)
 	stopInfos := sdk.SpawnInfo{
 		APITime:    time.Now(),
 		RemoteTime: time.Now(),
-		Message:    sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStop.ID, Args: []interface{}{u.Username}},
+		Message:    spwnMsg,
 	}
-	if errS := workflow.StopWorkflowNodeRun(db, store, p, *nodeRun, stopInfos, chEvent); errS != nil {
+	if errS := workflow.StopWorkflowNodeRun(db, store, p, *nodeRun, stopInfos, stop, chEvent); errS != nil {
 		chError <- sdk.WrapError(errS, "stopWorkflowNodeRunHandler> Unable to stop workflow node run")
 		return
 	}
@@ -595,6 +605,11 @@ func (api *API) postWorkflowRunHandler() Handler {
 			return err
 		}
 
+		//A node run can only be resumed in an existing workflow run
+		if opts.Manual != nil && opts.Manual.ResumeFrom != nil && (opts.Number == nil || len(opts.FromNodeIDs) != 1) {
+			return sdk.WrapError(sdk.ErrWrongRequest, "postWorkflowRunHandler> Resuming a pipeline needs a workflow run number and a single node")
+		}
+
 		var lastRun *sdk.WorkflowRun
 		if opts.Number != nil {
 			var errlr error
@@ -759,6 +774,25 @@ func runFromNode(db *gorp.DbMap, store cache.Store, opts sdk.WorkflowRunPostHand
 			}
 		}
 
+		//If a node run is resumed, keep its payload and PipelineParameters
+		if opts.Manual.ResumeFrom != nil && lastRun != nil {
+			nodeRuns := lastRun.WorkflowNodeRuns[fromNode.ID]
+			if len(nodeRuns) > 0 {
+				previous := nodeRuns[0]
+				for _, nr := range nodeRuns {
+					if nr.SubNumber > previous.SubNumber {
+						previous = nr
+					}
+				}
+				if opts.Manual.Payload == interface{}(nil) {
+					opts.Manual.Payload = previous.Payload
+				}
+				if len(opts.Manual.PipelineParameters) == 0 {
+					opts.Manual.PipelineParameters = previous.PipelineParameters
+				}
+			}
+		}
+
 		//If payload is not set, keep the default payload
 		if opts.Manual.Payload == interface{}(nil) {
 			opts.Manual.Payload = fromNode.Context.DefaultPayload
@@ -802,6 +836,9 @@ func (api *API) downloadworkflowArtifactDirectHandler() Handler {
 		if err != nil {
 			return sdk.WrapError(err, "downloadworkflowArtifactDirectHandler> Could not load artifact with hash %s", hash)
 		}
+		if art.IsReference() {
+			return sdk.WrapError(sdk.ErrWorkflowNodeRunArtifactReference, "downloadworkflowArtifactDirectHandler> Artifact %s references %s", art.Name, art.Ref)
+		}
 
 		w.Header().Add("Content-Type", "application/octet-stream")
 		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", art.Name))
@@ -846,6 +883,9 @@ func (api *API) getWorkflowNodeRunArtifactsHandler() Handler {
 		//Fetch artifacts
 		for i := range nodeRun.Artifacts {
 			a := &nodeRun.Artifacts[i]
+			if a.IsReference() {
+				continue
+			}
 			url, _ := objectstore.FetchTempURL(a)
 			if url != "" {
 				a.TempURL = url
@@ -879,6 +919,9 @@ func (api *API) getDownloadArtifactHandler() Handler {
 		if errA != nil {
 			return sdk.WrapError(errA, "getDownloadArtifactHandler> Cannot load artifacts")
 		}
+		if art.IsReference() {
+			return sdk.WrapError(sdk.ErrWorkflowNodeRunArtifactReference, "getDownloadArtifactHandler> Artifact %s references %s", art.Name, art.Ref)
+		}
 
 		w.Header().Add("Content-Type", "application/octet-stream")
 		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", art.Name))
@@ -929,6 +972,9 @@ func (api *API) getWorkflowRunArtifactsHandler() Handler {
 
 			wg := &sync.WaitGroup{}
 			for i := range runs[0].Artifacts {
+				if runs[0].Artifacts[i].IsReference() {
+					continue
+				}
 				wg.Add(1)
 				go func(a *sdk.WorkflowNodeRunArtifact) {
 					defer wg.Done()
@@ -998,7 +1044,34 @@ func (api *API) getWorkflowNodeRunJobStepHandler() Handler {
 				stepOrder, runJobID, nodeRunID, number, workflowName, projectKey)
 		}
 
-		logs, errL := workflow.LoadStepLogs(api.mustDB(), runJobID, stepOrder)
+		// The logs can be read by range of bytes with offset and limit, or by the last lines with tail
+		var logs *sdk.Log
+		var size int64
+		var errL error
+		if tail := r.FormValue("tail"); tail != "" {
+			lines, err := strconv.Atoi(tail)
+			if err != nil || lines < 0 {
+				return sdk.WrapError(sdk.ErrWrongRequest, "getWorkflowNodeRunJobStepHandler> tail: invalid number")
+			}
+			logs, size, errL = workflow.LoadStepLogsTail(api.mustDB(), runJobID, stepOrder, lines)
+		} else {
+			offset, limit := int64(0), int64(-1)
+			if o := r.FormValue("offset"); o != "" {
+				var err error
+				offset, err = strconv.ParseInt(o, 10, 64)
+				if err != nil || offset < 0 {
+					return sdk.WrapError(sdk.ErrWrongRequest, "getWorkflowNodeRunJobStepHandler> offset: invalid number")
+				}
+			}
+			if l := r.FormValue("limit"); l != "" {
+				var err error
+				limit, err = strconv.ParseInt(l, 10, 64)
+				if err != nil || limit < 0 {
+					return sdk.WrapError(sdk.ErrWrongRequest, "getWorkflowNodeRunJobStepHandler> limit: invalid number")
+				}
+			}
+			logs, size, errL = workflow.LoadStepLogsRange(api.mustDB(), runJobID, stepOrder, offset, limit)
+		}
 		if errL != nil {
 			return sdk.WrapError(errL, "getWorkflowNodeRunJobStepHandler> Cannot load log for runJob %d on step %d", runJobID, stepOrder)
 		}
@@ -1008,8 +1081,9 @@ func (api *API) getWorkflowNodeRunJobStepHandler() Handler {
 			ls = logs
 		}
 		result := &sdk.BuildState{
-			Status:   sdk.StatusFromString(stepStatus),
-			StepLogs: *ls,
+			Status:       sdk.StatusFromString(stepStatus),
+			StepLogs:     *ls,
+			StepLogsSize: size,
 		}
 
 		return WriteJSON(w, r, result, http.StatusOK)
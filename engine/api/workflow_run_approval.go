@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-gorp/gorp"
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getWorkflowRunApprovalsHandler() Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+		number, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+
+		run, errR := workflow.LoadRun(api.mustDB(), key, name, number, false)
+		if errR != nil {
+			return sdk.WrapError(errR, "getWorkflowRunApprovalsHandler> Unable to load workflow run")
+		}
+
+		approvals, errA := workflow.LoadNodeApprovals(api.mustDB(), run.ID)
+		if errA != nil {
+			return sdk.WrapError(errA, "getWorkflowRunApprovalsHandler> Unable to load approval requests")
+		}
+
+		return WriteJSON(w, r, approvals, http.StatusOK)
+	}
+}
+
+func (api *API) postWorkflowNodeApproveHandler() Handler {
+	return api.postWorkflowNodeDecisionHandler(true)
+}
+
+func (api *API) postWorkflowNodeRejectHandler() Handler {
+	return api.postWorkflowNodeDecisionHandler(false)
+}
+
+func (api *API) postWorkflowNodeDecisionHandler(approved bool) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+		number, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		nodeID, err := requestVarInt(r, "nodeID")
+		if err != nil {
+			return err
+		}
+
+		var decision sdk.WorkflowNodeApprovalDecision
+		if err := UnmarshalBody(r, &decision); err != nil {
+			return err
+		}
+
+		p, errP := project.Load(api.mustDB(), api.Cache, key, getUser(ctx), project.LoadOptions.WithVariables)
+		if errP != nil {
+			return sdk.WrapError(errP, "postWorkflowNodeDecisionHandler> Cannot load project")
+		}
+
+		run, errR := workflow.LoadRun(api.mustDB(), key, name, number, false)
+		if errR != nil {
+			return sdk.WrapError(errR, "postWorkflowNodeDecisionHandler> Unable to load workflow run")
+		}
+
+		chanEvent := make(chan interface{}, 1)
+		chanError := make(chan error, 1)
+		chanApproval := make(chan *sdk.WorkflowNodeApproval, 1)
+
+		go decideWorkflowNodeApproval(chanEvent, chanError, chanApproval, api.mustDB(), api.Cache, p, run, nodeID, getUser(ctx), approved, decision.Comment)
+
+		workflowRuns, workflowNodeRuns, workflowNodeJobRuns, err := workflow.GetWorkflowRunEventData(chanError, chanEvent)
+		if err != nil {
+			return err
+		}
+		workflow.ResyncNodeRunsWithCommits(api.mustDB(), api.Cache, p, workflowNodeRuns)
+		go workflow.SendEvent(api.mustDB(), workflowRuns, workflowNodeRuns, workflowNodeJobRuns, p.Key)
+
+		return WriteJSON(w, r, <-chanApproval, http.StatusOK)
+	}
+}
+
+func decideWorkflowNodeApproval(chEvent chan<- interface{}, chError chan<- error, chApproval chan<- *sdk.WorkflowNodeApproval, db *gorp.DbMap, store cache.Store, p *sdk.Project, wr *sdk.WorkflowRun, nodeID int64, u *sdk.User, approved bool, comment string) {
+	defer close(chEvent)
+	defer close(chError)
+	defer close(chApproval)
+
+	tx, errTx := db.Begin()
+	if errTx != nil {
+		chError <- sdk.WrapError(errTx, "decideWorkflowNodeApproval> Unable to create transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	a, errD := workflow.DecideNodeApproval(db, tx, store, p, wr, nodeID, u, approved, comment, chEvent)
+	if errD != nil {
+		if a != nil {
+			// The approval request has been closed, keep its new status
+			if errC := tx.Commit(); errC != nil {
+				chError <- sdk.WrapError(errC, "decideWorkflowNodeApproval> Unable to commit")
+				return
+			}
+		}
+		chError <- sdk.WrapError(errD, "decideWorkflowNodeApproval> Unable to decide on node %d", nodeID)
+		return
+	}
+
+	if errC := tx.Commit(); errC != nil {
+		chError <- sdk.WrapError(errC, "decideWorkflowNodeApproval> Unable to commit")
+		return
+	}
+	chApproval <- a
+}
@@ -1068,7 +1068,7 @@ func Test_getWorkflowNodeRunJobStepHandler(t *testing.T) {
 	test.NoError(t, errUJ)
 
 	// Add log
-	errAL := workflow.AddLog(api.mustDB(), jobRun, log)
+	errAL := workflow.AddLog(api.mustDB(), api.Cache, jobRun, log, nil)
 	test.NoError(t, errAL)
 
 	//Prepare request
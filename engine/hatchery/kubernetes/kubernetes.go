@@ -209,7 +209,7 @@ func (h *HatcheryKubernetes) SpawnWorker(spawnArgs hatchery.SpawnArguments) (str
 	var gracePeriodSecs int64
 	pod, err := h.k8sClient.CoreV1().Pods(h.Config.KubernetesNamespace).Create(&apiv1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:                       name,
 			DeletionGracePeriodSeconds: &gracePeriodSecs,
 			Labels: map[string]string{
 				LABEL_WORKER:        label,
@@ -300,8 +300,10 @@ func (h *HatcheryKubernetes) Init() error {
 
 func (h *HatcheryKubernetes) startKillAwolWorkerRoutine() {
 	for {
-		time.Sleep(30 * time.Second)
-		h.killAwolWorkers()
+		time.Sleep(10 * time.Second)
+		if err := h.killAwolWorkers(); err != nil {
+			log.Warning("hatchery:kubernetes> Cannot kill awol workers: %s", err)
+		}
 	}
 }
 
@@ -311,9 +313,20 @@ func (h *HatcheryKubernetes) killAwolWorkers() error {
 		return err
 	}
 
+	workers, err := h.Client().WorkerList()
+	if err != nil {
+		return err
+	}
+	disabledWorkers := make(map[string]bool, len(workers))
+	for _, w := range workers {
+		if w.Status == sdk.StatusDisabled {
+			disabledWorkers[w.Name] = true
+		}
+	}
+
 	var globalErr error
 	for _, pod := range pods.Items {
-		toDelete := false
+		toDelete := disabledWorkers[pod.Name]
 		for _, container := range pod.Status.ContainerStatuses {
 			if (container.State.Terminated != nil && container.State.Terminated.Reason == "Completed") || (container.State.Waiting != nil && container.State.Waiting.Reason == "ErrImagePull") {
 				toDelete = true
@@ -103,7 +103,7 @@ func (*HatcheryLocal) ModelType() string {
 }
 
 // CanSpawn return wether or not hatchery can spawn model.
-// requirements are not supported
+// services are started as docker containers if docker is available, memory requirements are not supported
 func (h *HatcheryLocal) CanSpawn(model *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
 	if h.Hatchery() == nil {
 		log.Debug("CanSpawn false Hatchery nil")
@@ -114,7 +114,7 @@ func (h *HatcheryLocal) CanSpawn(model *sdk.Model, jobID int64, requirements []s
 		return false
 	}
 	for _, r := range requirements {
-		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement {
+		if r.Type == sdk.MemoryRequirement || (r.Type == sdk.ServiceRequirement && !h.docker) {
 			return false
 		}
 	}
@@ -127,6 +127,7 @@ func (h *HatcheryLocal) killWorker(worker sdk.Worker) error {
 	for name, workerCmd := range h.workers {
 		if worker.Name == name {
 			log.Info("KillLocalWorker> Killing %s", worker.Name)
+			defer removeServices(workerCmd.services)
 			return workerCmd.cmd.Process.Kill()
 		}
 	}
@@ -194,6 +195,18 @@ func (h *HatcheryLocal) SpawnWorker(spawnArgs hatchery.SpawnArguments) (string,
 		args = append(args, "register")
 	}
 
+	var services []string
+	if spawnArgs.JobID > 0 {
+		hosts, containers, err := h.startServices(wName, spawnArgs.Requirements)
+		if err != nil {
+			return "", err
+		}
+		services = containers
+		if len(hosts) > 0 {
+			args = append(args, fmt.Sprintf("--services=%s", sdk.FormatServiceHosts(hosts)))
+		}
+	}
+
 	cmd := exec.Command("worker", args...)
 
 	// Clearenv
@@ -206,10 +219,11 @@ func (h *HatcheryLocal) SpawnWorker(spawnArgs hatchery.SpawnArguments) (string,
 	}
 
 	if err = cmd.Start(); err != nil {
+		removeServices(services)
 		return "", err
 	}
 	h.Lock()
-	h.workers[wName] = workerCmd{cmd: cmd, created: time.Now()}
+	h.workers[wName] = workerCmd{cmd: cmd, created: time.Now(), services: services}
 	h.Unlock()
 
 	// Wait in a goroutine so that when process exits, Wait() update cmd.ProcessState
@@ -267,6 +281,7 @@ func checkCapabilities(req []sdk.Requirement) ([]sdk.Requirement, error) {
 // Init register local hatchery with its worker model
 func (h *HatcheryLocal) Init() error {
 	h.workers = make(map[string]workerCmd)
+	h.docker = checkDocker()
 
 	genname := h.Configuration().Name
 	h.client = cdsclient.NewHatchery(
@@ -319,6 +334,7 @@ func (h *HatcheryLocal) localWorkerIndexCleanup() {
 	}
 
 	for _, name := range needToDeleteWorkers {
+		removeServices(h.workers[name].services)
 		delete(h.workers, name)
 	}
 }
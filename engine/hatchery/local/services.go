@@ -0,0 +1,73 @@
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/hatchery"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// checkDocker returns true if the docker daemon can be used to start the services of the jobs
+func checkDocker() bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	if err := exec.Command("docker", "info").Run(); err != nil {
+		log.Info("checkDocker> docker is not available, services are not supported: %v", err)
+		return false
+	}
+	return true
+}
+
+// startServices starts a docker container for each service required by the job of a worker. It returns the hosts
+// of the services, which are the IP of their containers, and the names of the containers
+func (h *HatcheryLocal) startServices(wName string, requirements []sdk.Requirement) (map[string]string, []string, error) {
+	hosts := map[string]string{}
+	var containers []string
+	for _, r := range requirements {
+		if r.Type != sdk.ServiceRequirement {
+			continue
+		}
+
+		name := hatchery.ServiceContainerName(r.Name, wName)
+		log.Info("startServices> starting service %s for worker %s", name, wName)
+		if _, err := docker(hatchery.DockerRunServiceArgs(r, wName, false)...); err != nil {
+			removeServices(containers)
+			return nil, nil, fmt.Errorf("cannot start service %s: %v", r.Name, err)
+		}
+		containers = append(containers, name)
+
+		ip, err := docker("inspect", "-f", "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}", name)
+		if err != nil || ip == "" {
+			removeServices(containers)
+			return nil, nil, fmt.Errorf("cannot get the address of service %s: %v", r.Name, err)
+		}
+		hosts[r.Name] = ip
+	}
+	return hosts, containers, nil
+}
+
+// removeServices removes the containers of the services of a worker
+func removeServices(containers []string) {
+	if len(containers) == 0 {
+		return
+	}
+	if _, err := docker(append([]string{"rm", "-f", "-v"}, containers...)...); err != nil {
+		log.Warning("removeServices> cannot remove containers %v: %v", containers, err)
+	}
+}
+
+func docker(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -26,9 +26,12 @@ type HatcheryLocal struct {
 	client  cdsclient.Interface
 	os      string
 	arch    string
+	// docker is true if the services of the jobs can be started as docker containers
+	docker bool
 }
 
 type workerCmd struct {
-	cmd     *exec.Cmd
-	created time.Time
+	cmd      *exec.Cmd
+	created  time.Time
+	services []string
 }
@@ -137,10 +137,10 @@ func (*HatcheryOpenstack) ModelType() string {
 }
 
 // CanSpawn return wether or not hatchery can spawn model
-// requirements are not supported
+// services are started with docker on the virtual machine, memory requirements are not supported
 func (h *HatcheryOpenstack) CanSpawn(model *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
 	for _, r := range requirements {
-		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement {
+		if r.Type == sdk.MemoryRequirement {
 			return false
 		}
 	}
@@ -151,7 +151,8 @@ func (h *HatcheryOpenstack) main() {
 	serverListTick := time.NewTicker(10 * time.Second).C
 	killAwolServersTick := time.NewTicker(30 * time.Second).C
 	killErrorServersTick := time.NewTicker(60 * time.Second).C
-	killDisabledWorkersTick := time.NewTicker(60 * time.Second).C
+	// the workers of the stopped jobs are disabled by the API, their servers are deleted without waiting too long
+	killDisabledWorkersTick := time.NewTicker(10 * time.Second).C
 
 	for {
 		select {
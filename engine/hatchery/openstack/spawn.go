@@ -18,7 +18,7 @@ import (
 )
 
 // SpawnWorker creates a new cloud instances
-// requirements are not supported
+// the services required by the job are started with docker on the instance
 func (h *HatcheryOpenstack) SpawnWorker(spawnArgs hatchery.SpawnArguments) (string, error) {
 	//generate a pretty cool name
 	name := spawnArgs.Model.Name + "-" + strings.Replace(namesgenerator.GetRandomName(0), "_", "-", -1)
@@ -104,6 +104,7 @@ export CDS_BOOKED_WORKFLOW_JOB_ID={{.WorkflowJobID}}
 export CDS_TTL={{.TTL}}
 {{.Graylog}}
 {{.Grpc}}
+{{.Services}}
 ./worker`
 
 	if spawnArgs.RegisterOnly {
@@ -147,6 +148,7 @@ export CDS_TTL={{.TTL}}
 		TTL                int
 		Graylog            string
 		Grpc               string
+		Services           string
 	}{
 		API:          h.Configuration().API.HTTP.URL,
 		Name:         name,
@@ -159,6 +161,10 @@ export CDS_TTL={{.TTL}}
 		Grpc:         grpc,
 	}
 
+	if spawnArgs.JobID > 0 {
+		udataParam.Services = hatchery.DockerServicesScript(spawnArgs.Requirements, name)
+	}
+
 	if spawnArgs.IsWorkflowJob {
 		udataParam.WorkflowJobID = spawnArgs.JobID
 	} else {
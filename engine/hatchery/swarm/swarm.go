@@ -248,13 +248,8 @@ func (h *HatcherySwarm) SpawnWorker(spawnArgs hatchery.SpawnArguments) (string,
 			} else if r.Type == sdk.ServiceRequirement {
 				//name= <alias> => the name of the host put in /etc/hosts of the worker
 				//value= "postgres:latest env_1=blabla env_2=blabla"" => we can add env variables in requirement name
-				tuple := strings.Split(r.Value, " ")
-				img := tuple[0]
-				env := []string{}
+				img, env := sdk.ParseServiceRequirement(r.Value)
 				serviceMemory := int64(1024)
-				if len(tuple) > 1 {
-					env = append(env, tuple[1:]...)
-				}
 				//option for power user : set the service memory with CDS_SERVICE_MEMORY=1024
 				for _, e := range env {
 					if strings.HasPrefix(e, "CDS_SERVICE_MEMORY=") {
@@ -82,7 +82,7 @@ func (h *HatcheryVSphere) SpawnWorker(spawnArgs hatchery.SpawnArguments) (string
 		return "", sdk.WrapError(errW, "SpawnWorker> state in error")
 	}
 
-	return "", h.launchScriptWorker(name, spawnArgs.IsWorkflowJob, spawnArgs.JobID, spawnArgs.Model, spawnArgs.RegisterOnly, info.Result.(types.ManagedObjectReference))
+	return "", h.launchScriptWorker(name, spawnArgs.IsWorkflowJob, spawnArgs.JobID, spawnArgs.Requirements, spawnArgs.Model, spawnArgs.RegisterOnly, info.Result.(types.ManagedObjectReference))
 }
 
 // createVMModel create a model for a specific worker model
@@ -169,8 +169,8 @@ func (h *HatcheryVSphere) createVMModel(model sdk.Model) (*object.VirtualMachine
 	return vm, nil
 }
 
-// launchScriptWorker launch a script on the worker
-func (h *HatcheryVSphere) launchScriptWorker(name string, isWorkflowJob bool, jobID int64, model sdk.Model, registerOnly bool, vmInfo types.ManagedObjectReference) error {
+// launchScriptWorker launch a script on the worker, it starts the services required by the job with docker
+func (h *HatcheryVSphere) launchScriptWorker(name string, isWorkflowJob bool, jobID int64, requirements []sdk.Requirement, model sdk.Model, registerOnly bool, vmInfo types.ManagedObjectReference) error {
 	ctx := context.Background()
 	// Retrieve the new VM
 	vm := object.NewVirtualMachine(h.vclient.Client, vmInfo)
@@ -203,9 +203,14 @@ func (h *HatcheryVSphere) launchScriptWorker(name string, isWorkflowJob bool, jo
 
 	env = append(env, h.getGraylogGrpcEnv(model)...)
 
+	var services string
+	if jobID > 0 {
+		services = strings.Replace(hatchery.DockerServicesScript(requirements, name), "\n", "; ", -1)
+	}
+
 	script := fmt.Sprintf(
-		`cd $HOME; rm -f worker; curl "%s/download/worker/linux/$(uname -m)" -o worker --retry 10 --retry-max-time 120 -C - >> /tmp/user_data 2>&1; chmod +x worker; PATH=$PATH ./worker`,
-		h.Configuration().API.HTTP.URL,
+		`cd $HOME; rm -f worker; curl "%s/download/worker/linux/$(uname -m)" -o worker --retry 10 --retry-max-time 120 -C - >> /tmp/user_data 2>&1; chmod +x worker; %sPATH=$PATH ./worker`,
+		h.Configuration().API.HTTP.URL, services,
 	)
 
 	if registerOnly {
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"mime"
 	"net/http"
 	"net/url"
@@ -12,7 +13,6 @@ import (
 	"time"
 
 	"github.com/fsamin/go-dump"
-	"github.com/gorhill/cronexpr"
 
 	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/sdk"
@@ -180,53 +180,96 @@ func (s *Service) prepareNextScheduledTaskExecution(t *sdk.Task) error {
 		return nil
 	}
 
+	//Parse the cron expression, the timezone and the options of the scheduler
+	conf, err := sdk.ParseSchedulerHookConfig(t.Config)
+	if err != nil {
+		return sdk.WrapError(err, "startTask> unable to parse scheduler configuration")
+	}
+
 	//Load the last execution of this task
 	execs, err := s.Dao.FindAllTaskExecutions(t)
 	if err != nil {
 		return sdk.WrapError(err, "startTask> unable to load last executions")
 	}
 
-	//The last execution has not been executed, let it go
-	if len(execs) > 0 && execs[len(execs)-1].ProcessingTimestamp == 0 {
-		log.Debug("Hooks> Scheduled tasks %s ready. Next execution scheduled on %v", t.UUID, time.Unix(0, execs[len(execs)-1].Timestamp))
-		return nil
+	now := time.Now()
+	var last *sdk.TaskExecution
+	if len(execs) > 0 {
+		last = &execs[len(execs)-1]
 	}
 
-	//Load the location for the timezone
-	confTimezone := t.Config["timezone"]
-	loc, err := time.LoadLocation(confTimezone.Value)
-	if err != nil {
-		return sdk.WrapError(err, "startTask> unable to parse timezone: %s", t.Config["timezone"])
+	//The last execution has not been executed, let it go unless it has been missed and the missed executions are skipped
+	if last != nil && last.ProcessingTimestamp == 0 {
+		if conf.CatchUp != sdk.SchedulerCatchUpNone || !isScheduledExecutionMissed(last, now) {
+			log.Debug("Hooks> Scheduled tasks %s ready. Next execution scheduled on %v", t.UUID, time.Unix(0, last.Timestamp))
+			return nil
+		}
+		log.Info("Hooks> Scheduled task %s: execution missed on %v is skipped", t.UUID, time.Unix(0, last.Timestamp))
+		s.Dao.DeleteTaskExecution(last)
+		last = nil
 	}
 
-	//Parse the cron expr
-	confCron := t.Config["cron"]
-	cronExpr, err := cronexpr.Parse(confCron.Value)
-	if err != nil {
-		return sdk.WrapError(err, "startTask> unable to parse cron expression: %s", t.Config["cron"])
+	//Craft a new execution
+	exec := nextScheduledTaskExecution(t, conf, last, now)
+
+	s.Dao.SaveTaskExecution(exec)
+	//We don't push in queue, we will the scheduler to run it
+
+	log.Debug("Hooks> Scheduled tasks %v ready. Next execution scheduled on %v", t.UUID, time.Unix(0, exec.Timestamp))
+
+	return nil
+}
+
+// schedulerMissedDelay is the delay after which a scheduled execution which has not been processed has been missed.
+// The executions are enqueued on time while the hooks µService is running
+const schedulerMissedDelay = time.Minute
+
+func isScheduledExecutionMissed(e *sdk.TaskExecution, now time.Time) bool {
+	return time.Unix(0, e.Timestamp).Add(schedulerMissedDelay).Before(now)
+}
+
+// nextScheduledTaskExecution returns the next execution of a scheduled task. If executions have been missed since
+// the last execution, the execution of a missed date is run at once according to the catch-up policy. Otherwise the
+// execution is scheduled on the next date given by the cron expression, delayed by a random jitter
+func nextScheduledTaskExecution(t *sdk.Task, conf *sdk.SchedulerHookConfig, last *sdk.TaskExecution, now time.Time) *sdk.TaskExecution {
+	var date, timestamp time.Time
+	var catchUp bool
+
+	if last != nil && conf.CatchUp != sdk.SchedulerCatchUpNone {
+		lastDate := time.Unix(0, last.Timestamp)
+		if last.ScheduledTask != nil && last.ScheduledTask.ScheduledTimestamp != 0 {
+			lastDate = time.Unix(0, last.ScheduledTask.ScheduledTimestamp)
+		}
+		max := 1
+		if conf.CatchUp == sdk.SchedulerCatchUpAll {
+			max = sdk.SchedulerMaxCatchUp
+		}
+		if missed := conf.MissedExecutions(lastDate, now, max); len(missed) > 0 {
+			date = missed[0]
+			timestamp = now
+			catchUp = true
+		}
 	}
 
-	//Compute a new date
-	t0 := time.Now().In(loc)
-	t1 := cronExpr.Next(t0)
+	if !catchUp {
+		date = conf.Next(now)
+		timestamp = date
+		if conf.Jitter > 0 {
+			timestamp = date.Add(time.Duration(rand.Int63n(int64(conf.Jitter))))
+		}
+	}
 
-	//Craft a new execution
-	exec := &sdk.TaskExecution{
-		Timestamp: t1.UnixNano(),
+	return &sdk.TaskExecution{
+		Timestamp: timestamp.UnixNano(),
 		Type:      t.Type,
 		UUID:      t.UUID,
 		Config:    t.Config,
 		ScheduledTask: &sdk.ScheduledTaskExecution{
-			DateScheduledExecution: fmt.Sprintf("%v", t1),
+			DateScheduledExecution: fmt.Sprintf("%v", date),
+			ScheduledTimestamp:     date.UnixNano(),
+			CatchUp:                catchUp,
 		},
 	}
-
-	s.Dao.SaveTaskExecution(exec)
-	//We don't push in queue, we will the scheduler to run it
-
-	log.Debug("Hooks> Scheduled tasks %v ready. Next execution scheduled on %v", t.UUID, time.Unix(0, exec.Timestamp))
-
-	return nil
 }
 
 func (s *Service) stopTask(ctx context.Context, t *sdk.Task) error {
@@ -255,7 +298,7 @@ func (s *Service) doTask(ctx context.Context, t *sdk.Task, e *sdk.TaskExecution)
 	case e.WebHook != nil:
 		h, err = s.doWebHookExecution(e)
 	case e.ScheduledTask != nil:
-		h, err = s.doScheduledTaskExecution(e)
+		h, err = s.doScheduledTaskExecution(t, e)
 	default:
 		err = fmt.Errorf("Unsupported task type %s", e.Type)
 	}
@@ -282,9 +325,29 @@ func (s *Service) doTask(ctx context.Context, t *sdk.Task, e *sdk.TaskExecution)
 	return nil
 }
 
-func (s *Service) doScheduledTaskExecution(t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error) {
+func (s *Service) doScheduledTaskExecution(task *sdk.Task, t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error) {
 	log.Debug("Hooks> Processing scheduled task %s", t.UUID)
 
+	conf, err := sdk.ParseSchedulerHookConfig(t.Config)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Hooks> doScheduledTaskExecution> Unable to parse scheduler configuration")
+	}
+
+	//Skip the execution while the previous run of the workflow is building
+	if conf.SkipIfBuilding {
+		confProj := task.Config["project"]
+		confWorkflow := task.Config["workflow"]
+		runs, err := s.cds.WorkflowRunList(confProj.Value, confWorkflow.Value, 0, 1)
+		if err != nil {
+			return nil, sdk.WrapError(err, "Hooks> doScheduledTaskExecution> Unable to load the last run of the workflow")
+		}
+		if len(runs) > 0 && !sdk.StatusIsTerminated(runs[0].Status) {
+			t.ScheduledTask.Skipped = fmt.Sprintf("the previous run %d is still %s", runs[0].Number, runs[0].Status)
+			log.Info("Hooks> Scheduled task %s skipped: %s", t.UUID, t.ScheduledTask.Skipped)
+			return nil, nil
+		}
+	}
+
 	// Prepare a struct to send to CDS API
 	h := sdk.WorkflowNodeRunHookEvent{
 		WorkflowNodeHookUUID: t.UUID,
@@ -316,7 +379,7 @@ func (s *Service) doScheduledTaskExecution(t *sdk.TaskExecution) (*sdk.WorkflowN
 	}
 	for k, v := range t.Config {
 		switch k {
-		case "project", "workflow", "cron", "timezone", "payload":
+		case "project", "workflow", "cron", "timezone", "payload", "jitter", "skip_if_building", "catch_up":
 		default:
 			payloadValues[k] = v.Value
 		}
@@ -2,6 +2,7 @@ package hooks
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -124,6 +125,58 @@ func Test_doWebHookExecutionBitbucket(t *testing.T) {
 	assert.Equal(t, "9f4fac7ec5642099982a86f584f2c4a362adb670", h.Payload["git.hash"])
 }
 
+func Test_nextScheduledTaskExecution(t *testing.T) {
+	task := &sdk.Task{
+		UUID: sdk.RandomString(10),
+		Type: TypeScheduler,
+		Config: sdk.WorkflowNodeHookConfig{
+			"cron":     {Value: "0 * * * *"},
+			"timezone": {Value: "UTC"},
+			"jitter":   {Value: "10m"},
+		},
+	}
+	now := time.Date(2018, time.March, 23, 14, 30, 0, 0, time.UTC)
+	last := &sdk.TaskExecution{
+		Timestamp:     time.Date(2018, time.March, 23, 10, 5, 0, 0, time.UTC).UnixNano(),
+		ScheduledTask: &sdk.ScheduledTaskExecution{ScheduledTimestamp: time.Date(2018, time.March, 23, 10, 0, 0, 0, time.UTC).UnixNano()},
+	}
+
+	for _, catchUp := range []string{sdk.SchedulerCatchUpNone, sdk.SchedulerCatchUpLast, sdk.SchedulerCatchUpAll} {
+		task.Config["catch_up"] = sdk.WorkflowNodeHookConfigValue{Value: catchUp}
+		conf, err := sdk.ParseSchedulerHookConfig(task.Config)
+		test.NoError(t, err)
+
+		// without previous execution, the next execution is on the next date, delayed by the jitter
+		e := nextScheduledTaskExecution(task, conf, nil, now)
+		next := time.Date(2018, time.March, 23, 15, 0, 0, 0, time.UTC)
+		assert.Equal(t, next.UnixNano(), e.ScheduledTask.ScheduledTimestamp, catchUp)
+		assert.False(t, e.ScheduledTask.CatchUp, catchUp)
+		assert.True(t, e.Timestamp >= next.UnixNano() && e.Timestamp < next.Add(10*time.Minute).UnixNano(), catchUp)
+
+		// the executions from 11AM to 2PM have been missed since the last one
+		e = nextScheduledTaskExecution(task, conf, last, now)
+		switch catchUp {
+		case sdk.SchedulerCatchUpNone:
+			assert.Equal(t, next.UnixNano(), e.ScheduledTask.ScheduledTimestamp, catchUp)
+			assert.False(t, e.ScheduledTask.CatchUp, catchUp)
+		case sdk.SchedulerCatchUpLast:
+			assert.Equal(t, time.Date(2018, time.March, 23, 14, 0, 0, 0, time.UTC).UnixNano(), e.ScheduledTask.ScheduledTimestamp, catchUp)
+			assert.True(t, e.ScheduledTask.CatchUp, catchUp)
+			assert.Equal(t, now.UnixNano(), e.Timestamp, catchUp)
+		case sdk.SchedulerCatchUpAll:
+			assert.Equal(t, time.Date(2018, time.March, 23, 11, 0, 0, 0, time.UTC).UnixNano(), e.ScheduledTask.ScheduledTimestamp, catchUp)
+			assert.True(t, e.ScheduledTask.CatchUp, catchUp)
+			assert.Equal(t, now.UnixNano(), e.Timestamp, catchUp)
+		}
+	}
+}
+
+func Test_isScheduledExecutionMissed(t *testing.T) {
+	now := time.Now()
+	assert.False(t, isScheduledExecutionMissed(&sdk.TaskExecution{Timestamp: now.Add(-10 * time.Second).UnixNano()}, now))
+	assert.True(t, isScheduledExecutionMissed(&sdk.TaskExecution{Timestamp: now.Add(-time.Hour).UnixNano()}, now))
+}
+
 var bitbucketPushEvent = `
 	{
     "eventKey": "repo:refs_changed",
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/engine/api/database"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+var logsMigrateBatchSize int
+
+func init() {
+	logsMigrateCmd.Flags().StringVar(&cfgFile, "config", "", "config file")
+	logsMigrateCmd.Flags().IntVar(&logsMigrateBatchSize, "batch-size", 100, "Number of logs loaded in each batch")
+	logsCmd.AddCommand(logsMigrateCmd)
+	mainCmd.AddCommand(logsCmd)
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Manage CDS workflow logs",
+}
+
+var logsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move the logs stored in the database to the log store",
+	Long: `Move the steps logs stored in the database to the log store of the configuration (api.log.store).
+Only the logs of the finished pipelines are moved, the command can be run while the API is running.
+
+$ engine logs migrate --config config.toml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		//Initialize config
+		config()
+
+		if err := workflow.InitLogStore(conf.API.Log.Store, conf.API.Log.StepMaxSize); err != nil {
+			sdk.Exit("Invalid log store: %v\n", err)
+		}
+
+		cfg, err := conf.API.ObjectstoreConfig()
+		if err != nil {
+			sdk.Exit("Invalid artifact configuration: %v\n", err)
+		}
+		if err := objectstore.Initialize(context.Background(), cfg); err != nil {
+			sdk.Exit("Cannot initialize storage: %v\n", err)
+		}
+
+		db := conf.API.Database
+		f, err := database.Init(db.User, db.Password, db.Name, db.Host, db.Port, db.SSLMode, db.ConnectTimeout, db.Timeout, db.MaxConn)
+		if err != nil {
+			sdk.Exit("Cannot connect to database: %v\n", err)
+		}
+
+		progress := func(done, total int) {
+			fmt.Printf("%d/%d logs moved\n", done, total)
+		}
+		if err := workflow.MigrateLogs(f.GetDBMap(), logsMigrateBatchSize, progress); err != nil {
+			sdk.Exit("Error: %v\n", err)
+		}
+		fmt.Println("done")
+	},
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/engine/api/database"
+	"github.com/ovh/cds/engine/api/secret"
+	"github.com/ovh/cds/sdk"
+)
+
+var reencryptBatchSize int
+
+func init() {
+	reencryptCmd.Flags().StringVar(&cfgFile, "config", "", "config file")
+	reencryptCmd.Flags().IntVar(&reencryptBatchSize, "batch-size", 100, "Number of rows re-encrypted in each transaction")
+	secretsCmd.AddCommand(reencryptCmd)
+	mainCmd.AddCommand(secretsCmd)
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage CDS secrets",
+}
+
+var reencryptCmd = &cobra.Command{
+	Use:   "reencrypt",
+	Short: "Re-encrypt all the secrets with the current key",
+	Long: `Re-encrypt all the project, application and environment variables, keys and repositories credentials with the current secrets key.
+
+To rotate the secrets key:
+ - move the current key to api.secrets.previousKeys, with its id (default is "default")
+ - set a new api.secrets.key and a new api.secrets.keyID, then restart the API
+ - run this command with the same configuration file
+ - remove the old key from api.secrets.previousKeys
+
+$ engine secrets reencrypt --config config.toml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		//Initialize config
+		config()
+
+		previousKeys, err := secret.ParseKeys(conf.API.Secrets.PreviousKeys)
+		if err != nil {
+			sdk.Exit("Invalid previous secret keys: %v\n", err)
+		}
+		if err := secret.Init(conf.API.Secrets.Key, conf.API.Secrets.KeyID, previousKeys); err != nil {
+			sdk.Exit("Cannot initialize secrets: %v\n", err)
+		}
+
+		db := conf.API.Database
+		f, err := database.Init(db.User, db.Password, db.Name, db.Host, db.Port, db.SSLMode, db.ConnectTimeout, db.Timeout, db.MaxConn)
+		if err != nil {
+			sdk.Exit("Cannot connect to database: %v\n", err)
+		}
+
+		for _, c := range secret.EncryptedColumns {
+			progress := func(p secret.ReencryptProgress) {
+				fmt.Printf("%s: %d/%d rows processed, %d re-encrypted\n", p.Column, p.Done, p.Total, p.Reencrypted)
+			}
+			if err := secret.Reencrypt(f.GetDBMap(), c, reencryptBatchSize, progress); err != nil {
+				sdk.Exit("Error: %v\n", err)
+			}
+			fmt.Printf("%s: done\n", c)
+		}
+	},
+}
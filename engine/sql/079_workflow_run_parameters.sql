@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE workflow ADD COLUMN run_parameters JSONB;
+
+-- +migrate Down
+ALTER TABLE workflow DROP COLUMN run_parameters;
@@ -0,0 +1,36 @@
+-- +migrate Up
+ALTER TABLE workflow_node_context ADD COLUMN approval JSONB;
+
+CREATE TABLE IF NOT EXISTS "workflow_node_approval" (
+    id BIGSERIAL PRIMARY KEY,
+    workflow_run_id BIGINT NOT NULL,
+    workflow_node_id BIGINT NOT NULL,
+    workflow_node_name TEXT NOT NULL,
+    sub_num BIGINT NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL,
+    expire_at TIMESTAMP WITH TIME ZONE,
+    rule JSONB,
+    source_node_runs JSONB,
+    hook_event JSONB,
+    manual JSONB
+);
+
+CREATE TABLE IF NOT EXISTS "workflow_node_approval_decision" (
+    id BIGSERIAL PRIMARY KEY,
+    workflow_node_approval_id BIGINT NOT NULL,
+    username TEXT NOT NULL,
+    approved BOOLEAN NOT NULL,
+    comment TEXT,
+    date TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+SELECT create_unique_index('workflow_node_approval', 'IDX_WORKFLOW_NODE_APPROVAL_RUN_NODE', 'workflow_run_id,workflow_node_id,sub_num');
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_NODE_APPROVAL_WORKFLOW_RUN', 'workflow_node_approval', 'workflow_run', 'workflow_run_id', 'id');
+SELECT create_unique_index('workflow_node_approval_decision', 'IDX_WORKFLOW_NODE_APPROVAL_DECISION_USER', 'workflow_node_approval_id,username');
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_NODE_APPROVAL_DECISION', 'workflow_node_approval_decision', 'workflow_node_approval', 'workflow_node_approval_id', 'id');
+
+-- +migrate Down
+DROP TABLE workflow_node_approval_decision;
+DROP TABLE workflow_node_approval;
+ALTER TABLE workflow_node_context DROP COLUMN approval;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "environment_deployment" (
+    id BIGSERIAL PRIMARY KEY,
+    environment_id BIGINT NOT NULL,
+    application_id BIGINT NOT NULL,
+    application_name TEXT NOT NULL,
+    version TEXT,
+    vcs_branch TEXT,
+    vcs_hash TEXT,
+    workflow_name TEXT NOT NULL,
+    workflow_run_number BIGINT NOT NULL,
+    workflow_node_name TEXT NOT NULL,
+    workflow_node_run_id BIGINT NOT NULL,
+    username TEXT,
+    date TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_ENVIRONMENT', 'environment_deployment', 'environment', 'environment_id', 'id');
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_APPLICATION', 'environment_deployment', 'application', 'application_id', 'id');
+
+-- +migrate Down
+DROP TABLE environment_deployment;
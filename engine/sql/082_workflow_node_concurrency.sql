@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE workflow_node_context ADD COLUMN concurrency JSONB;
+
+CREATE TABLE IF NOT EXISTS "workflow_node_run_concurrency" (
+    workflow_node_run_id BIGINT PRIMARY KEY,
+    group_name TEXT NOT NULL,
+    max_running INT NOT NULL DEFAULT 1,
+    status VARCHAR(50) NOT NULL,
+    queued TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+SELECT create_index('workflow_node_run_concurrency', 'IDX_WORKFLOW_NODE_RUN_CONCURRENCY_GROUP', 'group_name, status');
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_NODE_RUN_CONCURRENCY_NODE_RUN', 'workflow_node_run_concurrency', 'workflow_node_run', 'workflow_node_run_id', 'id');
+
+-- +migrate Down
+DROP TABLE workflow_node_run_concurrency;
+ALTER TABLE workflow_node_context DROP COLUMN concurrency;
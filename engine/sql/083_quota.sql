@@ -0,0 +1,30 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "quota" (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT,
+    group_id BIGINT,
+    max_concurrent_jobs INT NOT NULL DEFAULT 0,
+    max_daily_worker_minutes BIGINT NOT NULL DEFAULT 0,
+    priority INT NOT NULL DEFAULT 0
+);
+
+SELECT create_foreign_key_idx_cascade('FK_QUOTA_PROJECT', 'quota', 'project', 'project_id', 'id');
+SELECT create_foreign_key_idx_cascade('FK_QUOTA_GROUP', 'quota', 'group', 'group_id', 'id');
+SELECT create_unique_index('quota', 'IDX_QUOTA_PROJECT_UNIQ', 'project_id');
+SELECT create_unique_index('quota', 'IDX_QUOTA_GROUP_UNIQ', 'group_id');
+
+CREATE TABLE IF NOT EXISTS "worker_usage" (
+    id BIGSERIAL PRIMARY KEY,
+    project_id BIGINT NOT NULL,
+    workflow_node_run_job_id BIGINT NOT NULL,
+    start TIMESTAMP WITH TIME ZONE NOT NULL,
+    done TIMESTAMP WITH TIME ZONE NOT NULL,
+    seconds BIGINT NOT NULL
+);
+
+SELECT create_foreign_key_idx_cascade('FK_WORKER_USAGE_PROJECT', 'worker_usage', 'project', 'project_id', 'id');
+SELECT create_index('worker_usage', 'IDX_WORKER_USAGE_DONE', 'project_id,done');
+
+-- +migrate Down
+DROP TABLE worker_usage;
+DROP TABLE quota;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "project_vault" (
+    project_id BIGINT PRIMARY KEY,
+    address TEXT NOT NULL,
+    auth_method TEXT NOT NULL,
+    role_id TEXT,
+    credential BYTEA
+);
+
+SELECT create_foreign_key_idx_cascade('FK_PROJECT_VAULT_PROJECT', 'project_vault', 'project', 'project_id', 'id');
+
+-- +migrate Down
+DROP TABLE project_vault;
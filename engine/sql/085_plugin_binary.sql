@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "plugin_binary" (
+    id BIGSERIAL PRIMARY KEY,
+    plugin_name TEXT NOT NULL,
+    version TEXT NOT NULL,
+    os TEXT NOT NULL,
+    arch TEXT NOT NULL,
+    size BIGINT,
+    perm INT,
+    sha256sum TEXT NOT NULL,
+    object_path TEXT,
+    created TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+
+select create_unique_index('plugin_binary', 'IDX_PLUGIN_BINARY_NAME_VERSION_OS_ARCH', 'plugin_name,version,os,arch');
+
+-- +migrate Down
+DROP TABLE plugin_binary;
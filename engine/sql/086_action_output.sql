@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "action_output" (
+    id BIGSERIAL PRIMARY KEY,
+    action_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT ''
+);
+
+select create_foreign_key_idx_cascade('FK_ACTION_OUTPUT_ACTION', 'action_output', 'action', 'action_id', 'id');
+select create_unique_index('action_output', 'IDX_ACTION_OUTPUT_ACTION_NAME', 'action_id,name');
+
+-- +migrate Down
+DROP TABLE action_output;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE action_edge ADD COLUMN step_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_node_run ADD COLUMN outputs JSONB;
+
+-- +migrate Down
+ALTER TABLE action_edge DROP COLUMN step_name;
+ALTER TABLE workflow_node_run DROP COLUMN outputs;
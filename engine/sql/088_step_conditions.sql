@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE action_edge ADD COLUMN on_failure BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE action_edge ADD COLUMN continue_on_error BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE action_edge ADD COLUMN conditions JSONB;
+
+-- +migrate Down
+ALTER TABLE action_edge DROP COLUMN on_failure;
+ALTER TABLE action_edge DROP COLUMN continue_on_error;
+ALTER TABLE action_edge DROP COLUMN conditions;
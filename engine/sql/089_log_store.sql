@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN storage VARCHAR(50) NOT NULL DEFAULT 'database';
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN size BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN chunks INT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN compressed BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN storage;
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN size;
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN chunks;
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN compressed;
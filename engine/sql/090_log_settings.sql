@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "project_log_settings" (
+    project_id BIGINT PRIMARY KEY,
+    step_max_size BIGINT NOT NULL DEFAULT 0,
+    retention_days INT NOT NULL DEFAULT 0
+);
+SELECT create_foreign_key_idx_cascade('FK_PROJECT_LOG_SETTINGS_PROJECT', 'project_log_settings', 'project', 'project_id', 'id');
+
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN max_size BIGINT NOT NULL DEFAULT 0;
+SELECT create_index('workflow_node_run_job_logs', 'IDX_WORKFLOW_NODE_RUN_JOB_LOGS_LAST_MODIFIED', 'last_modified');
+
+-- +migrate Down
+DROP TABLE project_log_settings;
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN max_size;
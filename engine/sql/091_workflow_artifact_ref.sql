@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE workflow_node_run_artifacts ADD COLUMN ref TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE workflow_node_run_artifacts DROP COLUMN ref;
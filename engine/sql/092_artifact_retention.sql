@@ -0,0 +1,17 @@
+-- +migrate Up
+ALTER TABLE workflow_node_run_artifacts ADD COLUMN sha256sum TEXT NOT NULL DEFAULT '';
+ALTER TABLE workflow_node_run_artifacts ADD COLUMN metadata JSONB;
+SELECT create_index('workflow_node_run_artifacts', 'IDX_WORKFLOW_NODE_RUN_ARTIFACTS_CREATED', 'created');
+
+CREATE TABLE IF NOT EXISTS "project_artifact_settings" (
+    project_id BIGINT PRIMARY KEY,
+    retention_days INT NOT NULL DEFAULT 0,
+    tagged_only TEXT NOT NULL DEFAULT '',
+    keep_releases BOOLEAN NOT NULL DEFAULT false
+);
+SELECT create_foreign_key_idx_cascade('FK_PROJECT_ARTIFACT_SETTINGS_PROJECT', 'project_artifact_settings', 'project', 'project_id', 'id');
+
+-- +migrate Down
+DROP TABLE project_artifact_settings;
+ALTER TABLE workflow_node_run_artifacts DROP COLUMN sha256sum;
+ALTER TABLE workflow_node_run_artifacts DROP COLUMN metadata;
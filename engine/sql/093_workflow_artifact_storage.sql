@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE workflow_node_run_artifacts ADD COLUMN storage_node_run_id BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE workflow_node_run_artifacts DROP COLUMN storage_node_run_id;
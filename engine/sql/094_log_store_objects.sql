@@ -0,0 +1,14 @@
+-- +migrate Up
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN first_chunk INT NOT NULL DEFAULT 0;
+ALTER TABLE workflow_node_run_job_logs ADD COLUMN compressed_version INT NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS "workflow_node_run_job_logs_obsolete" (
+    id BIGSERIAL PRIMARY KEY,
+    container VARCHAR(256) NOT NULL,
+    name VARCHAR(256) NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+-- +migrate Down
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN first_chunk;
+ALTER TABLE workflow_node_run_job_logs DROP COLUMN compressed_version;
+DROP TABLE workflow_node_run_job_logs_obsolete;
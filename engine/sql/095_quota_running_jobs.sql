@@ -0,0 +1,5 @@
+-- +migrate Up
+SELECT create_index('workflow_node_run_job', 'IDX_WORKFLOW_NODE_RUN_JOB_STATUS', 'status');
+
+-- +migrate Down
+DROP INDEX IDX_WORKFLOW_NODE_RUN_JOB_STATUS;
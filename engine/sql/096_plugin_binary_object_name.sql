@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE plugin_binary ADD COLUMN object_name TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE plugin_binary DROP COLUMN object_name;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- the duplicated logs of a step, inserted by concurrent first writes, are dropped but the first one
+DELETE FROM workflow_node_run_job_logs
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (PARTITION BY workflow_node_run_job_id, step_order ORDER BY id) AS rank
+        FROM workflow_node_run_job_logs
+    ) AS logs
+    WHERE logs.rank > 1
+);
+SELECT create_unique_index('workflow_node_run_job_logs', 'IDX_WORKFLOW_NODE_RUN_JOB_LOGS_STEP_UNIQ', 'workflow_node_run_job_id,step_order');
+
+-- +migrate Down
+DROP INDEX IDX_WORKFLOW_NODE_RUN_JOB_LOGS_STEP_UNIQ;
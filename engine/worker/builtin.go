@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path"
 	"strings"
 
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/grpcplugin"
 	"github.com/ovh/cds/sdk/log"
 	"github.com/ovh/cds/sdk/plugin"
 )
@@ -23,6 +23,7 @@ func init() {
 	mapBuiltinActions[sdk.GitTagAction] = runGitTag
 	mapBuiltinActions[sdk.ReleaseAction] = runRelease
 	mapBuiltinActions[sdk.CheckoutApplication] = runCheckoutApplication
+	mapBuiltinActions[sdk.DockerBuildAction] = runDockerBuild
 }
 
 // BuiltInAction defines builtin action signature
@@ -78,8 +79,8 @@ func (w *currentWorker) runPlugin(ctx context.Context, a *sdk.Action, buildID in
 
 		//For the moment we consider that plugin name = action name = plugin binary file name
 		pluginName := a.Name
-		//The binary file has been downloaded during requirement check in /tmp
-		pluginBinary := path.Join(w.basedir, a.Name)
+		//The binary file has been downloaded during requirement check, in the version pinned by the job
+		pluginBinary := w.pluginBinaryPath(pluginName, w.pinnedPluginVersion(pluginName))
 
 		var tlsskipverify bool
 		if os.Getenv("CDS_SKIP_VERIFY") != "" {
@@ -107,23 +108,26 @@ func (w *currentWorker) runPlugin(ctx context.Context, a *sdk.Action, buildID in
 			env = append(env, fmt.Sprintf("%s=%s", envName, p.Value))
 		}
 
-		//Create the rpc server
-		pluginClient := plugin.NewClient(ctx, pluginName, pluginBinary, w.id, w.apiEndpoint, tlsskipverify, env...)
+		//Start the plugin, it speaks the net/rpc protocol or the GRPC one
+		pluginClient, err := grpcplugin.Start(ctx, pluginName, pluginBinary, w.id, w.apiEndpoint, tlsskipverify, env...)
+		if err != nil {
+			res.Reason = fmt.Sprintf("Unable to init plugin %s: %s\n", pluginName, err)
+			sendLog(res.Reason)
+			chanRes <- res
+			return
+		}
 		defer pluginClient.Kill()
 
-		//Get the plugin interface
-		_plugin, err := pluginClient.Instance()
+		manifest, err := pluginClient.Manifest(ctx)
 		if err != nil {
-			result := sdk.Result{
-				Status: sdk.StatusFail.String(),
-				Reason: fmt.Sprintf("Unable to init plugin %s: %s\n", pluginName, err),
-			}
-			sendLog(result.Reason)
-			chanRes <- result
+			res.Reason = fmt.Sprintf("Unable to get plugin %s manifest: %s\n", pluginName, err)
+			sendLog(res.Reason)
+			chanRes <- res
+			return
 		}
 
-		sendLog(fmt.Sprintf("Starting plugin: %s version %s\n", _plugin.Name(), _plugin.Version()))
-		log.Info("runPlugin> Starting plugin:%s version:%s stepOrder:%d", _plugin.Name(), _plugin.Version(), stepOrder)
+		sendLog(fmt.Sprintf("Starting plugin: %s version %s\n", manifest.Name, manifest.Version))
+		log.Info("runPlugin> Starting plugin:%s version:%s protocol:%d stepOrder:%d", manifest.Name, manifest.Version, pluginClient.ProtocolVersion, stepOrder)
 
 		//Manage all parameters
 		pluginSecrets := plugin.Secrets{
@@ -152,6 +156,18 @@ func (w *currentWorker) runPlugin(ctx context.Context, a *sdk.Action, buildID in
 			id = w.currentJob.wJob.WorkflowNodeRunID
 		}
 
+		legacyPlugin := pluginClient.Legacy()
+		if legacyPlugin == nil {
+			chanRes <- w.runGRPCPlugin(ctx, pluginClient, &grpcplugin.ActionQuery{
+				Options:           pluginArgs.Data,
+				Secrets:           pluginSecrets.Data,
+				JobID:             buildID,
+				WorkflowNodeRunID: id,
+				StepOrder:         int64(stepOrder),
+			}, buildID, &params, sendLog)
+			return
+		}
+
 		pluginAction := plugin.Job{
 			IDPipelineBuild:    id,
 			IDPipelineJobBuild: buildID,
@@ -164,7 +180,7 @@ func (w *currentWorker) runPlugin(ctx context.Context, a *sdk.Action, buildID in
 			pluginAction.IDWorkflowNodeRun = w.currentJob.wJob.WorkflowNodeRunID
 		}
 
-		pluginResult := _plugin.Run(pluginAction)
+		pluginResult := legacyPlugin.Run(pluginAction)
 		if pluginResult == plugin.Success {
 			res.Status = sdk.StatusSuccess.String()
 			log.Info("runPlugin> plugin success on stepOrder:%d", stepOrder)
@@ -189,3 +205,83 @@ func (w *currentWorker) runPlugin(ctx context.Context, a *sdk.Action, buildID in
 		return res
 	}
 }
+
+// pinnedPluginVersion returns the version of the plugin pinned by the requirements of the current job
+func (w *currentWorker) pinnedPluginVersion(name string) string {
+	requirements := w.currentJob.pbJob.Job.Action.Requirements
+	if w.currentJob.wJob != nil {
+		requirements = w.currentJob.wJob.Job.Action.Requirements
+	}
+	for _, r := range requirements {
+		if r.Type != sdk.PluginRequirement {
+			continue
+		}
+		if n, version := sdk.ParsePluginRequirement(r.Value); n == name {
+			return version
+		}
+	}
+	return ""
+}
+
+// runGRPCPlugin runs a plugin speaking the GRPC protocol: its logs are sent as step logs, its outputs are exported
+// as build variables and its artifacts are uploaded. Canceling the context stops the plugin
+func (w *currentWorker) runGRPCPlugin(ctx context.Context, c *grpcplugin.Client, q *grpcplugin.ActionQuery, buildID int64, params *[]sdk.Parameter, sendLog LoggerFunc) sdk.Result {
+	result, err := c.Run(ctx, q, func(e *grpcplugin.ActionEvent) error {
+		switch {
+		case e.Log != nil:
+			sendLog(e.Log.Value)
+		case e.Progress != nil:
+			sendLog(fmt.Sprintf("Progress: %d%% %s", e.Progress.Percent, e.Progress.Message))
+		case e.Output != nil:
+			if err := w.exportOutput(e.Output.Name, e.Output.Value, params); err != nil {
+				return err
+			}
+		case e.Artifact != nil:
+			upload := &sdk.Action{
+				Parameters: []sdk.Parameter{
+					{Name: "path", Type: sdk.StringParameter, Value: e.Artifact.Path},
+					{Name: "tag", Type: sdk.StringParameter, Value: e.Artifact.Tag},
+				},
+			}
+			if res := runArtifactUpload(w)(ctx, upload, buildID, params, sendLog); res.Status != sdk.StatusSuccess.String() {
+				return fmt.Errorf("unable to upload artifact %s: %s", e.Artifact.Path, res.Reason)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return sdk.Result{
+			Status: sdk.StatusFail.String(),
+			Reason: fmt.Sprintf("Plugin Failure: %v", err),
+		}
+	}
+
+	if result.Status != plugin.Success {
+		log.Info("runPlugin> plugin failure on stepOrder:%d", q.StepOrder)
+		return sdk.Result{
+			Status: sdk.StatusFail.String(),
+			Reason: fmt.Sprintf("Plugin Failure: %s", result.Details),
+		}
+	}
+	log.Info("runPlugin> plugin success on stepOrder:%d", q.StepOrder)
+	return sdk.Result{Status: sdk.StatusSuccess.String()}
+}
+
+// exportOutput exports an output of a builtin action or a plugin as a build variable. On a named step, the output is
+// also a step output given to the next jobs
+func (w *currentWorker) exportOutput(name, value string, params *[]sdk.Parameter) error {
+	v := sdk.Variable{
+		Name:  "cds.build." + name,
+		Type:  sdk.StringVariable,
+		Value: value,
+	}
+	if _, err := w.addVariableInPipelineBuild(v, params); err != nil {
+		return fmt.Errorf("unable to export variable %s: %v", name, err)
+	}
+	if w.currentJob.stepName != "" {
+		if err := w.addStepOutput(name, value, params); err != nil {
+			return fmt.Errorf("unable to export output %s: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -138,6 +141,12 @@ func runArtifactDownload(w *currentWorker) BuiltInAction {
 				continue
 			}
 
+			if a.IsReference() {
+				sendLog(fmt.Sprintf("%s is a reference to %s - skipped", a.Name, a.Ref))
+				wg.Done()
+				continue
+			}
+
 			go func(a *sdk.WorkflowNodeRunArtifact) {
 				defer wg.Done()
 
@@ -151,7 +160,8 @@ func runArtifactDownload(w *currentWorker) BuiltInAction {
 					return
 				}
 				sendLog(fmt.Sprintf("downloading artifact %s from workflow %s/%s on run %d...", destFile, project, workflow, n))
-				if err := w.client.WorkflowNodeRunArtifactDownload(project, workflow, *a, f); err != nil {
+				h := sha256.New()
+				if err := w.client.WorkflowNodeRunArtifactDownload(project, workflow, *a, io.MultiWriter(f, h)); err != nil {
 					res.Status = sdk.StatusFail.String()
 					res.Reason = err.Error()
 					log.Warning("Cannot download artifact %s: %s", destFile, err)
@@ -165,6 +175,15 @@ func runArtifactDownload(w *currentWorker) BuiltInAction {
 					sendLog(res.Reason)
 					return
 				}
+				// the artifacts uploaded before the sha256 checksums have none to verify
+				if sum := hex.EncodeToString(h.Sum(nil)); a.SHA256sum != "" && sum != a.SHA256sum {
+					_ = os.Remove(destFile)
+					res.Status = sdk.StatusFail.String()
+					res.Reason = fmt.Sprintf("Invalid checksum of artifact %s: expected sha256 %s, got %s", a.Name, a.SHA256sum, sum)
+					log.Warning("Cannot download artifact %s: %s", destFile, res.Reason)
+					sendLog(res.Reason)
+					return
+				}
 			}(a)
 			if len(artifacts) > 1 {
 				time.Sleep(3 * time.Second)
@@ -108,6 +108,14 @@ func runArtifactUpload(w *currentWorker) BuiltInAction {
 		tag.Value = strings.Replace(tag.Value, "/", "-", -1)
 		tag.Value = url.QueryEscape(tag.Value)
 
+		metadata, err := parseArtifactMetadata(sdk.ParameterValue(a.Parameters, "metadata"))
+		if err != nil {
+			res.Status = sdk.StatusFail.String()
+			res.Reason = err.Error()
+			sendLog(res.Reason)
+			return res
+		}
+
 		// Global all files matching filePath
 		filesPath, err := filepath.Glob(path)
 		if err != nil {
@@ -143,7 +151,7 @@ func runArtifactUpload(w *currentWorker) BuiltInAction {
 			go func(path string) {
 				log.Debug("Uploading %s", path)
 				defer wg.Done()
-				throughTempURL, duration, err := w.client.QueueArtifactUpload(buildID, tag.Value, path)
+				throughTempURL, duration, err := w.client.QueueArtifactUpload(buildID, tag.Value, path, metadata)
 				if err != nil {
 					chanError <- sdk.WrapError(err, "Error while uploading artifact %s", path)
 					wgErrors.Add(1)
@@ -174,3 +182,23 @@ func runArtifactUpload(w *currentWorker) BuiltInAction {
 		return res
 	}
 }
+
+// parseArtifactMetadata reads the metadata of the uploaded artifacts, given one KEY=VALUE per line
+func parseArtifactMetadata(s string) (map[string]string, error) {
+	var metadata map[string]string
+	for _, l := range strings.Split(s, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		i := strings.Index(l, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid metadata %s, expected KEY=VALUE", l)
+		}
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		metadata[strings.TrimSpace(l[:i])] = strings.TrimSpace(l[i+1:])
+	}
+	return metadata, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseArtifactMetadata(t *testing.T) {
+	m, err := parseArtifactMetadata("commit=abcdef\n\n  os = linux \nurl=http://example.com/?a=b\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"commit": "abcdef",
+		"os":     "linux",
+		"url":    "http://example.com/?a=b",
+	}, m)
+
+	m, err = parseArtifactMetadata("")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	_, err = parseArtifactMetadata("commit")
+	assert.Error(t, err)
+	_, err = parseArtifactMetadata("=linux")
+	assert.Error(t, err)
+}
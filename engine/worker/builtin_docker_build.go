@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// dockerBuildOptions are the parameters of a DockerBuild step
+type dockerBuildOptions struct {
+	dockerfile string
+	context    string
+	buildArgs  []docker.BuildArg
+	target     string
+	repository string
+	tags       []string
+	push       bool
+	registry   string
+	username   string
+	password   string
+}
+
+// newDockerBuildOptions reads the parameters of a DockerBuild step. The repository of the image is prefixed by the
+// registry, the Dockerfile is relative to the context, the build args are given one KEY=VALUE per line and the tags
+// are separated by commas
+func newDockerBuildOptions(a *sdk.Action) (*dockerBuildOptions, error) {
+	o := &dockerBuildOptions{
+		dockerfile: sdk.ParameterValue(a.Parameters, "dockerfile"),
+		context:    sdk.ParameterValue(a.Parameters, "context"),
+		target:     sdk.ParameterValue(a.Parameters, "target"),
+		push:       sdk.ParameterValue(a.Parameters, "push") != "false",
+		registry:   strings.TrimSuffix(sdk.ParameterValue(a.Parameters, "registry"), "/"),
+		username:   sdk.ParameterValue(a.Parameters, "registryUsername"),
+		password:   sdk.ParameterValue(a.Parameters, "registryPassword"),
+	}
+	if o.dockerfile == "" {
+		o.dockerfile = "Dockerfile"
+	}
+	if o.context == "" {
+		o.context = "."
+	}
+	o.dockerfile = filepath.Clean(o.dockerfile)
+	if filepath.IsAbs(o.dockerfile) || o.dockerfile == ".." || strings.HasPrefix(o.dockerfile, "../") {
+		return nil, fmt.Errorf("dockerfile %s must be in the context %s", o.dockerfile, o.context)
+	}
+
+	image := strings.ToLower(strings.TrimSpace(sdk.ParameterValue(a.Parameters, "image")))
+	if image == "" {
+		return nil, fmt.Errorf("image is not set. Nothing to perform")
+	}
+	if strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+		return nil, fmt.Errorf("image %s must not contain a tag, use the tags parameter", image)
+	}
+	o.repository = image
+	if o.registry != "" && !strings.HasPrefix(image, o.registry+"/") {
+		o.repository = o.registry + "/" + image
+	}
+	if o.push && o.registry == "" {
+		return nil, fmt.Errorf("registry is not set, the image cannot be pushed")
+	}
+
+	for _, l := range strings.Split(sdk.ParameterValue(a.Parameters, "buildArgs"), "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+		i := strings.Index(l, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid build arg %s, expected KEY=VALUE", l)
+		}
+		o.buildArgs = append(o.buildArgs, docker.BuildArg{Name: l[:i], Value: l[i+1:]})
+	}
+
+	for _, t := range strings.FieldsFunc(sdk.ParameterValue(a.Parameters, "tags"), func(r rune) bool { return r == ',' || r == ' ' }) {
+		o.tags = append(o.tags, strings.Replace(t, "/", "-", -1))
+	}
+	if len(o.tags) == 0 {
+		o.tags = []string{"latest"}
+	}
+	return o, nil
+}
+
+// references returns the references of the image, one per tag
+func (o *dockerBuildOptions) references() []string {
+	refs := make([]string, len(o.tags))
+	for i, t := range o.tags {
+		refs[i] = o.repository + ":" + t
+	}
+	return refs
+}
+
+// buildImageOptions returns the options to build the image with its first reference. The credentials of the worker
+// are kept for the build, to pull the base images from private registries
+func (o *dockerBuildOptions) buildImageOptions(workerAuths *docker.AuthConfigurations) docker.BuildImageOptions {
+	opts := docker.BuildImageOptions{
+		Name:           o.references()[0],
+		Dockerfile:     o.dockerfile,
+		ContextDir:     o.context,
+		BuildArgs:      o.buildArgs,
+		Target:         o.target,
+		RmTmpContainer: true,
+	}
+	if workerAuths != nil {
+		opts.AuthConfigs = *workerAuths
+	}
+	return opts
+}
+
+// pushAuth returns the credentials to push the image: the credentials of the step if any, else the credentials of
+// the worker for the registry
+func (o *dockerBuildOptions) pushAuth(workerAuths *docker.AuthConfigurations) docker.AuthConfiguration {
+	if o.username != "" {
+		return docker.AuthConfiguration{
+			Username:      o.username,
+			Password:      o.password,
+			ServerAddress: o.registry,
+		}
+	}
+	if workerAuths != nil {
+		for _, server := range []string{o.registry, "https://" + o.registry, "http://" + o.registry} {
+			if auth, ok := workerAuths.Configs[server]; ok {
+				return auth
+			}
+		}
+	}
+	return docker.AuthConfiguration{}
+}
+
+// repoDigest returns the digest of the image pushed in the repository, from the repo digests of the image
+func (o *dockerBuildOptions) repoDigest(repoDigests []string) string {
+	for _, d := range repoDigests {
+		if strings.HasPrefix(d, o.repository+"@") {
+			return strings.TrimPrefix(d, o.repository+"@")
+		}
+	}
+	return ""
+}
+
+func runDockerBuild(w *currentWorker) BuiltInAction {
+	return func(ctx context.Context, a *sdk.Action, buildID int64, params *[]sdk.Parameter, sendLog LoggerFunc) sdk.Result {
+		res := sdk.Result{Status: sdk.StatusSuccess.String()}
+		fail := func(format string, args ...interface{}) sdk.Result {
+			res.Status = sdk.StatusFail.String()
+			res.Reason = fmt.Sprintf(format, args...)
+			sendLog(res.Reason)
+			return res
+		}
+
+		o, err := newDockerBuildOptions(a)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		client, err := docker.NewClientFromEnv()
+		if err != nil {
+			return fail("Unable to connect to docker: %v", err)
+		}
+		// the worker may have no docker configuration
+		workerAuths, _ := docker.NewAuthConfigurationsFromDockerCfg()
+
+		out := &dockerLogWriter{sendLog: sendLog}
+
+		sendLog(fmt.Sprintf("Building image %s", o.repository))
+		buildOpts := o.buildImageOptions(workerAuths)
+		buildOpts.OutputStream = out
+		buildOpts.Context = ctx
+		if err := client.BuildImage(buildOpts); err != nil {
+			out.flush()
+			return fail("Unable to build image %s: %v", o.repository, err)
+		}
+		out.flush()
+
+		refs := o.references()
+		for _, t := range o.tags[1:] {
+			if err := client.TagImage(refs[0], docker.TagImageOptions{Repo: o.repository, Tag: t, Force: true, Context: ctx}); err != nil {
+				return fail("Unable to tag image %s with %s: %v", refs[0], t, err)
+			}
+		}
+
+		image, err := client.InspectImage(refs[0])
+		if err != nil {
+			return fail("Unable to inspect image %s: %v", refs[0], err)
+		}
+		imageID := image.ID
+		digest := imageID
+
+		if o.push {
+			auth := o.pushAuth(workerAuths)
+			if o.username != "" {
+				sendLog(fmt.Sprintf("Login to %s", o.registry))
+				if _, err := client.AuthCheck(&auth); err != nil {
+					return fail("Unable to login to %s: %v", o.registry, err)
+				}
+			}
+			for i, t := range o.tags {
+				sendLog(fmt.Sprintf("Pushing %s", refs[i]))
+				pushOpts := docker.PushImageOptions{
+					Name:         o.repository,
+					Tag:          t,
+					OutputStream: out,
+					Context:      ctx,
+				}
+				if err := client.PushImage(pushOpts, auth); err != nil {
+					out.flush()
+					return fail("Unable to push %s: %v", refs[i], err)
+				}
+				out.flush()
+			}
+
+			image, err := client.InspectImage(refs[0])
+			if err != nil {
+				return fail("Unable to inspect image %s: %v", refs[0], err)
+			}
+			digest = o.repoDigest(image.RepoDigests)
+			if digest == "" {
+				return fail("Unable to find the digest of image %s in %s", refs[0], o.repository)
+			}
+		}
+
+		sendLog(fmt.Sprintf("Image %s: %s", o.repository, digest))
+		if err := w.exportOutput("image.id", imageID, params); err != nil {
+			return fail("%v", err)
+		}
+		if err := w.exportOutput("image.digest", digest, params); err != nil {
+			return fail("%v", err)
+		}
+
+		// the pushed image is recorded as an artifact of the workflow, referencing the image in the registry
+		if o.push && w.currentJob.wJob != nil {
+			for i, t := range o.tags {
+				art := sdk.WorkflowNodeRunArtifact{
+					Name: refs[i],
+					Ref:  o.repository + "@" + digest,
+				}
+				if err := w.client.QueueArtifactReference(w.currentJob.wJob.ID, t, art); err != nil {
+					return fail("Unable to record artifact %s: %v", refs[i], err)
+				}
+			}
+		}
+
+		return res
+	}
+}
+
+// dockerLogWriter sends the output of docker as step logs, line by line
+type dockerLogWriter struct {
+	sendLog LoggerFunc
+	buf     bytes.Buffer
+}
+
+func (d *dockerLogWriter) Write(p []byte) (int, error) {
+	d.buf.Write(p)
+	for {
+		i := bytes.IndexByte(d.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		d.sendLog(string(d.buf.Next(i + 1)))
+	}
+	return len(p), nil
+}
+
+// flush sends the last line of the output, not ended by a new line
+func (d *dockerLogWriter) flush() {
+	if d.buf.Len() > 0 {
+		d.sendLog(d.buf.String() + "\n")
+		d.buf.Reset()
+	}
+}
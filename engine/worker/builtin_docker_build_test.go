@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_newDockerBuildOptions(t *testing.T) {
+	a := sdk.NewStepDockerBuild(map[string]string{
+		"image":     "MyApp",
+		"registry":  "registry.example.com/",
+		"tags":      "1.0.0, feat/foo,latest",
+		"buildArgs": "VERSION=1.0.0\n\nGOOS=linux\n",
+		"target":    "release",
+	})
+	o, err := newDockerBuildOptions(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, "registry.example.com/myapp", o.repository)
+	assert.Equal(t, []string{"1.0.0", "feat-foo", "latest"}, o.tags)
+	assert.Equal(t, []string{
+		"registry.example.com/myapp:1.0.0",
+		"registry.example.com/myapp:feat-foo",
+		"registry.example.com/myapp:latest",
+	}, o.references())
+
+	buildOpts := o.buildImageOptions(nil)
+	assert.Equal(t, "registry.example.com/myapp:1.0.0", buildOpts.Name)
+	assert.Equal(t, "Dockerfile", buildOpts.Dockerfile)
+	assert.Equal(t, ".", buildOpts.ContextDir)
+	assert.Equal(t, []docker.BuildArg{{Name: "VERSION", Value: "1.0.0"}, {Name: "GOOS", Value: "linux"}}, o.buildArgs)
+	assert.Equal(t, o.buildArgs, buildOpts.BuildArgs)
+	assert.Equal(t, "release", buildOpts.Target)
+
+	digests := []string{"other.example.com/myapp@sha256:aaa", "registry.example.com/myapp@sha256:bbb"}
+	assert.Equal(t, "sha256:bbb", o.repoDigest(digests))
+}
+
+func Test_dockerBuildOptionsAuth(t *testing.T) {
+	workerAuths := &docker.AuthConfigurations{Configs: map[string]docker.AuthConfiguration{
+		"https://registry.example.com": {Username: "worker", Password: "worker-secret", ServerAddress: "https://registry.example.com"},
+		"base.example.com":             {Username: "base", Password: "base-secret", ServerAddress: "base.example.com"},
+	}}
+
+	// without credentials on the step, the credentials of the worker are used to push
+	a := sdk.NewStepDockerBuild(map[string]string{"image": "myapp", "registry": "registry.example.com"})
+	o, err := newDockerBuildOptions(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, "worker", o.pushAuth(workerAuths).Username)
+	assert.Equal(t, *workerAuths, o.buildImageOptions(workerAuths).AuthConfigs)
+
+	// the credentials of the step are only used to push, the build keeps the credentials of the worker
+	a = sdk.NewStepDockerBuild(map[string]string{
+		"image":            "myapp",
+		"registry":         "registry.example.com",
+		"registryUsername": "step",
+		"registryPassword": "step-secret",
+	})
+	o, err = newDockerBuildOptions(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, docker.AuthConfiguration{Username: "step", Password: "step-secret", ServerAddress: "registry.example.com"}, o.pushAuth(workerAuths))
+	assert.Equal(t, *workerAuths, o.buildImageOptions(workerAuths).AuthConfigs)
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp", "registry": "other.example.com"})
+	o, err = newDockerBuildOptions(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, docker.AuthConfiguration{}, o.pushAuth(workerAuths))
+	assert.Equal(t, docker.AuthConfiguration{}, o.pushAuth(nil))
+}
+
+func Test_dockerLogWriter(t *testing.T) {
+	var lines []string
+	out := &dockerLogWriter{sendLog: func(l string) { lines = append(lines, l) }}
+	out.Write([]byte("Step 1/2 : FROM"))
+	out.Write([]byte(" alpine\nStep 2/2 : RUN true\n ---> abc"))
+	assert.Equal(t, []string{"Step 1/2 : FROM alpine\n", "Step 2/2 : RUN true\n"}, lines)
+	out.flush()
+	assert.Equal(t, " ---> abc\n", lines[2])
+}
+
+func Test_newDockerBuildOptionsErrors(t *testing.T) {
+	a := sdk.NewStepDockerBuild(map[string]string{"registry": "registry.example.com"})
+	_, err := newDockerBuildOptions(&a)
+	assert.Error(t, err)
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp:1.0.0", "registry": "registry.example.com"})
+	_, err = newDockerBuildOptions(&a)
+	assert.Error(t, err)
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp"})
+	_, err = newDockerBuildOptions(&a)
+	assert.Error(t, err)
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp", "push": "false"})
+	o, err := newDockerBuildOptions(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"myapp:latest"}, o.references())
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp", "push": "false", "buildArgs": "VERSION"})
+	_, err = newDockerBuildOptions(&a)
+	assert.Error(t, err)
+
+	a = sdk.NewStepDockerBuild(map[string]string{"image": "myapp", "push": "false", "context": "app", "dockerfile": "../Dockerfile"})
+	_, err = newDockerBuildOptions(&a)
+	assert.Error(t, err)
+}
@@ -125,6 +125,7 @@ func runScriptAction(w *currentWorker) BuiltInAction {
 
 			log.Info("runScriptAction> %s %s", shell, strings.Trim(fmt.Sprint(opts), "[]"))
 			cmd := exec.CommandContext(ctx, shell, opts...)
+			setProcessGroup(cmd)
 			res.Status = sdk.StatusUnknown.String()
 
 			env := os.Environ()
@@ -242,10 +243,24 @@ func runScriptAction(w *currentWorker) BuiltInAction {
 				chanRes <- res
 			}
 
+			// kill all the processes started by the script when the job is stopped
+			cmdDone := make(chan bool)
+			go func() {
+				select {
+				case <-ctx.Done():
+					if err := killProcessGroup(cmd); err != nil {
+						log.Warning("runScriptAction> cannot kill the processes of the script: %s", err)
+					}
+				case <-cmdDone:
+				}
+			}()
+
 			<-outchan
 			<-errchan
-			if err := cmd.Wait(); err != nil {
-				res.Reason = fmt.Sprintf("%s\n", err)
+			errWait := cmd.Wait()
+			close(cmdDone)
+			if errWait != nil {
+				res.Reason = fmt.Sprintf("%s\n", errWait)
 				sendLog(res.Reason)
 				res.Status = sdk.StatusFail.String()
 				chanRes <- res
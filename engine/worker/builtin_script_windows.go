@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on windows, where the script is killed alone
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the script
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
@@ -75,6 +75,9 @@ func initFlagsRun(cmd *cobra.Command) {
 	flags.Int64("booked-job-id", 0, "Booked job id")
 	viper.BindPFlag("booked_job_id", flags.Lookup("booked-job-id"))
 
+	flags.String("services", "", "Services started by the hatchery for the booked job. Ex: --services=postgres=172.17.0.2,redis=172.17.0.3")
+	viper.BindPFlag("services", flags.Lookup("services"))
+
 	flags.String("grpc-api", "", "CDS GRPC tcp address")
 	viper.BindPFlag("grpc_api", flags.Lookup("grpc-api"))
 
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+var cmdOutput = &cobra.Command{
+	Use:   "output",
+	Short: "worker output <name> <value>",
+	Long: `
+Inside a step script of a named step, you can create an output of the step with the worker command:
+
+	# worker output <name> <value>
+	worker output image_digest sha256:4f6e...
+
+The step has to be named in the pipeline:
+
+	steps:
+	- name: deploy
+	  script: ./deploy.sh
+
+
+## Scope
+
+You can use the output of the step ` + "`deploy`" + ` in :
+
+* the next steps of the current job with ` + "`{{.cds.build.deploy.image_digest}}`" + `
+* the next stages in same pipeline ` + "`{{.cds.build.deploy.image_digest}}`" + `
+* the next pipelines of the workflow ` + "`{{.cds.build.deploy.image_digest}}`" + `
+
+	`,
+	Run: outputCmd,
+}
+
+func outputCmd(cmd *cobra.Command, args []string) {
+	portS := os.Getenv(WorkerServerPort)
+	if portS == "" {
+		sdk.Exit("%s not found, are you running inside a CDS worker job?\n", WorkerServerPort)
+	}
+
+	port, err := strconv.Atoi(portS)
+	if err != nil {
+		sdk.Exit("cannot parse '%s' as a port number", portS)
+	}
+
+	if len(args) != 2 {
+		sdk.Exit("Wrong usage: See '%s'\n", cmd.Short)
+	}
+
+	data, err := json.Marshal(sdk.StepOutput{Name: args[0], Value: args[1]})
+	if err != nil {
+		sdk.Exit("internal error (%s)\n", err)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/output", port), bytes.NewReader(data))
+	if err != nil {
+		sdk.Exit("cannot add output: %s\n", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		sdk.Exit("cannot add output: %s\n", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		sdk.Exit("cannot add output: HTTP %d %s\n", resp.StatusCode, body)
+	}
+}
+
+func (wk *currentWorker) addStepOutputHandler(w http.ResponseWriter, r *http.Request) {
+	data, errra := ioutil.ReadAll(r.Body)
+	if errra != nil {
+		log.Error("addStepOutputHandler> Cannot ReadAll err: %s", errra)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var o sdk.StepOutput
+	if err := json.Unmarshal(data, &o); err != nil {
+		log.Error("addStepOutputHandler> Cannot Unmarshal err: %s", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := wk.addStepOutput(o.Name, o.Value, nil); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// addStepOutput exports an output of the current step. It is a build variable of the job, and an output of
+// the workflow node run given to the next stages and pipelines
+func (wk *currentWorker) addStepOutput(name, value string, params *[]sdk.Parameter) error {
+	if wk.currentJob.stepName == "" {
+		return sdk.NewError(sdk.ErrWrongRequest, fmt.Errorf("the step has no name, outputs are only available on named steps"))
+	}
+
+	o := sdk.StepOutput{Step: wk.currentJob.stepName, Name: name, Value: value}
+	v := sdk.Variable{Name: o.ParameterName(), Type: sdk.StringVariable, Value: o.Value}
+	if _, err := wk.addVariableInPipelineBuild(v, params); err != nil {
+		return err
+	}
+
+	if wk.currentJob.wJob == nil {
+		return nil
+	}
+	return wk.client.QueueJobStepOutput(wk.currentJob.wJob.ID, o)
+}
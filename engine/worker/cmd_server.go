@@ -34,6 +34,7 @@ func (w *currentWorker) serve(c context.Context) (int, error) {
 	log.Info("Export variable HTTP server: %s", listener.Addr().String())
 	r := mux.NewRouter()
 	r.HandleFunc("/var", w.addBuildVarHandler)
+	r.HandleFunc("/output", w.addStepOutputHandler)
 	r.HandleFunc("/upload", w.uploadHandler)
 	r.HandleFunc("/tmpl", w.tmplHandler)
 	r.HandleFunc("/tag", w.tagHandler)
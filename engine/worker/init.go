@@ -86,6 +86,7 @@ func initViper(w *currentWorker) {
 	}
 	w.bookedPBJobID = viper.GetInt64("booked_pb_job_id")
 	w.bookedWJobID = viper.GetInt64("booked_workflow_job_id")
+	w.services = sdk.ParseServiceHosts(viper.GetString("services"))
 
 	w.client = cdsclient.NewWorker(w.apiEndpoint, w.status.Name, &http.Client{
 		Timeout: time.Second * 10,
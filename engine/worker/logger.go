@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/ptypes"
@@ -18,13 +19,49 @@ import (
 	"github.com/ovh/cds/sdk/plugin"
 )
 
-var logsecrets []sdk.Variable
+// logsecrets masks the secrets of the current job in the logs of its steps
+var logsecrets struct {
+	sync.Mutex
+	masker *sdk.SecretMasker
+	steps  map[int]*sdk.LogMasker
+}
+
+// setLogSecrets sets the secrets masked in the logs of the steps of the current job
+func setLogSecrets(secrets []sdk.Variable) {
+	logsecrets.Lock()
+	defer logsecrets.Unlock()
+	logsecrets.masker = nil
+	if len(secrets) > 0 {
+		logsecrets.masker = sdk.NewSecretMasker(secrets)
+	}
+	logsecrets.steps = map[int]*sdk.LogMasker{}
+}
+
+// maskLog masks the secrets in a log of a step. The end of a log which could be the beginning of a secret is
+// sent with the next log of the step, or with its final log
+func maskLog(value string, stepOrder int, final bool) string {
+	logsecrets.Lock()
+	defer logsecrets.Unlock()
+	if logsecrets.masker == nil {
+		return value
+	}
+	m, ok := logsecrets.steps[stepOrder]
+	if !ok {
+		m = logsecrets.masker.NewLogMasker()
+		logsecrets.steps[stepOrder] = m
+	}
+	value = m.Mask(value)
+	if final {
+		value += m.Flush()
+		delete(logsecrets.steps, stepOrder)
+	}
+	return value
+}
 
 func (wk *currentWorker) sendLog(buildID int64, value string, stepOrder int, final bool) error {
-	for i := range logsecrets {
-		if len(logsecrets[i].Value) >= 6 {
-			value = strings.Replace(value, logsecrets[i].Value, "**"+logsecrets[i].Name+"**", -1)
-		}
+	value = maskLog(value, stepOrder, final)
+	if value == "" && !final {
+		return nil
 	}
 
 	var id = wk.currentJob.pbJob.PipelineBuildID
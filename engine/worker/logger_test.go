@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_maskLog(t *testing.T) {
+	setLogSecrets([]sdk.Variable{{Name: "cds.proj.key", Value: "-----BEGIN KEY-----\nc2VjcmV0\n-----END KEY-----", Type: sdk.KeyVariable}})
+	defer setLogSecrets(nil)
+
+	// a key printed line by line is masked as a whole
+	var got string
+	for _, line := range []string{"cat key.pem\n", "-----BEGIN KEY-----\n", "c2VjcmV0\n", "-----END KEY-----\n"} {
+		got += maskLog(line, 1, false)
+	}
+	got += maskLog("End of step\n", 1, true)
+	assert.Equal(t, "cat key.pem\n**cds.proj.key**\nEnd of step\n", got)
+
+	setLogSecrets(nil)
+	assert.Equal(t, "-----BEGIN KEY-----\n", maskLog("-----BEGIN KEY-----\n", 1, false))
+}
@@ -20,7 +20,9 @@ type currentWorker struct {
 	nbActionsDone int
 	basedir       string
 	manualExit    bool
-	logger        struct {
+	// services started by the hatchery for the booked job, by name, with their host
+	services map[string]string
+	logger   struct {
 		logChan chan sdk.Log
 		llist   *list.List
 	}
@@ -37,6 +39,7 @@ type currentWorker struct {
 		pbJob          sdk.PipelineBuildJob
 		wJob           *sdk.WorkflowNodeJobRun
 		currentStep    int
+		stepName       string
 		buildVariables []sdk.Variable
 		pkey           string
 		gitsshPath     string
@@ -55,6 +58,7 @@ func main() {
 	w := &currentWorker{}
 	cmd := cmdMain(w)
 	cmd.AddCommand(cmdExport)
+	cmd.AddCommand(cmdOutput)
 	cmd.AddCommand(cmdUpload(w))
 	cmd.AddCommand(cmdTmpl(w))
 	cmd.AddCommand(cmdTag(w))
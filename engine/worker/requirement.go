@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -15,8 +18,8 @@ import (
 	"github.com/shirou/gopsutil/mem"
 
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/grpcplugin"
 	"github.com/ovh/cds/sdk/log"
-	"github.com/ovh/cds/sdk/plugin"
 )
 
 var requirementCheckFuncs = map[string]func(w *currentWorker, r sdk.Requirement) (bool, error){
@@ -81,31 +84,77 @@ func checkRequirement(w *currentWorker, r sdk.Requirement) (bool, error) {
 }
 
 func checkPluginRequirement(w *currentWorker, r sdk.Requirement) (bool, error) {
-	pluginBinary := path.Join(w.basedir, r.Name)
+	name, version := sdk.ParsePluginRequirement(r.Value)
+	pluginBinary := w.pluginBinaryPath(name, version)
 
-	if _, err := os.Stat(pluginBinary); os.IsNotExist(err) {
-		//If the file doesn't exist. Download it.
-		if err := sdk.DownloadPlugin(r.Name, w.basedir); err != nil {
-			return false, err
-		}
-		if err := os.Chmod(pluginBinary, 0700); err != nil {
-			return false, err
-		}
+	if err := downloadPluginBinary(name, version, pluginBinary); err != nil {
+		log.Warning("checkPluginRequirement> Unable to download plugin %s: %s", r.Value, err)
+		return false, err
 	}
 
-	pluginClient := plugin.NewClient(context.Background(), r.Name, pluginBinary, "", "", false)
+	pluginClient, err := grpcplugin.Start(context.Background(), name, pluginBinary, "", "", false)
+	if err != nil {
+		log.Warning("checkPluginRequirement> Error Checking %s requirement : %s", r.Name, err)
+		return false, err
+	}
 	defer pluginClient.Kill()
 
-	_plugin, err := pluginClient.Instance()
+	manifest, err := pluginClient.Manifest(context.Background())
 	if err != nil {
 		log.Warning("checkPluginRequirement> Error Checking %s requirement : %s", r.Name, err)
 		return false, err
 	}
-	log.Debug("checkPluginRequirement> Plugin %s successfully started", _plugin.Name())
+	log.Debug("checkPluginRequirement> Plugin %s successfully started", manifest.Name)
 
 	return true, nil
 }
 
+// pluginBinaryPath returns the path of the plugin binary in the worker basedir, there is a file per pinned version
+func (w *currentWorker) pluginBinaryPath(name, version string) string {
+	if version == "" {
+		return path.Join(w.basedir, name)
+	}
+	return path.Join(w.basedir, name+"@"+version)
+}
+
+// downloadPluginBinary downloads the binary of the plugin for the OS and architecture of the worker.
+// The binary already downloaded is kept if its checksum matches
+func downloadPluginBinary(name, version, pluginBinary string) error {
+	b, err := sdk.GetPluginBinaryInfos(name, version, runtime.GOOS, runtime.GOARCH)
+	if err == sdk.ErrPluginNotFound && version == "" {
+		// plugins uploaded before the binaries registry
+		if _, err := os.Stat(pluginBinary); err == nil {
+			return nil
+		}
+		if err := sdk.DownloadPlugin(name, path.Dir(pluginBinary)); err != nil {
+			return err
+		}
+		return os.Chmod(pluginBinary, 0700)
+	}
+	if err != nil {
+		return err
+	}
+
+	if sum, err := fileSHA256(pluginBinary); err == nil && sum == b.SHA256Sum {
+		return nil
+	}
+	return sdk.DownloadPluginBinary(*b, pluginBinary)
+}
+
+func fileSHA256(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func checkHostnameRequirement(w *currentWorker, r sdk.Requirement) (bool, error) {
 	h, err := os.Hostname()
 	if err != nil {
@@ -153,6 +202,10 @@ func checkNetworkAccessRequirement(w *currentWorker, r sdk.Requirement) (bool, e
 }
 
 func checkServiceRequirement(w *currentWorker, r sdk.Requirement) (bool, error) {
+	// services started by the local and virtual machine hatcheries
+	if _, ok := w.services[r.Name]; ok {
+		return true, nil
+	}
 	// service are supported only for Model Docker
 	if w.model.Type != sdk.Docker {
 		return false, nil
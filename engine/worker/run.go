@@ -177,15 +177,56 @@ func (w *currentWorker) runJob(ctx context.Context, a *sdk.Action, buildID int64
 		r.Status = sdk.StatusDisabled.String()
 	}
 
+	if (r.Status == sdk.StatusSuccess.String() || r.Status == sdk.StatusWarning.String()) && len(a.Outputs) > 0 {
+		if err := w.exportActionOutputs(a, params); err != nil {
+			r.Status = sdk.StatusFail.String()
+			r.Reason = fmt.Sprintf("Unable to export outputs of action %s: %s", a.Name, err)
+		}
+	}
+
 	return r
 }
 
+// exportActionOutputs computes the outputs of an action once its steps are done, from its parameters and the
+// variables exported by its steps. They are available in the next steps as {{.cds.build.<output>}}
+func (w *currentWorker) exportActionOutputs(a *sdk.Action, params *[]sdk.Parameter) error {
+	for _, o := range a.Outputs {
+		value := o.Value
+		for _, p := range a.Parameters {
+			value = strings.Replace(value, "{{."+p.Name+"}}", p.Value, -1)
+		}
+		for _, v := range w.currentJob.buildVariables {
+			value = strings.Replace(value, "{{."+v.Name+"}}", v.Value, -1)
+		}
+		for _, p := range *params {
+			value = strings.Replace(value, "{{."+p.Name+"}}", p.Value, -1)
+		}
+
+		v := sdk.Variable{Name: "cds.build." + o.Name, Type: sdk.StringVariable, Value: value}
+		if _, err := w.addVariableInPipelineBuild(v, params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stepConditionsParameters returns the variables of the job against which the conditions of a step are checked: the
+// parameters of the job and the variables exported by its previous steps
+func (w *currentWorker) stepConditionsParameters(params []sdk.Parameter) []sdk.Parameter {
+	res := make([]sdk.Parameter, 0, len(params)+len(w.currentJob.buildVariables))
+	res = append(res, params...)
+	for _, v := range w.currentJob.buildVariables {
+		res = append(res, sdk.Parameter{Name: v.Name, Type: v.Type, Value: v.Value})
+	}
+	return res
+}
+
 func (w *currentWorker) runSteps(ctx context.Context, steps []sdk.Action, a *sdk.Action, buildID int64, params *[]sdk.Parameter, stepOrder int, stepName string, stepBaseCount int) (sdk.Result, int) {
 	log.Info("runSteps> start run %d stepOrder:%d len(steps):%d context=%p", buildID, stepOrder, len(steps), ctx)
 	defer func() {
 		log.Info("runSteps> end run %d stepOrder:%d len(steps):%d context=%p (%s)", buildID, stepOrder, len(steps), ctx, ctx.Err())
 	}()
-	var criticalStepFailed bool
+	var criticalStepFailed, hasWarning bool
 	var nbDisabledChildren int
 
 	// Nothing to do, success !
@@ -204,6 +245,7 @@ func (w *currentWorker) runSteps(ctx context.Context, steps []sdk.Action, a *sdk
 	for i, child := range steps {
 		if stepOrder == -1 {
 			w.currentJob.currentStep = stepBaseCount + i
+			w.currentJob.stepName = child.StepName
 		} else {
 			w.currentJob.currentStep = stepOrder
 		}
@@ -223,45 +265,86 @@ func (w *currentWorker) runSteps(ctx context.Context, steps []sdk.Action, a *sdk
 			continue
 		}
 
-		if !criticalStepFailed || child.AlwaysExecuted {
-			// Update step status
-			if err := w.updateStepStatus(buildID, w.currentJob.currentStep, sdk.StatusBuilding.String()); err != nil {
-				log.Warning("Cannot update step (%d) status (%s) for build %d: %s\n", w.currentJob.currentStep, sdk.StatusDisabled.String(), buildID, err)
+		if !stepMustRun(child, criticalStepFailed) {
+			// Update status of steps which are never built
+			status := sdk.StatusNeverBuilt
+			if child.OnFailure {
+				status = sdk.StatusSkipped
 			}
-			w.sendLog(buildID, fmt.Sprintf("Starting step %s\n", childName), w.currentJob.currentStep, false)
-
-			r = w.startAction(ctx, &child, buildID, params, w.currentJob.currentStep, childName)
-			if r.Status != sdk.StatusSuccess.String() && !child.Optional {
-				criticalStepFailed = true
+			if err := w.updateStepStatus(buildID, w.currentJob.currentStep, status.String()); err != nil {
+				log.Warning("Cannot update step (%d) status (%s) for build %d: %s", w.currentJob.currentStep, status.String(), buildID, err)
 			}
+			continue
+		}
 
-			if r.Reason != "" {
-				w.sendLog(buildID, fmt.Sprintf("End of step %s [%s] with reason: %s", childName, r.Status, r.Reason), w.currentJob.currentStep, true)
-			} else {
-				w.sendLog(buildID, fmt.Sprintf("End of step %s [%s]", childName, r.Status), w.currentJob.currentStep, true)
+		conditionsOK, err := sdk.WorkflowCheckConditions(child.Conditions, w.stepConditionsParameters(*params))
+		if err != nil {
+			w.sendLog(buildID, fmt.Sprintf("Unable to check conditions of step %s: %s\n", childName, err), w.currentJob.currentStep, false)
+		}
+		if !conditionsOK {
+			if err := w.updateStepStatus(buildID, w.currentJob.currentStep, sdk.StatusSkipped.String()); err != nil {
+				log.Warning("Cannot update step (%d) status (%s) for build %d: %s", w.currentJob.currentStep, sdk.StatusSkipped.String(), buildID, err)
 			}
+			w.sendLog(buildID, fmt.Sprintf("End of step %s [%s]: conditions are not satisfied\n", childName, sdk.StatusSkipped.String()), w.currentJob.currentStep, true)
+			continue
+		}
 
-			// Update step status
-			if err := w.updateStepStatus(buildID, w.currentJob.currentStep, r.Status); err != nil {
-				log.Warning("Cannot update step (%d) status (%s) for build %d: %s", w.currentJob.currentStep, sdk.StatusDisabled.String(), buildID, err)
-			}
-		} else if criticalStepFailed && !child.AlwaysExecuted { // Update status of steps which are never built
-			// Update step status
-			if err := w.updateStepStatus(buildID, w.currentJob.currentStep, sdk.StatusNeverBuilt.String()); err != nil {
-				log.Warning("Cannot update step (%d) status (%s) for build %d: %s", w.currentJob.currentStep, sdk.StatusNeverBuilt.String(), buildID, err)
-			}
+		// Update step status
+		if err := w.updateStepStatus(buildID, w.currentJob.currentStep, sdk.StatusBuilding.String()); err != nil {
+			log.Warning("Cannot update step (%d) status (%s) for build %d: %s\n", w.currentJob.currentStep, sdk.StatusDisabled.String(), buildID, err)
+		}
+		w.sendLog(buildID, fmt.Sprintf("Starting step %s\n", childName), w.currentJob.currentStep, false)
+
+		r = w.startAction(ctx, &child, buildID, params, w.currentJob.currentStep, childName)
+		switch {
+		case r.Status == sdk.StatusSuccess.String():
+		case r.Status == sdk.StatusWarning.String():
+			hasWarning = true
+		case child.ContinueOnError:
+			// the job continues but it will be in warning
+			hasWarning = true
+			r.Status = sdk.StatusWarning.String()
+		case !child.Optional:
+			criticalStepFailed = true
+		}
+
+		if r.Reason != "" {
+			w.sendLog(buildID, fmt.Sprintf("End of step %s [%s] with reason: %s", childName, r.Status, r.Reason), w.currentJob.currentStep, true)
+		} else {
+			w.sendLog(buildID, fmt.Sprintf("End of step %s [%s]", childName, r.Status), w.currentJob.currentStep, true)
+		}
+
+		// Update step status
+		if err := w.updateStepStatus(buildID, w.currentJob.currentStep, r.Status); err != nil {
+			log.Warning("Cannot update step (%d) status (%s) for build %d: %s", w.currentJob.currentStep, sdk.StatusDisabled.String(), buildID, err)
 		}
 	}
 
-	if criticalStepFailed {
+	switch {
+	case criticalStepFailed:
 		r.Status = sdk.StatusFail.String()
-	} else {
+	case hasWarning:
+		r.Status = sdk.StatusWarning.String()
+	default:
 		r.Status = sdk.StatusSuccess.String()
 	}
 
 	return r, nbDisabledChildren
 }
 
+// stepMustRun returns true if the step runs after the previous steps: always executed steps run whatever happened,
+// on failure steps run only after a failure and the other steps run only if nothing has failed
+func stepMustRun(step sdk.Action, criticalStepFailed bool) bool {
+	switch {
+	case step.AlwaysExecuted:
+		return true
+	case step.OnFailure:
+		return criticalStepFailed
+	default:
+		return !criticalStepFailed
+	}
+}
+
 func (w *currentWorker) updateStepStatus(buildID int64, stepOrder int, status string) error {
 	step := sdk.StepStatus{
 		StepOrder: stepOrder,
@@ -324,6 +407,27 @@ func generateWorkingDirectory() (string, error) {
 	return string(token), nil
 }
 
+// serviceParameters returns the cds.service.<name>.host parameters of the services required by a job. The host is given
+// by the hatchery which has started the service, or it is the name of the service for the docker hatcheries
+func (w *currentWorker) serviceParameters(requirements []sdk.Requirement) []sdk.Parameter {
+	var params []sdk.Parameter
+	for _, r := range requirements {
+		if r.Type != sdk.ServiceRequirement {
+			continue
+		}
+		host, ok := w.services[r.Name]
+		if !ok {
+			host = r.Name
+		}
+		params = append(params, sdk.Parameter{
+			Name:  sdk.ServiceHostParameterName(r.Name),
+			Type:  sdk.StringParameter,
+			Value: host,
+		})
+	}
+	return params
+}
+
 func workingDirectory(basedir, jobPath string) string {
 	gen, _ := generateWorkingDirectory()
 	return path.Join(basedir, jobPath, gen)
@@ -369,6 +473,9 @@ func (w *currentWorker) processJob(ctx context.Context, jobInfo *worker.Workflow
 		Value: jobInfo.NodeJobRun.Job.WorkerName,
 	})
 
+	// add the host of the services on parameters available
+	jobInfo.NodeJobRun.Parameters = append(jobInfo.NodeJobRun.Parameters, w.serviceParameters(jobInfo.NodeJobRun.Job.Action.Requirements)...)
+
 	// REPLACE ALL VARIABLE EVEN SECRETS HERE
 	processJobParameter(&jobInfo.NodeJobRun.Parameters, jobInfo.Secrets)
 	if err := w.processActionVariables(&jobInfo.NodeJobRun.Job.Action, nil, jobInfo.NodeJobRun.Parameters, jobInfo.Secrets); err != nil {
@@ -420,9 +527,9 @@ func (w *currentWorker) processJob(ctx context.Context, jobInfo *worker.Workflow
 		}
 	}
 
-	logsecrets = jobInfo.Secrets
+	setLogSecrets(jobInfo.Secrets)
 	res := w.startAction(ctx, &jobInfo.NodeJobRun.Job.Action, jobInfo.NodeJobRun.ID, &jobInfo.NodeJobRun.Parameters, -1, "")
-	logsecrets = nil
+	setLogSecrets(nil)
 
 	if err := teardownBuildDirectory(wd); err != nil {
 		log.Error("Cannot remove build directory: %s", err)
@@ -524,10 +631,10 @@ func (w *currentWorker) run(ctx context.Context, pbji *worker.PipelineBuildJobIn
 		}
 	}
 
-	logsecrets = pbji.Secrets
+	setLogSecrets(pbji.Secrets)
 
 	res := w.startAction(ctx, &pbji.PipelineBuildJob.Job.Action, pbji.PipelineBuildJob.ID, &pbji.PipelineBuildJob.Parameters, -1, "")
-	logsecrets = nil
+	setLogSecrets(nil)
 
 	if err := teardownBuildDirectory(wd); err != nil {
 		log.Error("Cannot remove build directory: %s", err)
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -50,3 +55,88 @@ func Test_processJobParameter(t *testing.T) {
 		assert.EqualValues(t, tt.want, tt.args.pbJob.Parameters)
 	}
 }
+
+func Test_stepMustRun(t *testing.T) {
+	testcases := []struct {
+		name               string
+		step               sdk.Action
+		criticalStepFailed bool
+		want               bool
+	}{
+		{name: "step after success", step: sdk.Action{}, want: true},
+		{name: "step after failure", step: sdk.Action{}, criticalStepFailed: true, want: false},
+		{name: "always executed step after failure", step: sdk.Action{AlwaysExecuted: true}, criticalStepFailed: true, want: true},
+		{name: "on failure step after success", step: sdk.Action{OnFailure: true}, want: false},
+		{name: "on failure step after failure", step: sdk.Action{OnFailure: true}, criticalStepFailed: true, want: true},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, tc.want, stepMustRun(tc.step, tc.criticalStepFailed), tc.name)
+	}
+}
+
+func Test_serviceParameters(t *testing.T) {
+	w := &currentWorker{services: map[string]string{"pg": "172.17.0.2"}}
+	params := w.serviceParameters([]sdk.Requirement{
+		{Name: "pg", Type: sdk.ServiceRequirement, Value: "postgres:9.6"},
+		{Name: "redis", Type: sdk.ServiceRequirement, Value: "redis"},
+		{Name: "git", Type: sdk.BinaryRequirement, Value: "git"},
+	})
+	if !assert.Len(t, params, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "cds.service.pg.host", params[0].Name)
+	assert.Equal(t, "172.17.0.2", params[0].Value)
+	// services of the docker hatcheries are reachable by their name
+	assert.Equal(t, "cds.service.redis.host", params[1].Name)
+	assert.Equal(t, "redis", params[1].Value)
+}
+
+func Test_runStepsConditionOnExportedVariable(t *testing.T) {
+	// the API records the status of the steps
+	var mutex sync.Mutex
+	statuses := map[int]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/queue/workflows/1/step" {
+			var s sdk.StepStatus
+			if err := json.NewDecoder(r.Body).Decode(&s); err == nil {
+				mutex.Lock()
+				statuses[s.StepOrder] = s.Status
+				mutex.Unlock()
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	sdk.InitEndpoint(srv.URL)
+
+	w := &currentWorker{}
+	w.logger.logChan = make(chan sdk.Log, 100)
+	w.currentJob.wJob = &sdk.WorkflowNodeJobRun{ID: 1}
+
+	// the first step exports cds.build.version with its output
+	steps := []sdk.Action{
+		{
+			Name:    "export",
+			Enabled: true,
+			Actions: []sdk.Action{{Name: "noop", Enabled: true}},
+			Outputs: []sdk.ActionOutput{{Name: "version", Value: "1.2.3"}},
+		},
+		{
+			Name:       "matching",
+			Enabled:    true,
+			Actions:    []sdk.Action{{Name: "noop", Enabled: true}},
+			Conditions: []sdk.WorkflowNodeCondition{{Variable: "cds.build.version", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "1.2.3"}},
+		},
+		{
+			Name:       "not matching",
+			Enabled:    true,
+			Actions:    []sdk.Action{{Name: "noop", Enabled: true}},
+			Conditions: []sdk.WorkflowNodeCondition{{Variable: "cds.build.version", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "2.0.0"}},
+		},
+	}
+	params := []sdk.Parameter{}
+	r, _ := w.runSteps(context.Background(), steps, nil, 1, &params, -1, "job", 0)
+	assert.Equal(t, sdk.StatusSuccess.String(), r.Status)
+	assert.Equal(t, sdk.StatusSuccess.String(), statuses[1])
+	assert.Equal(t, sdk.StatusSkipped.String(), statuses[2])
+}
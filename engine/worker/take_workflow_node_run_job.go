@@ -40,6 +40,7 @@ func (w *currentWorker) takeWorkflowJob(ctx context.Context, job sdk.WorkflowNod
 
 	//This goroutine try to get the pipeline build job every 5 seconds, if it fails, it cancel the build.
 	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	tick := time.NewTicker(5 * time.Second)
 	go func(cancel context.CancelFunc, jobID int64, tick *time.Ticker) {
 		for {
@@ -76,6 +77,11 @@ func (w *currentWorker) takeWorkflowJob(ctx context.Context, job sdk.WorkflowNod
 		}
 	}(cancel, job.ID, tick)
 
+	//The build is canceled as soon as the API notifies the stop of the job
+	if w.grpc.conn != nil {
+		go w.watchWorkflowJobStop(ctx, cancel, job.ID)
+	}
+
 	// Reset build variables
 	w.currentJob.buildVariables = nil
 	//Run !
@@ -113,3 +119,21 @@ func (w *currentWorker) takeWorkflowJob(ctx context.Context, job sdk.WorkflowNod
 	log.Error("takeWorkflowJob> Could not send built result 10 times, giving up. job: %d", job.ID)
 	return false, lasterr
 }
+
+// watchWorkflowJobStop waits for the stop signal of a job sent by the API through grpc, and cancels the build
+func (w *currentWorker) watchWorkflowJobStop(ctx context.Context, cancel context.CancelFunc, jobID int64) {
+	stream, err := grpc.NewWorkflowQueueClient(w.grpc.conn).WatchJobStop(ctx, &grpc.JobStopRequest{JobID: jobID})
+	if err != nil {
+		log.Warning("watchWorkflowJobStop> Unable to watch the stop of job %d: %v", jobID, err)
+		return
+	}
+	stop, err := stream.Recv()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warning("watchWorkflowJobStop> Unable to receive the stop of job %d: %v", jobID, err)
+		}
+		return
+	}
+	log.Info("watchWorkflowJobStop> Job %d has been stopped by %s (%s) - Cancelling context", jobID, stop.StoppedBy, stop.Reason)
+	cancel()
+}
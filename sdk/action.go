@@ -3,23 +3,45 @@ package sdk
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // Action is the base element of CDS pipeline
 type Action struct {
-	ID             int64         `json:"id" yaml:"-"`
-	Name           string        `json:"name" cli:"name"`
-	Type           string        `json:"type" yaml:"-" cli:"type"`
-	Description    string        `json:"description" yaml:"desc,omitempty"`
-	Requirements   []Requirement `json:"requirements"`
-	Parameters     []Parameter   `json:"parameters"`
-	Actions        []Action      `json:"actions" yaml:"actions,omitempty"`
-	Enabled        bool          `json:"enabled" yaml:"-"`
-	Deprecated     bool          `json:"deprecated" yaml:"-"`
-	Optional       bool          `json:"optional" yaml:"-"`
-	AlwaysExecuted bool          `json:"always_executed" yaml:"-"`
-	LastModified   int64         `json:"last_modified" cli:"modified"`
+	ID              int64                   `json:"id" yaml:"-"`
+	Name            string                  `json:"name" cli:"name"`
+	StepName        string                  `json:"step_name,omitempty" yaml:"-" cli:"-"`
+	Type            string                  `json:"type" yaml:"-" cli:"type"`
+	Description     string                  `json:"description" yaml:"desc,omitempty"`
+	Requirements    []Requirement           `json:"requirements"`
+	Parameters      []Parameter             `json:"parameters"`
+	Outputs         []ActionOutput          `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Actions         []Action                `json:"actions" yaml:"actions,omitempty"`
+	Enabled         bool                    `json:"enabled" yaml:"-"`
+	Deprecated      bool                    `json:"deprecated" yaml:"-"`
+	Optional        bool                    `json:"optional" yaml:"-"`
+	AlwaysExecuted  bool                    `json:"always_executed" yaml:"-"`
+	OnFailure       bool                    `json:"on_failure" yaml:"-"`
+	ContinueOnError bool                    `json:"continue_on_error" yaml:"-"`
+	Conditions      []WorkflowNodeCondition `json:"conditions,omitempty" yaml:"-"`
+	LastModified    int64                   `json:"last_modified" cli:"modified"`
+}
+
+// ParseActionName returns the name and the version of an action used as "name" or "name@version"
+func ParseActionName(name string) (string, string) {
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// ActionOutput is a value computed by the steps of an action, exported at the end of the action
+// as the build variable cds.build.<name>
+type ActionOutput struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
 }
 
 // ActionAudit Audit on action
@@ -37,6 +59,7 @@ type ActionPlugin struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Author      string `json:"author"`
+	Version     string `json:"version"`
 	Filename    string `json:"filename"`
 	Path        string `json:"path"`
 
@@ -56,6 +79,38 @@ func (a *ActionPlugin) GetPath() string {
 	return fmt.Sprintf("plugins")
 }
 
+// DefaultPluginVersion is the version of the plugins uploaded without version
+const DefaultPluginVersion = "snapshot"
+
+// PluginBinary is a binary of an action plugin, for a version, an OS and an architecture
+type PluginBinary struct {
+	ID         int64     `json:"id" cli:"-"`
+	PluginName string    `json:"plugin_name" cli:"plugin"`
+	Version    string    `json:"version" cli:"version"`
+	OS         string    `json:"os" cli:"os"`
+	Arch       string    `json:"arch" cli:"arch"`
+	Size       int64     `json:"size,omitempty" cli:"size"`
+	Perm       uint32    `json:"perm,omitempty" cli:"-"`
+	SHA256Sum  string    `json:"sha256sum" cli:"sha256sum"`
+	ObjectPath string    `json:"object_path,omitempty" cli:"-"`
+	ObjectName string    `json:"object_name,omitempty" cli:"-"`
+	Created    time.Time `json:"created" cli:"created"`
+}
+
+//GetName returns the name of the binary in the storage. The binaries are stored under a name unique for each
+//content, the binaries stored before have a name unique for each version, OS and architecture
+func (b *PluginBinary) GetName() string {
+	if b.ObjectName != "" {
+		return b.ObjectName
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", b.PluginName, b.Version, b.OS, b.Arch)
+}
+
+//GetPath returns the storage path of the plugin binary
+func (b *PluginBinary) GetPath() string {
+	return fmt.Sprintf("plugins")
+}
+
 // Action type
 const (
 	DefaultAction = "Default"
@@ -72,6 +127,7 @@ const (
 	GitTagAction        = "GitTag"
 	ReleaseAction       = "Release"
 	CheckoutApplication = "CheckoutApplication"
+	DockerBuildAction   = "DockerBuild"
 )
 
 // NewAction instanciate a new Action
@@ -28,6 +28,7 @@ type ActionScript struct {
 		JUnitReport      string                       `json:"jUnitReport,omitempty"`
 		Plugin           map[string]map[string]string `json:"plugin,omitempty"`
 		Release          map[string]string            `json:"release,omitempty"`
+		DockerBuild      map[string]string            `json:"dockerBuild,omitempty"`
 	} `json:"steps"`
 }
 
@@ -93,6 +94,16 @@ func NewStepRelease(v map[string]string) Action {
 	return newAction
 }
 
+// NewStepDockerBuild returns an action (basically used as a step of a job) of DockerBuild type
+func NewStepDockerBuild(v map[string]string) Action {
+	newAction := Action{
+		Name:       DockerBuildAction,
+		Type:       BuiltinAction,
+		Parameters: ParametersFromMap(v),
+	}
+	return newAction
+}
+
 // NewStepArtifactUpload returns an action (basically used as a step of a job) of artifact upload type
 func NewStepArtifactUpload(v map[string]string) Action {
 	newAction := Action{
@@ -224,6 +235,12 @@ func NewActionFromScript(btes []byte) (*Action, error) {
 			newAction = NewStepRelease(v.Release)
 		}
 
+		// Action builtin = DockerBuild
+		if v.DockerBuild != nil {
+			newAction = NewStepDockerBuild(v.DockerBuild)
+			goto next
+		}
+
 		//Action builtin = Plugin
 		if v.Plugin != nil {
 			a, err := NewStepPlugin(v.Plugin)
@@ -59,7 +59,9 @@ type BuildState struct {
 	Stages   []Stage `json:"stages"`
 	Logs     []Log   `json:"logs"`
 	StepLogs Log     `json:"step_logs"`
-	Status   Status  `json:"status"`
+	// StepLogsSize is the size in bytes of the whole logs of the step, StepLogs may contain only a part of them
+	StepLogsSize int64  `json:"step_logs_size,omitempty"`
+	Status       Status `json:"status"`
 }
 
 // Status reprensents a Build Action or Build Pipeline Status
@@ -84,6 +86,8 @@ func StatusFromString(in string) Status {
 		return StatusDisabled
 	case StatusSkipped.String():
 		return StatusSkipped
+	case StatusWarning.String():
+		return StatusWarning
 	default:
 		return StatusUnknown
 	}
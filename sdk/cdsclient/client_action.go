@@ -1,6 +1,11 @@
 package cdsclient
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/ovh/cds/sdk"
 )
 
@@ -32,3 +37,27 @@ func (c *client) ActionList() ([]sdk.Action, error) {
 	}
 	return actions, nil
 }
+
+// ActionImport imports an action described as code (see exportentities.Action), in yaml or json
+func (c *client) ActionImport(content io.Reader) (*sdk.Action, error) {
+	mods := []RequestModifier{
+		func(r *http.Request) {
+			// json is a subset of yaml
+			r.Header.Set("Content-Type", "application/x-yaml")
+		},
+	}
+
+	btes, _, code, err := c.Request("POST", "/action/import", content, mods...)
+	if err != nil {
+		return nil, err
+	}
+	if code >= 400 {
+		return nil, fmt.Errorf("HTTP Code %d", code)
+	}
+
+	a := &sdk.Action{}
+	if err := json.Unmarshal(btes, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
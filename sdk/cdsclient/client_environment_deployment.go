@@ -0,0 +1,35 @@
+package cdsclient
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) EnvironmentDeployments(projectKey string, envName string) ([]sdk.EnvironmentDeployment, error) {
+	deployments := []sdk.EnvironmentDeployment{}
+	if _, err := c.GetJSON("/project/"+projectKey+"/environment/"+url.QueryEscape(envName)+"/deployments", &deployments); err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+func (c *client) EnvironmentDeploymentsHistory(projectKey string, envName string, appName string, offset, limit int64) ([]sdk.EnvironmentDeployment, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit == 0 {
+		limit = 20
+	}
+
+	path := fmt.Sprintf("/project/%s/environment/%s/deployments/history?offset=%d&limit=%d", projectKey, url.QueryEscape(envName), offset, limit)
+	if appName != "" {
+		path += "&application=" + url.QueryEscape(appName)
+	}
+	deployments := []sdk.EnvironmentDeployment{}
+	if _, err := c.GetJSON(path, &deployments); err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/exportentities"
 )
 
@@ -181,3 +182,33 @@ func (c *client) WorkflowPush(projectKey string, tarContent io.Reader) ([]string
 
 	return messages, nil
 }
+
+func (c *client) WorkflowLintFile(projectKey string, content io.Reader, format string) ([]sdk.Warning, error) {
+	url := fmt.Sprintf("/project/%s/lint/workflows", projectKey)
+
+	var contentType string
+	switch format {
+	case "json":
+		contentType = "application/json"
+	case "yaml", "yml":
+		contentType = "application/x-yaml"
+	default:
+		return nil, exportentities.ErrUnsupportedFormat
+	}
+	mods := []RequestModifier{
+		func(r *http.Request) {
+			r.Header.Set("Content-Type", contentType)
+		},
+	}
+
+	btes, _, _, err := c.Request("POST", url, content, mods...)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := []sdk.Warning{}
+	if err := json.Unmarshal(btes, &warnings); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
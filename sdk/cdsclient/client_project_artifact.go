@@ -0,0 +1,23 @@
+package cdsclient
+
+import (
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) ProjectArtifactSettings(projectKey string) (*sdk.ProjectArtifactSettings, error) {
+	s := &sdk.ProjectArtifactSettings{}
+	if _, err := c.GetJSON("/project/"+projectKey+"/artifact/settings", s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *client) ProjectArtifactSettingsSet(projectKey string, s sdk.ProjectArtifactSettings) error {
+	_, err := c.PutJSON("/project/"+projectKey+"/artifact/settings", s, nil)
+	return err
+}
+
+func (c *client) ProjectArtifactSettingsDelete(projectKey string) error {
+	_, err := c.DeleteJSON("/project/"+projectKey+"/artifact/settings", nil)
+	return err
+}
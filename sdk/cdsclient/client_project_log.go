@@ -0,0 +1,23 @@
+package cdsclient
+
+import (
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) ProjectLogSettings(projectKey string) (*sdk.ProjectLogSettings, error) {
+	s := &sdk.ProjectLogSettings{}
+	if _, err := c.GetJSON("/project/"+projectKey+"/log/settings", s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *client) ProjectLogSettingsSet(projectKey string, s sdk.ProjectLogSettings) error {
+	_, err := c.PutJSON("/project/"+projectKey+"/log/settings", s, nil)
+	return err
+}
+
+func (c *client) ProjectLogSettingsDelete(projectKey string) error {
+	_, err := c.DeleteJSON("/project/"+projectKey+"/log/settings", nil)
+	return err
+}
@@ -0,0 +1,19 @@
+package cdsclient
+
+import (
+	"fmt"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) ProjectMigrate(projectKey string, dryRun, force bool) (*sdk.ProjectMigrationReport, error) {
+	r := &sdk.ProjectMigrationReport{}
+	mods := []RequestModifier{
+		WithQueryParameter("dryRun", fmt.Sprintf("%t", dryRun)),
+		WithQueryParameter("force", fmt.Sprintf("%t", force)),
+	}
+	if _, err := c.PostJSON("/project/"+projectKey+"/migrate", nil, r, mods...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
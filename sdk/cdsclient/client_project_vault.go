@@ -0,0 +1,23 @@
+package cdsclient
+
+import (
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) ProjectVaultGet(projectKey string) (*sdk.ProjectVault, error) {
+	v := &sdk.ProjectVault{}
+	if _, err := c.GetJSON("/project/"+projectKey+"/vault", v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (c *client) ProjectVaultSet(projectKey string, v sdk.ProjectVault) error {
+	_, err := c.PutJSON("/project/"+projectKey+"/vault", v, nil)
+	return err
+}
+
+func (c *client) ProjectVaultDelete(projectKey string) error {
+	_, err := c.DeleteJSON("/project/"+projectKey+"/vault", nil)
+	return err
+}
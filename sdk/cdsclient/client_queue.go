@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -189,19 +191,19 @@ func (c *client) QueueSendResult(id int64, res sdk.Result) error {
 	return err
 }
 
-func (c *client) QueueArtifactUpload(id int64, tag, filePath string) (bool, time.Duration, error) {
+func (c *client) QueueArtifactUpload(id int64, tag, filePath string, metadata map[string]string) (bool, time.Duration, error) {
 	t0 := time.Now()
 	store := new(sdk.ArtifactsStore)
 	_, _ = c.GetJSON("/artifact/store", store)
 	if store.TemporaryURLSupported {
-		err := c.queueIndirectArtifactUpload(id, tag, filePath)
+		err := c.queueIndirectArtifactUpload(id, tag, filePath, metadata)
 		return true, time.Since(t0), err
 	}
-	err := c.queueDirectArtifactUpload(id, tag, filePath)
+	err := c.queueDirectArtifactUpload(id, tag, filePath, metadata)
 	return false, time.Since(t0), err
 }
 
-func (c *client) queueIndirectArtifactUpload(id int64, tag, filePath string) error {
+func (c *client) queueIndirectArtifactUpload(id int64, tag, filePath string, metadata map[string]string) error {
 	f, errop := os.Open(filePath)
 	if errop != nil {
 		return errop
@@ -225,15 +227,19 @@ func (c *client) queueIndirectArtifactUpload(id int64, tag, filePath string) err
 	}
 	hashInBytes := hash.Sum(nil)[:16]
 	md5sumStr := hex.EncodeToString(hashInBytes)
+	sha256sum := sha256.Sum256(fileContent)
+	sha256sumStr := hex.EncodeToString(sha256sum[:])
 	_, name := filepath.Split(filePath)
 
 	art := sdk.WorkflowNodeRunArtifact{
-		Name:    name,
-		Tag:     tag,
-		Size:    stat.Size(),
-		Perm:    uint32(stat.Mode().Perm()),
-		MD5sum:  md5sumStr,
-		Created: time.Now(),
+		Name:      name,
+		Tag:       tag,
+		Size:      stat.Size(),
+		Perm:      uint32(stat.Mode().Perm()),
+		MD5sum:    md5sumStr,
+		SHA256sum: sha256sumStr,
+		Metadata:  metadata,
+		Created:   time.Now(),
 	}
 
 	uri := fmt.Sprintf("/queue/workflows/%d/artifact/%s/url", id, tag)
@@ -294,7 +300,7 @@ func (c *client) queueIndirectArtifactUpload(id int64, tag, filePath string) err
 	return callbackErr
 }
 
-func (c *client) queueDirectArtifactUpload(id int64, tag, filePath string) error {
+func (c *client) queueDirectArtifactUpload(id int64, tag, filePath string, metadata map[string]string) error {
 	f, errop := os.Open(filePath)
 	if errop != nil {
 		return errop
@@ -318,6 +324,8 @@ func (c *client) queueDirectArtifactUpload(id int64, tag, filePath string) error
 	}
 	hashInBytes := hash.Sum(nil)[:16]
 	md5sumStr := hex.EncodeToString(hashInBytes)
+	sha256sum := sha256.Sum256(fileContent)
+	sha256sumStr := hex.EncodeToString(sha256sum[:])
 	_, name := filepath.Split(filePath)
 
 	body := &bytes.Buffer{}
@@ -334,6 +342,14 @@ func (c *client) queueDirectArtifactUpload(id int64, tag, filePath string) error
 	writer.WriteField("size", strconv.FormatInt(stat.Size(), 10))
 	writer.WriteField("perm", strconv.FormatUint(uint64(stat.Mode().Perm()), 10))
 	writer.WriteField("md5sum", md5sumStr)
+	writer.WriteField("sha256sum", sha256sumStr)
+	if len(metadata) > 0 {
+		btes, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		writer.WriteField("metadata", string(btes))
+	}
 
 	if errclose := writer.Close(); errclose != nil {
 		return errclose
@@ -355,6 +371,18 @@ func (c *client) queueDirectArtifactUpload(id int64, tag, filePath string) error
 	return fmt.Errorf("x%d: %v", c.config.Retry, err)
 }
 
+func (c *client) QueueJobStepOutput(jobID int64, o sdk.StepOutput) error {
+	path := fmt.Sprintf("/queue/workflows/%d/output", jobID)
+	_, err := c.PostJSON(path, o, nil)
+	return err
+}
+
+func (c *client) QueueArtifactReference(id int64, tag string, art sdk.WorkflowNodeRunArtifact) error {
+	path := fmt.Sprintf("/queue/workflows/%d/artifact/%s/reference", id, tag)
+	_, err := c.PostJSON(path, art, nil)
+	return err
+}
+
 func (c *client) QueueJobTag(jobID int64, tags []sdk.WorkflowRunTag) error {
 	path := fmt.Sprintf("/queue/workflows/%d/tag", jobID)
 	_, err := c.PostJSON(path, tags, nil)
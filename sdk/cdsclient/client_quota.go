@@ -0,0 +1,49 @@
+package cdsclient
+
+import (
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) QuotaList() ([]sdk.QuotaUsage, error) {
+	usages := []sdk.QuotaUsage{}
+	if _, err := c.GetJSON("/admin/quota", &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+func (c *client) ProjectQuota(projectKey string) ([]sdk.QuotaUsage, error) {
+	usages := []sdk.QuotaUsage{}
+	if _, err := c.GetJSON("/project/"+projectKey+"/quota", &usages); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+func (c *client) ProjectQuotaSet(projectKey string, q sdk.Quota) error {
+	_, err := c.PutJSON("/project/"+projectKey+"/quota", q, nil)
+	return err
+}
+
+func (c *client) ProjectQuotaDelete(projectKey string) error {
+	_, err := c.DeleteJSON("/project/"+projectKey+"/quota", nil)
+	return err
+}
+
+func (c *client) GroupQuota(groupName string) (*sdk.QuotaUsage, error) {
+	usage := &sdk.QuotaUsage{}
+	if _, err := c.GetJSON("/group/"+groupName+"/quota", usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (c *client) GroupQuotaSet(groupName string, q sdk.Quota) error {
+	_, err := c.PutJSON("/group/"+groupName+"/quota", q, nil)
+	return err
+}
+
+func (c *client) GroupQuotaDelete(groupName string) error {
+	_, err := c.DeleteJSON("/group/"+groupName+"/quota", nil)
+	return err
+}
@@ -116,10 +116,12 @@ func (c *client) WorkflowRunNumberSet(projectKey string, workflowName string, nu
 	return nil
 }
 
-func (c *client) WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int) (*sdk.BuildState, error) {
+// WorkflowNodeRunJobStep returns the status and the logs of a step. A part of the logs is returned with the
+// query parameters offset and limit (in bytes), or tail (in lines), see WithQueryParameter
+func (c *client) WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int, mods ...RequestModifier) (*sdk.BuildState, error) {
 	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/job/%d/step/%d", projectKey, workflowName, number, nodeRunID, job, step)
 	buildState := sdk.BuildState{}
-	if _, err := c.GetJSON(url, &buildState); err != nil {
+	if _, err := c.GetJSON(url, &buildState, mods...); err != nil {
 		return nil, err
 	}
 	return &buildState, nil
@@ -208,11 +210,11 @@ func (c *client) WorkflowRunFromManual(projectKey string, workflowName string, m
 	return run, nil
 }
 
-func (c *client) WorkflowStop(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error) {
+func (c *client) WorkflowStop(projectKey string, workflowName string, number int64, reason string) (*sdk.WorkflowRun, error) {
 	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/stop", projectKey, workflowName, number)
 
 	run := &sdk.WorkflowRun{}
-	code, err := c.PostJSON(url, nil, run)
+	code, err := c.PostJSON(url, nil, run, WithQueryParameter("reason", reason))
 	if err != nil {
 		return nil, err
 	}
@@ -223,11 +225,11 @@ func (c *client) WorkflowStop(projectKey string, workflowName string, number int
 	return run, nil
 }
 
-func (c *client) WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64) (*sdk.WorkflowNodeRun, error) {
+func (c *client) WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64, reason string) (*sdk.WorkflowNodeRun, error) {
 	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/stop", projectKey, workflowName, number, fromNodeID)
 
 	nodeRun := &sdk.WorkflowNodeRun{}
-	code, err := c.PostJSON(url, nil, nodeRun)
+	code, err := c.PostJSON(url, nil, nodeRun, WithQueryParameter("reason", reason))
 	if err != nil {
 		return nil, err
 	}
@@ -237,3 +239,53 @@ func (c *client) WorkflowNodeStop(projectKey string, workflowName string, number
 
 	return nodeRun, nil
 }
+
+func (c *client) WorkflowRunApprovals(projectKey string, workflowName string, number int64) ([]sdk.WorkflowNodeApproval, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/approvals", projectKey, workflowName, number)
+	approvals := []sdk.WorkflowNodeApproval{}
+	if _, err := c.GetJSON(url, &approvals); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+func (c *client) WorkflowNodeApprove(projectKey string, workflowName string, number, nodeID int64, comment string) (*sdk.WorkflowNodeApproval, error) {
+	return c.workflowNodeDecide(projectKey, workflowName, number, nodeID, "approve", comment)
+}
+
+func (c *client) WorkflowNodeReject(projectKey string, workflowName string, number, nodeID int64, comment string) (*sdk.WorkflowNodeApproval, error) {
+	return c.workflowNodeDecide(projectKey, workflowName, number, nodeID, "reject", comment)
+}
+
+func (c *client) WorkflowConcurrencyGroup(group string) ([]sdk.WorkflowNodeRunConcurrency, error) {
+	path := fmt.Sprintf("/workflow/concurrency/%s", url.QueryEscape(group))
+	runs := []sdk.WorkflowNodeRunConcurrency{}
+	if _, err := c.GetJSON(path, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (c *client) WorkflowLint(projectKey string, workflowName string) ([]sdk.Warning, error) {
+	path := fmt.Sprintf("/project/%s/workflows/%s/lint", projectKey, workflowName)
+	warnings := []sdk.Warning{}
+	if _, err := c.GetJSON(path, &warnings); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+func (c *client) workflowNodeDecide(projectKey string, workflowName string, number, nodeID int64, decision, comment string) (*sdk.WorkflowNodeApproval, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/%s", projectKey, workflowName, number, nodeID, decision)
+
+	approval := &sdk.WorkflowNodeApproval{}
+	code, err := c.PostJSON(url, sdk.WorkflowNodeApprovalDecision{Comment: comment}, approval)
+	if err != nil {
+		return nil, err
+	}
+	if code >= 300 {
+		return nil, fmt.Errorf("Cannot %s workflow node %d. HTTP code error: %d", decision, nodeID, code)
+	}
+
+	return approval, nil
+}
@@ -59,6 +59,15 @@ func SetHeader(key, value string) RequestModifier {
 	}
 }
 
+// WithQueryParameter adds a query parameter to the url of http.Request
+func WithQueryParameter(name, value string) RequestModifier {
+	return func(req *http.Request) {
+		q := req.URL.Query()
+		q.Set(name, value)
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
 // PostJSON post the *in* struct as json. If set, it unmarshalls the response to *out*
 func (c *client) PostJSON(path string, in interface{}, out interface{}, mods ...RequestModifier) (int, error) {
 	_, _, code, err := c.RequestJSON(http.MethodPost, path, in, out, mods...)
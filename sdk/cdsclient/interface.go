@@ -24,6 +24,7 @@ type ExportImportInterface interface {
 	WorkflowExport(projectKey, name string, exportWithPermissions bool, exportFormat string) ([]byte, error)
 	WorkflowPull(projectKey, name string, exportWithPermissions bool) (*tar.Reader, error)
 	WorkflowImport(projectKey string, content io.Reader, format string, force bool) ([]string, error)
+	WorkflowLintFile(projectKey string, content io.Reader, format string) ([]sdk.Warning, error)
 	WorkflowPush(projectKey string, tarContent io.Reader) ([]string, error)
 }
 
@@ -64,6 +65,8 @@ type EnvironmentClient interface {
 	EnvironmentExport(projectKey, name string, exportWithPermissions bool, format string) ([]byte, error)
 	EnvironmentImport(projectKey string, content io.Reader, format string, force bool) ([]string, error)
 	EnvironmentGroupsImport(projectKey, envName string, content io.Reader, format string, force bool) (sdk.Environment, error)
+	EnvironmentDeployments(projectKey string, envName string) ([]sdk.EnvironmentDeployment, error)
+	EnvironmentDeploymentsHistory(projectKey string, envName string, appName string, offset, limit int64) ([]sdk.EnvironmentDeployment, error)
 	EnvironmentVariableClient
 	EnvironmentKeysClient
 }
@@ -95,6 +98,7 @@ type DownloadClient interface {
 type ActionClient interface {
 	ActionDelete(actionName string) error
 	ActionGet(actionName string, mods ...RequestModifier) (*sdk.Action, error)
+	ActionImport(content io.Reader) (*sdk.Action, error)
 	ActionList() ([]sdk.Action, error)
 }
 
@@ -135,6 +139,14 @@ type ProjectClient interface {
 	ProjectList(withApplications, withWorkflow bool, filters ...Filter) ([]sdk.Project, error)
 	ProjectKeysClient
 	ProjectVariablesClient
+	ProjectVaultClient
+	ProjectLogSettings(projectKey string) (*sdk.ProjectLogSettings, error)
+	ProjectLogSettingsSet(projectKey string, s sdk.ProjectLogSettings) error
+	ProjectLogSettingsDelete(projectKey string) error
+	ProjectArtifactSettings(projectKey string) (*sdk.ProjectArtifactSettings, error)
+	ProjectArtifactSettingsSet(projectKey string, s sdk.ProjectArtifactSettings) error
+	ProjectArtifactSettingsDelete(projectKey string) error
+	ProjectMigrate(projectKey string, dryRun, force bool) (*sdk.ProjectMigrationReport, error)
 	ProjectGroupsImport(projectKey string, content io.Reader, format string, force bool) (sdk.Project, error)
 }
 
@@ -145,6 +157,13 @@ type ProjectKeysClient interface {
 	ProjectKeysDelete(projectKey string, keyProjectName string) error
 }
 
+// ProjectVaultClient exposes project vault related functions
+type ProjectVaultClient interface {
+	ProjectVaultGet(projectKey string) (*sdk.ProjectVault, error)
+	ProjectVaultSet(projectKey string, v sdk.ProjectVault) error
+	ProjectVaultDelete(projectKey string) error
+}
+
 // ProjectVariablesClient exposes project variables related functions
 type ProjectVariablesClient interface {
 	ProjectVariablesList(key string) ([]sdk.Variable, error)
@@ -166,8 +185,10 @@ type QueueClient interface {
 	QueueJobInfo(id int64) (*sdk.WorkflowNodeJobRun, error)
 	QueueJobSendSpawnInfo(isWorkflowJob bool, id int64, in []sdk.SpawnInfo) error
 	QueueSendResult(int64, sdk.Result) error
-	QueueArtifactUpload(id int64, tag, filePath string) (bool, time.Duration, error)
+	QueueArtifactUpload(id int64, tag, filePath string, metadata map[string]string) (bool, time.Duration, error)
+	QueueArtifactReference(id int64, tag string, art sdk.WorkflowNodeRunArtifact) error
 	QueueJobTag(jobID int64, tags []sdk.WorkflowRunTag) error
+	QueueJobStepOutput(jobID int64, o sdk.StepOutput) error
 }
 
 // UserClient exposes users functions
@@ -208,16 +229,32 @@ type WorkflowClient interface {
 	WorkflowRunFromManual(projectKey string, workflowName string, manual sdk.WorkflowNodeRunManual, number, fromNodeID int64) (*sdk.WorkflowRun, error)
 	WorkflowRunNumberGet(projectKey string, workflowName string) (*sdk.WorkflowRunNumber, error)
 	WorkflowRunNumberSet(projectKey string, workflowName string, number int64) error
-	WorkflowStop(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error)
-	WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64) (*sdk.WorkflowNodeRun, error)
+	WorkflowStop(projectKey string, workflowName string, number int64, reason string) (*sdk.WorkflowRun, error)
+	WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64, reason string) (*sdk.WorkflowNodeRun, error)
+	WorkflowRunApprovals(projectKey string, workflowName string, number int64) ([]sdk.WorkflowNodeApproval, error)
+	WorkflowNodeApprove(projectKey string, workflowName string, number, nodeID int64, comment string) (*sdk.WorkflowNodeApproval, error)
+	WorkflowNodeReject(projectKey string, workflowName string, number, nodeID int64, comment string) (*sdk.WorkflowNodeApproval, error)
+	WorkflowConcurrencyGroup(group string) ([]sdk.WorkflowNodeRunConcurrency, error)
+	WorkflowLint(projectKey string, workflowName string) ([]sdk.Warning, error)
 	WorkflowNodeRun(projectKey string, name string, number int64, nodeRunID int64) (*sdk.WorkflowNodeRun, error)
 	WorkflowNodeRunArtifacts(projectKey string, name string, number int64, nodeRunID int64) ([]sdk.WorkflowNodeRunArtifact, error)
 	WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error
-	WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int) (*sdk.BuildState, error)
+	WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int, mods ...RequestModifier) (*sdk.BuildState, error)
 	WorkflowNodeRunRelease(projectKey string, workflowName string, runNumber int64, nodeRunID int64, release sdk.WorkflowNodeRunRelease) error
 	WorkflowAllHooksList() ([]sdk.WorkflowNodeHook, error)
 }
 
+// QuotaClient exposes quotas related functions
+type QuotaClient interface {
+	QuotaList() ([]sdk.QuotaUsage, error)
+	ProjectQuota(projectKey string) ([]sdk.QuotaUsage, error)
+	ProjectQuotaSet(projectKey string, q sdk.Quota) error
+	ProjectQuotaDelete(projectKey string) error
+	GroupQuota(groupName string) (*sdk.QuotaUsage, error)
+	GroupQuotaSet(groupName string, q sdk.Quota) error
+	GroupQuotaDelete(groupName string) error
+}
+
 // MonitoringClient exposes monitoring functions
 type MonitoringClient interface {
 	MonStatus() (*sdk.MonitoringStatus, error)
@@ -239,6 +276,7 @@ type Interface interface {
 	PipelineClient
 	ProjectClient
 	QueueClient
+	QuotaClient
 	Requirements() ([]sdk.Requirement, error)
 	ServiceRegister(sdk.Service) (string, error)
 	UserClient
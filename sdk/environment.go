@@ -288,3 +288,21 @@ func AddGroupInEnvironment(projectKey, envName, groupName string, permission int
 
 	return DecodeError(data)
 }
+
+// EnvironmentDeployment represents the deployment of an application version on an environment by a workflow node run
+type EnvironmentDeployment struct {
+	ID                int64     `json:"id" db:"id" cli:"-"`
+	EnvironmentID     int64     `json:"environment_id" db:"environment_id" cli:"-"`
+	ApplicationID     int64     `json:"application_id" db:"application_id" cli:"-"`
+	ApplicationName   string    `json:"application_name" db:"application_name" cli:"application"`
+	Version           string    `json:"version" db:"version" cli:"version"`
+	VCSBranch         string    `json:"vcs_branch" db:"vcs_branch" cli:"branch"`
+	VCSHash           string    `json:"vcs_hash" db:"vcs_hash" cli:"hash"`
+	WorkflowName      string    `json:"workflow_name" db:"workflow_name" cli:"workflow"`
+	WorkflowRunNumber int64     `json:"workflow_run_number" db:"workflow_run_number" cli:"run"`
+	WorkflowNodeName  string    `json:"workflow_node_name" db:"workflow_node_name" cli:"node"`
+	WorkflowNodeRunID int64     `json:"workflow_node_run_id" db:"workflow_node_run_id" cli:"-"`
+	Username          string    `json:"username" db:"username" cli:"by"`
+	Date              time.Time `json:"date" db:"date" cli:"date"`
+	URL               string    `json:"url,omitempty" db:"-" cli:"-"`
+}
@@ -135,6 +135,18 @@ var (
 	ErrDownloadDoesNotExist                  = Error{ID: 120, Status: http.StatusNotFound}
 	ErrTokenNotFound                         = Error{ID: 121, Status: http.StatusNotFound}
 	ErrWorkflowNotificationNodeRef           = Error{ID: 122, Status: http.StatusBadRequest}
+	ErrWorkflowRunParametersInvalid          = Error{ID: 123, Status: http.StatusBadRequest}
+	ErrWorkflowNodeApprovalClosed            = Error{ID: 124, Status: http.StatusBadRequest}
+	ErrWorkflowNodeApprovalNotFound          = Error{ID: 125, Status: http.StatusNotFound}
+	ErrQuotaExceeded                         = Error{ID: 126, Status: http.StatusTooManyRequests}
+	ErrQuotaNotFound                         = Error{ID: 127, Status: http.StatusNotFound}
+	ErrInvalidVaultReference                 = Error{ID: 128, Status: http.StatusBadRequest}
+	ErrProjectVaultNotFound                  = Error{ID: 129, Status: http.StatusNotFound}
+	ErrPluginNotFound                        = Error{ID: 130, Status: http.StatusNotFound}
+	ErrInvalidHookConfiguration              = Error{ID: 131, Status: http.StatusBadRequest}
+	ErrWorkflowNodeRunNotResumable           = Error{ID: 132, Status: http.StatusBadRequest}
+	ErrWorkflowNodeRunArtifactReference      = Error{ID: 133, Status: http.StatusBadRequest}
+	ErrWorkflowNodeRunArtifactChecksum       = Error{ID: 134, Status: http.StatusBadRequest}
 )
 
 var errorsAmericanEnglish = map[int]string{
@@ -258,6 +270,18 @@ var errorsAmericanEnglish = map[int]string{
 	ErrDownloadDoesNotExist.ID:                  "File does not exist",
 	ErrTokenNotFound.ID:                         "Token does not exist",
 	ErrWorkflowNotificationNodeRef.ID:           "An invalid workflow node reference has been found, if you want to delete a pipeline from your workflow check if this pipeline isn't referenced in your notifications list",
+	ErrWorkflowRunParametersInvalid.ID:          "Invalid workflow run parameters",
+	ErrWorkflowNodeApprovalClosed.ID:            "This approval request is closed",
+	ErrWorkflowNodeApprovalNotFound.ID:          "Approval request not found",
+	ErrQuotaExceeded.ID:                         "Quota exceeded",
+	ErrQuotaNotFound.ID:                         "Quota not found",
+	ErrInvalidVaultReference.ID:                 "Invalid vault reference, it must be formatted as path#field",
+	ErrProjectVaultNotFound.ID:                  "No vault configured on project",
+	ErrPluginNotFound.ID:                        "plugin binary not found",
+	ErrInvalidHookConfiguration.ID:              "invalid hook configuration",
+	ErrWorkflowNodeRunNotResumable.ID:           "pipeline cannot be resumed",
+	ErrWorkflowNodeRunArtifactReference.ID:      "artifact is a reference to an external resource and cannot be downloaded",
+	ErrWorkflowNodeRunArtifactChecksum.ID:       "artifact checksum does not match its content",
 }
 
 var errorsFrench = map[int]string{
@@ -381,6 +405,18 @@ var errorsFrench = map[int]string{
 	ErrDownloadDoesNotExist.ID:                  "Le fichier n'existe pas",
 	ErrTokenNotFound.ID:                         "Le token n'existe pas",
 	ErrWorkflowNotificationNodeRef.ID:           "Une référence de noeud de workflow est invalide dans vos notifications (si vous souhaitez supprimer un pipeline vérifiez qu'il ne soit plus référencé dans la liste de vos notifications)",
+	ErrWorkflowRunParametersInvalid.ID:          "Paramètres de lancement du workflow invalides",
+	ErrWorkflowNodeApprovalClosed.ID:            "Cette demande d'approbation est fermée",
+	ErrWorkflowNodeApprovalNotFound.ID:          "Demande d'approbation introuvable",
+	ErrQuotaExceeded.ID:                         "Quota dépassé",
+	ErrQuotaNotFound.ID:                         "Quota introuvable",
+	ErrInvalidVaultReference.ID:                 "Référence vault invalide, elle doit être de la forme chemin#champ",
+	ErrProjectVaultNotFound.ID:                  "Aucun vault configuré sur le projet",
+	ErrPluginNotFound.ID:                        "binaire du plugin introuvable",
+	ErrInvalidHookConfiguration.ID:              "configuration du hook invalide",
+	ErrWorkflowNodeRunNotResumable.ID:           "le pipeline ne peut pas être repris",
+	ErrWorkflowNodeRunArtifactReference.ID:      "l'artefact est une référence vers une ressource externe et ne peut pas être téléchargé",
+	ErrWorkflowNodeRunArtifactChecksum.ID:       "la somme de contrôle de l'artefact ne correspond pas à son contenu",
 }
 
 var errorsLanguages = []map[int]string{
@@ -3,17 +3,132 @@ package exportentities
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/ovh/cds/sdk"
 )
 
+var stepNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Action represents an exported sdk.Action made of steps. It is imported as a public action named
+// <name>@<version>, usable as a step by name@version, or by name for the latest imported version
+type Action struct {
+	Name         string                    `json:"name,omitempty" yaml:"name,omitempty"`
+	Version      string                    `json:"version,omitempty" yaml:"version,omitempty"`
+	Description  string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	Inputs       map[string]ParameterValue `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Outputs      map[string]ActionOutput   `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Requirements []Requirement             `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+	Steps        []Step                    `json:"steps,omitempty" yaml:"steps,omitempty"`
+}
+
+// ActionOutput represents an exported sdk.ActionOutput
+type ActionOutput struct {
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// NewAction creates an exportable action from a sdk.Action
+func NewAction(a sdk.Action) Action {
+	ea := Action{
+		Description:  a.Description,
+		Requirements: newRequirements(a.Requirements),
+		Steps:        newSteps(a),
+	}
+	ea.Name, ea.Version = sdk.ParseActionName(a.Name)
+
+	if len(a.Parameters) > 0 {
+		ea.Inputs = make(map[string]ParameterValue, len(a.Parameters))
+		for _, p := range a.Parameters {
+			ea.Inputs[p.Name] = ParameterValue{
+				Type:         p.Type,
+				DefaultValue: p.Value,
+				Description:  p.Description,
+			}
+		}
+	}
+	if len(a.Outputs) > 0 {
+		ea.Outputs = make(map[string]ActionOutput, len(a.Outputs))
+		for _, o := range a.Outputs {
+			ea.Outputs[o.Name] = ActionOutput{Value: o.Value, Description: o.Description}
+		}
+	}
+	return ea
+}
+
+// Action returns the sdk.Action, named <name>@<version> if the action is versioned
+func (ea Action) Action() (*sdk.Action, error) {
+	if ea.Name == "" {
+		return nil, sdk.NewError(sdk.ErrWrongRequest, fmt.Errorf("action name is mandatory"))
+	}
+	if strings.Contains(ea.Name, "@") || strings.Contains(ea.Version, "@") {
+		return nil, sdk.NewError(sdk.ErrWrongRequest, fmt.Errorf("invalid action %s@%s: name and version cannot contain @", ea.Name, ea.Version))
+	}
+
+	a := &sdk.Action{
+		Name:         ea.Name,
+		Type:         sdk.DefaultAction,
+		Description:  ea.Description,
+		Enabled:      true,
+		Requirements: computeJobRequirements(ea.Requirements),
+		Parameters:   []sdk.Parameter{},
+	}
+	if ea.Version != "" {
+		a.Name += "@" + ea.Version
+	}
+
+	for _, name := range sortedParameterNames(ea.Inputs) {
+		i := ea.Inputs[name]
+		t := i.Type
+		if t == "" {
+			t = sdk.StringParameter
+		}
+		a.Parameters = append(a.Parameters, sdk.Parameter{
+			Name:        name,
+			Type:        t,
+			Value:       i.DefaultValue,
+			Description: i.Description,
+		})
+	}
+
+	outputs := make([]string, 0, len(ea.Outputs))
+	for name := range ea.Outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+	for _, name := range outputs {
+		o := ea.Outputs[name]
+		a.Outputs = append(a.Outputs, sdk.ActionOutput{Name: name, Value: o.Value, Description: o.Description})
+	}
+
+	steps, err := computeSteps(ea.Steps)
+	if err != nil {
+		return nil, err
+	}
+	a.Actions = steps
+	return a, nil
+}
+
+func sortedParameterNames(params map[string]ParameterValue) []string {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func newSteps(a sdk.Action) []Step {
 	res := []Step{}
 	for i := range a.Actions {
 		act := &a.Actions[i]
 		s := Step{}
+		if act.StepName != "" {
+			s["name"] = act.StepName
+		}
 		if !act.Enabled {
 			s["enabled"] = act.Enabled
 		}
@@ -23,6 +138,15 @@ func newSteps(a sdk.Action) []Step {
 		if act.AlwaysExecuted {
 			s["always_executed"] = act.AlwaysExecuted
 		}
+		if act.OnFailure {
+			s["on_failure"] = act.OnFailure
+		}
+		if act.ContinueOnError {
+			s["continue_on_error"] = act.ContinueOnError
+		}
+		if len(act.Conditions) > 0 {
+			s["conditions"] = act.Conditions
+		}
 
 		switch act.Type {
 		case sdk.BuiltinAction:
@@ -61,6 +185,10 @@ func newSteps(a sdk.Action) []Step {
 				if tag != nil {
 					artifactUploadArgs["tag"] = tag.Value
 				}
+				metadata := sdk.ParameterFind(&act.Parameters, "metadata")
+				if metadata != nil && metadata.Value != "" {
+					artifactUploadArgs["metadata"] = metadata.Value
+				}
 				s["artifactUpload"] = artifactUploadArgs
 			case sdk.GitCloneAction:
 				gitCloneArgs := map[string]string{}
@@ -99,6 +227,14 @@ func newSteps(a sdk.Action) []Step {
 				if path != nil {
 					s["jUnitReport"] = path.Value
 				}
+			case sdk.DockerBuildAction:
+				dockerBuildArgs := map[string]string{}
+				for _, p := range act.Parameters {
+					if p.Value != "" {
+						dockerBuildArgs[p.Name] = p.Value
+					}
+				}
+				s[sdk.DockerBuildAction] = dockerBuildArgs
 			}
 		default:
 			args := map[string]string{}
@@ -353,6 +489,37 @@ func (s Step) AsArtifactDownload() (*sdk.Action, bool, error) {
 	return &a, true, nil
 }
 
+// Name returns the name of the step, its outputs are available as {{.cds.build.<name>.<output>}}
+func (s Step) Name() (string, error) {
+	bI, ok := s["name"]
+	if !ok {
+		return "", nil
+	}
+	name, ok := bI.(string)
+	if !ok || !stepNamePattern.MatchString(name) {
+		return "", fmt.Errorf("Malformatted Step : name must contain only letters, digits, - and _")
+	}
+	return name, nil
+}
+
+// Conditions returns the conditions of the step, checked against the job variables with the workflow conditions operators
+func (s Step) Conditions() ([]sdk.WorkflowNodeCondition, error) {
+	bI, ok := s["conditions"]
+	if !ok {
+		return nil, nil
+	}
+	var conditions []sdk.WorkflowNodeCondition
+	if err := mapstructure.Decode(bI, &conditions); err != nil {
+		return nil, fmt.Errorf("Malformatted Step : conditions must be a list of variable, operator and value")
+	}
+	for _, c := range conditions {
+		if _, ok := sdk.WorkflowConditionsOperators[c.Operator]; !ok {
+			return nil, fmt.Errorf("Malformatted Step : unknown condition operator %q", c.Operator)
+		}
+	}
+	return conditions, nil
+}
+
 // IsFlagged returns true the step has the flag set
 func (s Step) IsFlagged(flag string) (bool, error) {
 	bI, ok := s[flag]
@@ -0,0 +1,70 @@
+package exportentities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestActionAsCode(t *testing.T) {
+	content := `name: go-build
+version: 1.0.0
+description: Build a go program
+inputs:
+  package:
+    default: ./...
+    description: package to build
+  race:
+    type: boolean
+    default: "false"
+outputs:
+  binary:
+    value: "{{.cds.build.gobin}}"
+requirements:
+- binary: go
+steps:
+- script:
+  - go build -o bin {{.package}}
+  - worker export gobin bin
+- plugin-tmpl@1.2.0:
+    file: bin.tmpl
+`
+	var ea Action
+	if !assert.NoError(t, yaml.Unmarshal([]byte(content), &ea)) {
+		t.FailNow()
+	}
+
+	a, err := ea.Action()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "go-build@1.0.0", a.Name)
+	assert.Equal(t, sdk.DefaultAction, a.Type)
+	assert.Equal(t, []sdk.Parameter{
+		{Name: "package", Type: sdk.StringParameter, Value: "./...", Description: "package to build"},
+		{Name: "race", Type: sdk.BooleanParameter, Value: "false"},
+	}, a.Parameters)
+	assert.Equal(t, []sdk.ActionOutput{{Name: "binary", Value: "{{.cds.build.gobin}}"}}, a.Outputs)
+	assert.Equal(t, []sdk.Requirement{{Name: "go", Type: sdk.BinaryRequirement, Value: "go"}}, a.Requirements)
+	if assert.Len(t, a.Actions, 2) {
+		assert.Equal(t, sdk.ScriptAction, a.Actions[0].Name)
+		assert.Equal(t, "plugin-tmpl@1.2.0", a.Actions[1].Name)
+	}
+
+	// export gives back the same action
+	exported := NewAction(*a)
+	assert.Equal(t, ea.Name, exported.Name)
+	assert.Equal(t, ea.Version, exported.Version)
+	assert.Equal(t, ea.Inputs["race"], exported.Inputs["race"])
+	assert.Equal(t, ea.Outputs, exported.Outputs)
+}
+
+func TestActionAsCodeInvalidName(t *testing.T) {
+	_, err := Action{}.Action()
+	assert.Error(t, err)
+	_, err = Action{Name: "go@build"}.Action()
+	assert.Error(t, err)
+}
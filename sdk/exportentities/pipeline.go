@@ -58,11 +58,14 @@ type Job struct {
 // Step represents exported step used in a job
 type Step map[string]interface{}
 
+// stepAttributes are the keys of a step which are not its action
+var stepAttributes = []string{"enabled", "optional", "always_executed", "on_failure", "continue_on_error", "conditions", "name"}
+
 // IsValid returns true is the step is valid
 func (s Step) IsValid() bool {
 	keys := []string{}
 	for k := range s {
-		if k != "enabled" && k != "optional" && k != "always_executed" {
+		if !inArray(k, stepAttributes) {
 			keys = append(keys, k)
 		}
 	}
@@ -72,7 +75,7 @@ func (s Step) IsValid() bool {
 func (s Step) key() string {
 	keys := []string{}
 	for k := range s {
-		if k != "enabled" && k != "optional" && k != "always_executed" {
+		if !inArray(k, stepAttributes) {
 			keys = append(keys, k)
 		}
 	}
@@ -431,11 +434,37 @@ func (p *Pipeline) Pipeline() (*sdk.Pipeline, error) {
 
 func computeSteps(steps []Step) ([]sdk.Action, error) {
 	res := []sdk.Action{}
+	names := map[string]bool{}
 	for _, s := range steps {
 		a, err := computeStep(s)
 		if err != nil {
 			return nil, err
 		}
+		a.StepName, err = s.Name()
+		if err != nil {
+			return nil, err
+		}
+		a.OnFailure, err = s.IsFlagged("on_failure")
+		if err != nil {
+			return nil, err
+		}
+		if a.OnFailure && a.AlwaysExecuted {
+			return nil, fmt.Errorf("Malformatted Step : a step cannot be both on_failure and always_executed")
+		}
+		a.ContinueOnError, err = s.IsFlagged("continue_on_error")
+		if err != nil {
+			return nil, err
+		}
+		a.Conditions, err = s.Conditions()
+		if err != nil {
+			return nil, err
+		}
+		if a.StepName != "" {
+			if names[a.StepName] {
+				return nil, fmt.Errorf("Malformatted Step : name %s is used by several steps", a.StepName)
+			}
+			names[a.StepName] = true
+		}
 		res = append(res, *a)
 	}
 	return res, nil
@@ -506,7 +535,7 @@ func computeJobRequirements(req []Requirement) []sdk.Requirement {
 			val = r.Network
 			tpe = sdk.NetworkAccessRequirement
 		} else if r.Plugin != "" {
-			name = r.Plugin
+			name, _ = sdk.ParsePluginRequirement(r.Plugin)
 			val = r.Plugin
 			tpe = sdk.PluginRequirement
 		} else if r.Service.Name != "" {
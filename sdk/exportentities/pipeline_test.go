@@ -549,6 +549,45 @@ steps:
 	assert.Len(t, p.Stages[0].Jobs[0].Action.Actions[0].Parameters, 7)
 }
 
+func Test_ImportPipelineWithStepConditions(t *testing.T) {
+	in := `name: deploy
+steps:
+- script: ./deploy.sh
+  conditions:
+  - variable: git.branch
+    operator: eq
+    value: master
+- script: ./smoke-tests.sh
+  continue_on_error: true
+- script: ./rollback.sh
+  on_failure: true
+`
+
+	payload := &Pipeline{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	steps := p.Stages[0].Jobs[0].Action.Actions
+	if !assert.Len(t, steps, 3) {
+		t.FailNow()
+	}
+	assert.Equal(t, []sdk.WorkflowNodeCondition{{Variable: "git.branch", Operator: "eq", Value: "master"}}, steps[0].Conditions)
+	assert.True(t, steps[1].ContinueOnError)
+	assert.False(t, steps[1].OnFailure)
+	assert.True(t, steps[2].OnFailure)
+
+	exported := newSteps(p.Stages[0].Jobs[0].Action)
+	assert.Equal(t, steps[0].Conditions, exported[0]["conditions"])
+	assert.Equal(t, true, exported[1]["continue_on_error"])
+	assert.Equal(t, true, exported[2]["on_failure"])
+
+	payload.Steps[0]["conditions"] = []interface{}{map[interface{}]interface{}{"variable": "git.branch", "operator": "like", "value": "master"}}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
 func Test_IsFlagged(t *testing.T) {
 	testc := []struct {
 		flag     string
@@ -0,0 +1,489 @@
+package exportentities
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// ValidationError is an error found in a file, with its position when it is known
+type ValidationError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+}
+
+// Kinds of files which can be validated
+const (
+	KindWorkflow    = "workflow"
+	KindPipeline    = "pipeline"
+	KindApplication = "application"
+	KindEnvironment = "environment"
+	KindAction      = "action"
+)
+
+// KindFromFilename returns the kind of a file given its name, as written by workflow pull:
+// *.pip.yml for pipelines, *.app.yml for applications, *.env.yml for environments and *.yml for workflows.
+// Actions imported with cdsctl action import are named *.action.yml
+func KindFromFilename(filename string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(filename, ".yml"), ".yaml"), ".json")
+	switch {
+	case strings.HasSuffix(name, ".pip"):
+		return KindPipeline
+	case strings.HasSuffix(name, ".app"):
+		return KindApplication
+	case strings.HasSuffix(name, ".env"):
+		return KindEnvironment
+	case strings.HasSuffix(name, ".action"):
+		return KindAction
+	default:
+		return KindWorkflow
+	}
+}
+
+// Validate checks the structure of a workflow, pipeline, application or environment file without calling the API.
+// The kind of file is given by its name, see KindFromFilename. Errors are sorted by position
+func Validate(filename string, content []byte) []ValidationError {
+	v := &validator{file: filename, positions: newYAMLPositions(content)}
+	for _, d := range v.positions.duplicates {
+		v.errorAt(d.pos, "duplicated key %q", d.key)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		v.yamlError(err)
+		return v.sorted()
+	}
+
+	switch KindFromFilename(filename) {
+	case KindPipeline:
+		var version struct {
+			Version string `yaml:"version"`
+		}
+		yaml.Unmarshal(content, &version) // nolint
+		if version.Version == PipelineVersion1 {
+			var p PipelineV1
+			if v.decode(content, raw, &p) {
+				v.validatePipelineV1(p)
+			}
+		} else {
+			var p Pipeline
+			if v.decode(content, raw, &p) {
+				v.validatePipeline(p)
+			}
+		}
+	case KindApplication:
+		var a Application
+		if v.decode(content, raw, &a) {
+			v.validateVariables(a.Variables, "variables", sdk.AvailableVariableType)
+			v.validateVariables(a.Keys, "keys", keyTypes)
+		}
+	case KindEnvironment:
+		var e Environment
+		if v.decode(content, raw, &e) {
+			v.validateVariables(e.Values, "values", sdk.AvailableVariableType)
+			v.validateVariables(e.Keys, "keys", keyTypes)
+		}
+	case KindAction:
+		var a Action
+		if v.decode(content, raw, &a) {
+			v.validateAction(a)
+		}
+	default:
+		var w Workflow
+		if v.decode(content, raw, &w) {
+			v.validateWorkflow(w)
+		}
+	}
+	return v.sorted()
+}
+
+type validator struct {
+	file      string
+	positions *yamlPositions
+	errors    []ValidationError
+}
+
+func (v *validator) errorAt(pos yamlPosition, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{
+		File:    v.file,
+		Line:    pos.line,
+		Column:  pos.column,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) error(path []string, format string, args ...interface{}) {
+	v.errorAt(v.positions.get(path), format, args...)
+}
+
+func (v *validator) sorted() []ValidationError {
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		if v.errors[i].Line != v.errors[j].Line {
+			return v.errors[i].Line < v.errors[j].Line
+		}
+		return v.errors[i].Column < v.errors[j].Column
+	})
+	return v.errors
+}
+
+var yamlLineRegex = regexp.MustCompile(`line (\d+): (.*)`)
+
+// yamlError converts the errors returned by the yaml parser, which contain only the line
+func (v *validator) yamlError(err error) {
+	msgs := []string{err.Error()}
+	if e, ok := err.(*yaml.TypeError); ok {
+		msgs = e.Errors
+	}
+	for _, m := range msgs {
+		m = strings.TrimPrefix(m, "yaml: ")
+		if sub := yamlLineRegex.FindStringSubmatch(m); sub != nil {
+			line, _ := strconv.Atoi(sub[1])
+			v.errorAt(yamlPosition{line: line}, "%s", sub[2])
+			continue
+		}
+		v.errorAt(yamlPosition{}, "%s", m)
+	}
+}
+
+// decode unmarshals the content in out and checks there is no unknown key
+func (v *validator) decode(content []byte, raw interface{}, out interface{}) bool {
+	if err := yaml.Unmarshal(content, out); err != nil {
+		v.yamlError(err)
+		return false
+	}
+	v.checkKeys(raw, reflect.TypeOf(out), nil)
+	return true
+}
+
+// checkKeys checks all the keys of a decoded value are known by the type
+func (v *validator) checkKeys(value interface{}, t reflect.Type, path []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		fields := yamlFields(t)
+		keys := sortedKeys(m)
+		for _, k := range keys {
+			f, ok := fields[k]
+			if !ok {
+				v.error(append(path, k), "unknown key %q", k)
+				continue
+			}
+			v.checkKeys(m[k], f.Type, append(path, k))
+		}
+	case reflect.Map:
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return
+		}
+		for _, k := range sortedKeys(m) {
+			v.checkKeys(m[k], t.Elem(), append(path, k))
+		}
+	case reflect.Slice:
+		s, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i := range s {
+			v.checkKeys(s[i], t.Elem(), append(path, fmt.Sprintf("[%d]", i)))
+		}
+	}
+}
+
+func sortedKeys(m map[interface{}]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, fmt.Sprintf("%v", k))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// yamlFields returns the fields of a struct by their yaml key
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("yaml"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		if len(tag) > 1 && tag[1] == "inline" {
+			for k, sub := range yamlFields(f.Type) {
+				fields[k] = sub
+			}
+			continue
+		}
+		name := tag[0]
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+var keyTypes = []string{sdk.KeyTypeSSH, sdk.KeyTypePGP}
+
+var hookModelNames = []string{sdk.WebHookModelName, sdk.RepositoryWebHookModelName, sdk.SchedulerModelName, sdk.GitPollerModelName}
+
+func (v *validator) validateWorkflow(w Workflow) {
+	if err := w.checkValidity(); err != nil {
+		if errs, ok := err.(*sdk.MultiError); ok {
+			for _, e := range *errs {
+				v.error(nil, "%s", strings.TrimPrefix(e.Error(), "Error: "))
+			}
+		}
+	}
+
+	// Simple workflows have a single node, named by its pipeline, described at the root of the file
+	entries := w.Entries()
+	prefix := func(name string) []string {
+		if len(w.Workflow) == 0 {
+			return nil
+		}
+		return []string{"workflow", name}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		e := entries[name]
+		path := prefix(name)
+		for i, when := range e.When {
+			if when != "success" && when != "manual" {
+				v.error(append(path, "when", fmt.Sprintf("[%d]", i)), "unsupported when condition %q, it should be success or manual", when)
+			}
+		}
+		if e.Conditions != nil {
+			for i, c := range e.Conditions.PlainConditions {
+				if _, ok := sdk.WorkflowConditionsOperators[c.Operator]; !ok {
+					v.error(append(path, "conditions", "check", fmt.Sprintf("[%d]", i), "operator"), "unknown condition operator %q", c.Operator)
+				}
+			}
+		}
+		if len(w.Workflow) > 0 && e.PipelineName == "" {
+			v.error(path, "node %s has no pipeline", name)
+		}
+		for i, d := range e.DependsOn {
+			if _, ok := w.Workflow[d]; !ok {
+				v.error(append(path, "depends_on", fmt.Sprintf("[%d]", i)), "node %s depends on unknown node %s", name, d)
+			}
+		}
+	}
+
+	var roots int
+	for _, e := range entries {
+		if len(e.DependsOn) == 0 {
+			roots++
+		}
+	}
+	if len(w.Workflow) > 0 && roots != 1 {
+		v.error([]string{"workflow"}, "the workflow should have exactly one node without depends_on, found %d", roots)
+	}
+
+	if cycle := dependencyCycle(w.Workflow); len(cycle) > 0 {
+		v.error([]string{"workflow", cycle[0], "depends_on"}, "dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	hooks := map[string][]HookEntry{}
+	for name, h := range w.Hooks {
+		hooks[name] = h
+	}
+	if len(w.PipelineHooks) > 0 {
+		hooks[""] = w.PipelineHooks
+	}
+	hookNames := make([]string, 0, len(hooks))
+	for name := range hooks {
+		hookNames = append(hookNames, name)
+	}
+	sort.Strings(hookNames)
+	for _, name := range hookNames {
+		path := []string{"pipeline_hooks"}
+		if name != "" {
+			path = []string{"hooks", name}
+		}
+		for i, h := range hooks[name] {
+			if !inArray(h.Model, hookModelNames) {
+				v.error(append(path, fmt.Sprintf("[%d]", i), "type"), "unknown hook type %q, it should be one of %s", h.Model, strings.Join(hookModelNames, ", "))
+			}
+		}
+	}
+}
+
+// dependencyCycle returns the first cycle found in the depends_on of the nodes
+func dependencyCycle(entries map[string]NodeEntry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i := range stack {
+				if stack[i] == name {
+					return append(append([]string{}, stack[i:]...), name)
+				}
+			}
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, d := range entries[name].DependsOn {
+			if _, ok := entries[d]; !ok {
+				continue
+			}
+			if c := visit(d); c != nil {
+				return c
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if c := visit(name); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func (v *validator) validatePipeline(p Pipeline) {
+	v.validateParameters(p.Parameters, "parameters")
+	v.validateSteps(p.Steps, []string{"steps"})
+	for name, j := range p.Jobs {
+		v.validateSteps(j.Steps, []string{"jobs", name, "steps"})
+	}
+	for sname, s := range p.Stages {
+		for name, j := range s.Jobs {
+			v.validateSteps(j.Steps, []string{"stages", sname, "jobs", name, "steps"})
+		}
+	}
+}
+
+func (v *validator) validatePipelineV1(p PipelineV1) {
+	v.validateParameters(p.Parameters, "parameters")
+	for name := range p.StageOptions {
+		if !inArray(name, p.Stages) {
+			v.error([]string{"options", name}, "options on unknown stage %s", name)
+		}
+	}
+	for i, j := range p.Jobs {
+		path := []string{"jobs", fmt.Sprintf("[%d]", i)}
+		if j.Stage != "" && !inArray(j.Stage, p.Stages) {
+			v.error(append(path, "stage"), "job %s is in unknown stage %s", j.Name, j.Stage)
+		}
+		v.validateSteps(j.Steps, append(path, "steps"))
+	}
+}
+
+func (v *validator) validateAction(a Action) {
+	if a.Name == "" {
+		v.error(nil, "action name is mandatory")
+	}
+	if strings.Contains(a.Name, "@") {
+		v.error([]string{"name"}, "action name cannot contain @")
+	}
+	if strings.Contains(a.Version, "@") {
+		v.error([]string{"version"}, "action version cannot contain @")
+	}
+	v.validateParameters(a.Inputs, "inputs")
+	v.validateSteps(a.Steps, []string{"steps"})
+}
+
+func (v *validator) validateParameters(params map[string]ParameterValue, key string) {
+	for name, p := range params {
+		if p.Type != "" && !inArray(p.Type, sdk.AvailableParameterType) {
+			v.error([]string{key, name, "type"}, "unknown parameter type %q", p.Type)
+		}
+	}
+}
+
+func (v *validator) validateSteps(steps []Step, path []string) {
+	names := map[string]bool{}
+	for i, s := range steps {
+		stepPath := append(path[:len(path):len(path)], fmt.Sprintf("[%d]", i))
+		if !s.IsValid() {
+			v.error(stepPath, "a step should have exactly one action")
+		}
+		if _, err := s.Conditions(); err != nil {
+			v.error(append(stepPath, "conditions"), "%s", strings.TrimPrefix(err.Error(), "Malformatted Step : "))
+		}
+		onFailure, _ := s.IsFlagged("on_failure")
+		alwaysExecuted, _ := s.IsFlagged("always_executed")
+		if onFailure && alwaysExecuted {
+			v.error(append(stepPath, "on_failure"), "a step cannot be both on_failure and always_executed")
+		}
+		name, err := s.Name()
+		if err != nil {
+			v.error(append(stepPath, "name"), "invalid step name, it should contain only letters, digits, - and _")
+			continue
+		}
+		if name != "" && names[name] {
+			v.error(append(stepPath, "name"), "step name %s is used by several steps", name)
+		}
+		names[name] = true
+	}
+}
+
+func (v *validator) validateVariables(vars map[string]VariableValue, key string, types []string) {
+	for name, value := range vars {
+		if value.Type != "" && !inArray(value.Type, types) {
+			v.error([]string{key, name, "type"}, "unknown type %q, it should be one of %s", value.Type, strings.Join(types, ", "))
+		}
+	}
+}
+
+func inArray(s string, array []string) bool {
+	for _, a := range array {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package exportentities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindFromFilename(t *testing.T) {
+	assert.Equal(t, KindWorkflow, KindFromFilename("my-workflow.yml"))
+	assert.Equal(t, KindPipeline, KindFromFilename("dir/build.pip.yml"))
+	assert.Equal(t, KindApplication, KindFromFilename("my-app.app.yaml"))
+	assert.Equal(t, KindEnvironment, KindFromFilename("prod.env.yml"))
+	assert.Equal(t, KindAction, KindFromFilename("go-build.action.yml"))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "valid workflow",
+			filename: "w.yml",
+			content: `name: my-workflow
+version: v1.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    pipeline: deploy
+    when:
+    - success
+hooks:
+  build:
+  - type: Scheduler
+    config:
+      cron: "* * * * *"
+`,
+		},
+		{
+			name:     "unknown keys",
+			filename: "w.yml",
+			content: `name: my-workflow
+workflow:
+  build:
+    pipeline: build
+    aplication: my-app
+hooks:
+  build:
+  - type: WebHook
+    conf: {}
+`,
+			want: []string{
+				`w.yml:5:5: unknown key "aplication"`,
+				`w.yml:9:5: unknown key "conf"`,
+			},
+		},
+		{
+			name:     "duplicated node",
+			filename: "w.yml",
+			content: `name: my-workflow
+workflow:
+  build:
+    pipeline: build
+  build:
+    pipeline: other
+`,
+			want: []string{`w.yml:5:3: duplicated key "build"`},
+		},
+		{
+			name:     "bad when and condition operator",
+			filename: "w.yml",
+			content: `name: my-workflow
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    pipeline: deploy
+    when:
+    - always
+    conditions:
+      check:
+      - variable: cds.status
+        operator: equals
+        value: Success
+`,
+			want: []string{
+				`w.yml:10:5: unsupported when condition "always", it should be success or manual`,
+				`w.yml:14:9: unknown condition operator "equals"`,
+			},
+		},
+		{
+			name:     "dependency cycle",
+			filename: "w.yml",
+			content: `name: my-workflow
+workflow:
+  build:
+    pipeline: build
+  a:
+    depends_on: [b]
+    pipeline: a
+  b:
+    depends_on: [a]
+    pipeline: b
+  c:
+    depends_on: [unknown]
+    pipeline: c
+`,
+			want: []string{
+				`w.yml:6:5: dependency cycle: a -> b -> a`,
+				`w.yml:12:5: node c depends on unknown node unknown`,
+			},
+		},
+		{
+			name:     "unknown hook type",
+			filename: "w.yml",
+			content: `name: my-workflow
+pipeline: build
+pipeline_hooks:
+- type: Cron
+`,
+			want: []string{`w.yml:4:3: unknown hook type "Cron", it should be one of WebHook, RepositoryWebHook, Scheduler, Git Repository Poller`},
+		},
+		{
+			name:     "invalid pipeline",
+			filename: "build.pip.yml",
+			content: `version: v1.0
+name: build
+stages:
+- Build
+jobs:
+- job: compile
+  stage: Test
+  steps:
+  - script: |
+      echo foo: bar
+    gitClone:
+      branch: master
+`,
+			want: []string{
+				`build.pip.yml:7:3: job compile is in unknown stage Test`,
+				`build.pip.yml:9:3: a step should have exactly one action`,
+			},
+		},
+		{
+			name:     "invalid step names",
+			filename: "deploy.pip.yml",
+			content: `version: v1.0
+name: deploy
+jobs:
+- job: deploy
+  steps:
+  - name: deploy
+    script: ./deploy.sh
+  - name: deploy
+    script: ./check.sh
+  - name: check url
+    script: curl {{.cds.build.deploy.url}}
+`,
+			want: []string{
+				`deploy.pip.yml:8:5: step name deploy is used by several steps`,
+				`deploy.pip.yml:10:5: invalid step name, it should contain only letters, digits, - and _`,
+			},
+		},
+		{
+			name:     "invalid step conditions",
+			filename: "deploy.pip.yml",
+			content: `version: v1.0
+name: deploy
+jobs:
+- job: deploy
+  steps:
+  - script: ./deploy.sh
+    conditions:
+    - variable: git.branch
+      operator: like
+      value: master
+  - script: ./rollback.sh
+    on_failure: true
+    always_executed: true
+`,
+			want: []string{
+				`deploy.pip.yml:7:5: unknown condition operator "like"`,
+				`deploy.pip.yml:12:5: a step cannot be both on_failure and always_executed`,
+			},
+		},
+		{
+			name:     "invalid variable type",
+			filename: "my-app.app.yml",
+			content: `name: my-app
+variables:
+  foo:
+    type: strange
+    value: bar
+`,
+			want: []string{`my-app.app.yml:4:5: unknown type "strange", it should be one of password, text, string, key, boolean, number, vault`},
+		},
+		{
+			name:     "invalid action",
+			filename: "go-build.action.yml",
+			content: `name: go-build
+version: 1@0
+inputs:
+  package:
+    type: strange
+steps:
+- script: go build {{.package}}
+  jUnitReport: report.xml
+`,
+			want: []string{
+				`go-build.action.yml:2:1: action version cannot contain @`,
+				`go-build.action.yml:5:5: unknown parameter type "strange"`,
+				`go-build.action.yml:7:1: a step should have exactly one action`,
+			},
+		},
+		{
+			name:     "yaml syntax error",
+			filename: "w.yml",
+			content:  "name: [my-workflow\npipeline: build\n",
+			want:     []string{"w.yml:1: did not find expected ',' or ']'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range Validate(tt.filename, []byte(tt.content)) {
+				got = append(got, e.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -17,31 +17,36 @@ type Workflow struct {
 	Workflow map[string]NodeEntry   `json:"workflow,omitempty" yaml:"workflow,omitempty"`
 	Hooks    map[string][]HookEntry `json:"hooks,omitempty" yaml:"hooks,omitempty"`
 	// This will be filled for simple workflows
-	DependsOn       []string                    `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
-	Conditions      *sdk.WorkflowNodeConditions `json:"conditions,omitempty" yaml:"conditions,omitempty"`
-	When            []string                    `json:"when,omitempty" yaml:"when,omitempty"` //This is use only for manual and success condition
-	PipelineName    string                      `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
-	Payload         map[string]interface{}      `json:"payload,omitempty" yaml:"payload,omitempty"`
-	Parameters      map[string]string           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	ApplicationName string                      `json:"application,omitempty" yaml:"application,omitempty"`
-	EnvironmentName string                      `json:"environment,omitempty" yaml:"environment,omitempty"`
-	PipelineHooks   []HookEntry                 `json:"pipeline_hooks,omitempty" yaml:"pipeline_hooks,omitempty"`
-	Permissions     map[string]int              `json:"permissions,omitempty" yaml:"permissions,omitempty"`
-	Metadata        map[string]string           `json:"metadata,omitempty" yaml:"metadata,omitempty" db:"-"`
-	PurgeTags       []string                    `json:"purge_tags,omitempty" yaml:"purge_tags,omitempty" db:"-"`
+	DependsOn       []string                      `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Conditions      *sdk.WorkflowNodeConditions   `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	When            []string                      `json:"when,omitempty" yaml:"when,omitempty"` //This is use only for manual and success condition
+	PipelineName    string                        `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+	Payload         map[string]interface{}        `json:"payload,omitempty" yaml:"payload,omitempty"`
+	Parameters      map[string]string             `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	ApplicationName string                        `json:"application,omitempty" yaml:"application,omitempty"`
+	EnvironmentName string                        `json:"environment,omitempty" yaml:"environment,omitempty"`
+	PipelineHooks   []HookEntry                   `json:"pipeline_hooks,omitempty" yaml:"pipeline_hooks,omitempty"`
+	Permissions     map[string]int                `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	Metadata        map[string]string             `json:"metadata,omitempty" yaml:"metadata,omitempty" db:"-"`
+	PurgeTags       []string                      `json:"purge_tags,omitempty" yaml:"purge_tags,omitempty" db:"-"`
+	RunParameters   []sdk.WorkflowParameterSchema `json:"run_parameters,omitempty" yaml:"run_parameters,omitempty" db:"-"`
+	Approval        *sdk.WorkflowNodeApprovalRule `json:"approval,omitempty" yaml:"approval,omitempty"`
+	Concurrency     *sdk.WorkflowNodeConcurrency  `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
 }
 
 type NodeEntry struct {
-	ID              int64                       `json:"-" yaml:"-"`
-	DependsOn       []string                    `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
-	Conditions      *sdk.WorkflowNodeConditions `json:"conditions,omitempty" yaml:"conditions,omitempty"`
-	When            []string                    `json:"when,omitempty" yaml:"when,omitempty"` //This is use only for manual and success condition
-	PipelineName    string                      `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
-	ApplicationName string                      `json:"application,omitempty" yaml:"application,omitempty"`
-	EnvironmentName string                      `json:"environment,omitempty" yaml:"environment,omitempty"`
-	OneAtATime      *bool                       `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty"`
-	Payload         map[string]interface{}      `json:"payload,omitempty" yaml:"payload,omitempty"`
-	Parameters      map[string]string           `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	ID              int64                         `json:"-" yaml:"-"`
+	DependsOn       []string                      `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Conditions      *sdk.WorkflowNodeConditions   `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	When            []string                      `json:"when,omitempty" yaml:"when,omitempty"` //This is use only for manual and success condition
+	PipelineName    string                        `json:"pipeline,omitempty" yaml:"pipeline,omitempty"`
+	ApplicationName string                        `json:"application,omitempty" yaml:"application,omitempty"`
+	EnvironmentName string                        `json:"environment,omitempty" yaml:"environment,omitempty"`
+	OneAtATime      *bool                         `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty"`
+	Approval        *sdk.WorkflowNodeApprovalRule `json:"approval,omitempty" yaml:"approval,omitempty"`
+	Concurrency     *sdk.WorkflowNodeConcurrency  `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+	Payload         map[string]interface{}        `json:"payload,omitempty" yaml:"payload,omitempty"`
+	Parameters      map[string]string             `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 }
 
 type HookEntry struct {
@@ -68,6 +73,7 @@ func NewWorkflow(w sdk.Workflow, withPermission bool) (Workflow, error) {
 	}
 
 	exportedWorkflow.PurgeTags = w.PurgeTags
+	exportedWorkflow.RunParameters = w.RunParameters
 	nodes := w.Nodes(false)
 
 	if withPermission {
@@ -125,6 +131,14 @@ func NewWorkflow(w sdk.Workflow, withPermission bool) (Workflow, error) {
 			entry.OneAtATime = &n.Context.Mutex
 		}
 
+		if n.Context.Approval != nil {
+			entry.Approval = n.Context.Approval
+		}
+
+		if n.Context.Concurrency != nil {
+			entry.Concurrency = n.Context.Concurrency
+		}
+
 		if n.Context.HasDefaultPayload() {
 			enc := dump.NewDefaultEncoder(nil)
 			enc.ExtraFields.DetailedMap = false
@@ -161,6 +175,8 @@ func NewWorkflow(w sdk.Workflow, withPermission bool) (Workflow, error) {
 		exportedWorkflow.PipelineName = entry.PipelineName
 		exportedWorkflow.EnvironmentName = entry.EnvironmentName
 		exportedWorkflow.DependsOn = entry.DependsOn
+		exportedWorkflow.Approval = entry.Approval
+		exportedWorkflow.Concurrency = entry.Concurrency
 		if entry.Conditions != nil && (len(entry.Conditions.PlainConditions) > 0 || entry.Conditions.LuaScript != "") {
 			exportedWorkflow.When = entry.When
 			exportedWorkflow.Conditions = entry.Conditions
@@ -219,6 +235,8 @@ func (w Workflow) Entries() map[string]NodeEntry {
 		When:            w.When,
 		Payload:         w.Payload,
 		Parameters:      w.Parameters,
+		Approval:        w.Approval,
+		Concurrency:     w.Concurrency,
 	}
 	return map[string]NodeEntry{
 		w.PipelineName: singleEntry,
@@ -254,6 +272,12 @@ func (w Workflow) checkValidity() error {
 		if len(w.PipelineHooks) != 0 {
 			mError.Append(fmt.Errorf("Error: wrong usage: pipeline_hooks not allowed here"))
 		}
+		if w.Approval != nil {
+			mError.Append(fmt.Errorf("Error: wrong usage: approval not allowed here"))
+		}
+		if w.Concurrency != nil {
+			mError.Append(fmt.Errorf("Error: wrong usage: concurrency not allowed here"))
+		}
 	} else {
 		if len(w.Hooks) > 0 {
 			mError.Append(fmt.Errorf("Error: wrong usage: hooks not allowed here"))
@@ -266,6 +290,10 @@ func (w Workflow) checkValidity() error {
 		}
 	}
 
+	if err := sdk.WorkflowParametersSchema(w.RunParameters).IsValid(); err != nil {
+		mError.Append(fmt.Errorf("Error: wrong usage: invalid run parameters: %v", err))
+	}
+
 	if mError.IsEmpty() {
 		return nil
 	}
@@ -314,6 +342,7 @@ func (w Workflow) GetWorkflow() (*sdk.Workflow, error) {
 		return nil, err
 	}
 	wf.PurgeTags = w.PurgeTags
+	wf.RunParameters = w.RunParameters
 	if len(w.Metadata) > 0 {
 		wf.Metadata = make(map[string]string, len(w.Metadata))
 		for k, v := range w.Metadata {
@@ -432,6 +461,20 @@ func (e *NodeEntry) getNode(name string) (*sdk.WorkflowNode, error) {
 		node.Context.Mutex = *e.OneAtATime
 	}
 
+	if e.Approval != nil {
+		if node.Context == nil {
+			node.Context = &sdk.WorkflowNodeContext{}
+		}
+		node.Context.Approval = e.Approval
+	}
+
+	if e.Concurrency != nil {
+		if node.Context == nil {
+			node.Context = &sdk.WorkflowNodeContext{}
+		}
+		node.Context.Concurrency = e.Concurrency
+	}
+
 	return node, nil
 }
 
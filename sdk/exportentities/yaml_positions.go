@@ -0,0 +1,134 @@
+package exportentities
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yamlPosition is a position in a yaml file, line and column start at 1
+type yamlPosition struct {
+	line, column int
+}
+
+type yamlDuplicate struct {
+	key string
+	pos yamlPosition
+}
+
+// yamlPositions indexes the position of the keys and sequence items of a yaml file by their path.
+// The yaml parser does not expose positions, so the file is scanned line by line. Only the block style,
+// used by the files generated by CDS, is indexed; flow style collections get the position of their key
+type yamlPositions struct {
+	positions  map[string]yamlPosition
+	duplicates []yamlDuplicate
+}
+
+// yamlPathSeparator joins the path elements, yaml keys can contain dots
+const yamlPathSeparator = "\x1f"
+
+var yamlKeyRegex = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s"'#\-?:][^#]*?|-[^\s#][^#]*?)\s*:(\s|$)`)
+
+type yamlScanEntry struct {
+	indent int
+	path   string
+	// block is true for keys without a value on their line, their value is on the next lines
+	block bool
+}
+
+func newYAMLPositions(content []byte) *yamlPositions {
+	p := &yamlPositions{positions: map[string]yamlPosition{}}
+	stack := []yamlScanEntry{}
+	items := map[string]int{}
+	// Lines of block scalars (| or >) are skipped
+	scalarIndent := -1
+
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if scalarIndent >= 0 {
+			if trimmed == "" || indent > scalarIndent {
+				continue
+			}
+			scalarIndent = -1
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+
+		// Sequence items, possibly nested on the same line
+		for trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			// A key stays the parent of all the items of its sequence, even if they are at its own indent
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				if top.indent < indent || (top.indent == indent && top.block) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			var parent string
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1].path
+			}
+			path := parent + yamlPathSeparator + fmt.Sprintf("[%d]", items[parent])
+			items[parent]++
+			p.positions[path] = yamlPosition{line: i + 1, column: indent + 1}
+			stack = append(stack, yamlScanEntry{indent: indent, path: path})
+
+			rest := strings.TrimLeft(strings.TrimPrefix(trimmed, "-"), " ")
+			indent += len(trimmed) - len(rest)
+			trimmed = rest
+		}
+		if trimmed == "" {
+			continue
+		}
+
+		sub := yamlKeyRegex.FindStringSubmatch(trimmed)
+		if sub == nil {
+			continue
+		}
+		key := strings.TrimSpace(sub[1])
+		if len(key) >= 2 && (key[0] == '"' || key[0] == '\'') {
+			key = key[1 : len(key)-1]
+		}
+		value := strings.TrimSpace(trimmed[len(sub[0]):])
+		if strings.HasPrefix(value, "#") {
+			value = ""
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		var parent string
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1].path
+		}
+		path := parent + yamlPathSeparator + key
+		pos := yamlPosition{line: i + 1, column: indent + 1}
+		if _, exists := p.positions[path]; exists {
+			p.duplicates = append(p.duplicates, yamlDuplicate{key: key, pos: pos})
+			// The children of a duplicated key are not indexed
+			path = fmt.Sprintf("%s%s#%d", path, yamlPathSeparator, i)
+		} else {
+			p.positions[path] = pos
+		}
+		delete(items, path)
+		stack = append(stack, yamlScanEntry{indent: indent, path: path, block: value == ""})
+
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			scalarIndent = indent
+		}
+	}
+	return p
+}
+
+// get returns the position of a path, or of its nearest indexed ancestor
+func (p *yamlPositions) get(path []string) yamlPosition {
+	for i := len(path); i > 0; i-- {
+		if pos, ok := p.positions[yamlPathSeparator+strings.Join(path[:i], yamlPathSeparator)]; ok {
+			return pos
+		}
+	}
+	return yamlPosition{}
+}
@@ -0,0 +1,31 @@
+package exportentities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYAMLPositions(t *testing.T) {
+	content := `version: v1.0
+steps:
+- script: echo a
+- script: echo b
+  name: b
+jobs:
+  - job: build
+    steps:
+    - script: make
+    - script: make test
+other: value
+`
+	p := newYAMLPositions([]byte(content))
+	assert.Empty(t, p.duplicates)
+	assert.Equal(t, yamlPosition{line: 3, column: 1}, p.get([]string{"steps", "[0]"}))
+	assert.Equal(t, yamlPosition{line: 4, column: 1}, p.get([]string{"steps", "[1]"}))
+	assert.Equal(t, yamlPosition{line: 5, column: 3}, p.get([]string{"steps", "[1]", "name"}))
+	assert.Equal(t, yamlPosition{line: 7, column: 3}, p.get([]string{"jobs", "[0]"}))
+	assert.Equal(t, yamlPosition{line: 9, column: 5}, p.get([]string{"jobs", "[0]", "steps", "[0]"}))
+	assert.Equal(t, yamlPosition{line: 10, column: 5}, p.get([]string{"jobs", "[0]", "steps", "[1]"}))
+	assert.Equal(t, yamlPosition{line: 11, column: 1}, p.get([]string{"other"}))
+}
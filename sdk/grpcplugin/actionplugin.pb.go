@@ -0,0 +1,534 @@
+// Code generated by protoc-gen-go.
+// source: actionplugin.proto
+// DO NOT EDIT!
+
+/*
+Package grpcplugin is a generated protocol buffer package.
+
+It is generated from these files:
+	actionplugin.proto
+
+It has these top-level messages:
+	ActionPluginManifest
+	ActionPluginParameter
+	ActionQuery
+	ActionEvent
+	LogLine
+	Progress
+	Output
+	Artifact
+	ActionResult
+*/
+package grpcplugin
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import google_protobuf "github.com/golang/protobuf/ptypes/empty"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// ActionPluginManifest describes the plugin and its parameters
+type ActionPluginManifest struct {
+	Name        string                   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version     string                   `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	Description string                   `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	Author      string                   `protobuf:"bytes,4,opt,name=author" json:"author,omitempty"`
+	Parameters  []*ActionPluginParameter `protobuf:"bytes,5,rep,name=parameters" json:"parameters,omitempty"`
+}
+
+func (m *ActionPluginManifest) Reset()                    { *m = ActionPluginManifest{} }
+func (m *ActionPluginManifest) String() string            { return proto.CompactTextString(m) }
+func (*ActionPluginManifest) ProtoMessage()               {}
+func (*ActionPluginManifest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *ActionPluginManifest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ActionPluginManifest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ActionPluginManifest) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *ActionPluginManifest) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *ActionPluginManifest) GetParameters() []*ActionPluginParameter {
+	if m != nil {
+		return m.Parameters
+	}
+	return nil
+}
+
+type ActionPluginParameter struct {
+	Name        string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Type        string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Description string `protobuf:"bytes,3,opt,name=description" json:"description,omitempty"`
+	Value       string `protobuf:"bytes,4,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *ActionPluginParameter) Reset()                    { *m = ActionPluginParameter{} }
+func (m *ActionPluginParameter) String() string            { return proto.CompactTextString(m) }
+func (*ActionPluginParameter) ProtoMessage()               {}
+func (*ActionPluginParameter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *ActionPluginParameter) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ActionPluginParameter) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *ActionPluginParameter) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *ActionPluginParameter) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// ActionQuery is the input of a plugin run
+type ActionQuery struct {
+	Options           map[string]string `protobuf:"bytes,1,rep,name=options" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Secrets           map[string]string `protobuf:"bytes,2,rep,name=secrets" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	JobID             int64             `protobuf:"varint,3,opt,name=jobID" json:"jobID,omitempty"`
+	WorkflowNodeRunID int64             `protobuf:"varint,4,opt,name=workflowNodeRunID" json:"workflowNodeRunID,omitempty"`
+	StepOrder         int64             `protobuf:"varint,5,opt,name=stepOrder" json:"stepOrder,omitempty"`
+}
+
+func (m *ActionQuery) Reset()                    { *m = ActionQuery{} }
+func (m *ActionQuery) String() string            { return proto.CompactTextString(m) }
+func (*ActionQuery) ProtoMessage()               {}
+func (*ActionQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *ActionQuery) GetOptions() map[string]string {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+func (m *ActionQuery) GetSecrets() map[string]string {
+	if m != nil {
+		return m.Secrets
+	}
+	return nil
+}
+
+func (m *ActionQuery) GetJobID() int64 {
+	if m != nil {
+		return m.JobID
+	}
+	return 0
+}
+
+func (m *ActionQuery) GetWorkflowNodeRunID() int64 {
+	if m != nil {
+		return m.WorkflowNodeRunID
+	}
+	return 0
+}
+
+func (m *ActionQuery) GetStepOrder() int64 {
+	if m != nil {
+		return m.StepOrder
+	}
+	return 0
+}
+
+// ActionEvent is streamed by the plugin during its run, only one of its fields is set.
+// The last event of a run is its result
+type ActionEvent struct {
+	Log      *LogLine      `protobuf:"bytes,1,opt,name=log" json:"log,omitempty"`
+	Progress *Progress     `protobuf:"bytes,2,opt,name=progress" json:"progress,omitempty"`
+	Output   *Output       `protobuf:"bytes,3,opt,name=output" json:"output,omitempty"`
+	Artifact *Artifact     `protobuf:"bytes,4,opt,name=artifact" json:"artifact,omitempty"`
+	Result   *ActionResult `protobuf:"bytes,5,opt,name=result" json:"result,omitempty"`
+}
+
+func (m *ActionEvent) Reset()                    { *m = ActionEvent{} }
+func (m *ActionEvent) String() string            { return proto.CompactTextString(m) }
+func (*ActionEvent) ProtoMessage()               {}
+func (*ActionEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ActionEvent) GetLog() *LogLine {
+	if m != nil {
+		return m.Log
+	}
+	return nil
+}
+
+func (m *ActionEvent) GetProgress() *Progress {
+	if m != nil {
+		return m.Progress
+	}
+	return nil
+}
+
+func (m *ActionEvent) GetOutput() *Output {
+	if m != nil {
+		return m.Output
+	}
+	return nil
+}
+
+func (m *ActionEvent) GetArtifact() *Artifact {
+	if m != nil {
+		return m.Artifact
+	}
+	return nil
+}
+
+func (m *ActionEvent) GetResult() *ActionResult {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+type LogLine struct {
+	Value string `protobuf:"bytes,1,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *LogLine) Reset()                    { *m = LogLine{} }
+func (m *LogLine) String() string            { return proto.CompactTextString(m) }
+func (*LogLine) ProtoMessage()               {}
+func (*LogLine) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *LogLine) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type Progress struct {
+	Percent int32  `protobuf:"varint,1,opt,name=percent" json:"percent,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *Progress) Reset()                    { *m = Progress{} }
+func (m *Progress) String() string            { return proto.CompactTextString(m) }
+func (*Progress) ProtoMessage()               {}
+func (*Progress) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *Progress) GetPercent() int32 {
+	if m != nil {
+		return m.Percent
+	}
+	return 0
+}
+
+func (m *Progress) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Output is a variable exported for the next steps of the job
+type Output struct {
+	Name  string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *Output) Reset()                    { *m = Output{} }
+func (m *Output) String() string            { return proto.CompactTextString(m) }
+func (*Output) ProtoMessage()               {}
+func (*Output) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *Output) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Output) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// Artifact is a file of the workspace uploaded by the worker
+type Artifact struct {
+	Path string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Tag  string `protobuf:"bytes,2,opt,name=tag" json:"tag,omitempty"`
+}
+
+func (m *Artifact) Reset()                    { *m = Artifact{} }
+func (m *Artifact) String() string            { return proto.CompactTextString(m) }
+func (*Artifact) ProtoMessage()               {}
+func (*Artifact) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *Artifact) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *Artifact) GetTag() string {
+	if m != nil {
+		return m.Tag
+	}
+	return ""
+}
+
+type ActionResult struct {
+	Status  string `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Details string `protobuf:"bytes,2,opt,name=details" json:"details,omitempty"`
+}
+
+func (m *ActionResult) Reset()                    { *m = ActionResult{} }
+func (m *ActionResult) String() string            { return proto.CompactTextString(m) }
+func (*ActionResult) ProtoMessage()               {}
+func (*ActionResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *ActionResult) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *ActionResult) GetDetails() string {
+	if m != nil {
+		return m.Details
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ActionPluginManifest)(nil), "grpcplugin.ActionPluginManifest")
+	proto.RegisterType((*ActionPluginParameter)(nil), "grpcplugin.ActionPluginParameter")
+	proto.RegisterType((*ActionQuery)(nil), "grpcplugin.ActionQuery")
+	proto.RegisterType((*ActionEvent)(nil), "grpcplugin.ActionEvent")
+	proto.RegisterType((*LogLine)(nil), "grpcplugin.LogLine")
+	proto.RegisterType((*Progress)(nil), "grpcplugin.Progress")
+	proto.RegisterType((*Output)(nil), "grpcplugin.Output")
+	proto.RegisterType((*Artifact)(nil), "grpcplugin.Artifact")
+	proto.RegisterType((*ActionResult)(nil), "grpcplugin.ActionResult")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for ActionPlugin service
+
+type ActionPluginClient interface {
+	Manifest(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*ActionPluginManifest, error)
+	Run(ctx context.Context, in *ActionQuery, opts ...grpc.CallOption) (ActionPlugin_RunClient, error)
+}
+
+type actionPluginClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewActionPluginClient(cc *grpc.ClientConn) ActionPluginClient {
+	return &actionPluginClient{cc}
+}
+
+func (c *actionPluginClient) Manifest(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*ActionPluginManifest, error) {
+	out := new(ActionPluginManifest)
+	err := grpc.Invoke(ctx, "/grpcplugin.ActionPlugin/Manifest", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actionPluginClient) Run(ctx context.Context, in *ActionQuery, opts ...grpc.CallOption) (ActionPlugin_RunClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ActionPlugin_serviceDesc.Streams[0], c.cc, "/grpcplugin.ActionPlugin/Run", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &actionPluginRunClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ActionPlugin_RunClient interface {
+	Recv() (*ActionEvent, error)
+	grpc.ClientStream
+}
+
+type actionPluginRunClient struct {
+	grpc.ClientStream
+}
+
+func (x *actionPluginRunClient) Recv() (*ActionEvent, error) {
+	m := new(ActionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for ActionPlugin service
+
+type ActionPluginServer interface {
+	Manifest(context.Context, *google_protobuf.Empty) (*ActionPluginManifest, error)
+	Run(*ActionQuery, ActionPlugin_RunServer) error
+}
+
+func RegisterActionPluginServer(s *grpc.Server, srv ActionPluginServer) {
+	s.RegisterService(&_ActionPlugin_serviceDesc, srv)
+}
+
+func _ActionPlugin_Manifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActionPluginServer).Manifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcplugin.ActionPlugin/Manifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActionPluginServer).Manifest(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActionPlugin_Run_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ActionQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ActionPluginServer).Run(m, &actionPluginRunServer{stream})
+}
+
+type ActionPlugin_RunServer interface {
+	Send(*ActionEvent) error
+	grpc.ServerStream
+}
+
+type actionPluginRunServer struct {
+	grpc.ServerStream
+}
+
+func (x *actionPluginRunServer) Send(m *ActionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ActionPlugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcplugin.ActionPlugin",
+	HandlerType: (*ActionPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Manifest",
+			Handler:    _ActionPlugin_Manifest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Run",
+			Handler:       _ActionPlugin_Run_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "actionplugin.proto",
+}
+
+func init() { proto.RegisterFile("actionplugin.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 608 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xd1, 0x6e, 0xd3, 0x30,
+	0x14, 0x55, 0x9a, 0xb6, 0xeb, 0x6e, 0xf6, 0x00, 0x66, 0x8c, 0xa8, 0x20, 0x51, 0x22, 0x90, 0x26,
+	0x84, 0xb2, 0x2a, 0xbc, 0xc0, 0x1e, 0x26, 0x26, 0x6d, 0x0f, 0x93, 0x06, 0x1b, 0xe6, 0x0b, 0xbc,
+	0xf6, 0x36, 0x0b, 0x4b, 0xe3, 0xc8, 0x76, 0x56, 0xf5, 0x2b, 0xf8, 0x26, 0xbe, 0x86, 0x3f, 0xe0,
+	0x19, 0xd9, 0xb1, 0x5b, 0x4b, 0xed, 0x10, 0xbc, 0xf9, 0xfa, 0x9c, 0xe3, 0xdc, 0x73, 0x1c, 0x5f,
+	0x20, 0x6c, 0xa2, 0x0a, 0x5e, 0xd5, 0x65, 0x93, 0x17, 0x55, 0x5a, 0x0b, 0xae, 0x38, 0x81, 0x5c,
+	0xd4, 0x93, 0x76, 0x67, 0xf8, 0x3c, 0xe7, 0x3c, 0x2f, 0xf1, 0xc8, 0x20, 0x37, 0xcd, 0xec, 0x08,
+	0xe7, 0xb5, 0x5a, 0xb6, 0xc4, 0xe4, 0x67, 0x00, 0xfb, 0xa7, 0x46, 0x7f, 0x6d, 0xd8, 0x9f, 0x59,
+	0x55, 0xcc, 0x50, 0x2a, 0x42, 0xa0, 0x5b, 0xb1, 0x39, 0xc6, 0xc1, 0x28, 0x38, 0xdc, 0xa5, 0x66,
+	0x4d, 0x62, 0xd8, 0xb9, 0x47, 0x21, 0x0b, 0x5e, 0xc5, 0x1d, 0xb3, 0xed, 0x4a, 0x32, 0x82, 0x68,
+	0x8a, 0x72, 0x22, 0x8a, 0x5a, 0x1f, 0x15, 0x87, 0x06, 0xf5, 0xb7, 0xc8, 0x01, 0xf4, 0x59, 0xa3,
+	0x6e, 0xb9, 0x88, 0xbb, 0x06, 0xb4, 0x15, 0x39, 0x05, 0xa8, 0x99, 0x60, 0x73, 0x54, 0x28, 0x64,
+	0xdc, 0x1b, 0x85, 0x87, 0x51, 0xf6, 0x2a, 0x5d, 0xb7, 0x9f, 0xfa, 0xdd, 0x5d, 0x3b, 0x26, 0xf5,
+	0x44, 0xc9, 0x02, 0x9e, 0x6e, 0x25, 0x6d, 0xf5, 0x40, 0xa0, 0xab, 0x96, 0x35, 0x5a, 0x03, 0x66,
+	0xfd, 0x0f, 0xdd, 0xef, 0x43, 0xef, 0x9e, 0x95, 0x0d, 0xda, 0xe6, 0xdb, 0x22, 0xf9, 0xd5, 0x81,
+	0xa8, 0xfd, 0xf2, 0xd7, 0x06, 0xc5, 0x92, 0x9c, 0xc0, 0x0e, 0x37, 0x7c, 0x19, 0x07, 0xc6, 0xc8,
+	0xeb, 0x4d, 0x23, 0x86, 0x99, 0x5e, 0xb5, 0xb4, 0xf3, 0x4a, 0x89, 0x25, 0x75, 0x22, 0xad, 0x97,
+	0x38, 0x11, 0xa8, 0x64, 0xdc, 0xf9, 0xbb, 0xfe, 0x5b, 0x4b, 0xb3, 0x7a, 0x2b, 0xd2, 0x5d, 0x7e,
+	0xe7, 0x37, 0x17, 0x67, 0xc6, 0x41, 0x48, 0xdb, 0x82, 0xbc, 0x83, 0xc7, 0x0b, 0x2e, 0xee, 0x66,
+	0x25, 0x5f, 0x7c, 0xe1, 0x53, 0xa4, 0x4d, 0x75, 0x71, 0x66, 0x7c, 0x84, 0x74, 0x13, 0x20, 0x2f,
+	0x60, 0x57, 0x2a, 0xac, 0xaf, 0xc4, 0x14, 0x45, 0xdc, 0x33, 0xac, 0xf5, 0xc6, 0xf0, 0x18, 0xf6,
+	0xfc, 0xd6, 0xc9, 0x23, 0x08, 0xef, 0x70, 0x69, 0x03, 0xd6, 0xcb, 0x75, 0x52, 0x1d, 0x2f, 0xa9,
+	0xe3, 0xce, 0x87, 0x40, 0x6b, 0xfd, 0xb6, 0xff, 0x47, 0x9b, 0xfc, 0x0e, 0x5c, 0xd2, 0xe7, 0xf7,
+	0x58, 0x29, 0xf2, 0x06, 0xc2, 0x92, 0xe7, 0x46, 0x1b, 0x65, 0x4f, 0xfc, 0x94, 0x2e, 0x79, 0x7e,
+	0x59, 0x54, 0x48, 0x35, 0x4e, 0xc6, 0x30, 0xa8, 0x05, 0xcf, 0x05, 0x4a, 0x69, 0xce, 0x8c, 0xb2,
+	0x7d, 0x9f, 0x7b, 0x6d, 0x31, 0xba, 0x62, 0x91, 0xb7, 0xd0, 0xe7, 0x8d, 0xaa, 0x1b, 0x65, 0x32,
+	0x8c, 0x32, 0xe2, 0xf3, 0xaf, 0x0c, 0x42, 0x2d, 0x43, 0x9f, 0xce, 0x84, 0x2a, 0x66, 0x6c, 0xa2,
+	0xe2, 0xee, 0xe6, 0xe9, 0xa7, 0x16, 0xa3, 0x2b, 0x16, 0x19, 0x43, 0x5f, 0xa0, 0x6c, 0x4a, 0x65,
+	0x92, 0x8d, 0xb2, 0x78, 0xf3, 0x7e, 0xa9, 0xc1, 0xa9, 0xe5, 0x25, 0x2f, 0x61, 0xc7, 0x3a, 0x5a,
+	0xa7, 0x13, 0xf8, 0xff, 0xe0, 0x09, 0x0c, 0x9c, 0x0d, 0xfd, 0x3e, 0x6b, 0x14, 0x13, 0xac, 0x94,
+	0xe1, 0xf4, 0xa8, 0x2b, 0x35, 0x32, 0x47, 0x29, 0x59, 0xee, 0xb2, 0x75, 0x65, 0x92, 0x41, 0xbf,
+	0xb5, 0xb5, 0xf5, 0xb5, 0x6c, 0xbd, 0x91, 0x64, 0x0c, 0x03, 0x67, 0x4e, 0xab, 0x6a, 0xa6, 0x6e,
+	0x9d, 0x4a, 0xaf, 0xf5, 0xcd, 0x2a, 0x96, 0x5b, 0x8d, 0x5e, 0x26, 0x9f, 0x60, 0xcf, 0xb7, 0xa7,
+	0xa7, 0x81, 0x54, 0x4c, 0x35, 0xd2, 0xea, 0x6c, 0xa5, 0xfb, 0x9c, 0xa2, 0x62, 0x45, 0x29, 0x5d,
+	0x9f, 0xb6, 0xcc, 0x7e, 0x04, 0xb0, 0xe7, 0xbf, 0x72, 0x72, 0x06, 0x83, 0xd5, 0xb0, 0x3a, 0x48,
+	0xdb, 0x19, 0x97, 0xba, 0x19, 0x97, 0x9e, 0xeb, 0x19, 0x37, 0x1c, 0x3d, 0x34, 0x48, 0x56, 0xca,
+	0x8f, 0x10, 0xd2, 0xa6, 0x22, 0xcf, 0x1e, 0x78, 0x68, 0xc3, 0x2d, 0x80, 0xf9, 0x03, 0xc7, 0xc1,
+	0x4d, 0xdf, 0x7c, 0xec, 0xfd, 0x9f, 0x01, 0x00, 0x96, 0x0f, 0x89, 0xa9, 0x80, 0x05, 0x00, 0x00,
+}
@@ -0,0 +1,266 @@
+package grpcplugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	goplugin "github.com/hashicorp/go-plugin"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/ovh/cds/sdk/plugin"
+)
+
+//StartTimeout is the time given to a plugin to write its handshake
+var StartTimeout = time.Minute
+
+//Client is a running action plugin. It speaks the net/rpc protocol of the sdk/plugin package or the GRPC protocol,
+//depending on the handshake written by the plugin
+type Client struct {
+	ProtocolVersion int
+	cmd             *exec.Cmd
+	// net/rpc plugins
+	rpcClient *goplugin.RPCClient
+	legacy    plugin.CDSAction
+	// GRPC plugins
+	conn   *grpc.ClientConn
+	client ActionPluginClient
+}
+
+//Start launches a plugin binary and connects to it. The plugin has to be stopped with Kill
+func Start(ctx context.Context, name, binary, id, url string, tlsSkipVerify bool, envs ...string) (*Client, error) {
+	cmd := exec.CommandContext(ctx, binary)
+	// filter technical env variables
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "CDS_") {
+			continue
+		}
+		cmd.Env = append(cmd.Env, e)
+	}
+	cmd.Env = append(cmd.Env, envs...)
+	// variables expected by the net/rpc plugins
+	cmd.Env = append(cmd.Env,
+		plugin.Handshake.MagicCookieKey+"="+plugin.Handshake.MagicCookieValue,
+		"PLUGIN_MIN_PORT=10000",
+		"PLUGIN_MAX_PORT=25000",
+	)
+	cmd.Stderr = os.Stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c := &Client{cmd: cmd}
+
+	line, err := readHandshake(stdout)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+	// The plugin output is not used after the handshake
+	go io.Copy(ioutil.Discard, stdout) // nolint
+
+	var network, address string
+	c.ProtocolVersion, network, address, err = parseHandshake(line)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+
+	switch c.ProtocolVersion {
+	case int(plugin.Handshake.ProtocolVersion):
+		err = c.connectRPC(name, network, address, plugin.Options{ID: id, URL: url, TlsSkipVerify: tlsSkipVerify})
+	case ProtocolVersion:
+		err = c.connectGRPC(network, address)
+	default:
+		err = fmt.Errorf("unsupported protocol version %d", c.ProtocolVersion)
+	}
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("plugin %s: %v", name, err)
+	}
+	return c, nil
+}
+
+func readHandshake(r io.Reader) (string, error) {
+	type handshake struct {
+		line string
+		err  error
+	}
+	chanLine := make(chan handshake, 1)
+	go func() {
+		line, err := bufio.NewReader(r).ReadString('\n')
+		chanLine <- handshake{line, err}
+	}()
+
+	select {
+	case h := <-chanLine:
+		if h.err != nil {
+			return "", fmt.Errorf("unable to read handshake: %v", h.err)
+		}
+		return h.line, nil
+	case <-time.After(StartTimeout):
+		return "", fmt.Errorf("timeout while waiting for handshake")
+	}
+}
+
+//parseHandshake parses the line "core-protocol|app-protocol|network|address[|grpc]" written by the plugins
+func parseHandshake(line string) (int, string, string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) < 4 {
+		return 0, "", "", fmt.Errorf("invalid handshake %q", line)
+	}
+	if parts[0] != strconv.Itoa(goplugin.CoreProtocolVersion) {
+		return 0, "", "", fmt.Errorf("incompatible core protocol version %s", parts[0])
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid protocol version %s", parts[1])
+	}
+	if (len(parts) > 4 && parts[4] == "grpc") != (version == ProtocolVersion) {
+		return 0, "", "", fmt.Errorf("protocol version %d does not match handshake %q", version, line)
+	}
+	switch parts[2] {
+	case "tcp", "unix":
+	default:
+		return 0, "", "", fmt.Errorf("unknown network %s", parts[2])
+	}
+	return version, parts[2], parts[3], nil
+}
+
+func (c *Client) connectRPC(name, network, address string, opts plugin.Options) error {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return err
+	}
+	c.rpcClient, err = goplugin.NewRPCClient(conn, map[string]goplugin.Plugin{name: plugin.CDSActionPlugin{}})
+	if err != nil {
+		return err
+	}
+	raw, err := c.rpcClient.Dispense(name)
+	if err != nil {
+		return err
+	}
+	c.legacy = raw.(plugin.CDSAction)
+	c.legacy.Init(opts)
+	return nil
+}
+
+func (c *Client) connectGRPC(network, address string) error {
+	var err error
+	c.conn, err = grpc.Dial(address,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(StartTimeout),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	c.client = NewActionPluginClient(c.conn)
+	return nil
+}
+
+//Legacy returns the plugin speaking the net/rpc protocol, nil for GRPC plugins
+func (c *Client) Legacy() plugin.CDSAction {
+	return c.legacy
+}
+
+//Manifest returns the description of the plugin, whatever its protocol
+func (c *Client) Manifest(ctx context.Context) (*ActionPluginManifest, error) {
+	if c.legacy == nil {
+		return c.client.Manifest(ctx, &empty.Empty{})
+	}
+
+	m := &ActionPluginManifest{
+		Name:        c.legacy.Name(),
+		Version:     c.legacy.Version(),
+		Description: c.legacy.Description(),
+		Author:      c.legacy.Author(),
+	}
+	params := c.legacy.Parameters()
+	for _, name := range params.Names() {
+		m.Parameters = append(m.Parameters, &ActionPluginParameter{
+			Name:        name,
+			Type:        string(params.GetType(name)),
+			Description: params.GetDescription(name),
+			Value:       params.GetValue(name),
+		})
+	}
+	return m, nil
+}
+
+//Run runs a GRPC plugin. Each event streamed by the plugin is given to the handler, until the plugin result.
+//Canceling the context stops the plugin run
+func (c *Client) Run(ctx context.Context, q *ActionQuery, handler func(*ActionEvent) error) (*ActionResult, error) {
+	if c.client == nil {
+		return nil, fmt.Errorf("plugin protocol version %d does not support streaming", c.ProtocolVersion)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.client.Run(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("plugin has not sent any result")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.Result != nil {
+			return e.Result, nil
+		}
+		if err := handler(e); err != nil {
+			return nil, err
+		}
+	}
+}
+
+//Kill stops the plugin process
+func (c *Client) Kill() {
+	if c.rpcClient != nil {
+		// ask the plugin to exit
+		if err := c.rpcClient.Close(); err != nil {
+			c.cmd.Process.Kill() // nolint
+		}
+	} else {
+		if c.conn != nil {
+			c.conn.Close()
+		}
+		// GRPC plugins exit gracefully on interrupt
+		if err := c.cmd.Process.Signal(os.Interrupt); err != nil {
+			c.cmd.Process.Kill() // nolint
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.cmd.Wait() // nolint
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.cmd.Process.Kill() // nolint
+		<-done
+	}
+}
@@ -0,0 +1,171 @@
+package grpcplugin
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/ovh/cds/sdk/plugin"
+)
+
+// The test binary is also used as a plugin binary
+const testPluginEnv = "GRPCPLUGIN_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(testPluginEnv) {
+	case "grpc":
+		if err := Serve(&testAction{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "rpc":
+		plugin.Serve(&testLegacyAction{})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type testAction struct{}
+
+func (testAction) Manifest() *ActionPluginManifest {
+	return &ActionPluginManifest{
+		Name:    "plugin-test",
+		Version: "1.0",
+		Parameters: []*ActionPluginParameter{
+			{Name: "message", Type: string(plugin.StringParameter)},
+		},
+	}
+}
+
+func (testAction) Run(ctx context.Context, q *ActionQuery, e *Events) error {
+	switch q.Options["mode"] {
+	case "fail":
+		return fmt.Errorf("failure")
+	case "wait":
+		e.Log("waiting")
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	e.Log("message: %s", q.Options["message"])
+	e.Progress(50, "half")
+	e.Output("version", "1.2.3")
+	e.Artifact("*.tar.gz", "v1.2.3")
+	return nil
+}
+
+type testLegacyAction struct {
+	plugin.Common
+}
+
+func (testLegacyAction) Name() string        { return "plugin-legacy" }
+func (testLegacyAction) Description() string { return "legacy plugin" }
+func (testLegacyAction) Author() string      { return "CDS" }
+func (testLegacyAction) Parameters() plugin.Parameters {
+	params := plugin.NewParameters()
+	params.Add("message", plugin.StringParameter, "the message", "hello")
+	return params
+}
+func (testLegacyAction) Run(plugin.IJob) plugin.Result { return plugin.Success }
+
+func TestParseHandshake(t *testing.T) {
+	v, network, address, err := parseHandshake("1|1|unix|/tmp/plugin123\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/tmp/plugin123", address)
+
+	v, network, address, err = parseHandshake("1|2|tcp|127.0.0.1:1234|grpc\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:1234", address)
+
+	for _, line := range []string{"", "This binary is a plugin", "2|1|unix|/tmp/p", "1|2|unix|/tmp/p", "1|1|unix|/tmp/p|grpc", "1|2|udp|host|grpc"} {
+		_, _, _, err := parseHandshake(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func TestGRPCPlugin(t *testing.T) {
+	c, err := Start(context.Background(), "plugin-test", os.Args[0], "", "", false, testPluginEnv+"=grpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer c.Kill()
+	assert.Equal(t, ProtocolVersion, c.ProtocolVersion)
+	assert.Nil(t, c.Legacy())
+
+	m, err := c.Manifest(context.Background())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "plugin-test", m.Name)
+	assert.Len(t, m.Parameters, 1)
+
+	var events []*ActionEvent
+	q := &ActionQuery{
+		Options: map[string]string{"message": "my secret value"},
+		Secrets: map[string]string{"password": "secret"},
+	}
+	res, err := c.Run(context.Background(), q, func(e *ActionEvent) error {
+		events = append(events, e)
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, plugin.Success, res.Status)
+	if !assert.Len(t, events, 4) {
+		t.FailNow()
+	}
+	assert.Equal(t, "message: my **password** value", events[0].Log.Value)
+	assert.Equal(t, int32(50), events[1].Progress.Percent)
+	assert.Equal(t, "version", events[2].Output.Name)
+	assert.Equal(t, "1.2.3", events[2].Output.Value)
+	assert.Equal(t, "v1.2.3", events[3].Artifact.Tag)
+
+	res, err = c.Run(context.Background(), &ActionQuery{Options: map[string]string{"mode": "fail"}}, func(*ActionEvent) error { return nil })
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, plugin.Fail, res.Status)
+	assert.Equal(t, "failure", res.Details)
+
+	// The run is canceled as soon as the plugin has started
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = c.Run(ctx, &ActionQuery{Options: map[string]string{"mode": "wait"}}, func(*ActionEvent) error {
+		cancel()
+		return nil
+	})
+	assert.Error(t, err)
+}
+
+func TestLegacyPlugin(t *testing.T) {
+	c, err := Start(context.Background(), "plugin-legacy", os.Args[0], "", "", false, testPluginEnv+"=rpc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer c.Kill()
+	assert.Equal(t, 1, c.ProtocolVersion)
+	if !assert.NotNil(t, c.Legacy()) {
+		t.FailNow()
+	}
+
+	m, err := c.Manifest(context.Background())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "plugin-legacy", m.Name)
+	if !assert.Len(t, m.Parameters, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, "hello", m.Parameters[0].Value)
+
+	_, err = c.Run(context.Background(), &ActionQuery{}, nil)
+	assert.Error(t, err)
+	assert.Equal(t, plugin.Result(plugin.Success), c.Legacy().Run(plugin.Job{}))
+}
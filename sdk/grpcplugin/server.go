@@ -0,0 +1,171 @@
+package grpcplugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/ovh/cds/sdk/plugin"
+)
+
+//ProtocolVersion is the version of the GRPC plugin protocol. The version 1 is the net/rpc protocol of the sdk/plugin package
+const ProtocolVersion = 2
+
+//Action is the interface implemented by the action plugins speaking the GRPC protocol
+type Action interface {
+	Manifest() *ActionPluginManifest
+	//Run executes the action. The context is canceled when the job is stopped. A non nil error fails the step
+	Run(ctx context.Context, q *ActionQuery, e *Events) error
+}
+
+//Events sends the events of a plugin run to the worker
+type Events struct {
+	stream  ActionPlugin_RunServer
+	secrets map[string]string
+}
+
+//Log sends a log line, the secrets of the query are replaced by their name
+func (e *Events) Log(format string, i ...interface{}) error {
+	s := fmt.Sprintf(format, i...)
+	for k, v := range e.secrets {
+		if len(v) >= 6 {
+			s = strings.Replace(s, v, "**"+k+"**", -1)
+		}
+	}
+	return e.stream.Send(&ActionEvent{Log: &LogLine{Value: s}})
+}
+
+//Progress sends the progress of the action, in percent
+func (e *Events) Progress(percent int, message string) error {
+	return e.stream.Send(&ActionEvent{Progress: &Progress{Percent: int32(percent), Message: message}})
+}
+
+//Output exports a variable. It is available in the next steps of the job as {{.cds.build.<name>}}
+func (e *Events) Output(name, value string) error {
+	return e.stream.Send(&ActionEvent{Output: &Output{Name: name, Value: value}})
+}
+
+//Artifact asks the worker to upload files of the workspace as artifacts
+func (e *Events) Artifact(path, tag string) error {
+	return e.stream.Send(&ActionEvent{Artifact: &Artifact{Path: path, Tag: tag}})
+}
+
+type actionServer struct {
+	action Action
+}
+
+func (s *actionServer) Manifest(context.Context, *empty.Empty) (*ActionPluginManifest, error) {
+	return s.action.Manifest(), nil
+}
+
+func (s *actionServer) Run(q *ActionQuery, stream ActionPlugin_RunServer) error {
+	result := &ActionResult{Status: plugin.Success}
+	if err := s.action.Run(stream.Context(), q, &Events{stream: stream, secrets: q.Secrets}); err != nil {
+		result.Status = plugin.Fail
+		result.Details = err.Error()
+	}
+	return stream.Send(&ActionEvent{Result: result})
+}
+
+//Serve has to be called in main func of every GRPC plugin. It returns when the worker stops the plugin
+func Serve(a Action) error {
+	if os.Getenv(plugin.Handshake.MagicCookieKey) != plugin.Handshake.MagicCookieValue {
+		return fmt.Errorf("This binary is a CDS plugin, it is not meant to be executed directly")
+	}
+
+	lis, err := listen()
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer()
+	RegisterActionPluginServer(s, &actionServer{a})
+
+	// The handshake is compatible with the net/rpc plugins one, with the GRPC protocol version
+	fmt.Printf("%d|%d|%s|%s|grpc\n", goplugin.CoreProtocolVersion, ProtocolVersion, lis.Addr().Network(), lis.Addr().String())
+	os.Stdout.Sync()
+
+	stopped := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		close(stopped)
+		s.GracefulStop()
+	}()
+
+	err = s.Serve(lis)
+	// The unix socket is removed when the listener is closed
+	lis.Close()
+	select {
+	case <-stopped:
+		return nil
+	default:
+		return err
+	}
+}
+
+func listen() (net.Listener, error) {
+	if runtime.GOOS == "windows" {
+		return net.Listen("tcp", "127.0.0.1:0")
+	}
+
+	f, err := ioutil.TempFile("", "cds-plugin")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return net.Listen("unix", path)
+}
+
+//Main func call by GRPC plugins, it serves the plugin or displays its information
+func Main(a Action) {
+	var cmdInfo = &cobra.Command{
+		Use:   "info",
+		Short: "info: Print plugin Information anything to the screen",
+		Run: func(cmd *cobra.Command, args []string) {
+			m := a.Manifest()
+			fmt.Printf("%s\n\n## Parameters\n\n", m.Description)
+			for _, p := range m.Parameters {
+				fmt.Printf("* **%s**: %s\n", p.Name, p.Description)
+			}
+		},
+	}
+
+	var cmdVersion = &cobra.Command{
+		Use:   "version",
+		Short: "Print plugin version",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Printf("CDS Plugin version:%s protocol:%d os:%s architecture:%s\n", a.Manifest().Version, ProtocolVersion, runtime.GOOS, runtime.GOARCH)
+		},
+	}
+
+	var rootCmd = &cobra.Command{
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := Serve(a); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+	rootCmd.AddCommand(cmdInfo)
+	rootCmd.AddCommand(cmdVersion)
+	rootCmd.Execute()
+}
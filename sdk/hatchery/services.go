@@ -0,0 +1,66 @@
+package hatchery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// ServiceLocalHost is the host of the services started on the network of a virtual machine
+const ServiceLocalHost = "127.0.0.1"
+
+// ServiceContainerName returns the name of the container running a service for a worker
+func ServiceContainerName(service, workerName string) string {
+	return service + "-" + workerName
+}
+
+// DockerRunServiceArgs returns the arguments of the docker run command starting the service of a requirement for
+// a worker. The containers are labeled with the worker name, to be removed with the worker.
+// With hostNetwork, the service uses the network of the host and it is reachable on ServiceLocalHost
+func DockerRunServiceArgs(r sdk.Requirement, workerName string, hostNetwork bool) []string {
+	img, env := sdk.ParseServiceRequirement(r.Value)
+	args := []string{"run", "-d",
+		"--name", ServiceContainerName(r.Name, workerName),
+		"--label", "service_worker=" + workerName,
+		"--label", "service_name=" + r.Name,
+	}
+	if hostNetwork {
+		args = append(args, "--network", "host")
+	}
+	for _, e := range env {
+		//option for power user : set the service memory with CDS_SERVICE_MEMORY=1024
+		if strings.HasPrefix(e, "CDS_SERVICE_MEMORY=") {
+			if m, err := strconv.Atoi(strings.TrimPrefix(e, "CDS_SERVICE_MEMORY=")); err == nil {
+				args = append(args, "--memory", fmt.Sprintf("%dm", m))
+			}
+			continue
+		}
+		args = append(args, "-e", e)
+	}
+	return append(args, img)
+}
+
+// DockerServicesScript returns the shell commands starting with docker the services required by a job on the
+// virtual machine of its worker. The services use the network of the machine, each started service is added
+// to the CDS_SERVICES variable read by the worker
+func DockerServicesScript(requirements []sdk.Requirement, workerName string) string {
+	var script string
+	for _, r := range requirements {
+		if r.Type != sdk.ServiceRequirement {
+			continue
+		}
+		args := DockerRunServiceArgs(r, workerName, true)
+		for i := range args {
+			args[i] = shellQuote(args[i])
+		}
+		host := sdk.FormatServiceHosts(map[string]string{r.Name: ServiceLocalHost})
+		script += fmt.Sprintf("docker %s >> /tmp/user_data 2>&1 && export CDS_SERVICES=\"$CDS_SERVICES\"%s\n", strings.Join(args, " "), shellQuote(","+host))
+	}
+	return script
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
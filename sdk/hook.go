@@ -68,6 +68,18 @@ var (
 				Value:        "{}",
 				Configurable: true,
 			},
+			"jitter": {
+				Value:        "0s",
+				Configurable: true,
+			},
+			"skip_if_building": {
+				Value:        "false",
+				Configurable: true,
+			},
+			"catch_up": {
+				Value:        SchedulerCatchUpLast,
+				Configurable: true,
+			},
 		},
 	}
 )
@@ -0,0 +1,142 @@
+package sdk
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+// These are the catch-up policies of the scheduler hooks, for the executions missed while the hooks µService was down
+const (
+	// SchedulerCatchUpNone skips the missed executions
+	SchedulerCatchUpNone = "none"
+	// SchedulerCatchUpLast runs once the missed executions
+	SchedulerCatchUpLast = "last"
+	// SchedulerCatchUpAll runs each missed execution, up to SchedulerMaxCatchUp executions
+	SchedulerCatchUpAll = "all"
+)
+
+// SchedulerMaxCatchUp is the maximum number of missed executions of a scheduler hook run with SchedulerCatchUpAll
+const SchedulerMaxCatchUp = 100
+
+// schedulerJitterCheckedExecutions is the number of next executions of a scheduler hook in which the minimal
+// interval between two executions is searched to validate the jitter
+const schedulerJitterCheckedExecutions = 1000
+
+// SchedulerHookConfig is the parsed configuration of a scheduler hook
+type SchedulerHookConfig struct {
+	Cron           *cronexpr.Expression
+	Location       *time.Location
+	Jitter         time.Duration
+	SkipIfBuilding bool
+	CatchUp        string
+}
+
+// ParseSchedulerHookConfig parses the configuration of a scheduler hook. The keys missing in the configuration of
+// the hooks created before their introduction take their default value
+func ParseSchedulerHookConfig(cfg WorkflowNodeHookConfig) (*SchedulerHookConfig, error) {
+	value := func(k string) string {
+		if v, ok := cfg[k]; ok {
+			return v.Value
+		}
+		return SchedulerModel.DefaultConfig[k].Value
+	}
+
+	c := &SchedulerHookConfig{}
+	var err error
+	if c.Cron, err = cronexpr.Parse(value("cron")); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %s: %v", value("cron"), err)
+	}
+	if c.Location, err = time.LoadLocation(value("timezone")); err != nil {
+		return nil, fmt.Errorf("invalid timezone %s: %v", value("timezone"), err)
+	}
+
+	if j := value("jitter"); j != "" {
+		if c.Jitter, err = time.ParseDuration(j); err != nil || c.Jitter < 0 {
+			return nil, fmt.Errorf("invalid jitter %s: it should be a positive duration, as 30s or 5m", j)
+		}
+	}
+
+	if s := value("skip_if_building"); s != "" {
+		if c.SkipIfBuilding, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("invalid skip_if_building %s: it should be true or false", s)
+		}
+	}
+
+	switch c.CatchUp = value("catch_up"); c.CatchUp {
+	case SchedulerCatchUpNone, SchedulerCatchUpLast, SchedulerCatchUpAll:
+	default:
+		return nil, fmt.Errorf("invalid catch_up %s: it should be %s, %s or %s", c.CatchUp, SchedulerCatchUpNone, SchedulerCatchUpLast, SchedulerCatchUpAll)
+	}
+	return c, nil
+}
+
+// ValidateSchedulerHookConfig parses the configuration of a scheduler hook saved by a user, and checks that its
+// jitter is lower than the interval between two executions. This check is too long to be done on each parsing
+func ValidateSchedulerHookConfig(cfg WorkflowNodeHookConfig) (*SchedulerHookConfig, error) {
+	c, err := ParseSchedulerHookConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// a jitter as long as the interval between two executions would swap or merge them
+	if c.Jitter > 0 {
+		if min := c.minInterval(time.Now(), schedulerJitterCheckedExecutions); min > 0 && c.Jitter >= min {
+			return nil, fmt.Errorf("invalid jitter %s: it should be lower than the interval of %s between two executions", c.Jitter, min)
+		}
+	}
+	return c, nil
+}
+
+// Next returns the date of the next execution after t, in the timezone of the hook and without the jitter
+func (c *SchedulerHookConfig) Next(t time.Time) time.Time {
+	return c.Cron.Next(t.In(c.Location))
+}
+
+// NextExecutions returns the dates of the n next executions after t, without the jitter
+func (c *SchedulerHookConfig) NextExecutions(t time.Time, n int) []time.Time {
+	dates := []time.Time{}
+	for i := 0; i < n; i++ {
+		t = c.Next(t)
+		if t.IsZero() {
+			break
+		}
+		dates = append(dates, t)
+	}
+	return dates
+}
+
+// minInterval returns the minimal interval between two of the n next executions after t, 0 if there are less than
+// two executions
+func (c *SchedulerHookConfig) minInterval(t time.Time, n int) time.Duration {
+	var min time.Duration
+	dates := c.NextExecutions(t, n)
+	for i := 1; i < len(dates); i++ {
+		if d := dates[i].Sub(dates[i-1]); min == 0 || d < min {
+			min = d
+		}
+	}
+	return min
+}
+
+// MissedExecutions returns the dates of the executions after last and before now, at most max dates which are
+// the latest ones
+func (c *SchedulerHookConfig) MissedExecutions(last, now time.Time, max int) []time.Time {
+	dates := []time.Time{}
+	for t := c.Next(last); !t.IsZero() && t.Before(now); t = c.Next(t) {
+		if len(dates) == max {
+			dates = dates[1:]
+		}
+		dates = append(dates, t)
+	}
+	return dates
+}
+
+// SchedulerHookPreview is the preview of the next executions of a scheduler hook. Each execution is delayed by a
+// random duration up to the jitter
+type SchedulerHookPreview struct {
+	Timezone   string      `json:"timezone" cli:"timezone"`
+	Jitter     string      `json:"jitter" cli:"jitter"`
+	Executions []time.Time `json:"executions" cli:"executions"`
+}
@@ -0,0 +1,146 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedulerHookConfig(t *testing.T) {
+	// the hooks created before the options have only the cron, the timezone and the payload
+	c, err := ParseSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 1 * * *"},
+		"timezone": {Value: "Europe/Paris"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "Europe/Paris", c.Location.String())
+	assert.Equal(t, time.Duration(0), c.Jitter)
+	assert.False(t, c.SkipIfBuilding)
+	assert.Equal(t, SchedulerCatchUpLast, c.CatchUp)
+
+	c, err = ParseSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":             {Value: "*/5 * * * *"},
+		"timezone":         {Value: "UTC"},
+		"jitter":           {Value: "30s"},
+		"skip_if_building": {Value: "true"},
+		"catch_up":         {Value: SchedulerCatchUpAll},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 30*time.Second, c.Jitter)
+	assert.True(t, c.SkipIfBuilding)
+	assert.Equal(t, SchedulerCatchUpAll, c.CatchUp)
+
+	for k, v := range map[string]string{
+		"cron":             "every day",
+		"timezone":         "Mars/Olympus",
+		"jitter":           "-1m",
+		"skip_if_building": "sometimes",
+		"catch_up":         "first",
+	} {
+		cfg := WorkflowNodeHookConfig{"cron": {Value: "0 1 * * *"}, "timezone": {Value: "UTC"}}
+		cfg[k] = WorkflowNodeHookConfigValue{Value: v}
+		_, err := ParseSchedulerHookConfig(cfg)
+		assert.Error(t, err, k)
+	}
+
+}
+
+func TestValidateSchedulerHookConfig(t *testing.T) {
+	// the jitter must be lower than the minimal interval between two executions
+	for cron, jitter := range map[string]string{
+		"*/5 * * * *":   "5m",
+		"0 0,1 * * *":   "90m",
+		"0 9 * * 1-5":   "24h",
+		"0 0 1,15 * *":  "336h",
+		"30 8,20 * * *": "12h",
+	} {
+		cfg := WorkflowNodeHookConfig{
+			"cron":     {Value: cron},
+			"timezone": {Value: "UTC"},
+			"jitter":   {Value: jitter},
+		}
+		_, err := ValidateSchedulerHookConfig(cfg)
+		assert.Error(t, err, cron)
+		// the hooks saved before the check still run
+		_, err = ParseSchedulerHookConfig(cfg)
+		assert.NoError(t, err, cron)
+	}
+
+	_, err := ValidateSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 0,1 * * *"},
+		"timezone": {Value: "UTC"},
+		"jitter":   {Value: "59m"},
+	})
+	assert.NoError(t, err)
+
+	_, err = ValidateSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 1 * * *"},
+		"timezone": {Value: "Mars/Olympus"},
+	})
+	assert.Error(t, err)
+}
+
+func TestSchedulerHookConfigMinInterval(t *testing.T) {
+	c, err := ParseSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 9 * * 1-5"},
+		"timezone": {Value: "UTC"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	now := time.Date(2018, time.March, 23, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 24*time.Hour, c.minInterval(now, 10))
+	assert.Equal(t, time.Duration(0), c.minInterval(now, 1))
+}
+
+func TestSchedulerHookConfigNextExecutions(t *testing.T) {
+	c, err := ParseSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 1 * * *"},
+		"timezone": {Value: "Europe/Paris"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// 1AM in Paris is 0AM UTC in winter, and 11PM UTC the day before after the switch to summer time on March 25
+	now := time.Date(2018, time.March, 23, 12, 0, 0, 0, time.UTC)
+	dates := c.NextExecutions(now, 3)
+	if !assert.Len(t, dates, 3) {
+		t.FailNow()
+	}
+	assert.Equal(t, time.Date(2018, time.March, 24, 0, 0, 0, 0, time.UTC), dates[0].UTC())
+	assert.Equal(t, time.Date(2018, time.March, 25, 0, 0, 0, 0, time.UTC), dates[1].UTC())
+	assert.Equal(t, time.Date(2018, time.March, 25, 23, 0, 0, 0, time.UTC), dates[2].UTC())
+}
+
+func TestSchedulerHookConfigMissedExecutions(t *testing.T) {
+	c, err := ParseSchedulerHookConfig(WorkflowNodeHookConfig{
+		"cron":     {Value: "0 * * * *"},
+		"timezone": {Value: "UTC"},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	last := time.Date(2018, time.March, 23, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2018, time.March, 23, 14, 30, 0, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2018, time.March, 23, 11, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 23, 12, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 23, 13, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 23, 14, 0, 0, 0, time.UTC),
+	}, c.MissedExecutions(last, now, 10))
+
+	// only the latest missed executions are returned
+	assert.Equal(t, []time.Time{
+		time.Date(2018, time.March, 23, 13, 0, 0, 0, time.UTC),
+		time.Date(2018, time.March, 23, 14, 0, 0, 0, time.UTC),
+	}, c.MissedExecutions(last, now, 2))
+
+	assert.Empty(t, c.MissedExecutions(now, now, 10))
+}
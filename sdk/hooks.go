@@ -34,4 +34,10 @@ type WebHookExecution struct {
 // ScheduledTaskExecution contains specific data for a scheduled task execution
 type ScheduledTaskExecution struct {
 	DateScheduledExecution string
+	// ScheduledTimestamp is the date of the execution given by the cron expression, before the jitter
+	ScheduledTimestamp int64
+	// CatchUp is set on the executions missed while the hooks µService was down
+	CatchUp bool
+	// Skipped is the reason why the execution has not triggered the workflow
+	Skipped string
 }
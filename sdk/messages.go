@@ -70,10 +70,18 @@ var (
 	MsgWorkflowStarting                    = &Message{"MsgWorkflowStarting", trad{FR: "Le workflow %s#%s a été démarré", EN: "Workflow %s#%s has been started"}, nil}
 	MsgWorkflowError                       = &Message{"MsgWorkflowError", trad{FR: "Une erreur est survenue: %v", EN: "An error has occured: %v"}, nil}
 	MsgWorkflowNodeStop                    = &Message{"MsgWorkflowNodeStop", trad{FR: "Le pipeline a été arrété par %s", EN: "The pipeline has been stopped by %s"}, nil}
+	MsgWorkflowNodeStopReason              = &Message{"MsgWorkflowNodeStopReason", trad{FR: "Le pipeline a été arrété par %s : %s", EN: "The pipeline has been stopped by %s: %s"}, nil}
 	MsgWorkflowNodeMutex                   = &Message{"MsgWorkflowNodeMutex", trad{FR: "Le pipeline %s est mis en attente tant qu'il est en cours sur un autre run", EN: "The pipeline %s is waiting while it's running on another run"}, nil}
 	MsgWorkflowNodeMutexRelease            = &Message{"MsgWorkflowNodeMutexRelease", trad{FR: "Lancement du pipeline %s", EN: "Triggering pipeline %s"}, nil}
 	MsgWorkflowImportedUpdated             = &Message{"MsgWorkflowImportedUpdated", trad{FR: "Le workflow %s a été mis à jour", EN: "Workflow %s has been updated"}, nil}
 	MsgWorkflowImportedInserted            = &Message{"MsgWorkflowImportedInserted", trad{FR: "Le workflow %s a été créé", EN: "Workflow %s has been created"}, nil}
+	MsgWorkflowNodeApprovalWaiting         = &Message{"MsgWorkflowNodeApprovalWaiting", trad{FR: "Le pipeline %s est en attente de %d approbation(s)", EN: "The pipeline %s is waiting for %d approval(s)"}, nil}
+	MsgWorkflowNodeApprovalApproved        = &Message{"MsgWorkflowNodeApprovalApproved", trad{FR: "Le pipeline %s a été approuvé par %s", EN: "The pipeline %s has been approved by %s"}, nil}
+	MsgWorkflowNodeApprovalRejected        = &Message{"MsgWorkflowNodeApprovalRejected", trad{FR: "Le pipeline %s a été rejeté par %s", EN: "The pipeline %s has been rejected by %s"}, nil}
+	MsgWorkflowNodeApprovalExpired         = &Message{"MsgWorkflowNodeApprovalExpired", trad{FR: "La demande d'approbation du pipeline %s a expiré", EN: "The approval request of the pipeline %s has expired"}, nil}
+	MsgWorkflowNodeConcurrencyQueued       = &Message{"MsgWorkflowNodeConcurrencyQueued", trad{FR: "Le pipeline %s est en attente du groupe de concurrence %s (position %d)", EN: "The pipeline %s is waiting for the concurrency group %s (position %d)"}, nil}
+	MsgWorkflowNodeConcurrencyRelease      = &Message{"MsgWorkflowNodeConcurrencyRelease", trad{FR: "Le groupe de concurrence %s est disponible, lancement du pipeline %s", EN: "The concurrency group %s is available, triggering pipeline %s"}, nil}
+	MsgWorkflowNodeConcurrencyCanceled     = &Message{"MsgWorkflowNodeConcurrencyCanceled", trad{FR: "Le pipeline %s a été annulé par un run plus récent dans le groupe de concurrence %s", EN: "The pipeline %s has been canceled by a newer run in the concurrency group %s"}, nil}
 )
 
 // Messages contains all sdk Messages
@@ -126,10 +134,18 @@ var Messages = map[string]*Message{
 	MsgWorkflowStarting.ID:                    MsgWorkflowStarting,
 	MsgWorkflowError.ID:                       MsgWorkflowError,
 	MsgWorkflowNodeStop.ID:                    MsgWorkflowNodeStop,
+	MsgWorkflowNodeStopReason.ID:              MsgWorkflowNodeStopReason,
 	MsgWorkflowImportedUpdated.ID:             MsgWorkflowImportedUpdated,
 	MsgWorkflowImportedInserted.ID:            MsgWorkflowImportedInserted,
 	MsgWorkflowNodeMutex.ID:                   MsgWorkflowNodeMutex,
 	MsgWorkflowNodeMutexRelease.ID:            MsgWorkflowNodeMutexRelease,
+	MsgWorkflowNodeApprovalWaiting.ID:         MsgWorkflowNodeApprovalWaiting,
+	MsgWorkflowNodeApprovalApproved.ID:        MsgWorkflowNodeApprovalApproved,
+	MsgWorkflowNodeApprovalRejected.ID:        MsgWorkflowNodeApprovalRejected,
+	MsgWorkflowNodeApprovalExpired.ID:         MsgWorkflowNodeApprovalExpired,
+	MsgWorkflowNodeConcurrencyQueued.ID:       MsgWorkflowNodeConcurrencyQueued,
+	MsgWorkflowNodeConcurrencyRelease.ID:      MsgWorkflowNodeConcurrencyRelease,
+	MsgWorkflowNodeConcurrencyCanceled.ID:     MsgWorkflowNodeConcurrencyCanceled,
 }
 
 //Message represent a struc format translated messages
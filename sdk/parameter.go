@@ -160,7 +160,8 @@ func ParametersFromPipelineParameters(pipParams []Parameter) map[string]string {
 func variablesToParameters(prefix string, variables []Variable) []Parameter {
 	res := []Parameter{}
 	for _, t := range variables {
-		if NeedPlaceholder(t.Type) {
+		// Secrets and vault references are sent to the worker as secrets when the job is taken
+		if NeedPlaceholder(t.Type) || t.Type == VaultVariable {
 			continue
 		}
 		t.Name = prefix + "." + t.Name
@@ -2,9 +2,14 @@ package sdk
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -46,8 +51,27 @@ func DownloadPlugin(name string, destdir string) error {
 	return fmt.Errorf("x5: %s", lasterr)
 }
 
-//UploadPlugin uploads binary file to perform a new action
+//UploadPlugin uploads binary file to perform a new action. The version of the plugin is given by the plugin itself
 func UploadPlugin(filePath string, update bool) ([]byte, error) {
+	method := "POST"
+	if update {
+		method = "PUT"
+	}
+	return uploadPluginFile(method, "/plugin", filePath, nil)
+}
+
+//UploadPluginBinary uploads the binary of an existing plugin for a version, an OS and an architecture
+func UploadPluginBinary(filePath, name, version, goos, goarch string) error {
+	fields := map[string]string{
+		"version": version,
+		"os":      goos,
+		"arch":    goarch,
+	}
+	_, err := uploadPluginFile("POST", fmt.Sprintf("/plugin/%s/binary", name), filePath, fields)
+	return err
+}
+
+func uploadPluginFile(method, path, filePath string, fields map[string]string) ([]byte, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, err
 	}
@@ -58,10 +82,13 @@ func UploadPlugin(filePath string, update bool) ([]byte, error) {
 	}
 	defer file.Close()
 
-	//_, name := filepath.Split(filePath)
-
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
+	for k, v := range fields {
+		if err := writer.WriteField(k, v); err != nil {
+			return nil, err
+		}
+	}
 	part, errc := writer.CreateFormFile("UploadFile", filepath.Base(filePath))
 	if errc != nil {
 		return nil, errc
@@ -74,11 +101,7 @@ func UploadPlugin(filePath string, update bool) ([]byte, error) {
 	if err := writer.Close(); err != nil {
 		return nil, err
 	}
-	method := "POST"
-	if update {
-		method = "PUT"
-	}
-	btes, code, err := UploadMultiPart(method, "/plugin", body, SetHeader("uploadfile", filePath), SetHeader("Content-Type", writer.FormDataContentType()))
+	btes, code, err := UploadMultiPart(method, path, body, SetHeader("uploadfile", filePath), SetHeader("Content-Type", writer.FormDataContentType()))
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +113,85 @@ func UploadPlugin(filePath string, update bool) ([]byte, error) {
 	return btes, nil
 }
 
+//GetPluginBinaryInfos returns the binary of a plugin matching a version, an OS and an architecture.
+//The latest version is returned if version is empty
+func GetPluginBinaryInfos(name, version, goos, goarch string) (*PluginBinary, error) {
+	uri := fmt.Sprintf("/plugin/%s/binary/%s/%s", name, goos, goarch)
+	if version != "" {
+		uri += "?version=" + url.QueryEscape(version)
+	}
+	data, code, err := Request("GET", uri, nil)
+	if code == http.StatusNotFound {
+		return nil, ErrPluginNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if code >= 300 {
+		return nil, fmt.Errorf("HTTP %d", code)
+	}
+
+	b := &PluginBinary{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+//DownloadPluginBinary downloads a plugin binary in destPath, and checks its SHA-256 checksum
+func DownloadPluginBinary(b PluginBinary, destPath string) error {
+	var lasterr error
+	for retry := 5; retry >= 0; retry-- {
+		uri := fmt.Sprintf("/plugin/download/%s?accept-redirect=true&version=%s&os=%s&arch=%s", b.PluginName, url.QueryEscape(b.Version), b.OS, b.Arch)
+		reader, code, err := Stream("GET", uri, nil)
+		if err != nil {
+			lasterr = err
+			continue
+		}
+		if code >= 300 {
+			reader.Close()
+			lasterr = fmt.Errorf("HTTP %d", code)
+			continue
+		}
+
+		lasterr = writePluginBinary(reader, destPath, b.SHA256Sum)
+		reader.Close()
+		if lasterr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("x5: %s", lasterr)
+}
+
+// writePluginBinary writes an executable file, which is removed if its content does not match the SHA-256 checksum
+func writePluginBinary(r io.Reader, destPath, sha256sum string) error {
+	//If the file already exists, remove it
+	if _, errstat := os.Stat(destPath); errstat == nil {
+		os.RemoveAll(destPath)
+	}
+
+	f, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY, 0700)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	_, errc := io.Copy(io.MultiWriter(f, hash), r)
+	if err := f.Close(); err != nil && errc == nil {
+		errc = err
+	}
+	if errc == nil {
+		if sum := hex.EncodeToString(hash.Sum(nil)); sum != sha256sum {
+			errc = fmt.Errorf("invalid checksum for %s: %s, expected %s", filepath.Base(destPath), sum, sha256sum)
+		}
+	}
+	if errc != nil {
+		os.Remove(destPath)
+		return errc
+	}
+	return nil
+}
+
 //DeletePlugin delete plugin
 func DeletePlugin(name string) error {
 	path := fmt.Sprintf("/plugin/%s", name)
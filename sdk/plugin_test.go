@@ -0,0 +1,59 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePluginRequirement(t *testing.T) {
+	tests := []struct {
+		value   string
+		name    string
+		version string
+	}{
+		{value: "plugin-tmpl", name: "plugin-tmpl"},
+		{value: "plugin-tmpl@1.2.0", name: "plugin-tmpl", version: "1.2.0"},
+		{value: "plugin@tmpl@1.2.0", name: "plugin@tmpl", version: "1.2.0"},
+		{value: "@1.2.0", name: "@1.2.0"},
+	}
+	for _, tt := range tests {
+		name, version := ParsePluginRequirement(tt.value)
+		assert.Equal(t, tt.name, name, tt.value)
+		assert.Equal(t, tt.version, version, tt.value)
+	}
+}
+
+func TestPluginBinaryGetName(t *testing.T) {
+	b := PluginBinary{PluginName: "plugin-tmpl", Version: "1.2.0", OS: "linux", Arch: "amd64"}
+	assert.Equal(t, "plugin-tmpl-1.2.0-linux-amd64", b.GetName())
+
+	// the binaries stored with their checksum keep the name they are stored under
+	b.ObjectName = "plugin-tmpl-1.2.0-linux-amd64-0123abcd"
+	assert.Equal(t, "plugin-tmpl-1.2.0-linux-amd64-0123abcd", b.GetName())
+}
+
+func TestWritePluginBinary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cds-plugin")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	dest := filepath.Join(dir, "plugin-tmpl")
+
+	// sha256 of "plugin"
+	sum := "5e689e2b01672bf33996e75d5e372ff60c536ce1599a1458e867cd8f4bef5160"
+	assert.NoError(t, writePluginBinary(strings.NewReader("plugin"), dest, sum))
+	content, err := ioutil.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, "plugin", string(content))
+
+	err = writePluginBinary(strings.NewReader("another plugin"), dest, sum)
+	assert.Error(t, err)
+	_, err = os.Stat(dest)
+	assert.True(t, os.IsNotExist(err), "an invalid binary must be removed")
+}
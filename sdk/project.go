@@ -32,6 +32,46 @@ type Project struct {
 	VCSServers        []ProjectVCSServer `json:"vcs_servers" yaml:"vcs_servers" db:"-" cli:"-"`
 }
 
+// ProjectLogSettings are the limits of the steps logs of the workflows of a project
+type ProjectLogSettings struct {
+	ProjectID int64 `json:"-" cli:"-"`
+	// StepMaxSize is the maximum size in bytes of the logs of a step, 0 for the default limit of the API
+	StepMaxSize int64 `json:"step_max_size" cli:"step_max_size"`
+	// RetentionDays is the number of days the logs are kept, 0 to keep them with their workflow run
+	RetentionDays int `json:"retention_days" cli:"retention_days"`
+}
+
+// IsValid checks the log settings
+func (s ProjectLogSettings) IsValid() error {
+	if s.StepMaxSize < 0 {
+		return fmt.Errorf("Invalid step max size: %d", s.StepMaxSize)
+	}
+	if s.RetentionDays < 0 {
+		return fmt.Errorf("Invalid retention: %d days", s.RetentionDays)
+	}
+	return nil
+}
+
+// ProjectArtifactSettings are the retention rules of the artifacts of the workflows of a project
+type ProjectArtifactSettings struct {
+	ProjectID int64 `json:"-" cli:"-"`
+	// RetentionDays is the number of days the artifacts are kept, 0 to keep them with their workflow run
+	RetentionDays int `json:"retention_days" cli:"retention_days"`
+	// TaggedOnly is a tag of the workflow runs. If set, only the artifacts of the runs with this tag are kept, the
+	// artifacts of the other runs are deleted once the run is over
+	TaggedOnly string `json:"tagged_only,omitempty" cli:"tagged_only"`
+	// KeepReleases keeps forever the artifacts of the runs which made a release
+	KeepReleases bool `json:"keep_releases" cli:"keep_releases"`
+}
+
+// IsValid checks the artifact settings
+func (s ProjectArtifactSettings) IsValid() error {
+	if s.RetentionDays < 0 {
+		return fmt.Errorf("Invalid retention: %d days", s.RetentionDays)
+	}
+	return nil
+}
+
 // ProjectVCSServer represents associations between a project and a vcs server
 type ProjectVCSServer struct {
 	Name string            `json:"name" yaml:"name" db:"-" cli:"-"`
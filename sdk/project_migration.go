@@ -0,0 +1,40 @@
+package sdk
+
+// These are the kinds of the items of a project converted to workflows by a migration
+const (
+	ProjectMigrationItemApplication = "application"
+	ProjectMigrationItemTrigger     = "trigger"
+	ProjectMigrationItemScheduler   = "scheduler"
+	ProjectMigrationItemPoller      = "poller"
+	ProjectMigrationItemHook        = "hook"
+)
+
+// ProjectMigrationReport is the report of the migration of the applications and pipelines of a project to workflows
+type ProjectMigrationReport struct {
+	ProjectKey string                 `json:"project_key"`
+	DryRun     bool                   `json:"dry_run"`
+	Items      []ProjectMigrationItem `json:"items"`
+}
+
+// ProjectMigrationItem is an application, trigger, scheduler, poller or hook of a project converted by a migration.
+// Reason explains why an item is not converted, or what is changed by its conversion
+type ProjectMigrationItem struct {
+	Kind        string `json:"kind" cli:"kind"`
+	Application string `json:"application" cli:"application"`
+	Pipeline    string `json:"pipeline,omitempty" cli:"pipeline"`
+	Environment string `json:"environment,omitempty" cli:"environment"`
+	Workflow    string `json:"workflow,omitempty" cli:"workflow"`
+	Converted   bool   `json:"converted" cli:"converted"`
+	Reason      string `json:"reason,omitempty" cli:"reason"`
+}
+
+// NotConverted returns the items which cannot be converted
+func (r *ProjectMigrationReport) NotConverted() []ProjectMigrationItem {
+	items := []ProjectMigrationItem{}
+	for _, i := range r.Items {
+		if !i.Converted {
+			items = append(items, i)
+		}
+	}
+	return items
+}
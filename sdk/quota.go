@@ -0,0 +1,50 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+// Quota limits the jobs run by a project, or by all the projects managed by a group.
+// A zero limit means unlimited. Jobs of the projects with the highest priority are served first
+type Quota struct {
+	ID                    int64  `json:"id" cli:"-"`
+	ProjectID             int64  `json:"project_id,omitempty" cli:"-"`
+	ProjectKey            string `json:"project_key,omitempty" cli:"project"`
+	GroupID               int64  `json:"group_id,omitempty" cli:"-"`
+	GroupName             string `json:"group_name,omitempty" cli:"group"`
+	MaxConcurrentJobs     int    `json:"max_concurrent_jobs" cli:"max_concurrent_jobs"`
+	MaxDailyWorkerMinutes int64  `json:"max_daily_worker_minutes" cli:"max_daily_worker_minutes"`
+	Priority              int    `json:"priority" cli:"priority"`
+}
+
+// IsValid checks the quota limits
+func (q Quota) IsValid() error {
+	if q.MaxConcurrentJobs < 0 {
+		return fmt.Errorf("Invalid maximum concurrent jobs %d", q.MaxConcurrentJobs)
+	}
+	if q.MaxDailyWorkerMinutes < 0 {
+		return fmt.Errorf("Invalid maximum daily worker minutes %d", q.MaxDailyWorkerMinutes)
+	}
+	return nil
+}
+
+// QuotaUsage is the current consumption of a quota. Worker minutes are computed over the last 24 hours
+type QuotaUsage struct {
+	Quota
+	ConcurrentJobs     int   `json:"concurrent_jobs" cli:"concurrent_jobs"`
+	DailyWorkerMinutes int64 `json:"daily_worker_minutes" cli:"daily_worker_minutes"`
+}
+
+// Exceeded returns true if no more job can be started on the quota
+func (u *QuotaUsage) Exceeded() bool {
+	if u == nil {
+		return false
+	}
+	if u.MaxConcurrentJobs > 0 && u.ConcurrentJobs >= u.MaxConcurrentJobs {
+		return true
+	}
+	if u.MaxDailyWorkerMinutes > 0 && u.DailyWorkerMinutes >= u.MaxDailyWorkerMinutes {
+		return true
+	}
+	return false
+}
@@ -1,5 +1,10 @@
 package sdk
 
+import (
+	"sort"
+	"strings"
+)
+
 const (
 	//BinaryRequirement refers to the need to a specific binary on host running the action
 	BinaryRequirement = "binary"
@@ -24,6 +29,51 @@ const (
 // RequirementsList is a list of requirement
 type RequirementList []Requirement
 
+// ParsePluginRequirement returns the plugin name and the pinned version of a plugin requirement value,
+// "plugin-name" or "plugin-name@version". The version is empty when the requirement is on the latest version
+func ParsePluginRequirement(value string) (string, string) {
+	return ParseActionName(value)
+}
+
+// ParseServiceRequirement returns the image and the environment variables of a service requirement value,
+// "image env_1=value env_2=value"
+func ParseServiceRequirement(value string) (string, []string) {
+	tuple := strings.Fields(value)
+	if len(tuple) == 0 {
+		return "", nil
+	}
+	return tuple[0], tuple[1:]
+}
+
+// ServiceHostParameterName returns the name of the job variable giving the host of a service, cds.service.<name>.host
+func ServiceHostParameterName(service string) string {
+	return "cds.service." + service + ".host"
+}
+
+// FormatServiceHosts formats the hosts of the services started by a hatchery as "name=host,name=host",
+// it is given to the worker with the CDS_SERVICES variable
+func FormatServiceHosts(hosts map[string]string) string {
+	services := make([]string, 0, len(hosts))
+	for name, host := range hosts {
+		services = append(services, name+"="+host)
+	}
+	sort.Strings(services)
+	return strings.Join(services, ",")
+}
+
+// ParseServiceHosts parses the hosts of the services formatted by FormatServiceHosts
+func ParseServiceHosts(value string) map[string]string {
+	hosts := map[string]string{}
+	for _, s := range strings.Split(value, ",") {
+		tuple := strings.SplitN(s, "=", 2)
+		if len(tuple) != 2 || tuple[0] == "" || tuple[1] == "" {
+			continue
+		}
+		hosts[tuple[0]] = tuple[1]
+	}
+	return hosts
+}
+
 // Values returns all Requirement.Value
 func (l RequirementList) Values() []string {
 	values := make([]string, len(l))
@@ -0,0 +1,28 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceRequirement(t *testing.T) {
+	img, env := ParseServiceRequirement("postgres:9.6 POSTGRES_USER=cds  POSTGRES_PASSWORD=cds")
+	assert.Equal(t, "postgres:9.6", img)
+	assert.Equal(t, []string{"POSTGRES_USER=cds", "POSTGRES_PASSWORD=cds"}, env)
+
+	img, env = ParseServiceRequirement("redis")
+	assert.Equal(t, "redis", img)
+	assert.Empty(t, env)
+}
+
+func TestServiceHosts(t *testing.T) {
+	hosts := map[string]string{"pg": "172.17.0.2", "redis": "172.17.0.3"}
+	value := FormatServiceHosts(hosts)
+	assert.Equal(t, "pg=172.17.0.2,redis=172.17.0.3", value)
+	assert.Equal(t, hosts, ParseServiceHosts(value))
+
+	// the virtual machine hatcheries append the services to the variable
+	assert.Equal(t, map[string]string{"pg": "127.0.0.1"}, ParseServiceHosts(",pg=127.0.0.1"))
+	assert.Empty(t, ParseServiceHosts(""))
+}
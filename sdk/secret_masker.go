@@ -0,0 +1,164 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SecretMinLength is the minimal length of the secret values masked in the logs, the shorter values would mask
+// too much of the logs
+const SecretMinLength = 6
+
+// SecretMasker masks in the logs the values of secret variables, as well as their base64 and URL encoded forms.
+// Each form of a value is replaced by **name** where name is the name of the variable
+type SecretMasker struct {
+	replacer *strings.Replacer
+	forms    []string
+}
+
+// NewSecretMasker returns a masker of the values of the variables
+func NewSecretMasker(secrets []Variable) *SecretMasker {
+	type secretForm struct {
+		value, name string
+	}
+
+	var forms []secretForm
+	known := map[string]bool{}
+	for _, s := range secrets {
+		if len(s.Value) < SecretMinLength {
+			continue
+		}
+		for _, f := range secretForms(s.Value) {
+			if len(f) < SecretMinLength || known[f] {
+				continue
+			}
+			known[f] = true
+			forms = append(forms, secretForm{value: f, name: s.Name})
+		}
+	}
+
+	// The replacer tries the values in the order of its arguments, the longest values go first so that a value
+	// containing another one is masked as a whole
+	sort.Slice(forms, func(i, j int) bool {
+		if len(forms[i].value) != len(forms[j].value) {
+			return len(forms[i].value) > len(forms[j].value)
+		}
+		return forms[i].value < forms[j].value
+	})
+
+	m := &SecretMasker{}
+	if len(forms) == 0 {
+		return m
+	}
+	oldnew := make([]string, 0, 2*len(forms))
+	for _, f := range forms {
+		oldnew = append(oldnew, f.value, "**"+f.name+"**")
+		m.forms = append(m.forms, f.value)
+	}
+	m.replacer = strings.NewReplacer(oldnew...)
+	return m
+}
+
+// secretForms returns the value with its URL encoded forms and its base64 encoded forms
+func secretForms(value string) []string {
+	forms := []string{value, url.QueryEscape(value), url.PathEscape(value)}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding} {
+		// the value may be encoded with other data, as user:password in a basic authentication header
+		for offset := 0; offset < 3; offset++ {
+			forms = append(forms, base64Form(enc, value, offset))
+		}
+	}
+	return forms
+}
+
+// base64Form returns the part of the base64 encoding of a value which does not depend on the data around it,
+// when the value starts at offset bytes of a group of 3 bytes of the encoded data
+func base64Form(enc *base64.Encoding, value string, offset int) string {
+	s := enc.EncodeToString(append(make([]byte, offset), value...))
+	// each character encodes 6 bits
+	start := (offset*8 + 5) / 6
+	end := (offset + len(value)) * 8 / 6
+	return s[start:end]
+}
+
+// Mask replaces the secrets of a string
+func (m *SecretMasker) Mask(s string) string {
+	if m == nil || m.replacer == nil {
+		return s
+	}
+	return m.replacer.Replace(s)
+}
+
+// partialSuffix returns the length of the longest end of a string which is the beginning of a secret
+func (m *SecretMasker) partialSuffix(s string) int {
+	var n int
+	for _, f := range m.forms {
+		// the end of s is at most the secret without its last character
+		from := len(s) - len(f) + 1
+		if from < 0 {
+			from = 0
+		}
+		// a longer end than the current one starts before len(s) - n
+		for i := from; i < len(s)-n; i++ {
+			j := strings.IndexByte(s[i:len(s)-n], f[0])
+			if j < 0 {
+				break
+			}
+			i += j
+			if strings.HasPrefix(f, s[i:]) {
+				n = len(s) - i
+				break
+			}
+		}
+	}
+	return n
+}
+
+// NewLogMasker returns a masker of the secrets in the successive chunks of a log
+func (m *SecretMasker) NewLogMasker() *LogMasker {
+	return &LogMasker{masker: m}
+}
+
+// ResumeLogMasker returns a masker of the secrets in the next chunks of a log, pending is the end of the previous
+// chunk kept by Mask, as returned by Remainder
+func (m *SecretMasker) ResumeLogMasker(pending string) *LogMasker {
+	return &LogMasker{masker: m, pending: pending}
+}
+
+// LogMasker masks the secrets in the successive chunks of a log. The end of a chunk which could be the beginning
+// of a secret is kept until the next chunk, so that a secret split across chunks is masked too
+type LogMasker struct {
+	masker  *SecretMasker
+	pending string
+}
+
+// Mask returns a masked chunk, preceded by the end of the previous chunk and without its end which could be the
+// beginning of a secret
+func (l *LogMasker) Mask(chunk string) string {
+	if l.masker == nil || l.masker.replacer == nil {
+		return chunk
+	}
+	s := l.masker.Mask(l.pending + chunk)
+	n := l.masker.partialSuffix(s)
+	l.pending = s[len(s)-n:]
+	return s[:len(s)-n]
+}
+
+// Flush returns the end of the last chunk kept by Mask, at the end of the log
+func (l *LogMasker) Flush() string {
+	s := l.pending
+	l.pending = ""
+	return s
+}
+
+// Pending returns true if the end of the last chunk is kept by Mask
+func (l *LogMasker) Pending() bool {
+	return l.pending != ""
+}
+
+// Remainder returns the end of the last chunk kept by Mask, without flushing it
+func (l *LogMasker) Remainder() string {
+	return l.pending
+}
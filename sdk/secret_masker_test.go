@@ -0,0 +1,141 @@
+package sdk
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSecrets = []Variable{
+	{Name: "cds.proj.password", Value: "my$ecret pa55word!", Type: SecretVariable},
+	{Name: "cds.app.token", Value: "abcdef0123456789", Type: SecretVariable},
+	{Name: "cds.env.short", Value: "12345", Type: SecretVariable},
+}
+
+func TestSecretMaskerMask(t *testing.T) {
+	m := NewSecretMasker(testSecrets)
+
+	tests := []struct {
+		name, log, want string
+	}{
+		{
+			name: "clear value",
+			log:  "the password is my$ecret pa55word!\n",
+			want: "the password is **cds.proj.password**\n",
+		},
+		{
+			name: "several values",
+			log:  "abcdef0123456789:my$ecret pa55word!",
+			want: "**cds.app.token**:**cds.proj.password**",
+		},
+		{
+			name: "base64 value with padding",
+			log:  "echo " + base64.StdEncoding.EncodeToString([]byte("abcdef0123456789")),
+			want: "echo **cds.app.token**Q==",
+		},
+		{
+			name: "query escaped value",
+			log:  "curl https://host/?p=" + url.QueryEscape("my$ecret pa55word!"),
+			want: "curl https://host/?p=**cds.proj.password**",
+		},
+		{
+			name: "path escaped value",
+			log:  "curl https://host/" + url.PathEscape("my$ecret pa55word!"),
+			want: "curl https://host/**cds.proj.password**",
+		},
+		{
+			name: "base64 value",
+			log:  "echo " + base64.StdEncoding.EncodeToString([]byte("my$ecret pa55word!")),
+			want: "echo **cds.proj.password**",
+		},
+		{
+			name: "base64 url value",
+			log:  base64.URLEncoding.EncodeToString([]byte("my$ecret pa55word!")),
+			want: "**cds.proj.password**",
+		},
+		{
+			name: "short value",
+			log:  "12345",
+			want: "12345",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.Mask(tt.log), tt.name)
+	}
+}
+
+func TestSecretMaskerMaskEncodedWithOtherData(t *testing.T) {
+	m := NewSecretMasker(testSecrets)
+	for _, user := range []string{"a", "ab", "abc"} {
+		header := base64.StdEncoding.EncodeToString([]byte(user + ":abcdef0123456789"))
+		masked := m.Mask("Authorization: Basic " + header)
+		assert.Contains(t, masked, "**cds.app.token**", user)
+		assert.NotContains(t, masked, header[4:len(header)-4], user)
+	}
+}
+
+func TestLogMaskerSplitSecret(t *testing.T) {
+	value := "my$ecret pa55word!"
+	encoded := base64.StdEncoding.EncodeToString([]byte(value))
+	logs := []string{
+		"begin\nthe password is " + value + "\n",
+		"begin\nthe encoded password is " + encoded + "\n",
+		"begin\n" + value + value + "\nend\n",
+	}
+
+	for _, l := range logs {
+		want := NewSecretMasker(testSecrets).Mask(l)
+		// the log is sent by chunks of any size
+		for size := 1; size <= len(l); size++ {
+			lm := NewSecretMasker(testSecrets).NewLogMasker()
+			var got string
+			for i := 0; i < len(l); i += size {
+				end := i + size
+				if end > len(l) {
+					end = len(l)
+				}
+				got += lm.Mask(l[i:end])
+				assert.NotContains(t, got, "pa55", "chunk size %d", size)
+			}
+			got += lm.Flush()
+			assert.Equal(t, want, got, "chunk size %d", size)
+			assert.False(t, lm.Pending())
+		}
+	}
+}
+
+func TestLogMaskerKeepsOnlyTheBeginningOfASecret(t *testing.T) {
+	lm := NewSecretMasker(testSecrets).NewLogMasker()
+	assert.Equal(t, "line\nthe password is ", lm.Mask("line\nthe password is my$ecret"))
+	assert.True(t, lm.Pending())
+	assert.Equal(t, "my$ecret and not the password\n", lm.Mask(" and not the password\n"))
+	assert.False(t, lm.Pending())
+
+	assert.Equal(t, "a line ending with the beginning of a secret my$e", lm.Mask("a line ending with the beginning of a secret my$e")+lm.Flush())
+}
+
+func TestSecretMaskerWithoutSecret(t *testing.T) {
+	m := NewSecretMasker(nil)
+	assert.Equal(t, "nothing to mask", m.Mask("nothing to mask"))
+	lm := m.NewLogMasker()
+	assert.Equal(t, "nothing to mask", lm.Mask("nothing to mask"))
+	assert.Equal(t, "", lm.Flush())
+
+	var nilMasker *SecretMasker
+	assert.Equal(t, "nothing to mask", nilMasker.Mask("nothing to mask"))
+	assert.Equal(t, "nothing to mask", nilMasker.NewLogMasker().Mask("nothing to mask"))
+}
+
+func TestResumeLogMasker(t *testing.T) {
+	m := NewSecretMasker(testSecrets)
+	lm := m.NewLogMasker()
+	assert.Equal(t, "the password is ", lm.Mask("the password is my$e"))
+	assert.True(t, lm.Pending())
+
+	// the end of the chunk is kept elsewhere and the masking goes on with a new masker
+	resumed := m.ResumeLogMasker(lm.Remainder())
+	assert.Equal(t, "**cds.proj.password**\n", resumed.Mask("cret pa55word!\n"))
+	assert.False(t, resumed.Pending())
+}
@@ -1,6 +1,9 @@
 package sdk
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Variable represent a variable for a project or pipeline
 type Variable struct {
@@ -27,6 +30,7 @@ const (
 	BooleanVariable    = "boolean"
 	NumberVariable     = "number"
 	RepositoryVariable = "repository"
+	VaultVariable      = "vault"
 )
 
 var (
@@ -38,9 +42,29 @@ var (
 		KeyVariable,
 		BooleanVariable,
 		NumberVariable,
+		VaultVariable,
 	}
 )
 
+// IsValid checks the value of a variable given its type
+func (v Variable) IsValid() error {
+	if v.Type == VaultVariable {
+		if _, _, err := ParseVaultReference(v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseVaultReference splits the value of a vault variable, formatted as path#field, into the path of the secret and its field
+func ParseVaultReference(ref string) (string, string, error) {
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", "", ErrInvalidVaultReference
+	}
+	return strings.Trim(ref[:i], "/"), ref[i+1:], nil
+}
+
 // NeedPlaceholder returns true if variable type is either secret or key
 func NeedPlaceholder(t string) bool {
 	switch t {
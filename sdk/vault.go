@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+// Authentication methods used by a project on its vault
+const (
+	VaultAuthToken   = "token"
+	VaultAuthAppRole = "approle"
+)
+
+// ProjectVault is the vault used to resolve the vault variables of a project when a job is taken by a worker
+type ProjectVault struct {
+	ProjectID  int64  `json:"-" cli:"-"`
+	Address    string `json:"address" cli:"address"`
+	AuthMethod string `json:"auth_method" cli:"auth_method"`
+	Token      string `json:"token,omitempty" cli:"-"`
+	RoleID     string `json:"role_id,omitempty" cli:"role_id"`
+	SecretID   string `json:"secret_id,omitempty" cli:"-"`
+}
+
+// IsValid checks the vault configuration
+func (v ProjectVault) IsValid() error {
+	if v.Address == "" {
+		return fmt.Errorf("Invalid vault address")
+	}
+	switch v.AuthMethod {
+	case VaultAuthToken:
+		if v.Token == "" {
+			return fmt.Errorf("Invalid vault configuration: token is mandatory")
+		}
+	case VaultAuthAppRole:
+		if v.RoleID == "" || v.SecretID == "" {
+			return fmt.Errorf("Invalid vault configuration: role_id and secret_id are mandatory")
+		}
+	default:
+		return fmt.Errorf("Invalid vault authentication method %s. It must be %s or %s", v.AuthMethod, VaultAuthToken, VaultAuthAppRole)
+	}
+	return nil
+}
+
+// Credential returns the secret used to authenticate on the vault
+func (v ProjectVault) Credential() string {
+	if v.AuthMethod == VaultAuthAppRole {
+		return v.SecretID
+	}
+	return v.Token
+}
+
+// SetCredential sets the secret used to authenticate on the vault
+func (v *ProjectVault) SetCredential(s string) {
+	if v.AuthMethod == VaultAuthAppRole {
+		v.SecretID = s
+		return
+	}
+	v.Token = s
+}
@@ -7,8 +7,8 @@ import (
 
 // Warning contains information about user action configuration
 type Warning struct {
-	ID           int64             `json:"id"`
-	Message      string            `json:"message"`
+	ID           int64             `json:"id" cli:"id"`
+	Message      string            `json:"message" cli:"message"`
 	MessageParam map[string]string `json:"message_param"`
 
 	Action      Action      `json:"action"`
@@ -10,22 +10,23 @@ import (
 
 //Workflow represents a pipeline based workflow
 type Workflow struct {
-	ID            int64                  `json:"id" db:"id" cli:"-"`
-	Name          string                 `json:"name" db:"name" cli:"name,key"`
-	Description   string                 `json:"description,omitempty" db:"description" cli:"description"`
-	LastModified  time.Time              `json:"last_modified" db:"last_modified"`
-	ProjectID     int64                  `json:"project_id,omitempty" db:"project_id" cli:"-"`
-	ProjectKey    string                 `json:"project_key" db:"-" cli:"-"`
-	RootID        int64                  `json:"root_id,omitempty" db:"root_node_id" cli:"-"`
-	Root          *WorkflowNode          `json:"root" db:"-" cli:"-"`
-	Joins         []WorkflowNodeJoin     `json:"joins,omitempty" db:"-" cli:"-"`
-	Groups        []GroupPermission      `json:"groups,omitempty" db:"-" cli:"-"`
-	Permission    int                    `json:"permission,omitempty" db:"-" cli:"-"`
-	Metadata      Metadata               `json:"metadata" yaml:"metadata" db:"-"`
-	Usage         *Usage                 `json:"usage,omitempty" db:"-" cli:"-"`
-	HistoryLength int64                  `json:"history_length" db:"history_length" cli:"-"`
-	PurgeTags     []string               `json:"purge_tags,omitempty" db:"-" cli:"-"`
-	Notifications []WorkflowNotification `json:"notifications,omitempty" db:"-" cli:"-"`
+	ID            int64                    `json:"id" db:"id" cli:"-"`
+	Name          string                   `json:"name" db:"name" cli:"name,key"`
+	Description   string                   `json:"description,omitempty" db:"description" cli:"description"`
+	LastModified  time.Time                `json:"last_modified" db:"last_modified"`
+	ProjectID     int64                    `json:"project_id,omitempty" db:"project_id" cli:"-"`
+	ProjectKey    string                   `json:"project_key" db:"-" cli:"-"`
+	RootID        int64                    `json:"root_id,omitempty" db:"root_node_id" cli:"-"`
+	Root          *WorkflowNode            `json:"root" db:"-" cli:"-"`
+	Joins         []WorkflowNodeJoin       `json:"joins,omitempty" db:"-" cli:"-"`
+	Groups        []GroupPermission        `json:"groups,omitempty" db:"-" cli:"-"`
+	Permission    int                      `json:"permission,omitempty" db:"-" cli:"-"`
+	Metadata      Metadata                 `json:"metadata" yaml:"metadata" db:"-"`
+	Usage         *Usage                   `json:"usage,omitempty" db:"-" cli:"-"`
+	HistoryLength int64                    `json:"history_length" db:"history_length" cli:"-"`
+	PurgeTags     []string                 `json:"purge_tags,omitempty" db:"-" cli:"-"`
+	Notifications []WorkflowNotification   `json:"notifications,omitempty" db:"-" cli:"-"`
+	RunParameters WorkflowParametersSchema `json:"run_parameters,omitempty" db:"-" cli:"-"`
 }
 
 // WorkflowNotification represents notifications on a workflow
@@ -680,16 +681,18 @@ type WorkflowNodeCondition struct {
 
 //WorkflowNodeContext represents a context attached on a node
 type WorkflowNodeContext struct {
-	ID                        int64                  `json:"id" db:"id"`
-	WorkflowNodeID            int64                  `json:"workflow_node_id" db:"workflow_node_id"`
-	ApplicationID             int64                  `json:"application_id" db:"application_id"`
-	Application               *Application           `json:"application,omitempty" db:"-"`
-	Environment               *Environment           `json:"environment,omitempty" db:"-"`
-	EnvironmentID             int64                  `json:"environment_id" db:"environment_id"`
-	DefaultPayload            interface{}            `json:"default_payload,omitempty" db:"-"`
-	DefaultPipelineParameters []Parameter            `json:"default_pipeline_parameters,omitempty" db:"-"`
-	Conditions                WorkflowNodeConditions `json:"conditions,omitempty" db:"-"`
-	Mutex                     bool                   `json:"mutex"`
+	ID                        int64                     `json:"id" db:"id"`
+	WorkflowNodeID            int64                     `json:"workflow_node_id" db:"workflow_node_id"`
+	ApplicationID             int64                     `json:"application_id" db:"application_id"`
+	Application               *Application              `json:"application,omitempty" db:"-"`
+	Environment               *Environment              `json:"environment,omitempty" db:"-"`
+	EnvironmentID             int64                     `json:"environment_id" db:"environment_id"`
+	DefaultPayload            interface{}               `json:"default_payload,omitempty" db:"-"`
+	DefaultPipelineParameters []Parameter               `json:"default_pipeline_parameters,omitempty" db:"-"`
+	Conditions                WorkflowNodeConditions    `json:"conditions,omitempty" db:"-"`
+	Mutex                     bool                      `json:"mutex"`
+	Approval                  *WorkflowNodeApprovalRule `json:"approval,omitempty" db:"-"`
+	Concurrency               *WorkflowNodeConcurrency  `json:"concurrency,omitempty" db:"-"`
 }
 
 // HasDefaultPayload returns true if the node has a default payload
@@ -0,0 +1,142 @@
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Workflow node approval status
+const (
+	WorkflowNodeApprovalWaiting  = "Waiting"
+	WorkflowNodeApprovalApproved = "Approved"
+	WorkflowNodeApprovalRejected = "Rejected"
+	WorkflowNodeApprovalExpired  = "Expired"
+)
+
+// WorkflowNodeApprovalRule describes the approvals needed before running a workflow node
+type WorkflowNodeApprovalRule struct {
+	Groups       []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	MinApprovals int      `json:"min_approvals,omitempty" yaml:"min_approvals,omitempty"`
+	Expiration   string   `json:"expiration,omitempty" yaml:"expiration,omitempty"`
+}
+
+// IsValid checks the approval rule
+func (r *WorkflowNodeApprovalRule) IsValid() error {
+	if r == nil {
+		return nil
+	}
+	if r.MinApprovals < 0 {
+		return fmt.Errorf("Invalid minimum approvals count %d", r.MinApprovals)
+	}
+	if r.Expiration != "" {
+		d, err := time.ParseDuration(r.Expiration)
+		if err != nil {
+			return fmt.Errorf("Invalid approval expiration %s: %v", r.Expiration, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("Invalid approval expiration %s", r.Expiration)
+		}
+	}
+	return nil
+}
+
+// Required returns the number of approvals needed
+func (r *WorkflowNodeApprovalRule) Required() int {
+	if r.MinApprovals < 1 {
+		return 1
+	}
+	return r.MinApprovals
+}
+
+// ExpireAt returns the expiration date of an approval request created at the given date
+func (r *WorkflowNodeApprovalRule) ExpireAt(created time.Time) *time.Time {
+	if r.Expiration == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(r.Expiration)
+	if err != nil {
+		return nil
+	}
+	t := created.Add(d)
+	return &t
+}
+
+// CanBeApprovedBy returns true if the user is allowed to approve or reject: the user is an admin or a member of one
+// of the groups of the rule. Nobody but the admins can approve with a rule without groups
+func (r *WorkflowNodeApprovalRule) CanBeApprovedBy(u *User) bool {
+	if u.Admin {
+		return true
+	}
+	for _, g := range u.Groups {
+		for _, name := range r.Groups {
+			if g.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WorkflowNodeApproval is an approval request on a workflow node for a workflow run
+type WorkflowNodeApproval struct {
+	ID               int64                          `json:"id" db:"id"`
+	WorkflowRunID    int64                          `json:"workflow_run_id" db:"workflow_run_id"`
+	WorkflowNodeID   int64                          `json:"workflow_node_id" db:"workflow_node_id"`
+	WorkflowNodeName string                         `json:"workflow_node_name" db:"workflow_node_name"`
+	SubNumber        int64                          `json:"subnumber" db:"sub_num"`
+	Status           string                         `json:"status" db:"status"`
+	Created          time.Time                      `json:"created" db:"created"`
+	ExpireAt         *time.Time                     `json:"expire_at,omitempty" db:"expire_at"`
+	Rule             WorkflowNodeApprovalRule       `json:"rule" db:"-"`
+	SourceNodeRuns   []int64                        `json:"source_node_runs,omitempty" db:"-"`
+	HookEvent        *WorkflowNodeRunHookEvent      `json:"hook_event,omitempty" db:"-"`
+	Manual           *WorkflowNodeRunManual         `json:"manual,omitempty" db:"-"`
+	Decisions        []WorkflowNodeApprovalDecision `json:"decisions,omitempty" db:"-"`
+}
+
+// WorkflowNodeApprovalDecision is an approval or a rejection of an approval request
+type WorkflowNodeApprovalDecision struct {
+	ID         int64     `json:"id" db:"id"`
+	ApprovalID int64     `json:"approval_id" db:"workflow_node_approval_id"`
+	Username   string    `json:"username" db:"username"`
+	Approved   bool      `json:"approved" db:"approved"`
+	Comment    string    `json:"comment,omitempty" db:"comment"`
+	Date       time.Time `json:"date" db:"date"`
+}
+
+// Approvals returns the number of approvals
+func (a *WorkflowNodeApproval) Approvals() int {
+	var n int
+	for _, d := range a.Decisions {
+		if d.Approved {
+			n++
+		}
+	}
+	return n
+}
+
+// IsExpired returns true if the approval request is expired
+func (a *WorkflowNodeApproval) IsExpired() bool {
+	return a.ExpireAt != nil && time.Now().After(*a.ExpireAt)
+}
+
+// Decide records a decision and computes the new status of the approval request
+func (a *WorkflowNodeApproval) Decide(d WorkflowNodeApprovalDecision) error {
+	if a.Status != WorkflowNodeApprovalWaiting {
+		return NewError(ErrWorkflowNodeApprovalClosed, fmt.Errorf("approval request is %s", a.Status))
+	}
+	for _, previous := range a.Decisions {
+		if previous.Username == d.Username {
+			return NewError(ErrWorkflowNodeApprovalClosed, fmt.Errorf("%s has already decided", d.Username))
+		}
+	}
+
+	a.Decisions = append(a.Decisions, d)
+	switch {
+	case !d.Approved:
+		a.Status = WorkflowNodeApprovalRejected
+	case a.Approvals() >= a.Rule.Required():
+		a.Status = WorkflowNodeApprovalApproved
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowNodeApprovalRule_IsValid(t *testing.T) {
+	var nilRule *WorkflowNodeApprovalRule
+	assert.NoError(t, nilRule.IsValid())
+	assert.NoError(t, (&WorkflowNodeApprovalRule{Groups: []string{"ops"}, MinApprovals: 2, Expiration: "24h"}).IsValid())
+	assert.Error(t, (&WorkflowNodeApprovalRule{MinApprovals: -1}).IsValid())
+	assert.Error(t, (&WorkflowNodeApprovalRule{Expiration: "tomorrow"}).IsValid())
+	assert.Error(t, (&WorkflowNodeApprovalRule{Expiration: "-1h"}).IsValid())
+}
+
+func TestWorkflowNodeApprovalRule_CanBeApprovedBy(t *testing.T) {
+	rule := WorkflowNodeApprovalRule{Groups: []string{"ops"}}
+	assert.True(t, rule.CanBeApprovedBy(&User{Username: "john", Groups: []Group{{Name: "ops"}}}))
+	assert.True(t, rule.CanBeApprovedBy(&User{Username: "admin", Admin: true}))
+	assert.False(t, rule.CanBeApprovedBy(&User{Username: "jane", Groups: []Group{{Name: "dev"}}}))
+	assert.False(t, (&WorkflowNodeApprovalRule{}).CanBeApprovedBy(&User{Username: "jane"}))
+	assert.True(t, (&WorkflowNodeApprovalRule{}).CanBeApprovedBy(&User{Username: "admin", Admin: true}))
+}
+
+func TestWorkflowNodeApproval_Decide(t *testing.T) {
+	a := WorkflowNodeApproval{
+		Status: WorkflowNodeApprovalWaiting,
+		Rule:   WorkflowNodeApprovalRule{MinApprovals: 2},
+	}
+
+	assert.NoError(t, a.Decide(WorkflowNodeApprovalDecision{Username: "john", Approved: true}))
+	assert.Equal(t, WorkflowNodeApprovalWaiting, a.Status)
+
+	assert.Error(t, a.Decide(WorkflowNodeApprovalDecision{Username: "john", Approved: true}), "john has already decided")
+
+	assert.NoError(t, a.Decide(WorkflowNodeApprovalDecision{Username: "jane", Approved: true}))
+	assert.Equal(t, WorkflowNodeApprovalApproved, a.Status)
+	assert.Equal(t, 2, a.Approvals())
+
+	err := a.Decide(WorkflowNodeApprovalDecision{Username: "bob", Approved: false})
+	assert.Error(t, err, "approval request is closed")
+	assert.Equal(t, ErrWorkflowNodeApprovalClosed.ID, err.(Error).ID)
+
+	rejected := WorkflowNodeApproval{Status: WorkflowNodeApprovalWaiting}
+	assert.NoError(t, rejected.Decide(WorkflowNodeApprovalDecision{Username: "john", Approved: false}))
+	assert.Equal(t, WorkflowNodeApprovalRejected, rejected.Status)
+}
+
+func TestWorkflowNodeApproval_IsExpired(t *testing.T) {
+	created := time.Now().Add(-2 * time.Hour)
+	rule := WorkflowNodeApprovalRule{Expiration: "1h"}
+	a := WorkflowNodeApproval{Created: created, ExpireAt: rule.ExpireAt(created)}
+	assert.True(t, a.IsExpired())
+
+	a.ExpireAt = nil
+	assert.False(t, a.IsExpired())
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status of a workflow node run in a concurrency group
+const (
+	WorkflowNodeConcurrencyWaiting = "Waiting"
+	WorkflowNodeConcurrencyReady   = "Ready"
+	WorkflowNodeConcurrencyRunning = "Running"
+)
+
+// WorkflowNodeConcurrency attaches a workflow node to a named concurrency group shared across workflows and projects.
+// At most Max node runs holding the group are executed at once, the others are waiting in the group queue
+type WorkflowNodeConcurrency struct {
+	Group         string `json:"group" yaml:"group"`
+	Max           int    `json:"max,omitempty" yaml:"max,omitempty"`
+	CancelPending bool   `json:"cancel_pending,omitempty" yaml:"cancel_pending,omitempty"`
+}
+
+// IsValid checks the concurrency group definition
+func (c *WorkflowNodeConcurrency) IsValid() error {
+	if c == nil {
+		return nil
+	}
+	if !NamePatternRegex.MatchString(c.Group) {
+		return fmt.Errorf("Invalid concurrency group name %s. It should match %s", c.Group, NamePattern)
+	}
+	if c.Max < 0 {
+		return fmt.Errorf("Invalid maximum concurrency %d", c.Max)
+	}
+	return nil
+}
+
+// Limit returns the maximum number of node runs holding the group at once
+func (c *WorkflowNodeConcurrency) Limit() int {
+	if c.Max < 1 {
+		return 1
+	}
+	return c.Max
+}
+
+// WorkflowNodeRunConcurrency is a workflow node run holding or waiting for a concurrency group
+type WorkflowNodeRunConcurrency struct {
+	WorkflowNodeRunID int64     `json:"workflow_node_run_id" db:"workflow_node_run_id" cli:"-"`
+	Group             string    `json:"group" db:"group_name" cli:"group"`
+	Max               int       `json:"max" db:"max_running" cli:"-"`
+	Status            string    `json:"status" db:"status" cli:"status"`
+	Queued            time.Time `json:"queued" db:"queued" cli:"queued"`
+	ProjectKey        string    `json:"project_key" db:"-" cli:"project"`
+	WorkflowName      string    `json:"workflow_name" db:"-" cli:"workflow"`
+	Number            int64     `json:"num" db:"-" cli:"run"`
+	WorkflowNodeName  string    `json:"workflow_node_name" db:"-" cli:"node"`
+}
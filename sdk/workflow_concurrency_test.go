@@ -0,0 +1,20 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowNodeConcurrency_IsValid(t *testing.T) {
+	var nilConcurrency *WorkflowNodeConcurrency
+	assert.NoError(t, nilConcurrency.IsValid())
+	assert.NoError(t, (&WorkflowNodeConcurrency{Group: "deploy-prod", Max: 2}).IsValid())
+	assert.Error(t, (&WorkflowNodeConcurrency{Group: "deploy prod"}).IsValid())
+	assert.Error(t, (&WorkflowNodeConcurrency{Group: "deploy-prod", Max: -1}).IsValid())
+}
+
+func TestWorkflowNodeConcurrency_Limit(t *testing.T) {
+	assert.Equal(t, 1, (&WorkflowNodeConcurrency{Group: "deploy-prod"}).Limit())
+	assert.Equal(t, 3, (&WorkflowNodeConcurrency{Group: "deploy-prod", Max: 3}).Limit())
+}
@@ -88,6 +88,9 @@ type WorkflowRunInfo struct {
 	IsError     bool   `json:"is_error" db:"-"`
 }
 
+// WorkflowRunTagRelease is the tag of the workflow runs which made a release, its value is the tag of the release
+const WorkflowRunTagRelease = "cds.release"
+
 //WorkflowRunTag is a tag on workflow run
 type WorkflowRunTag struct {
 	WorkflowRunID int64  `json:"-" db:"workflow_run_id"`
@@ -114,6 +117,7 @@ type WorkflowNodeRun struct {
 	Payload            interface{}                      `json:"payload"`
 	PipelineParameters []Parameter                      `json:"pipeline_parameters"`
 	BuildParameters    []Parameter                      `json:"build_parameters"`
+	Outputs            []StepOutput                     `json:"outputs,omitempty"`
 	Artifacts          []WorkflowNodeRunArtifact        `json:"artifacts,omitempty"`
 	Tests              *venom.Tests                     `json:"tests,omitempty"`
 	Commits            []VCSCommit                      `json:"commits,omitempty"`
@@ -124,6 +128,45 @@ type WorkflowNodeRun struct {
 	CanBeRun           bool                             `json:"can_be_run"`
 }
 
+// StepOutput is a value exported by a named step of a job with the worker output command. It is available
+// in the next stages of the pipeline and in the next pipelines of the workflow as {{.cds.build.<step>.<name>}}
+type StepOutput struct {
+	Step  string `json:"step"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ParameterName returns the name of the build parameter of the output
+func (o StepOutput) ParameterName() string {
+	return "cds.build." + o.Step + "." + o.Name
+}
+
+// MergeStepOutputs returns the outputs with the new ones, a new output replaces the output of the same step and name
+func MergeStepOutputs(outputs []StepOutput, news ...StepOutput) []StepOutput {
+	res := make([]StepOutput, 0, len(outputs)+len(news))
+	res = append(res, outputs...)
+next:
+	for _, n := range news {
+		for i := range res {
+			if res[i].Step == n.Step && res[i].Name == n.Name {
+				res[i].Value = n.Value
+				continue next
+			}
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+// StepOutputsToParameters returns the outputs as build parameters
+func StepOutputsToParameters(outputs []StepOutput) []Parameter {
+	params := make([]Parameter, 0, len(outputs))
+	for _, o := range outputs {
+		params = append(params, Parameter{Name: o.ParameterName(), Type: StringParameter, Value: o.Value})
+	}
+	return params
+}
+
 // WorkflowNodeTriggerRun Represent the state of a trigger
 type WorkflowNodeTriggerRun struct {
 	WorkflowDestNodeID int64  `json:"workflow_dest_node_id" db:"-"`
@@ -141,19 +184,23 @@ func (nr *WorkflowNodeRun) Translate(lang string) {
 
 //WorkflowNodeRunArtifact represents tests list
 type WorkflowNodeRunArtifact struct {
-	WorkflowID        int64     `json:"workflow_id" db:"workflow_run_id"`
-	WorkflowNodeRunID int64     `json:"workflow_node_run_id" db:"workflow_node_run_id"`
-	ID                int64     `json:"id" db:"id"`
-	Name              string    `json:"name" db:"name"`
-	Tag               string    `json:"tag" db:"tag"`
-	DownloadHash      string    `json:"download_hash" db:"download_hash"`
-	Size              int64     `json:"size,omitempty" db:"size"`
-	Perm              uint32    `json:"perm,omitempty" db:"perm"`
-	MD5sum            string    `json:"md5sum,omitempty" db:"md5sum"`
-	ObjectPath        string    `json:"object_path,omitempty" db:"object_path"`
-	Created           time.Time `json:"created,omitempty" db:"created"`
-	TempURL           string    `json:"temp_url,omitempty" db:"-"`
-	TempURLSecretKey  string    `json:"-" db:"-"`
+	WorkflowID        int64             `json:"workflow_id" db:"workflow_run_id"`
+	WorkflowNodeRunID int64             `json:"workflow_node_run_id" db:"workflow_node_run_id"`
+	ID                int64             `json:"id" db:"id"`
+	Name              string            `json:"name" db:"name"`
+	Tag               string            `json:"tag" db:"tag"`
+	DownloadHash      string            `json:"download_hash" db:"download_hash"`
+	Size              int64             `json:"size,omitempty" db:"size"`
+	Perm              uint32            `json:"perm,omitempty" db:"perm"`
+	MD5sum            string            `json:"md5sum,omitempty" db:"md5sum"`
+	SHA256sum         string            `json:"sha256sum,omitempty" db:"sha256sum"`
+	ObjectPath        string            `json:"object_path,omitempty" db:"object_path"`
+	Ref               string            `json:"ref,omitempty" db:"ref"`
+	StorageNodeRunID  int64             `json:"storage_node_run_id,omitempty" db:"storage_node_run_id"`
+	Metadata          map[string]string `json:"metadata,omitempty" db:"-"`
+	Created           time.Time         `json:"created,omitempty" db:"created"`
+	TempURL           string            `json:"temp_url,omitempty" db:"-"`
+	TempURLSecretKey  string            `json:"-" db:"-"`
 }
 
 //WorkflowNodeJobRun represents an job to be run
@@ -171,6 +218,16 @@ type WorkflowNodeJobRun struct {
 	Model             string      `json:"model,omitempty" db:"model"`
 	BookedBy          Hatchery    `json:"bookedby" db:"-"`
 	SpawnInfos        []SpawnInfo `json:"spawninfos" db:"-"`
+	StopReason        string      `json:"stop_reason,omitempty" db:"-"`
+	StoppedBy         string      `json:"stopped_by,omitempty" db:"-"`
+}
+
+// WorkflowNodeJobRunStop is the signal sent to the worker running a job when the job is stopped, with the user who
+// stopped it and why
+type WorkflowNodeJobRunStop struct {
+	JobID     int64  `json:"job_id"`
+	Reason    string `json:"reason,omitempty"`
+	StoppedBy string `json:"stopped_by"`
 }
 
 //WorkflowNodeJobRunInfo represents info on a job
@@ -199,9 +256,17 @@ type WorkflowNodeRunHookEvent struct {
 
 //WorkflowNodeRunManual is an instanc of event received on a hook
 type WorkflowNodeRunManual struct {
-	Payload            interface{} `json:"payload" db:"-"`
-	PipelineParameters []Parameter `json:"pipeline_parameter" db:"-"`
-	User               User        `json:"user" db:"-"`
+	Payload            interface{}            `json:"payload" db:"-"`
+	PipelineParameters []Parameter            `json:"pipeline_parameter" db:"-"`
+	User               User                   `json:"user" db:"-"`
+	ResumeFrom         *WorkflowNodeRunResume `json:"resume_from,omitempty" db:"-"`
+}
+
+// WorkflowNodeRunResume resumes the last run of a node from its first failed stage, or from one of its jobs. The
+// successful jobs of the previous stages and of the resumed stage are not run again: their results, exported
+// variables and artifacts are reused
+type WorkflowNodeRunResume struct {
+	Job string `json:"job,omitempty"`
 }
 
 //GetName returns the name the artifact
@@ -209,10 +274,25 @@ func (a *WorkflowNodeRunArtifact) GetName() string {
 	return a.Name
 }
 
-//GetPath returns the path of the artifact
+//GetPath returns the path of the artifact, in the storage of the node run which uploaded it
 func (a *WorkflowNodeRunArtifact) GetPath() string {
-	container := fmt.Sprintf("%d-%d-%s", a.WorkflowID, a.WorkflowNodeRunID, a.Tag)
+	container := fmt.Sprintf("%d-%d-%s", a.WorkflowID, a.GetStorageNodeRunID(), a.Tag)
 	container = url.QueryEscape(container)
 	container = strings.Replace(container, "/", "-", -1)
 	return container
 }
+
+//GetStorageNodeRunID returns the id of the node run which uploaded the artifact. The artifacts reused by a
+//resumed node run are still stored with the node run which uploaded them
+func (a *WorkflowNodeRunArtifact) GetStorageNodeRunID() int64 {
+	if a.StorageNodeRunID != 0 {
+		return a.StorageNodeRunID
+	}
+	return a.WorkflowNodeRunID
+}
+
+//IsReference returns true if the artifact references an image pushed to a docker registry instead of a stored file.
+//Its content cannot be downloaded
+func (a *WorkflowNodeRunArtifact) IsReference() bool {
+	return a.Ref != ""
+}
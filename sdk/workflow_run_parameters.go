@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Workflow run parameter types
+const (
+	WorkflowRunParameterString  = "string"
+	WorkflowRunParameterNumber  = "number"
+	WorkflowRunParameterBoolean = "boolean"
+)
+
+// WorkflowRunParameterTypes lists all the supported types of workflow run parameters
+var WorkflowRunParameterTypes = []string{
+	WorkflowRunParameterString,
+	WorkflowRunParameterNumber,
+	WorkflowRunParameterBoolean,
+}
+
+// WorkflowParameterSchema describes a parameter expected when running a workflow
+type WorkflowParameterSchema struct {
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Regex       string   `json:"regex,omitempty" yaml:"regex,omitempty"`
+}
+
+// WorkflowParametersSchema is a list of workflow parameters definitions
+type WorkflowParametersSchema []WorkflowParameterSchema
+
+// IsValid checks the definition of the parameter
+func (s WorkflowParameterSchema) IsValid() error {
+	if !NamePatternRegex.MatchString(s.Name) {
+		return fmt.Errorf("Invalid parameter name %s. It should match %s", s.Name, NamePattern)
+	}
+
+	switch s.Type {
+	case "", WorkflowRunParameterString, WorkflowRunParameterNumber, WorkflowRunParameterBoolean:
+	default:
+		return fmt.Errorf("Invalid type %s for parameter %s. It should be one of %s", s.Type, s.Name, strings.Join(WorkflowRunParameterTypes, ", "))
+	}
+
+	if s.Regex != "" {
+		if _, err := regexp.Compile(s.Regex); err != nil {
+			return fmt.Errorf("Invalid regex for parameter %s: %v", s.Name, err)
+		}
+	}
+
+	for _, e := range s.Enum {
+		if err := s.checkType(e); err != nil {
+			return fmt.Errorf("Invalid enum value for parameter %s: %v", s.Name, err)
+		}
+	}
+
+	if s.Default != "" {
+		if err := s.Check(s.Default); err != nil {
+			return fmt.Errorf("Invalid default value for parameter %s: %v", s.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s WorkflowParameterSchema) checkType(value string) error {
+	switch s.Type {
+	case WorkflowRunParameterNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s is not a number", value)
+		}
+	case WorkflowRunParameterBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s is not a boolean", value)
+		}
+	}
+	return nil
+}
+
+// Check checks a value against the parameter definition
+func (s WorkflowParameterSchema) Check(value string) error {
+	if err := s.checkType(value); err != nil {
+		return err
+	}
+
+	if len(s.Enum) > 0 {
+		var found bool
+		for _, e := range s.Enum {
+			if e == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is not one of %s", value, strings.Join(s.Enum, ", "))
+		}
+	}
+
+	if s.Regex != "" {
+		rx, err := regexp.Compile(s.Regex)
+		if err != nil {
+			return err
+		}
+		if !rx.MatchString(value) {
+			return fmt.Errorf("%s does not match %s", value, s.Regex)
+		}
+	}
+
+	return nil
+}
+
+// IsValid checks all the parameters definitions
+func (schema WorkflowParametersSchema) IsValid() error {
+	mError := new(MultiError)
+	names := map[string]bool{}
+	for _, s := range schema {
+		if names[s.Name] {
+			mError.Append(fmt.Errorf("Duplicate parameter %s", s.Name))
+			continue
+		}
+		names[s.Name] = true
+		if err := s.IsValid(); err != nil {
+			mError.Append(err)
+		}
+	}
+	if mError.IsEmpty() {
+		return nil
+	}
+	return mError
+}
+
+// Check checks the values against the parameters definitions. It returns the values
+// which have to be added to the run because they are missing and have a default value
+func (schema WorkflowParametersSchema) Check(values map[string]string) (map[string]string, error) {
+	defaults := map[string]string{}
+	mError := new(MultiError)
+	for _, s := range schema {
+		v, ok := values[s.Name]
+		if !ok || v == "" {
+			if s.Default != "" {
+				defaults[s.Name] = s.Default
+				continue
+			}
+			if s.Required {
+				mError.Append(fmt.Errorf("Parameter %s is required", s.Name))
+			}
+			continue
+		}
+		if err := s.Check(v); err != nil {
+			mError.Append(fmt.Errorf("Invalid parameter %s: %v", s.Name, err))
+		}
+	}
+	if !mError.IsEmpty() {
+		return nil, NewError(ErrWorkflowRunParametersInvalid, mError)
+	}
+	return defaults, nil
+}
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkflowParametersSchema_IsValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  WorkflowParametersSchema
+		wantErr bool
+	}{
+		{
+			name: "valid schema",
+			schema: WorkflowParametersSchema{
+				{Name: "env", Type: WorkflowRunParameterString, Enum: []string{"dev", "prod"}, Default: "dev"},
+				{Name: "replicas", Type: WorkflowRunParameterNumber, Default: "3"},
+				{Name: "version", Regex: `^v[0-9]+\.[0-9]+$`, Required: true},
+			},
+		},
+		{
+			name:    "invalid name",
+			schema:  WorkflowParametersSchema{{Name: "my param"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			schema:  WorkflowParametersSchema{{Name: "param", Type: "date"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid regex",
+			schema:  WorkflowParametersSchema{{Name: "param", Regex: "[a-z"}},
+			wantErr: true,
+		},
+		{
+			name:    "default value not in enum",
+			schema:  WorkflowParametersSchema{{Name: "env", Enum: []string{"dev", "prod"}, Default: "preprod"}},
+			wantErr: true,
+		},
+		{
+			name:    "enum value with wrong type",
+			schema:  WorkflowParametersSchema{{Name: "replicas", Type: WorkflowRunParameterNumber, Enum: []string{"1", "two"}}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate parameter",
+			schema:  WorkflowParametersSchema{{Name: "env"}, {Name: "env"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.IsValid()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestWorkflowParametersSchema_Check(t *testing.T) {
+	schema := WorkflowParametersSchema{
+		{Name: "env", Enum: []string{"dev", "prod"}, Default: "dev"},
+		{Name: "replicas", Type: WorkflowRunParameterNumber},
+		{Name: "dry-run", Type: WorkflowRunParameterBoolean},
+		{Name: "version", Regex: `^v[0-9]+\.[0-9]+$`, Required: true},
+	}
+
+	defaults, err := schema.Check(map[string]string{"version": "v1.2", "replicas": "3", "dry-run": "true"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "dev"}, defaults)
+
+	_, err = schema.Check(map[string]string{"env": "prod"})
+	assert.Error(t, err, "version is required")
+
+	_, err = schema.Check(map[string]string{"version": "1.2"})
+	assert.Error(t, err, "version does not match the regex")
+
+	_, err = schema.Check(map[string]string{"version": "v1.2", "env": "preprod"})
+	assert.Error(t, err, "env is not in the enum")
+
+	_, err = schema.Check(map[string]string{"version": "v1.2", "replicas": "three"})
+	assert.Error(t, err, "replicas is not a number")
+
+	_, err = schema.Check(map[string]string{"version": "v1.2", "dry-run": "maybe"})
+	assert.Error(t, err, "dry-run is not a boolean")
+	assert.Equal(t, ErrWorkflowRunParametersInvalid.ID, err.(Error).ID)
+}
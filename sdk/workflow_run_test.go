@@ -0,0 +1,29 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeStepOutputs(t *testing.T) {
+	outputs := []StepOutput{
+		{Step: "build", Name: "version", Value: "1.0.0"},
+		{Step: "deploy", Name: "url", Value: "http://a"},
+	}
+	res := MergeStepOutputs(outputs,
+		StepOutput{Step: "deploy", Name: "url", Value: "http://b"},
+		StepOutput{Step: "test", Name: "url", Value: "http://c"},
+	)
+	assert.Equal(t, []StepOutput{
+		{Step: "build", Name: "version", Value: "1.0.0"},
+		{Step: "deploy", Name: "url", Value: "http://b"},
+		{Step: "test", Name: "url", Value: "http://c"},
+	}, res)
+	assert.Equal(t, "http://a", outputs[1].Value, "the given outputs must not be modified")
+
+	params := StepOutputsToParameters(res)
+	assert.Len(t, params, 3)
+	assert.Equal(t, "cds.build.deploy.url", params[1].Name)
+	assert.Equal(t, "http://b", params[1].Value)
+}